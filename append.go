@@ -0,0 +1,238 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"reflect"
+
+	"azul3d.org/lmath.v1"
+)
+
+// transformDir transforms the direction v by the upper 3x3 portion of the
+// matrix m (i.e. ignoring translation).
+func transformDir(v lmath.Vec3, m lmath.Mat4) lmath.Vec3 {
+	return lmath.Vec3{
+		v.X*m[0][0] + v.Y*m[1][0] + v.Z*m[2][0],
+		v.X*m[0][1] + v.Y*m[1][1] + v.Z*m[2][1],
+		v.X*m[0][2] + v.Y*m[1][2] + v.Z*m[2][2],
+	}
+}
+
+// mergeData returns the per-vertex data slice a, padded with zero values to
+// base elements, with the per-vertex data slice b (or n zero values, if b is
+// not valid) appended. Arrays of data (e.g. [][]gfx.Vec3) are merged per
+// array element.
+func mergeData(a, b reflect.Value, t reflect.Type, base, n int) reflect.Value {
+	if !a.IsValid() {
+		a = reflect.MakeSlice(t, 0, base+n)
+	}
+	if t.Elem().Kind() == reflect.Slice {
+		l := a.Len()
+		if b.IsValid() && b.Len() > l {
+			l = b.Len()
+		}
+		out := reflect.MakeSlice(t, l, l)
+		for i := 0; i < l; i++ {
+			var ai, bi reflect.Value
+			if i < a.Len() {
+				ai = a.Index(i)
+			}
+			if b.IsValid() && i < b.Len() {
+				bi = b.Index(i)
+			}
+			out.Index(i).Set(mergeData(ai, bi, t.Elem(), base, n))
+		}
+		return out
+	}
+	zero := reflect.Zero(t.Elem())
+	for a.Len() < base {
+		a = reflect.Append(a, zero)
+	}
+	if b.IsValid() {
+		return reflect.AppendSlice(a, b)
+	}
+	for i := 0; i < n; i++ {
+		a = reflect.Append(a, zero)
+	}
+	return a
+}
+
+// Append appends the vertex data of the other mesh, transformed by the given
+// matrix, to this mesh. It is typically used to pre-batch static geometry
+// (e.g. of a level) into fewer meshes, and thus fewer draw calls.
+//
+// The indices of the other mesh are rebased onto the vertices of this mesh. If
+// either mesh is indexed then the result is indexed (sequential indices are
+// generated for the non-indexed mesh). Meshes whose primitive is a strip,
+// fan, or loop must both use primitive restart, such that a RestartIndex can
+// be inserted between them.
+//
+// Data slices present in only one of the meshes are reconciled by padding the
+// other with default values: white for colors, and zero for barycentric
+// coordinates, texture coordinates, and custom attributes. The "Normal" and
+// "Tangent" custom attributes (see GenerateNormals and GenerateTangents) are
+// transformed and renormalized along with the vertices.
+//
+// All of the data slices of this mesh are marked as changed and it's bounding
+// box is recalculated.
+//
+// Append panics if the primitives of the meshes differ, if strip primitives
+// are used without primitive restart, or if a custom attribute of the same
+// name has a different type in each mesh.
+//
+// The mesh's write lock and the other mesh's read lock must be held for this
+// method to operate safely.
+func (m *Mesh) Append(other *Mesh, transform Mat4) {
+	if m.Primitive != other.Primitive {
+		panic(fmt.Sprintf("Append(): primitive %v differs from %v", other.Primitive, m.Primitive))
+	}
+	strip := m.Primitive != Triangles && m.Primitive != Lines && m.Primitive != Points
+	if strip && !(m.PrimitiveRestart && other.PrimitiveRestart) {
+		panic(fmt.Sprintf("Append(): %v primitive requires PrimitiveRestart", m.Primitive))
+	}
+	base, n := len(m.Vertices), len(other.Vertices)
+	mat := transform.Mat4()
+
+	// Rebase the indices.
+	if len(m.Indices) > 0 || len(other.Indices) > 0 || strip {
+		if len(m.Indices) == 0 {
+			for i := 0; i < base; i++ {
+				m.Indices = append(m.Indices, uint32(i))
+			}
+		}
+		if strip && len(m.Indices) > 0 {
+			m.Indices = append(m.Indices, RestartIndex)
+		}
+		if len(other.Indices) > 0 {
+			for _, idx := range other.Indices {
+				if idx != RestartIndex || !other.PrimitiveRestart {
+					idx += uint32(base)
+				}
+				m.Indices = append(m.Indices, idx)
+			}
+		} else {
+			for i := 0; i < n; i++ {
+				m.Indices = append(m.Indices, uint32(base+i))
+			}
+		}
+		m.IndicesChanged = true
+	}
+
+	// Transform the vertices.
+	for _, v := range other.Vertices {
+		m.Vertices = append(m.Vertices, ConvertVec3(v.Vec3().TransformMat4(mat)))
+	}
+	m.VerticesChanged = true
+
+	if len(m.Colors) > 0 || len(other.Colors) > 0 {
+		white := Color{1, 1, 1, 1}
+		for len(m.Colors) < base {
+			m.Colors = append(m.Colors, white)
+		}
+		if len(other.Colors) > 0 {
+			m.Colors = append(m.Colors, other.Colors...)
+		} else {
+			for i := 0; i < n; i++ {
+				m.Colors = append(m.Colors, white)
+			}
+		}
+		m.ColorsChanged = true
+	}
+
+	if len(m.Bary) > 0 || len(other.Bary) > 0 {
+		for len(m.Bary) < base {
+			m.Bary = append(m.Bary, Vec3{})
+		}
+		if len(other.Bary) > 0 {
+			m.Bary = append(m.Bary, other.Bary...)
+		} else {
+			m.Bary = append(m.Bary, make([]Vec3, n)...)
+		}
+		m.BaryChanged = true
+	}
+
+	for i := range other.TexCoords {
+		if i >= len(m.TexCoords) {
+			m.TexCoords = append(m.TexCoords, TexCoordSet{})
+		}
+	}
+	for i := range m.TexCoords {
+		set := &m.TexCoords[i]
+		for len(set.Slice) < base {
+			set.Slice = append(set.Slice, TexCoord{})
+		}
+		if i < len(other.TexCoords) {
+			set.Slice = append(set.Slice, other.TexCoords[i].Slice...)
+		} else {
+			set.Slice = append(set.Slice, make([]TexCoord, n)...)
+		}
+		set.Changed = true
+	}
+
+	// Transform the normals by the inverse transpose (which preserves their
+	// perpendicularity under non-uniform scaling) and the tangents by the
+	// matrix itself. Mirroring transforms flip the tangent space handedness.
+	inv, _ := mat.Inverse()
+	normalMat := inv.Transposed()
+	det := transformDir(lmath.Vec3{1, 0, 0}, mat).Dot(
+		transformDir(lmath.Vec3{0, 1, 0}, mat).Cross(transformDir(lmath.Vec3{0, 0, 1}, mat)),
+	)
+	otherAttribs := make(map[string]interface{}, len(other.Attribs))
+	for name, a := range other.Attribs {
+		otherAttribs[name] = a.Data
+	}
+	if normals, ok := otherAttribs["Normal"].([]Vec3); ok {
+		out := make([]Vec3, len(normals))
+		for i, nrm := range normals {
+			t, _ := transformDir(nrm.Vec3(), normalMat).Normalized()
+			out[i] = ConvertVec3(t)
+		}
+		otherAttribs["Normal"] = out
+	}
+	if tangents, ok := otherAttribs["Tangent"].([]Vec4); ok {
+		out := make([]Vec4, len(tangents))
+		for i, tan := range tangents {
+			t, _ := transformDir(lmath.Vec3{float64(tan.X), float64(tan.Y), float64(tan.Z)}, mat).Normalized()
+			w := tan.W
+			if det < 0 {
+				w = -w
+			}
+			out[i] = Vec4{float32(t.X), float32(t.Y), float32(t.Z), w}
+		}
+		otherAttribs["Tangent"] = out
+	}
+
+	// Merge the custom attributes.
+	if m.Attribs == nil && len(otherAttribs) > 0 {
+		m.Attribs = make(map[string]VertexAttrib)
+	}
+	for name := range otherAttribs {
+		if _, ok := m.Attribs[name]; !ok {
+			m.Attribs[name] = VertexAttrib{}
+		}
+	}
+	for name, a := range m.Attribs {
+		av, bv := reflect.ValueOf(a.Data), reflect.ValueOf(otherAttribs[name])
+		var t reflect.Type
+		switch {
+		case av.IsValid() && bv.IsValid() && av.Type() != bv.Type():
+			panic(fmt.Sprintf("Append(): attribute %q has type %v, want %v", name, bv.Type(), av.Type()))
+		case av.IsValid():
+			t = av.Type()
+		default:
+			t = bv.Type()
+		}
+		if t.Kind() != reflect.Slice {
+			continue
+		}
+		m.Attribs[name] = VertexAttrib{
+			Data:    mergeData(av, bv, t, base, n).Interface(),
+			Changed: true,
+		}
+	}
+
+	m.CalculateBounds()
+}
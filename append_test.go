@@ -0,0 +1,63 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestMeshAppend(t *testing.T) {
+	a := NewMesh()
+	a.Vertices = []Vec3{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}}
+	a.Colors = []Color{{1, 0, 0, 1}, {1, 0, 0, 1}, {1, 0, 0, 1}}
+
+	b := foldMesh()
+	b.Colors = nil
+	b.TexCoords = []TexCoordSet{{Slice: make([]TexCoord, 4)}}
+	b.GenerateNormals(180)
+
+	// Translate and mirror along X.
+	a.Append(b, Mat4{
+		{-1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{10, 0, 0, 1},
+	})
+
+	wantIndices := []uint32{0, 1, 2, 3, 5, 4, 3, 4, 6}
+	if !reflect.DeepEqual(a.Indices, wantIndices) {
+		t.Fatalf("got indices %v, want %v", a.Indices, wantIndices)
+	}
+	if len(a.Vertices) != 7 || a.Vertices[5] != (Vec3{9, 0, 0}) {
+		t.Fatalf("got vertices %v", a.Vertices)
+	}
+	if len(a.Colors) != 7 || a.Colors[6] != (Color{1, 1, 1, 1}) {
+		t.Fatalf("got colors %v", a.Colors)
+	}
+	if len(a.TexCoords) != 1 || len(a.TexCoords[0].Slice) != 7 {
+		t.Fatalf("got texture coordinates %v", a.TexCoords)
+	}
+	normals := a.Attribs["Normal"].Data.([]Vec3)
+	if len(normals) != 7 || !vec3Near(normals[5], Vec3{0, 0, 1}) || !vec3Near(normals[6], Vec3{-1, 0, 0}) {
+		t.Fatalf("got normals %v", normals)
+	}
+	if !a.HasChanged() {
+		t.Fatal("HasChanged = false after Append")
+	}
+}
+
+func TestMeshAppendMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic appending a different primitive")
+		}
+	}()
+	a, b := NewMesh(), NewMesh()
+	b.Primitive = Lines
+	a.Append(b, ConvertMat4(lmath.Mat4Identity))
+}
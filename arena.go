@@ -0,0 +1,119 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// FrameArena is a frame-scoped allocator for transient render data, such as
+// draw lists, sort keys, and uniform staging buffers. Slices are carved out of
+// large backing buffers which are reused each frame once Reset is called, so
+// that after the first few frames (once the buffers have grown large enough)
+// rendering a frame causes no garbage collector pressure:
+//
+//	arena := new(gfx.FrameArena)
+//	for {
+//	    drawList := arena.Objects(len(visible))
+//	    drawList = append(drawList, visible...)
+//	    sort.Sort(gfx.ByState(drawList))
+//	    ...
+//	    arena.Reset()
+//	}
+//
+// Slices returned by the arena have a length of zero and a capacity of the
+// requested size; appending beyond that capacity is safe (the slice is then
+// simply allocated elsewhere). Slices must not be used after the next call to
+// Reset.
+//
+// A FrameArena is not safe for concurrent use; typically each goroutine that
+// builds frame data has it's own arena.
+type FrameArena struct {
+	objects    []*Object
+	objectsOff int
+
+	keys    []uint64
+	keysOff int
+
+	floats    []float32
+	floatsOff int
+
+	bytes    []byte
+	bytesOff int
+}
+
+// arenaGrow returns the new capacity of a backing buffer of capacity c that
+// must fit at least n elements. Since the capacity at least doubles each time,
+// the buffer quickly grows to fit the data of an entire frame.
+func arenaGrow(c, n int) int {
+	c *= 2
+	if c < n {
+		c = n
+	}
+	if c < 64 {
+		c = 64
+	}
+	return c
+}
+
+// Objects returns a slice of objects (e.g. a draw list) with length zero and
+// capacity n.
+func (a *FrameArena) Objects(n int) []*Object {
+	if a.objectsOff+n > len(a.objects) {
+		// The old buffer is still referenced by slices handed out this frame,
+		// so it is simply abandoned to the garbage collector.
+		a.objects = make([]*Object, arenaGrow(len(a.objects), n))
+		a.objectsOff = 0
+	}
+	s := a.objects[a.objectsOff : a.objectsOff : a.objectsOff+n]
+	a.objectsOff += n
+	return s
+}
+
+// Keys returns a slice of sort keys with length zero and capacity n.
+func (a *FrameArena) Keys(n int) []uint64 {
+	if a.keysOff+n > len(a.keys) {
+		a.keys = make([]uint64, arenaGrow(len(a.keys), n))
+		a.keysOff = 0
+	}
+	s := a.keys[a.keysOff : a.keysOff : a.keysOff+n]
+	a.keysOff += n
+	return s
+}
+
+// Floats returns a slice of floating-point values (e.g. for staging uniform
+// data) with length zero and capacity n.
+func (a *FrameArena) Floats(n int) []float32 {
+	if a.floatsOff+n > len(a.floats) {
+		a.floats = make([]float32, arenaGrow(len(a.floats), n))
+		a.floatsOff = 0
+	}
+	s := a.floats[a.floatsOff : a.floatsOff : a.floatsOff+n]
+	a.floatsOff += n
+	return s
+}
+
+// Bytes returns a slice of bytes with length zero and capacity n.
+func (a *FrameArena) Bytes(n int) []byte {
+	if a.bytesOff+n > len(a.bytes) {
+		a.bytes = make([]byte, arenaGrow(len(a.bytes), n))
+		a.bytesOff = 0
+	}
+	s := a.bytes[a.bytesOff : a.bytesOff : a.bytesOff+n]
+	a.bytesOff += n
+	return s
+}
+
+// Reset makes all of the arena's memory available for reuse, typically at the
+// end of each frame. All slices previously returned by the arena become
+// invalid.
+//
+// Object pointers are cleared, such that the arena does not keep objects
+// from being garbage collected.
+func (a *FrameArena) Reset() {
+	for i := range a.objects[:a.objectsOff] {
+		a.objects[i] = nil
+	}
+	a.objectsOff = 0
+	a.keysOff = 0
+	a.floatsOff = 0
+	a.bytesOff = 0
+}
@@ -0,0 +1,38 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestFrameArenaNoOverlap(t *testing.T) {
+	a := new(FrameArena)
+	x := a.Keys(4)
+	y := a.Keys(4)
+	x = append(x, 1, 2, 3, 4, 5) // Exceeds capacity, must not touch y.
+	y = append(y, 9)
+	if x[0] != 1 || y[0] != 9 {
+		t.Fatalf("overlap: x=%v y=%v", x, y)
+	}
+}
+
+func TestFrameArenaSteadyState(t *testing.T) {
+	a := new(FrameArena)
+	objs := []*Object{NewObject(), NewObject()}
+	frame := func() {
+		list := a.Objects(len(objs))
+		list = append(list, objs...)
+		keys := a.Keys(len(list))
+		for range list {
+			keys = append(keys, 0)
+		}
+		a.Floats(16)
+		a.Bytes(256)
+		a.Reset()
+	}
+	frame()
+	if n := testing.AllocsPerRun(100, frame); n != 0 {
+		t.Fatalf("got %v allocations per frame, want 0", n)
+	}
+}
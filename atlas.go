@@ -0,0 +1,340 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// ErrAtlasTooLarge is returned by Atlas.Add when an image is larger than the
+// maximum size of the atlas.
+var ErrAtlasTooLarge = errors.New("atlas: image larger than maximum atlas size")
+
+type atlasEntry struct {
+	key     interface{}
+	rect    image.Rectangle
+	lastUse uint64
+}
+
+type atlasShelf struct {
+	y, height, x int
+}
+
+// Atlas is a dynamic texture atlas, typically used as a glyph cache by text
+// renderers, into which small images are packed on demand. It is safe for
+// applications displaying arbitrary text (e.g. chat) for long periods of
+// time:
+//  - When the atlas is full it grows (doubling in size) up to MaxSize.
+//  - When it is full at MaxSize, the least recently used half of the images
+//    are evicted. Evicted images are simply no longer found by Lookup, and
+//    should be rendered and added again as needed.
+//  - Images may be removed explicitly, which leaves unused space behind. Such
+//    fragmentation is reclaimed by Compact, typically called during idle
+//    frames (see CompactIdle).
+//
+// Each image is surrounded by transparent padding, such that mipmapping and
+// linear filtering do not bleed neighbouring images into each other.
+//
+// The atlas is not safe for concurrent use by multiple goroutines.
+type Atlas struct {
+	// The texture of the atlas, which should be loaded (see
+	// Renderer.LoadTexture) after images are added to it. Images added to an
+	// already loaded texture are queued as partial updates (see
+	// Texture.UpdateRect). When the atlas is rebuilt (see Generation) the
+	// pending updates are discarded instead, and the texture must be loaded
+	// again by the caller.
+	Texture *Texture
+
+	// The maximum width and height of the atlas.
+	MaxSize int
+
+	// The padding, in pixels, around each image.
+	Padding int
+
+	img        *image.RGBA
+	minSize    int
+	entries    map[interface{}]*atlasEntry
+	shelves    []atlasShelf
+	clock      uint64
+	generation int
+}
+
+// NewAtlas returns a new empty atlas with the given initial and maximum sizes
+// (the width and height of the atlas, typically powers of two), and a
+// padding of one pixel.
+func NewAtlas(size, maxSize int) *Atlas {
+	a := &Atlas{
+		Texture: NewTexture(),
+		MaxSize: maxSize,
+		Padding: 1,
+		minSize: size,
+		entries: make(map[interface{}]*atlasEntry),
+	}
+	a.Texture.KeepDataOnLoad = true
+	a.Texture.MinFilter = LinearMipmapLinear
+	a.Texture.MagFilter = Linear
+	a.Texture.WrapU = Clamp
+	a.Texture.WrapV = Clamp
+	a.reset(size)
+	return a
+}
+
+// Size returns the current width and height of the atlas.
+func (a *Atlas) Size() int {
+	return a.img.Bounds().Dx()
+}
+
+// Len returns the number of images in the atlas.
+func (a *Atlas) Len() int {
+	return len(a.entries)
+}
+
+// Generation returns a number which is incremented each time the atlas is
+// rebuilt (i.e. grows, evicts images, or is compacted), after which the rectangles of all
+// images may have changed and must be looked up again (e.g. cached texture
+// coordinates are invalid).
+func (a *Atlas) Generation() int {
+	return a.generation
+}
+
+// Lookup returns the rectangle of the image with the given key within the
+// atlas, marking it as recently used. If there is no such image (i.e. it was
+// never added or has been evicted) then ok is false.
+func (a *Atlas) Lookup(key interface{}) (r image.Rectangle, ok bool) {
+	e, ok := a.entries[key]
+	if !ok {
+		return image.Rectangle{}, false
+	}
+	a.clock++
+	e.lastUse = a.clock
+	return e.rect, true
+}
+
+// TexCoords returns the texture coordinates of the given rectangle of the
+// atlas (e.g. as returned by Lookup).
+func (a *Atlas) TexCoords(r image.Rectangle) (min, max TexCoord) {
+	s := float32(a.Size())
+	min = TexCoord{float32(r.Min.X) / s, float32(r.Min.Y) / s}
+	max = TexCoord{float32(r.Max.X) / s, float32(r.Max.Y) / s}
+	return
+}
+
+// Add adds the given image to the atlas under the given key (which must be
+// comparable, e.g. a struct holding a font, size, and rune), growing or
+// evicting images as needed, and returns it's rectangle within the atlas. If
+// an image with the key already exists, it's rectangle is returned.
+//
+// ErrAtlasTooLarge is returned if the image cannot fit even in an empty atlas
+// of the maximum size.
+//
+// If the atlas had to be rebuilt to make room for the image (i.e. the
+// generation changed, see Generation) then no partial update is queued, and
+// a loaded texture must be loaded again (see Renderer.LoadTexture).
+//
+// The texture's lock is acquired by this method.
+func (a *Atlas) Add(key interface{}, img image.Image) (image.Rectangle, error) {
+	if r, ok := a.Lookup(key); ok {
+		return r, nil
+	}
+	size := img.Bounds().Size()
+	if size.X+2*a.Padding > a.MaxSize || size.Y+2*a.Padding > a.MaxSize {
+		return image.Rectangle{}, ErrAtlasTooLarge
+	}
+	gen := a.generation
+	r, ok := a.pack(size)
+	for !ok {
+		if a.Size() < a.MaxSize {
+			a.rebuild(a.Size()*2, len(a.entries))
+		} else {
+			a.rebuild(a.Size(), len(a.entries)/2)
+		}
+		r, ok = a.pack(size)
+	}
+	a.clock++
+	a.entries[key] = &atlasEntry{key: key, rect: r, lastUse: a.clock}
+	draw.Draw(a.img, r, img, img.Bounds().Min, draw.Src)
+
+	a.Texture.Lock()
+	if a.Texture.Loaded && a.generation == gen {
+		sub := a.img.SubImage(r).(*image.RGBA)
+		pix := make([]uint8, 0, 4*r.Dx()*r.Dy())
+		for y := 0; y < r.Dy(); y++ {
+			i := y * sub.Stride
+			pix = append(pix, sub.Pix[i:i+4*r.Dx()]...)
+		}
+		a.Texture.UpdateRect(0, r, pix)
+	}
+	a.Texture.Unlock()
+	return r, nil
+}
+
+// Remove removes the image with the given key from the atlas, reporting
+// whether it was present. The space used by the image is not reused until the
+// atlas is compacted (see Compact) or rebuilt.
+func (a *Atlas) Remove(key interface{}) bool {
+	if _, ok := a.entries[key]; !ok {
+		return false
+	}
+	delete(a.entries, key)
+	return true
+}
+
+// Fragmentation returns the fraction of the space allocated within the atlas
+// which is not used by any image, from 0.0 (tightly packed) to 1.0 (every
+// image was removed). Space is wasted by removed images, and by images shorter
+// than the shelf they were packed into.
+func (a *Atlas) Fragmentation() float64 {
+	var allocated, used int
+	for _, s := range a.shelves {
+		allocated += s.x * s.height
+	}
+	if allocated == 0 {
+		return 0
+	}
+	for _, e := range a.entries {
+		used += (e.rect.Dx() + 2*a.Padding) * (e.rect.Dy() + 2*a.Padding)
+	}
+	return 1 - float64(used)/float64(allocated)
+}
+
+// Compact repacks every image of the atlas tightly, reclaiming the space left
+// behind by removed images, and shrinks the atlas (down to it's initial size)
+// if the images fit into a smaller one. It prevents the texture memory used
+// by long-running applications from growing over time.
+//
+// As the rectangles of all images change the generation is incremented (see
+// Generation) and the texture must be loaded again. The texture's lock is
+// acquired by this method.
+func (a *Atlas) Compact() {
+	entries := make([]*atlasEntry, 0, len(a.entries))
+	for _, e := range a.entries {
+		entries = append(entries, e)
+	}
+	sort.Sort(atlasByHeight(entries))
+	size := a.Size()
+	for size/2 >= a.minSize && a.fits(size/2, entries) {
+		size /= 2
+	}
+	a.rebuild(size, len(entries))
+}
+
+// CompactIdle compacts the atlas (see Compact) only if it's fragmentation is
+// at least the given threshold (e.g. 0.25), reporting whether it did so. It
+// is intended to be called by the application during idle frames (e.g. when
+// the frame finished well ahead of it's deadline, or nothing is animating),
+// such that the cost of compaction and of uploading the texture again does
+// not cause a visible hitch.
+func (a *Atlas) CompactIdle(threshold float64) bool {
+	if len(a.shelves) == 0 || a.Fragmentation() < threshold {
+		return false
+	}
+	a.Compact()
+	return true
+}
+
+// fits tells if the given images (sorted by height) fit into an atlas of the
+// given size.
+func (a *Atlas) fits(size int, entries []*atlasEntry) bool {
+	saved := a.shelves
+	defer func() { a.shelves = saved }()
+	a.shelves = nil
+	for _, e := range entries {
+		if _, ok := a.packAt(size, e.rect.Size()); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// pack finds space for an image of the given size (excluding padding) using
+// shelf packing, returning it's rectangle.
+func (a *Atlas) pack(size image.Point) (image.Rectangle, bool) {
+	return a.packAt(a.Size(), size)
+}
+
+// packAt is like pack, but for an atlas of the given size.
+func (a *Atlas) packAt(atlasSize int, size image.Point) (image.Rectangle, bool) {
+	w, h := size.X+2*a.Padding, size.Y+2*a.Padding
+	place := func(s *atlasShelf) image.Rectangle {
+		min := image.Pt(s.x+a.Padding, s.y+a.Padding)
+		s.x += w
+		return image.Rectangle{min, min.Add(size)}
+	}
+	for i := range a.shelves {
+		s := &a.shelves[i]
+		if h <= s.height && s.x+w <= atlasSize {
+			return place(s), true
+		}
+	}
+	y := 0
+	if n := len(a.shelves); n > 0 {
+		last := a.shelves[n-1]
+		y = last.y + last.height
+	}
+	if y+h > atlasSize || w > atlasSize {
+		return image.Rectangle{}, false
+	}
+	a.shelves = append(a.shelves, atlasShelf{y: y, height: h})
+	return place(&a.shelves[len(a.shelves)-1]), true
+}
+
+// reset resets the atlas to an empty one of the given size.
+func (a *Atlas) reset(size int) {
+	a.img = image.NewRGBA(image.Rect(0, 0, size, size))
+	a.shelves = a.shelves[:0]
+	a.Texture.Lock()
+	a.Texture.Source = a.img
+	a.Texture.Bounds = a.img.Bounds()
+	a.Texture.Updates = nil
+	a.Texture.Unlock()
+}
+
+// rebuild rebuilds the atlas at the given size, keeping only the keep most
+// recently used images.
+func (a *Atlas) rebuild(size, keep int) {
+	entries := make([]*atlasEntry, 0, len(a.entries))
+	for _, e := range a.entries {
+		entries = append(entries, e)
+	}
+	sort.Sort(atlasByUse(entries))
+	if keep < len(entries) {
+		for _, e := range entries[keep:] {
+			delete(a.entries, e.key)
+		}
+		entries = entries[:keep]
+	}
+
+	// Pack the largest images first, for a tighter fit.
+	sort.Sort(atlasByHeight(entries))
+	old := a.img
+	a.reset(size)
+	for _, e := range entries {
+		r, ok := a.pack(e.rect.Size())
+		if !ok {
+			// Cannot happen when growing, and evicting at least half of the
+			// images frees enough space in practice; drop the image.
+			delete(a.entries, e.key)
+			continue
+		}
+		draw.Draw(a.img, r, old, e.rect.Min, draw.Src)
+		e.rect = r
+	}
+	a.generation++
+}
+
+type atlasByUse []*atlasEntry
+
+func (s atlasByUse) Len() int           { return len(s) }
+func (s atlasByUse) Less(i, j int) bool { return s[i].lastUse > s[j].lastUse }
+func (s atlasByUse) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type atlasByHeight []*atlasEntry
+
+func (s atlasByHeight) Len() int           { return len(s) }
+func (s atlasByHeight) Less(i, j int) bool { return s[i].rect.Dy() > s[j].rect.Dy() }
+func (s atlasByHeight) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
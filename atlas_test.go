@@ -0,0 +1,148 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func atlasGlyph(c uint8) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 6, 6))
+	for i := range img.Pix {
+		img.Pix[i] = c
+	}
+	return img
+}
+
+func TestAtlasGrow(t *testing.T) {
+	a := NewAtlas(16, 64)
+	var rects []image.Rectangle
+	for i := 0; i < 4; i++ {
+		r, err := a.Add(i, atlasGlyph(uint8(i+1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rects = append(rects, r)
+	}
+	// Four 8x8 (padded) glyphs fill a 16x16 atlas exactly.
+	if a.Size() != 16 || a.Generation() != 0 {
+		t.Fatalf("size %d generation %d, want 16 and 0", a.Size(), a.Generation())
+	}
+	if _, err := a.Add(4, atlasGlyph(5)); err != nil {
+		t.Fatal(err)
+	}
+	if a.Size() != 32 || a.Generation() != 1 || a.Len() != 5 {
+		t.Fatalf("size %d generation %d len %d, want 32, 1, and 5", a.Size(), a.Generation(), a.Len())
+	}
+
+	// Every glyph must have kept it's pixels.
+	for i := 0; i < 5; i++ {
+		r, ok := a.Lookup(i)
+		if !ok {
+			t.Fatalf("glyph %d missing after growing", i)
+		}
+		got := a.Texture.Source.At(r.Min.X, r.Min.Y).(color.RGBA)
+		if got.R != uint8(i+1) {
+			t.Fatalf("glyph %d has pixel %v after growing", i, got)
+		}
+	}
+}
+
+func TestAtlasEvict(t *testing.T) {
+	a := NewAtlas(16, 16)
+	for i := 0; i < 4; i++ {
+		a.Add(i, atlasGlyph(1))
+	}
+	// Use glyphs 0 and 3 recently, such that 1 and 2 are evicted.
+	a.Lookup(0)
+	a.Lookup(3)
+	if _, err := a.Add(4, atlasGlyph(1)); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []bool{true, false, false, true, true} {
+		if _, ok := a.Lookup(i); ok != want {
+			t.Errorf("glyph %d present = %t, want %t", i, ok, want)
+		}
+	}
+	if _, err := a.Add(5, image.NewRGBA(image.Rect(0, 0, 16, 16))); err != ErrAtlasTooLarge {
+		t.Errorf("got error %v, want ErrAtlasTooLarge", err)
+	}
+}
+
+func TestAtlasUpdates(t *testing.T) {
+	a := NewAtlas(16, 16)
+	a.Texture.Loaded = true
+	r, _ := a.Add(0, atlasGlyph(1))
+	if len(a.Texture.Updates) != 1 || a.Texture.Updates[0].Rect != r {
+		t.Fatalf("got updates %+v, want one for %v", a.Texture.Updates, r)
+	}
+}
+
+func TestAtlasGrowLoaded(t *testing.T) {
+	a := NewAtlas(16, 64)
+	a.Texture.Loaded = true
+	for i := 0; i < 4; i++ {
+		a.Add(i, atlasGlyph(1))
+	}
+	if len(a.Texture.Updates) != 4 {
+		t.Fatalf("got %d updates, want 4", len(a.Texture.Updates))
+	}
+
+	// Growing rebuilds the atlas, whose texture must then be loaded again
+	// instead of being updated partially.
+	if _, err := a.Add(4, atlasGlyph(1)); err != nil {
+		t.Fatal(err)
+	}
+	if a.Generation() != 1 {
+		t.Fatalf("generation %d, want 1", a.Generation())
+	}
+	if len(a.Texture.Updates) != 0 {
+		t.Fatalf("got updates %+v after growing, want none", a.Texture.Updates)
+	}
+	if b := a.Texture.Bounds; b != image.Rect(0, 0, 32, 32) {
+		t.Fatalf("texture bounds %v after growing, want 32x32", b)
+	}
+}
+
+func TestAtlasCompact(t *testing.T) {
+	a := NewAtlas(16, 64)
+	for i := 0; i < 5; i++ {
+		a.Add(i, atlasGlyph(uint8(i+1)))
+	}
+	if a.Size() != 32 {
+		t.Fatalf("size %d, want 32", a.Size())
+	}
+	if a.CompactIdle(0.25) {
+		t.Fatal("compacted an atlas without fragmentation")
+	}
+	for i := 0; i < 4; i++ {
+		if !a.Remove(i) {
+			t.Fatalf("glyph %d not removed", i)
+		}
+	}
+	if a.Remove(0) {
+		t.Fatal("removed glyph twice")
+	}
+	if f := a.Fragmentation(); f < 0.5 {
+		t.Fatalf("fragmentation %v, want at least 0.5", f)
+	}
+
+	gen := a.Generation()
+	if !a.CompactIdle(0.25) {
+		t.Fatal("fragmented atlas not compacted")
+	}
+	if a.Size() != 16 || a.Generation() != gen+1 || a.Fragmentation() != 0 {
+		t.Fatalf("size %d generation %d fragmentation %v, want 16, %d, and 0", a.Size(), a.Generation(), a.Fragmentation(), gen+1)
+	}
+	r, ok := a.Lookup(4)
+	if !ok {
+		t.Fatal("glyph missing after compaction")
+	}
+	if got := a.Texture.Source.At(r.Min.X, r.Min.Y).(color.RGBA); got.R != 5 {
+		t.Fatalf("glyph has pixel %v after compaction", got)
+	}
+}
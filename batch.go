@@ -0,0 +1,121 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// The functions in this file operate on batches of data at once, for use in
+// the hot loops of scenes with tens of thousands of objects (e.g. computing
+// world matrices, world-space bounds, and visibility). Where available they
+// use SIMD instructions (see batch_amd64.s), otherwise they fall back to pure
+// Go implementations.
+
+// MulMat4s multiplies each matrix of src by m and stores the results in dst,
+// i.e. it is equivalent to:
+//  for i := range src {
+//      dst[i] = src[i].Mul(m)
+//  }
+// A panic occurs if len(dst) < len(src). The dst and src slices may be the
+// same slice.
+func MulMat4s(dst, src []lmath.Mat4, m lmath.Mat4) {
+	if len(dst) < len(src) {
+		panic("MulMat4s: len(dst) < len(src)")
+	}
+	mulMat4s(dst, src, &m)
+}
+
+func mulMat4sGeneric(dst, src []lmath.Mat4, m *lmath.Mat4) {
+	for i := range src {
+		a := &src[i]
+		var r lmath.Mat4
+		for row := 0; row < 4; row++ {
+			a0, a1, a2, a3 := a[row][0], a[row][1], a[row][2], a[row][3]
+			for col := 0; col < 4; col++ {
+				r[row][col] = a0*m[0][col] + a1*m[1][col] + a2*m[2][col] + a3*m[3][col]
+			}
+		}
+		dst[i] = r
+	}
+}
+
+// TransformRect3s transforms each axis-aligned bounding box of src by the
+// matrix at the same index in m, and stores the axis-aligned bounding box of
+// each result in dst. A panic occurs if len(dst) or len(m) is less than
+// len(src).
+//
+// The matrices must be affine (i.e. not projection matrices).
+func TransformRect3s(dst, src []lmath.Rect3, m []lmath.Mat4) {
+	if len(dst) < len(src) || len(m) < len(src) {
+		panic("TransformRect3s: len(dst) or len(m) < len(src)")
+	}
+	for i := range src {
+		// Arvo's method: each component of the result is the translation plus
+		// the sum of the minimum (or maximum) products over each axis.
+		b, t := &src[i], &m[i]
+		min := [3]float64{t[3][0], t[3][1], t[3][2]}
+		max := min
+		bmin := [3]float64{b.Min.X, b.Min.Y, b.Min.Z}
+		bmax := [3]float64{b.Max.X, b.Max.Y, b.Max.Z}
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				e := bmin[row] * t[row][col]
+				f := bmax[row] * t[row][col]
+				min[col] += math.Min(e, f)
+				max[col] += math.Max(e, f)
+			}
+		}
+		dst[i] = lmath.Rect3{
+			Min: lmath.Vec3{min[0], min[1], min[2]},
+			Max: lmath.Vec3{max[0], max[1], max[2]},
+		}
+	}
+}
+
+// CullRect3s tests each axis-aligned bounding box of boxes against the given
+// planes and stores whether or not it is visible in visible, returning the
+// number of visible boxes. A panic occurs if len(visible) < len(boxes).
+//
+// Each plane is given as its equation coefficients a, b, c, and d, where a
+// point (x, y, z) is inside the plane if:
+//  a*x + b*y + c*z + d >= 0
+// A box is visible unless it is entirely outside of any plane. Typically the
+// planes are the six planes of a camera's viewing frustum.
+func CullRect3s(visible []bool, boxes []lmath.Rect3, planes [][4]float64) int {
+	if len(visible) < len(boxes) {
+		panic("CullRect3s: len(visible) < len(boxes)")
+	}
+	n := 0
+	for i := range boxes {
+		b := &boxes[i]
+		v := true
+		for _, p := range planes {
+			// Test the corner of the box furthest along the plane's normal
+			// (the "positive vertex"); if it is outside then so is the box.
+			x, y, z := b.Min.X, b.Min.Y, b.Min.Z
+			if p[0] >= 0 {
+				x = b.Max.X
+			}
+			if p[1] >= 0 {
+				y = b.Max.Y
+			}
+			if p[2] >= 0 {
+				z = b.Max.Z
+			}
+			if p[0]*x+p[1]*y+p[2]*z+p[3] < 0 {
+				v = false
+				break
+			}
+		}
+		visible[i] = v
+		if v {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,12 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "azul3d.org/lmath.v1"
+
+// mulMat4s is implemented in batch_amd64.s using SSE2 instructions.
+//
+//go:noescape
+func mulMat4s(dst, src []lmath.Mat4, m *lmath.Mat4)
@@ -0,0 +1,73 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+#include "textflag.h"
+
+// func mulMat4s(dst, src []lmath.Mat4, m *lmath.Mat4)
+//
+// Each row of a result is the sum of the rows of m scaled by the elements of
+// the same row of the source matrix. The rows of m are kept in X4-X11 (two
+// float64 values per register) while X0-X3 hold the accumulators. Products
+// are summed in the same order as mulMat4sGeneric so the results are
+// identical.
+TEXT ·mulMat4s(SB), NOSPLIT, $0-56
+	MOVQ dst_base+0(FP), DI
+	MOVQ src_base+24(FP), SI
+	MOVQ src_len+32(FP), CX
+	MOVQ m+48(FP), DX
+
+	MOVUPD 0(DX), X4
+	MOVUPD 16(DX), X5
+	MOVUPD 32(DX), X6
+	MOVUPD 48(DX), X7
+	MOVUPD 64(DX), X8
+	MOVUPD 80(DX), X9
+	MOVUPD 96(DX), X10
+	MOVUPD 112(DX), X11
+
+	// Four rows per matrix.
+	SHLQ $2, CX
+	JZ   done
+
+loop:
+	MOVSD    0(SI), X0
+	UNPCKLPD X0, X0
+	MOVAPD   X0, X1
+	MULPD    X4, X0
+	MULPD    X5, X1
+
+	MOVSD    8(SI), X2
+	UNPCKLPD X2, X2
+	MOVAPD   X2, X3
+	MULPD    X6, X2
+	MULPD    X7, X3
+	ADDPD    X2, X0
+	ADDPD    X3, X1
+
+	MOVSD    16(SI), X2
+	UNPCKLPD X2, X2
+	MOVAPD   X2, X3
+	MULPD    X8, X2
+	MULPD    X9, X3
+	ADDPD    X2, X0
+	ADDPD    X3, X1
+
+	MOVSD    24(SI), X2
+	UNPCKLPD X2, X2
+	MOVAPD   X2, X3
+	MULPD    X10, X2
+	MULPD    X11, X3
+	ADDPD    X2, X0
+	ADDPD    X3, X1
+
+	MOVUPD X0, 0(DI)
+	MOVUPD X1, 16(DI)
+
+	ADDQ $32, SI
+	ADDQ $32, DI
+	DECQ CX
+	JNZ  loop
+
+done:
+	RET
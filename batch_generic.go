@@ -0,0 +1,14 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !amd64
+// +build !amd64
+
+package gfx
+
+import "azul3d.org/lmath.v1"
+
+func mulMat4s(dst, src []lmath.Mat4, m *lmath.Mat4) {
+	mulMat4sGeneric(dst, src, m)
+}
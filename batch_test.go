@@ -0,0 +1,106 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math/rand"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func randMat4s(r *rand.Rand, n int) []lmath.Mat4 {
+	m := make([]lmath.Mat4, n)
+	for i := range m {
+		for row := range m[i] {
+			for col := range m[i][row] {
+				m[i][row][col] = r.Float64()*200 - 100
+			}
+		}
+	}
+	return m
+}
+
+func TestMulMat4s(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	src := randMat4s(r, 33)
+	m := randMat4s(r, 1)[0]
+
+	got := make([]lmath.Mat4, len(src))
+	MulMat4s(got, src, m)
+	want := make([]lmath.Mat4, len(src))
+	mulMat4sGeneric(want, src, &m)
+	for i := range src {
+		if got[i] != want[i] {
+			t.Fatalf("%d: got %v, want %v", i, got[i], want[i])
+		}
+		if !got[i].Equals(src[i].Mul(m)) {
+			t.Fatalf("%d: got %v, want %v", i, got[i], src[i].Mul(m))
+		}
+	}
+
+	// In-place.
+	MulMat4s(src, src, m)
+	for i := range src {
+		if src[i] != want[i] {
+			t.Fatalf("in-place %d: got %v, want %v", i, src[i], want[i])
+		}
+	}
+}
+
+func TestTransformRect3s(t *testing.T) {
+	src := []lmath.Rect3{{Min: lmath.Vec3{-1, -1, -1}, Max: lmath.Vec3{1, 1, 1}}}
+	m := []lmath.Mat4{{
+		{0, 2, 0, 0},  // X -> 2Y
+		{-1, 0, 0, 0}, // Y -> -X
+		{0, 0, 1, 0},
+		{10, 20, 30, 1},
+	}}
+	dst := make([]lmath.Rect3, 1)
+	TransformRect3s(dst, src, m)
+	want := lmath.Rect3{Min: lmath.Vec3{9, 18, 29}, Max: lmath.Vec3{11, 22, 31}}
+	if dst[0] != want {
+		t.Fatalf("got %v, want %v", dst[0], want)
+	}
+}
+
+func TestCullRect3s(t *testing.T) {
+	// The half-space x >= 0.
+	planes := [][4]float64{{1, 0, 0, 0}}
+	boxes := []lmath.Rect3{
+		{Min: lmath.Vec3{1, 0, 0}, Max: lmath.Vec3{2, 1, 1}},
+		{Min: lmath.Vec3{-1, 0, 0}, Max: lmath.Vec3{1, 1, 1}},
+		{Min: lmath.Vec3{-2, 0, 0}, Max: lmath.Vec3{-1, 1, 1}},
+	}
+	visible := make([]bool, len(boxes))
+	if n := CullRect3s(visible, boxes, planes); n != 2 {
+		t.Fatalf("got %d visible, want 2", n)
+	}
+	if !visible[0] || !visible[1] || visible[2] {
+		t.Fatalf("got %v", visible)
+	}
+}
+
+func BenchmarkMulMat4s(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	src := randMat4s(r, 10000)
+	m := randMat4s(r, 1)[0]
+	dst := make([]lmath.Mat4, len(src))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MulMat4s(dst, src, m)
+	}
+}
+
+func BenchmarkMulMat4sGeneric(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	src := randMat4s(r, 10000)
+	m := randMat4s(r, 1)[0]
+	dst := make([]lmath.Mat4, len(src))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mulMat4sGeneric(dst, src, &m)
+	}
+}
@@ -0,0 +1,431 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// EvictKind specifies which copy of a resource's data is to be evicted, see
+// the Budget type.
+type EvictKind uint8
+
+const (
+	// EvictGPU evicts the GPU copy of the resource (i.e. it's native mesh or
+	// texture), which is restored by loading the resource again.
+	EvictGPU EvictKind = iota
+
+	// EvictCPU evicts the CPU copy of the resource (i.e. it's data slices or
+	// source images), such that it cannot be restored once it's GPU copy is
+	// evicted, except by the application.
+	EvictCPU
+)
+
+type budgetEntry struct {
+	res      interface{}
+	gpu      int64
+	lastUse  uint64
+	priority int
+	pinned   bool
+}
+
+type budgetCandidate struct {
+	*budgetEntry
+	gpu, cpu int64
+}
+
+// budgetByEviction sorts candidates in eviction order (lowest priority, then
+// least recently used, first).
+type budgetByEviction []budgetCandidate
+
+func (s budgetByEviction) Len() int      { return len(s) }
+func (s budgetByEviction) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s budgetByEviction) Less(i, j int) bool {
+	if s[i].priority != s[j].priority {
+		return s[i].priority < s[j].priority
+	}
+	return s[i].lastUse < s[j].lastUse
+}
+
+// Budget limits the GPU and CPU memory used by meshes and textures. Resources
+// are tracked by the budget and marked as used each time they are drawn (see
+// the Use and UseObject methods). Once per frame (e.g. upon the FrameEnd
+// event, see FrameEvents) Enforce is called, which evicts the least recently
+// used resources until each budget is met:
+//  - Resources with a lower priority are evicted before those with a higher
+//    priority (regardless of when they were used), see SetPriority.
+//  - Pinned resources are never evicted, see Pin.
+//
+// If an eviction function is set (see SetEvictFunc) then the application is
+// notified of the resources to evict instead, and may e.g. replace them with
+// lower resolution versions. Otherwise resources are evicted automatically:
+//  - The GPU copy of a resource is evicted only if it's data is kept on the
+//    CPU (see Mesh.KeepDataOnLoad), such that renderers load it again the next
+//    time it is drawn.
+//  - The CPU copy of a resource is evicted only if it is loaded (i.e. it's
+//    data is already on the GPU).
+//
+// Memory usage is estimated from the size of each resource's data, actual GPU
+// memory usage depends on the renderer and graphics hardware.
+//
+// It is safe to use from multiple goroutines concurrently.
+type Budget struct {
+	access             sync.Mutex
+	gpuLimit, cpuLimit int64
+	frame              uint64
+	entries            map[interface{}]*budgetEntry
+	evict              func(res interface{}, kind EvictKind)
+}
+
+// NewBudget returns a new budget with the given GPU and CPU memory limits in
+// bytes, a limit of zero is unlimited.
+func NewBudget(gpu, cpu int64) *Budget {
+	return &Budget{
+		gpuLimit: gpu,
+		cpuLimit: cpu,
+		entries:  make(map[interface{}]*budgetEntry),
+	}
+}
+
+// SetLimits sets the GPU and CPU memory limits in bytes, a limit of zero is
+// unlimited.
+func (b *Budget) SetLimits(gpu, cpu int64) {
+	b.access.Lock()
+	b.gpuLimit, b.cpuLimit = gpu, cpu
+	b.access.Unlock()
+}
+
+// SetEvictFunc sets the function which is called by Enforce for each
+// resource (a *Mesh or *Texture) that should be evicted, instead of evicting
+// resources automatically. If fn is nil then resources are evicted
+// automatically. The function is called without the resource being locked.
+func (b *Budget) SetEvictFunc(fn func(res interface{}, kind EvictKind)) {
+	b.access.Lock()
+	b.evict = fn
+	b.access.Unlock()
+}
+
+// Track begins tracking the memory used by the given resource, which must be
+// a *Mesh or *Texture. It should be called before the resource is loaded, as
+// it's GPU memory usage is estimated from it's data (unless it's data is
+// kept, see KeepDataOnLoad).
+//
+// This method properly read-locks the resource.
+func (b *Budget) Track(res interface{}) {
+	gpu, _, ok := resourceSize(res)
+	if !ok {
+		panic("Track(): resource must be a *Mesh or *Texture")
+	}
+	b.access.Lock()
+	e, ok := b.entries[res]
+	if !ok {
+		e = &budgetEntry{res: res, lastUse: b.frame}
+		b.entries[res] = e
+	}
+	if gpu > e.gpu {
+		e.gpu = gpu
+	}
+	b.access.Unlock()
+}
+
+// Untrack stops tracking the given resource, e.g. before it is destroyed.
+func (b *Budget) Untrack(res interface{}) {
+	b.access.Lock()
+	delete(b.entries, res)
+	b.access.Unlock()
+}
+
+// SetPriority sets the priority of the given tracked resource (zero by
+// default). Resources with a lower priority are evicted first.
+func (b *Budget) SetPriority(res interface{}, priority int) {
+	b.access.Lock()
+	if e, ok := b.entries[res]; ok {
+		e.priority = priority
+	}
+	b.access.Unlock()
+}
+
+// Pin sets whether the given tracked resource is pinned, pinned resources are
+// never evicted.
+func (b *Budget) Pin(res interface{}, pinned bool) {
+	b.access.Lock()
+	if e, ok := b.entries[res]; ok {
+		e.pinned = pinned
+	}
+	b.access.Unlock()
+}
+
+// Use marks the given tracked resource as used in the current frame.
+func (b *Budget) Use(res interface{}) {
+	b.access.Lock()
+	if e, ok := b.entries[res]; ok {
+		e.lastUse = b.frame
+	}
+	b.access.Unlock()
+}
+
+// UseObject marks each tracked mesh and texture of the given object as used
+// in the current frame, it is typically called each time the object is drawn.
+//
+// The object's read lock must be held for this method to operate safely.
+func (b *Budget) UseObject(o *Object) {
+	b.access.Lock()
+	for _, m := range o.Meshes {
+		if e, ok := b.entries[m]; ok {
+			e.lastUse = b.frame
+		}
+	}
+	for _, t := range o.Textures {
+		if e, ok := b.entries[t]; ok {
+			e.lastUse = b.frame
+		}
+	}
+	b.access.Unlock()
+}
+
+// Usage returns the estimated GPU and CPU memory, in bytes, used by the
+// tracked resources.
+//
+// This method properly read-locks each tracked resource.
+func (b *Budget) Usage() (gpu, cpu int64) {
+	b.access.Lock()
+	defer b.access.Unlock()
+	for _, e := range b.entries {
+		g, c := b.measure(e)
+		gpu += g
+		cpu += c
+	}
+	return
+}
+
+// measure returns the GPU and CPU memory used by the entry, updating it's GPU
+// estimate. The budget's lock must be held.
+func (b *Budget) measure(e *budgetEntry) (gpu, cpu int64) {
+	g, cpu, _ := resourceSize(e.res)
+	if g > e.gpu {
+		e.gpu = g
+	}
+	if resourceLoaded(e.res) {
+		gpu = e.gpu
+	}
+	return gpu, cpu
+}
+
+// Enforce evicts resources (or notifies the application of the resources to
+// evict, see SetEvictFunc) until the GPU and CPU memory usage is within the
+// budget, and returns the number of resources evicted. It then advances the
+// current frame, and should be called once per frame.
+//
+// This method properly locks each tracked resource.
+func (b *Budget) Enforce() (evicted int) {
+	b.access.Lock()
+	var (
+		gpuUsed, cpuUsed int64
+		candidates       []budgetCandidate
+	)
+	for _, e := range b.entries {
+		gpu, cpu := b.measure(e)
+		gpuUsed += gpu
+		cpuUsed += cpu
+		if !e.pinned && e.lastUse != b.frame {
+			candidates = append(candidates, budgetCandidate{e, gpu, cpu})
+		}
+	}
+	sort.Sort(budgetByEviction(candidates))
+	gpuLimit, cpuLimit, fn := b.gpuLimit, b.cpuLimit, b.evict
+	b.frame++
+	b.access.Unlock()
+
+	for _, c := range candidates {
+		if gpuLimit > 0 && gpuUsed > gpuLimit && c.gpu > 0 && (fn != nil || resourceHasData(c.res)) {
+			if fn != nil {
+				fn(c.res, EvictGPU)
+			} else {
+				evictResource(c.res, EvictGPU)
+			}
+			gpuUsed -= c.gpu
+			evicted++
+			continue
+		}
+		if cpuLimit > 0 && cpuUsed > cpuLimit && c.cpu > 0 && (fn != nil || resourceLoaded(c.res)) {
+			if fn != nil {
+				fn(c.res, EvictCPU)
+			} else {
+				evictResource(c.res, EvictCPU)
+			}
+			cpuUsed -= c.cpu
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// evictResource evicts the given copy of the resource's data.
+func evictResource(res interface{}, kind EvictKind) {
+	switch r := res.(type) {
+	case *Mesh:
+		r.Lock()
+		if kind == EvictGPU {
+			if r.NativeMesh != nil && r.release() {
+				r.NativeMesh.Destroy()
+			}
+			r.NativeMesh = nil
+			r.Loaded = false
+			r.IndicesChanged = true
+			r.VerticesChanged = true
+			r.ColorsChanged = true
+			r.BaryChanged = true
+			for i := range r.TexCoords {
+				r.TexCoords[i].Changed = true
+			}
+			for name, a := range r.Attribs {
+				a.Changed = true
+				r.Attribs[name] = a
+			}
+		} else {
+			r.KeepDataOnLoad = false
+			r.ClearData()
+		}
+		r.Unlock()
+	case *Texture:
+		r.Lock()
+		if kind == EvictGPU {
+			if r.NativeTexture != nil {
+				r.NativeTexture.Destroy()
+			}
+			r.NativeTexture = nil
+			r.Loaded = false
+		} else {
+			r.KeepDataOnLoad = false
+			r.ClearData()
+		}
+		r.Unlock()
+	}
+}
+
+// resourceLoaded tells if the resource is loaded.
+func resourceLoaded(res interface{}) bool {
+	switch r := res.(type) {
+	case *Mesh:
+		r.RLock()
+		defer r.RUnlock()
+		return r.Loaded
+	case *Texture:
+		r.RLock()
+		defer r.RUnlock()
+		return r.Loaded
+	}
+	return false
+}
+
+// resourceHasData tells if the resource's data is present on the CPU, such
+// that it can be loaded again.
+func resourceHasData(res interface{}) bool {
+	_, cpu, _ := resourceSize(res)
+	return cpu > 0
+}
+
+// resourceSize returns the estimated GPU memory used by the resource once
+// loaded (from it's data, or zero if it has none) and the CPU memory used by
+// it's data. If the resource is not a *Mesh or *Texture, ok is false.
+func resourceSize(res interface{}) (gpu, cpu int64, ok bool) {
+	switch r := res.(type) {
+	case *Mesh:
+		r.RLock()
+		defer r.RUnlock()
+		cpu = int64(len(r.Indices)*4 + len(r.Vertices)*12 + len(r.Colors)*16 + len(r.Bary)*12)
+		for _, set := range r.TexCoords {
+			cpu += int64(len(set.Slice) * 8)
+		}
+		for _, a := range r.Attribs {
+			cpu += sliceSize(reflect.ValueOf(a.Data))
+		}
+		return cpu, cpu, true
+
+	case *Texture:
+		r.RLock()
+		defer r.RUnlock()
+		images := r.Layers
+		if r.Source != nil {
+			images = append([]image.Image{r.Source}, images...)
+		}
+		for _, img := range images {
+			cpu += imageSize(img)
+		}
+		cpu += int64(len(r.Buffer) * 4)
+		if cpu == 0 {
+			return 0, 0, true
+		}
+
+		// The GPU uses the texture's format, plus a third for mipmaps.
+		gpu = cpu
+		if r.Type != BufferTexture {
+			layers := len(r.Layers)
+			if layers == 0 {
+				layers = 1
+			}
+			gpu = int64(r.Bounds.Dx()*r.Bounds.Dy()*layers) * texelBits(r.Format) / 8
+			if r.MinFilter.Mipmapped() {
+				gpu += gpu / 3
+			}
+		}
+		return gpu, cpu, true
+	}
+	return 0, 0, false
+}
+
+// sliceSize returns the size in bytes of the elements of the slice (or of
+// each slice within it).
+func sliceSize(v reflect.Value) int64 {
+	if v.Kind() != reflect.Slice {
+		return 0
+	}
+	if v.Type().Elem().Kind() == reflect.Slice {
+		var n int64
+		for i := 0; i < v.Len(); i++ {
+			n += sliceSize(v.Index(i))
+		}
+		return n
+	}
+	return int64(v.Len()) * int64(v.Type().Elem().Size())
+}
+
+// imageSize returns the size in bytes of the image's pixel data.
+func imageSize(img image.Image) int64 {
+	switch i := img.(type) {
+	case *image.RGBA:
+		return int64(len(i.Pix))
+	case *image.NRGBA:
+		return int64(len(i.Pix))
+	case *image.Gray:
+		return int64(len(i.Pix))
+	case *CompressedImage:
+		return int64(len(i.Data))
+	case nil:
+		return 0
+	}
+	b := img.Bounds()
+	return int64(b.Dx() * b.Dy() * 4)
+}
+
+// texelBits returns the number of bits per texel of the format.
+func texelBits(f TexFormat) int64 {
+	switch f {
+	case DXT1, DXT1RGBA, ETC2:
+		return 4
+	case DXT3, DXT5, ETC2RGBA, ASTC4x4:
+		return 8
+	case ASTC8x8:
+		return 2
+	case RGBA16F:
+		return 64
+	case RGBA32F:
+		return 128
+	}
+	return 32
+}
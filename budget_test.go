@@ -0,0 +1,76 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func budgetMesh(r Renderer) *Mesh {
+	m := NewMesh()
+	m.KeepDataOnLoad = true
+	m.Vertices = make([]Vec3, 100) // 1200 bytes.
+	r.LoadMesh(m, nil)
+	return m
+}
+
+func TestBudgetEvictGPU(t *testing.T) {
+	r := Nil()
+	b := NewBudget(2500, 0)
+	a, c, d := budgetMesh(r), budgetMesh(r), budgetMesh(r)
+	for _, m := range []*Mesh{a, c, d} {
+		b.Track(m)
+	}
+	if gpu, cpu := b.Usage(); gpu != 3600 || cpu != 3600 {
+		t.Fatalf("usage %d %d, want 3600 3600", gpu, cpu)
+	}
+
+	// Nothing is evicted in the frame the resources were tracked.
+	if n := b.Enforce(); n != 0 {
+		t.Fatalf("evicted %d in first frame", n)
+	}
+	b.Use(a)
+	b.Pin(d, true)
+	if n := b.Enforce(); n != 1 {
+		t.Fatalf("evicted %d, want 1", n)
+	}
+	if !a.Loaded || c.Loaded || !d.Loaded || len(c.Vertices) != 100 {
+		t.Fatal("wrong mesh evicted")
+	}
+	if gpu, _ := b.Usage(); gpu != 2400 {
+		t.Fatalf("gpu usage %d, want 2400", gpu)
+	}
+}
+
+func TestBudgetEvictFunc(t *testing.T) {
+	r := Nil()
+	b := NewBudget(0, 1000)
+	a, c := budgetMesh(r), budgetMesh(r)
+	b.Track(a)
+	b.Track(c)
+	b.SetPriority(a, 1)
+	b.Enforce()
+
+	var got []interface{}
+	b.SetEvictFunc(func(res interface{}, kind EvictKind) {
+		if kind != EvictCPU {
+			t.Fatalf("got kind %v, want EvictCPU", kind)
+		}
+		got = append(got, res)
+	})
+	if n := b.Enforce(); n != 2 || got[0] != c || got[1] != a {
+		t.Fatalf("evicted %d %v, want lower priority mesh first", n, got)
+	}
+	if len(a.Vertices) != 100 {
+		t.Fatal("evict function set but mesh was evicted automatically")
+	}
+}
+
+func TestBudgetTrackPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	NewBudget(0, 0).Track(NewObject())
+}
@@ -6,6 +6,7 @@ package gfx
 
 import (
 	"image"
+	"math"
 	"sync"
 
 	"azul3d.org/lmath.v1"
@@ -26,6 +27,17 @@ type Camera struct {
 	// The projection matrix of the camera, which is responsible for projecting
 	// world coordinates into device coordinates.
 	Projection Mat4
+
+	// The projection matrix of the camera as of the previous frame, used
+	// along with the camera's PrevModel to calculate motion vectors. It is
+	// typically updated once per frame using UpdateMotion.
+	PrevProjection Mat4
+
+	// The viewing rectangle of the camera in window coordinates (i.e. the
+	// view given to SetOrtho, SetOrthoCentered, or SetPersp), which is used
+	// to convert between window and world coordinates (see ScreenPoint and
+	// UnprojectRay).
+	View image.Rectangle
 }
 
 // SetOrtho sets this camera's Projection matrix to an orthographic one.
@@ -48,6 +60,27 @@ func (c *Camera) SetOrtho(view image.Rectangle, near, far float64) {
 	h = float64(int((h / 2.0)) * 2)
 	m := lmath.Mat4Ortho(0, w, 0, h, near, far)
 	c.Projection = ConvertMat4(m)
+	c.View = view
+}
+
+// SetOrthoCentered sets this camera's Projection matrix to an orthographic one
+// which is centered on the camera, such that height units of the world are
+// visible vertically (i.e. smaller heights zoom in). The visible width
+// follows from the aspect ratio of the view.
+//
+// The view parameter is the viewing rectangle for the orthographic
+// projection in window coordinates.
+//
+// The near and far parameters describe the minimum closest and maximum
+// furthest clipping points of the view frustum.
+//
+// The camera's write lock must be held for this method to operate safely.
+func (c *Camera) SetOrthoCentered(view image.Rectangle, height, near, far float64) {
+	h := height / 2
+	w := h * float64(view.Dx()) / float64(view.Dy())
+	m := lmath.Mat4Ortho(-w, w, -h, h, near, far)
+	c.Projection = ConvertMat4(m)
+	c.View = view
 }
 
 // SetPersp sets this camera's Projection matrix to an perspective one.
@@ -70,6 +103,23 @@ func (c *Camera) SetPersp(view image.Rectangle, fov, near, far float64) {
 	aspectRatio := float64(view.Dx()) / float64(view.Dy())
 	m := lmath.Mat4Perspective(fov, aspectRatio, near, far)
 	c.Projection = ConvertMat4(m)
+	c.View = view
+}
+
+// ViewProjection returns the view-projection matrix of the camera, which
+// transforms points in the world into clip space.
+//
+// The camera's read lock must be held for this method to operate safely.
+func (c *Camera) ViewProjection() lmath.Mat4 {
+	return viewProjection(c.Object.Transform.Mat4(), c.Projection)
+}
+
+// viewProjection returns the view-projection matrix of a camera with the
+// given world matrix and projection.
+func viewProjection(model lmath.Mat4, proj Mat4) lmath.Mat4 {
+	cameraInv, _ := model.Inverse()
+	cameraInv = cameraInv.Mul(zUpRightToYUpRight)
+	return cameraInv.Mul(proj.Mat4())
 }
 
 // Project returns a 2D point in normalized device space coordinates given a 3D
@@ -80,13 +130,49 @@ func (c *Camera) SetPersp(view image.Rectangle, fov, near, far float64) {
 //
 // The camera's read lock must be held for this method to operate safely.
 func (c *Camera) Project(p3 lmath.Vec3) (p2 lmath.Vec2, ok bool) {
-	cameraInv, _ := c.Object.Transform.Mat4().Inverse()
-	cameraInv = cameraInv.Mul(zUpRightToYUpRight)
+	p2, ok = c.ViewProjection().Project(p3)
+	return
+}
 
-	projection := c.Projection.Mat4()
-	vp := cameraInv.Mul(projection)
+// ScreenPoint returns the point in window coordinates (within the camera's
+// View rectangle, with the Y axis pointing down) given a 3D point in the
+// world, e.g. for placing a UI overlay above an object.
+//
+// If ok=false is returned then the point is outside of the camera's view and
+// the returned point may not be meaningful.
+//
+// The camera's read lock must be held for this method to operate safely.
+func (c *Camera) ScreenPoint(p3 lmath.Vec3) (p image.Point, ok bool) {
+	ndc, ok := c.Project(p3)
+	x := (ndc.X + 1) / 2 * float64(c.View.Dx())
+	y := (1 - ndc.Y) / 2 * float64(c.View.Dy())
+	p = image.Pt(int(math.Floor(x)), int(math.Floor(y))).Add(c.View.Min)
+	return p, ok
+}
 
-	p2, ok = vp.Project(p3)
+// UnprojectRay returns the ray in the world which passes through the given
+// point in window coordinates (within the camera's View rectangle, with the
+// Y axis pointing down), e.g. for picking objects with the mouse cursor. The
+// ray begins at the near clipping plane, and it's direction is normalized.
+//
+// If ok=false is returned then the camera's view-projection matrix is not
+// invertible and the ray is not meaningful.
+//
+// The camera's read lock must be held for this method to operate safely.
+func (c *Camera) UnprojectRay(x, y int) (origin, dir lmath.Vec3, ok bool) {
+	inv, ok := c.ViewProjection().Inverse()
+	if !ok {
+		return
+	}
+	// Use the center of the pixel.
+	ndcX := (float64(x-c.View.Min.X)+0.5)/float64(c.View.Dx())*2 - 1
+	ndcY := 1 - (float64(y-c.View.Min.Y)+0.5)/float64(c.View.Dy())*2
+	unproject := func(z float64) lmath.Vec3 {
+		v := lmath.Vec4{ndcX, ndcY, z, 1}.Transform(inv)
+		return v.Vec3().DivScalar(v.W)
+	}
+	origin = unproject(-1)
+	dir, ok = unproject(1).Sub(origin).Normalized()
 	return
 }
 
@@ -95,8 +181,10 @@ func (c *Camera) Project(p3 lmath.Vec3) (p2 lmath.Vec2, ok bool) {
 // The camera's read lock must be held for this method to operate safely.
 func (c *Camera) Copy() *Camera {
 	return &Camera{
-		Object:     c.Object.Copy(),
-		Projection: c.Projection,
+		Object:         c.Object.Copy(),
+		Projection:     c.Projection,
+		PrevProjection: c.PrevProjection,
+		View:           c.View,
 	}
 }
 
@@ -106,6 +194,8 @@ func (c *Camera) Copy() *Camera {
 func (c *Camera) Reset() {
 	c.Object.Reset()
 	c.Projection = ConvertMat4(lmath.Mat4Identity)
+	c.PrevProjection = ConvertMat4(lmath.Mat4Identity)
+	c.View = image.Rectangle{}
 }
 
 // Destroy destroys this camera for use by other callees to NewCamera. You must
@@ -124,6 +214,8 @@ var camPool = sync.Pool{
 		return &Camera{
 			NewObject(),
 			ConvertMat4(lmath.Mat4Identity),
+			ConvertMat4(lmath.Mat4Identity),
+			image.Rectangle{},
 		}
 	},
 }
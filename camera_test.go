@@ -0,0 +1,57 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestCameraScreenPoint(t *testing.T) {
+	view := image.Rect(0, 0, 640, 480)
+	c := NewCamera()
+	c.SetPersp(view, 75, 0.1, 1000)
+
+	// The camera looks down the +Y axis, so points straight ahead project to
+	// the center of the view.
+	p, ok := c.ScreenPoint(lmath.Vec3{0, 10, 0})
+	if !ok || p != image.Pt(320, 240) {
+		t.Errorf("ScreenPoint = %v, %v, want (320,240)", p, ok)
+	}
+	if p, _ := c.ScreenPoint(lmath.Vec3{0, 10, 1}); p.Y >= 240 {
+		t.Errorf("point above the view center projected to %v", p)
+	}
+	if _, ok := c.ScreenPoint(lmath.Vec3{0, -10, 0}); ok {
+		t.Error("point behind the camera is in view")
+	}
+
+	origin, dir, ok := c.UnprojectRay(320, 240)
+	if !ok || !dir.AlmostEquals(lmath.Vec3{0, 1, 0}, 1e-2) || origin.Y < 0 {
+		t.Errorf("UnprojectRay = %v, %v, %v", origin, dir, ok)
+	}
+
+	// A point along the ray through a pixel projects back to that pixel.
+	_, dir, _ = c.UnprojectRay(100, 400)
+	if p, _ := c.ScreenPoint(dir.MulScalar(50)); p != image.Pt(100, 400) {
+		t.Errorf("round trip projected to %v, want (100,400)", p)
+	}
+}
+
+func TestCameraOrthoCentered(t *testing.T) {
+	c := NewCamera()
+	c.SetOrthoCentered(image.Rect(0, 0, 200, 100), 10, 0.1, 100)
+
+	// Ten units are visible vertically, twenty horizontally.
+	p, ok := c.ScreenPoint(lmath.Vec3{5.05, 10, 2.45})
+	if !ok || p != image.Pt(150, 25) {
+		t.Errorf("ScreenPoint = %v, %v, want (150,25)", p, ok)
+	}
+	origin, dir, ok := c.UnprojectRay(150, 25)
+	if !ok || !dir.AlmostEquals(lmath.Vec3{0, 1, 0}, 1e-6) || !lmath.AlmostEqual(origin.X, 5.05, 1e-6) {
+		t.Errorf("UnprojectRay = %v, %v, %v", origin, dir, ok)
+	}
+}
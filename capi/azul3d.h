@@ -0,0 +1,63 @@
+/*
+ * Copyright 2014 The Azul3D Authors. All rights reserved.
+ * Use of this source code is governed by a BSD-style
+ * license that can be found in the LICENSE file.
+ *
+ * C API of azul3d.org/gfx.v1/capi, see the package documentation.
+ *
+ * Every resource is referred to by an opaque handle, zero is never a valid
+ * handle. Functions returning a handle return zero on failure, other functions
+ * return zero on success and -1 on failure. The message of the last failure is
+ * returned by azul3d_last_error, the string is valid until the next call to it.
+ *
+ * Matrices and vectors are arrays of floats, colors are sRGB-encoded RGBA.
+ */
+#ifndef AZUL3D_H
+#define AZUL3D_H
+
+#include <stdint.h>
+
+typedef uint64_t azul3d_handle;
+
+const char* azul3d_last_error(void);
+int azul3d_release(azul3d_handle h);
+
+/* A renderer which does not render anything. */
+azul3d_handle azul3d_nil_renderer(void);
+
+/* Meshes, n is the number of vertices (or indices). */
+azul3d_handle azul3d_mesh_new(void);
+int azul3d_mesh_set_vertices(azul3d_handle mesh, const float* xyz, int n);
+int azul3d_mesh_set_colors(azul3d_handle mesh, const float* rgba, int n);
+int azul3d_mesh_set_texcoords(azul3d_handle mesh, int set, const float* uv, int n);
+int azul3d_mesh_set_indices(azul3d_handle mesh, const uint32_t* indices, int n);
+
+/* Textures, pix is width*height*4 bytes of RGBA pixels, top row first. */
+azul3d_handle azul3d_texture_new_rgba(const uint8_t* pix, int width, int height);
+
+/*
+ * Shaders, inputs of n floats are set as float (n=1), vec3 (n=3),
+ * vec4 (n=4), mat4 (n=16, row-major), or otherwise float arrays.
+ */
+azul3d_handle azul3d_shader_new(const char* name, const char* vert, const char* frag);
+int azul3d_shader_set_input(azul3d_handle shader, const char* name, const float* v, int n);
+
+/* Loads a mesh, texture, or shader, blocking until it is loaded. */
+int azul3d_load(azul3d_handle renderer, azul3d_handle resource);
+
+/* Objects, the transform functions also accept camera handles. */
+azul3d_handle azul3d_object_new(azul3d_handle shader);
+int azul3d_object_add_mesh(azul3d_handle obj, azul3d_handle mesh);
+int azul3d_object_add_texture(azul3d_handle obj, azul3d_handle tex);
+int azul3d_object_set_transform(azul3d_handle obj, const float pos[3], const float rot[3], const float scale[3]);
+
+/* Cameras, fov is in degrees. */
+azul3d_handle azul3d_camera_new(void);
+int azul3d_camera_set_persp(azul3d_handle cam, azul3d_handle renderer, double fov, double near, double far);
+
+/* Drawing, azul3d_render presents the frame. */
+int azul3d_clear(azul3d_handle renderer, float r, float g, float b, float a);
+int azul3d_draw(azul3d_handle renderer, azul3d_handle obj, azul3d_handle cam);
+int azul3d_render(azul3d_handle renderer);
+
+#endif
@@ -0,0 +1,161 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package capi
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+// maxSlice is the maximum length of a C array converted into a Go slice.
+const maxSlice = 1 << 28
+
+func floats(p *C.float, n C.int) []float32 {
+	if p == nil || n <= 0 {
+		return nil
+	}
+	return (*[maxSlice]float32)(unsafe.Pointer(p))[:n:n]
+}
+
+func uint32s(p *C.uint32_t, n C.int) []uint32 {
+	if p == nil || n <= 0 {
+		return nil
+	}
+	return (*[maxSlice]uint32)(unsafe.Pointer(p))[:n:n]
+}
+
+func uint8s(p *C.uint8_t, n C.int) []uint8 {
+	if p == nil || n <= 0 {
+		return nil
+	}
+	return (*[maxSlice]uint8)(unsafe.Pointer(p))[:n:n]
+}
+
+var lastErrorC *C.char
+
+//export azul3d_last_error
+func azul3d_last_error() *C.char {
+	lastError.Lock()
+	defer lastError.Unlock()
+	if lastErrorC != nil {
+		C.free(unsafe.Pointer(lastErrorC))
+	}
+	lastErrorC = C.CString(lastError.msg)
+	return lastErrorC
+}
+
+//export azul3d_release
+func azul3d_release(h C.uint64_t) C.int {
+	return C.int(status(release(Handle(h))))
+}
+
+//export azul3d_nil_renderer
+func azul3d_nil_renderer() C.uint64_t {
+	return C.uint64_t(Register(gfx.Nil()))
+}
+
+//export azul3d_mesh_new
+func azul3d_mesh_new() C.uint64_t {
+	return C.uint64_t(newMesh())
+}
+
+//export azul3d_mesh_set_vertices
+func azul3d_mesh_set_vertices(mesh C.uint64_t, xyz *C.float, n C.int) C.int {
+	return C.int(status(meshSetVertices(Handle(mesh), floats(xyz, n*3))))
+}
+
+//export azul3d_mesh_set_colors
+func azul3d_mesh_set_colors(mesh C.uint64_t, rgba *C.float, n C.int) C.int {
+	return C.int(status(meshSetColors(Handle(mesh), floats(rgba, n*4))))
+}
+
+//export azul3d_mesh_set_texcoords
+func azul3d_mesh_set_texcoords(mesh C.uint64_t, set C.int, uv *C.float, n C.int) C.int {
+	return C.int(status(meshSetTexCoords(Handle(mesh), int(set), floats(uv, n*2))))
+}
+
+//export azul3d_mesh_set_indices
+func azul3d_mesh_set_indices(mesh C.uint64_t, indices *C.uint32_t, n C.int) C.int {
+	return C.int(status(meshSetIndices(Handle(mesh), uint32s(indices, n))))
+}
+
+//export azul3d_texture_new_rgba
+func azul3d_texture_new_rgba(pix *C.uint8_t, width, height C.int) C.uint64_t {
+	h, err := newTextureRGBA(uint8s(pix, width*height*4), int(width), int(height))
+	return C.uint64_t(handleStatus(h, err))
+}
+
+//export azul3d_shader_new
+func azul3d_shader_new(name, vert, frag *C.char) C.uint64_t {
+	return C.uint64_t(newShader(C.GoString(name), C.GoString(vert), C.GoString(frag)))
+}
+
+//export azul3d_shader_set_input
+func azul3d_shader_set_input(shader C.uint64_t, name *C.char, v *C.float, n C.int) C.int {
+	return C.int(status(shaderSetInputFloats(Handle(shader), C.GoString(name), floats(v, n))))
+}
+
+//export azul3d_load
+func azul3d_load(renderer, resource C.uint64_t) C.int {
+	return C.int(status(load(Handle(renderer), Handle(resource))))
+}
+
+//export azul3d_object_new
+func azul3d_object_new(shader C.uint64_t) C.uint64_t {
+	h, err := newObject(Handle(shader))
+	return C.uint64_t(handleStatus(h, err))
+}
+
+//export azul3d_object_add_mesh
+func azul3d_object_add_mesh(obj, mesh C.uint64_t) C.int {
+	return C.int(status(objectAddMesh(Handle(obj), Handle(mesh))))
+}
+
+//export azul3d_object_add_texture
+func azul3d_object_add_texture(obj, tex C.uint64_t) C.int {
+	return C.int(status(objectAddTexture(Handle(obj), Handle(tex))))
+}
+
+//export azul3d_object_set_transform
+func azul3d_object_set_transform(obj C.uint64_t, pos, rot, scale *C.float) C.int {
+	vec := func(p *C.float) lmath.Vec3 {
+		v := floats(p, 3)
+		return lmath.Vec3{float64(v[0]), float64(v[1]), float64(v[2])}
+	}
+	return C.int(status(objectSetTransform(Handle(obj), vec(pos), vec(rot), vec(scale))))
+}
+
+//export azul3d_camera_new
+func azul3d_camera_new() C.uint64_t {
+	return C.uint64_t(newCamera())
+}
+
+//export azul3d_camera_set_persp
+func azul3d_camera_set_persp(cam, renderer C.uint64_t, fov, near, far C.double) C.int {
+	return C.int(status(cameraSetPersp(Handle(cam), Handle(renderer), float64(fov), float64(near), float64(far))))
+}
+
+//export azul3d_clear
+func azul3d_clear(renderer C.uint64_t, r, g, b, a C.float) C.int {
+	return C.int(status(clearRenderer(Handle(renderer), gfx.Color{float32(r), float32(g), float32(b), float32(a)})))
+}
+
+//export azul3d_draw
+func azul3d_draw(renderer, obj, cam C.uint64_t) C.int {
+	return C.int(status(draw(Handle(renderer), Handle(obj), Handle(cam))))
+}
+
+//export azul3d_render
+func azul3d_render(renderer C.uint64_t) C.int {
+	return C.int(status(render(Handle(renderer))))
+}
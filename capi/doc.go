@@ -0,0 +1,28 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package capi exports a thin C API for the core operations of package gfx,
+// such that programs written in other languages can embed an azul3d renderer.
+//
+// The C API is handle-based: every resource (renderer, mesh, texture, shader,
+// object, or camera) is referred to by an opaque 64-bit handle, and no Go
+// pointers are ever passed to C. This keeps the C surface independent of the
+// Go API, which may continue to evolve freely. See azul3d.h for the C
+// declarations.
+//
+// Renderers are created by the Go host program (e.g. when it opens a window),
+// which hands them to C using Register:
+//  r := ... // Create a window and it's renderer.
+//  h := capi.Register(r)
+//  C.run_my_app(C.uint64_t(h))
+//
+// Alternatively, the package may be built into a C shared library by a main
+// package which imports it, in which case azul3d_nil_renderer provides a
+// renderer which does not render anything (e.g. for testing bindings):
+//  go build -buildmode=c-shared -o libazul3d.so azul3d.org/gfx.v1/cmd/libazul3d
+//
+// Functions returning a handle return zero if an error occurs, other functions
+// return zero on success and -1 if an error occurs. In both cases the error
+// can be retrieved with azul3d_last_error.
+package capi
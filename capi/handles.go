@@ -0,0 +1,175 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package capi
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"azul3d.org/gfx.v1"
+)
+
+// Handle is an opaque handle to a resource, as used by the C API. The zero
+// handle is never valid.
+type Handle uint64
+
+// ErrInvalidHandle is returned when a handle does not refer to a resource
+// (e.g. because it has already been released).
+var ErrInvalidHandle = errors.New("capi: invalid handle")
+
+var handles = struct {
+	sync.Mutex
+	next      Handle
+	resources map[Handle]interface{}
+}{
+	resources: make(map[Handle]interface{}),
+}
+
+// newHandle registers the resource and returns a new handle to it.
+func newHandle(v interface{}) Handle {
+	handles.Lock()
+	handles.next++
+	h := handles.next
+	handles.resources[h] = v
+	handles.Unlock()
+	return h
+}
+
+// Register registers the given renderer and returns a handle to it, such that
+// it may be used by the C API.
+//
+// This function is safe to invoke from multiple goroutines concurrently.
+func Register(r gfx.Renderer) Handle {
+	return newHandle(r)
+}
+
+// lookup returns the resource referred to by the handle.
+func lookup(h Handle) (interface{}, error) {
+	handles.Lock()
+	v, ok := handles.resources[h]
+	handles.Unlock()
+	if !ok {
+		return nil, ErrInvalidHandle
+	}
+	return v, nil
+}
+
+// wrongType returns an error describing that the handle refers to the
+// resource v, which is not of the wanted kind.
+func wrongType(h Handle, v interface{}, want string) error {
+	return fmt.Errorf("capi: handle %d is a %T, not a %s", h, v, want)
+}
+
+func lookupRenderer(h Handle) (gfx.Renderer, error) {
+	v, err := lookup(h)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := v.(gfx.Renderer)
+	if !ok {
+		return nil, wrongType(h, v, "renderer")
+	}
+	return r, nil
+}
+
+func lookupMesh(h Handle) (*gfx.Mesh, error) {
+	v, err := lookup(h)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(*gfx.Mesh)
+	if !ok {
+		return nil, wrongType(h, v, "mesh")
+	}
+	return m, nil
+}
+
+func lookupTexture(h Handle) (*gfx.Texture, error) {
+	v, err := lookup(h)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := v.(*gfx.Texture)
+	if !ok {
+		return nil, wrongType(h, v, "texture")
+	}
+	return t, nil
+}
+
+func lookupShader(h Handle) (*gfx.Shader, error) {
+	v, err := lookup(h)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(*gfx.Shader)
+	if !ok {
+		return nil, wrongType(h, v, "shader")
+	}
+	return s, nil
+}
+
+// lookupObject returns the object referred to by the handle, which may also be
+// a camera (as cameras are objects).
+func lookupObject(h Handle) (*gfx.Object, error) {
+	v, err := lookup(h)
+	if err != nil {
+		return nil, err
+	}
+	switch o := v.(type) {
+	case *gfx.Object:
+		return o, nil
+	case *gfx.Camera:
+		return o.Object, nil
+	}
+	return nil, wrongType(h, v, "object")
+}
+
+func lookupCamera(h Handle) (*gfx.Camera, error) {
+	v, err := lookup(h)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := v.(*gfx.Camera)
+	if !ok {
+		return nil, wrongType(h, v, "camera")
+	}
+	return c, nil
+}
+
+// release releases the handle, destroying the resource it refers to (except
+// for renderers, which are owned by the host program).
+func release(h Handle) error {
+	handles.Lock()
+	v, ok := handles.resources[h]
+	delete(handles.resources, h)
+	handles.Unlock()
+	if !ok {
+		return ErrInvalidHandle
+	}
+	switch r := v.(type) {
+	case *gfx.Mesh:
+		r.Lock()
+		r.Destroy()
+		r.Unlock()
+	case *gfx.Texture:
+		r.Lock()
+		r.Destroy()
+		r.Unlock()
+	case *gfx.Shader:
+		r.Lock()
+		r.Destroy()
+		r.Unlock()
+	case *gfx.Camera:
+		r.Lock()
+		r.Destroy()
+		r.Unlock()
+	case *gfx.Object:
+		r.Lock()
+		r.Destroy()
+		r.Unlock()
+	}
+	return nil
+}
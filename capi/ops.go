@@ -0,0 +1,311 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package capi
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+// This file implements each operation of the C API in Go, such that the cgo
+// wrappers (see capi.go) merely convert their arguments.
+
+var lastError struct {
+	sync.Mutex
+	msg string
+}
+
+// status records the error (if any) as the last error, and returns the C API
+// status code for it: zero on success, and -1 on failure.
+func status(err error) int {
+	if err == nil {
+		return 0
+	}
+	lastError.Lock()
+	lastError.msg = err.Error()
+	lastError.Unlock()
+	return -1
+}
+
+// handleStatus is like status, except it returns the handle on success and
+// the zero handle on failure.
+func handleStatus(h Handle, err error) Handle {
+	if status(err) != 0 {
+		return 0
+	}
+	return h
+}
+
+func newMesh() Handle {
+	return newHandle(gfx.NewMesh())
+}
+
+func meshSetVertices(h Handle, xyz []float32) error {
+	m, err := lookupMesh(h)
+	if err != nil {
+		return err
+	}
+	if len(xyz)%3 != 0 {
+		return fmt.Errorf("capi: vertex data length %d is not a multiple of 3", len(xyz))
+	}
+	m.Lock()
+	m.Vertices = m.Vertices[:0]
+	for i := 0; i < len(xyz); i += 3 {
+		m.Vertices = append(m.Vertices, gfx.Vec3{xyz[i], xyz[i+1], xyz[i+2]})
+	}
+	m.VerticesChanged = true
+	m.AABB = lmath.Rect3Zero
+	m.Unlock()
+	return nil
+}
+
+func meshSetColors(h Handle, rgba []float32) error {
+	m, err := lookupMesh(h)
+	if err != nil {
+		return err
+	}
+	if len(rgba)%4 != 0 {
+		return fmt.Errorf("capi: color data length %d is not a multiple of 4", len(rgba))
+	}
+	m.Lock()
+	m.Colors = m.Colors[:0]
+	for i := 0; i < len(rgba); i += 4 {
+		m.Colors = append(m.Colors, gfx.Color{rgba[i], rgba[i+1], rgba[i+2], rgba[i+3]})
+	}
+	m.ColorsChanged = true
+	m.Unlock()
+	return nil
+}
+
+func meshSetTexCoords(h Handle, set int, uv []float32) error {
+	m, err := lookupMesh(h)
+	if err != nil {
+		return err
+	}
+	if len(uv)%2 != 0 || set < 0 {
+		return fmt.Errorf("capi: invalid texture coordinate set %d of length %d", set, len(uv))
+	}
+	m.Lock()
+	for len(m.TexCoords) <= set {
+		m.TexCoords = append(m.TexCoords, gfx.TexCoordSet{})
+	}
+	tcs := make([]gfx.TexCoord, 0, len(uv)/2)
+	for i := 0; i < len(uv); i += 2 {
+		tcs = append(tcs, gfx.TexCoord{uv[i], uv[i+1]})
+	}
+	m.TexCoords[set] = gfx.TexCoordSet{Slice: tcs, Changed: true}
+	m.Unlock()
+	return nil
+}
+
+func meshSetIndices(h Handle, indices []uint32) error {
+	m, err := lookupMesh(h)
+	if err != nil {
+		return err
+	}
+	m.Lock()
+	m.Indices = append(m.Indices[:0], indices...)
+	m.IndicesChanged = true
+	m.Unlock()
+	return nil
+}
+
+func newTextureRGBA(pix []uint8, width, height int) (Handle, error) {
+	if width <= 0 || height <= 0 || len(pix) != width*height*4 {
+		return 0, fmt.Errorf("capi: invalid %dx%d RGBA pixel data of length %d", width, height, len(pix))
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	copy(img.Pix, pix)
+	t := gfx.NewTexture()
+	t.Source = img
+	t.MinFilter = gfx.LinearMipmapLinear
+	t.MagFilter = gfx.Linear
+	return newHandle(t), nil
+}
+
+func newShader(name, vert, frag string) Handle {
+	s := gfx.NewShader(name)
+	s.GLSLVert = []byte(vert)
+	s.GLSLFrag = []byte(frag)
+	return newHandle(s)
+}
+
+func shaderSetInputFloats(h Handle, name string, v []float32) error {
+	s, err := lookupShader(h)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	switch len(v) {
+	case 1:
+		s.Inputs[name] = v[0]
+	case 3:
+		s.Inputs[name] = gfx.Vec3{v[0], v[1], v[2]}
+	case 4:
+		s.Inputs[name] = gfx.Vec4{v[0], v[1], v[2], v[3]}
+	case 16:
+		var m gfx.Mat4
+		for i := range v {
+			m[i/4][i%4] = v[i]
+		}
+		s.Inputs[name] = m
+	default:
+		s.Inputs[name] = append([]float32(nil), v...)
+	}
+	return nil
+}
+
+// load loads the mesh, texture, or shader using the renderer and waits for it
+// to finish loading.
+func load(renderer, resource Handle) error {
+	r, err := lookupRenderer(renderer)
+	if err != nil {
+		return err
+	}
+	v, err := lookup(resource)
+	if err != nil {
+		return err
+	}
+	switch res := v.(type) {
+	case *gfx.Mesh:
+		done := make(chan *gfx.Mesh, 1)
+		r.LoadMesh(res, done)
+		<-done
+	case *gfx.Texture:
+		done := make(chan *gfx.Texture, 1)
+		r.LoadTexture(res, done)
+		<-done
+	case *gfx.Shader:
+		done := make(chan *gfx.Shader, 1)
+		r.LoadShader(res, done)
+		<-done
+		res.RLock()
+		defer res.RUnlock()
+		if len(res.Error) > 0 {
+			return errors.New(string(res.Error))
+		}
+	default:
+		return wrongType(resource, v, "mesh, texture, or shader")
+	}
+	return nil
+}
+
+func newObject(shader Handle) (Handle, error) {
+	s, err := lookupShader(shader)
+	if err != nil {
+		return 0, err
+	}
+	o := gfx.NewObject()
+	o.Shader = s
+	return newHandle(o), nil
+}
+
+func objectAddMesh(obj, mesh Handle) error {
+	o, err := lookupObject(obj)
+	if err != nil {
+		return err
+	}
+	m, err := lookupMesh(mesh)
+	if err != nil {
+		return err
+	}
+	o.Lock()
+	o.Meshes = append(o.Meshes, m)
+	o.CachedBounds = nil
+	o.Unlock()
+	return nil
+}
+
+func objectAddTexture(obj, tex Handle) error {
+	o, err := lookupObject(obj)
+	if err != nil {
+		return err
+	}
+	t, err := lookupTexture(tex)
+	if err != nil {
+		return err
+	}
+	o.Lock()
+	o.Textures = append(o.Textures, t)
+	o.Unlock()
+	return nil
+}
+
+// objectSetTransform sets the position, euler rotation (in degrees), and scale
+// of the object or camera.
+func objectSetTransform(obj Handle, pos, rot, scale lmath.Vec3) error {
+	o, err := lookupObject(obj)
+	if err != nil {
+		return err
+	}
+	o.Lock()
+	o.Transform.SetPos(pos)
+	o.Transform.SetRot(rot)
+	o.Transform.SetScale(scale)
+	o.Unlock()
+	return nil
+}
+
+func newCamera() Handle {
+	return newHandle(gfx.NewCamera())
+}
+
+func cameraSetPersp(cam, renderer Handle, fov, near, far float64) error {
+	c, err := lookupCamera(cam)
+	if err != nil {
+		return err
+	}
+	r, err := lookupRenderer(renderer)
+	if err != nil {
+		return err
+	}
+	c.Lock()
+	c.SetPersp(r.Bounds(), fov, near, far)
+	c.Unlock()
+	return nil
+}
+
+func clearRenderer(renderer Handle, bg gfx.Color) error {
+	r, err := lookupRenderer(renderer)
+	if err != nil {
+		return err
+	}
+	b := r.Bounds()
+	r.Clear(b, bg)
+	r.ClearDepth(b, 1.0)
+	return nil
+}
+
+func draw(renderer, obj, cam Handle) error {
+	r, err := lookupRenderer(renderer)
+	if err != nil {
+		return err
+	}
+	o, err := lookupObject(obj)
+	if err != nil {
+		return err
+	}
+	c, err := lookupCamera(cam)
+	if err != nil {
+		return err
+	}
+	r.Draw(r.Bounds(), o, c)
+	return nil
+}
+
+func render(renderer Handle) error {
+	r, err := lookupRenderer(renderer)
+	if err != nil {
+		return err
+	}
+	r.Render()
+	return nil
+}
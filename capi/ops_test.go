@@ -0,0 +1,64 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package capi
+
+import (
+	"testing"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+func TestOps(t *testing.T) {
+	must := func(code int) {
+		if code != 0 {
+			t.Fatal(lastError.msg)
+		}
+	}
+	r := Register(gfx.Nil())
+	mesh := newMesh()
+	must(status(meshSetVertices(mesh, []float32{-1, 0, -1, 1, 0, -1, 0, 0, 1})))
+	must(status(meshSetIndices(mesh, []uint32{0, 1, 2})))
+	tex := handleStatus(newTextureRGBA(make([]uint8, 2*2*4), 2, 2))
+	if tex == 0 {
+		t.Fatal(lastError.msg)
+	}
+	shader := newShader("test", "vert", "frag")
+	must(status(shaderSetInputFloats(shader, "Color", []float32{1, 0, 0, 1})))
+	for _, h := range []Handle{mesh, tex, shader} {
+		must(status(load(r, h)))
+	}
+
+	obj := handleStatus(newObject(shader))
+	must(status(objectAddMesh(obj, mesh)))
+	must(status(objectAddTexture(obj, tex)))
+	cam := newCamera()
+	must(status(objectSetTransform(cam, lmath.Vec3{0, -3, 0}, lmath.Vec3{}, lmath.Vec3{1, 1, 1})))
+	must(status(cameraSetPersp(cam, r, 75, 0.1, 100)))
+	must(status(clearRenderer(r, gfx.Color{0, 0, 0, 1})))
+	must(status(draw(r, obj, cam)))
+	must(status(render(r)))
+
+	for _, h := range []Handle{obj, cam, mesh, tex, shader, r} {
+		must(status(release(h)))
+	}
+}
+
+func TestOpsErrors(t *testing.T) {
+	if status(release(12345678)) != -1 || lastError.msg != ErrInvalidHandle.Error() {
+		t.Fatalf("release of invalid handle: %q", lastError.msg)
+	}
+	shader := newShader("test", "", "")
+	if status(meshSetVertices(shader, nil)) != -1 {
+		t.Fatal("expected error setting vertices of a shader")
+	}
+	mesh := newMesh()
+	if status(meshSetVertices(mesh, []float32{1, 2})) != -1 {
+		t.Fatal("expected error for vertex data of length 2")
+	}
+	if h := handleStatus(newTextureRGBA(make([]uint8, 3), 1, 1)); h != 0 {
+		t.Fatal("expected zero handle for invalid pixel data")
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command libazul3d builds the C API of package capi into a C library:
+//  go build -buildmode=c-shared -o libazul3d.so azul3d.org/gfx.v1/cmd/libazul3d
+//  go build -buildmode=c-archive -o libazul3d.a azul3d.org/gfx.v1/cmd/libazul3d
+//
+// The C declarations are found in capi/azul3d.h.
+package main
+
+import "C"
+
+import _ "azul3d.org/gfx.v1/capi"
+
+func main() {}
@@ -26,6 +26,38 @@ func (c Color) RGBA() (r, g, b, a uint32) {
 	return
 }
 
+// Mul returns the component-wise product of c and o, which is how colors are
+// tinted (see ApplyTint).
+func (c Color) Mul(o Color) Color {
+	return Color{c.R * o.R, c.G * o.G, c.B * o.B, c.A * o.A}
+}
+
+// Linear returns the sRGB-encoded color c converted into linear space. The
+// alpha component is not converted.
+func (c Color) Linear() Color {
+	return Color{srgbToLinear(c.R), srgbToLinear(c.G), srgbToLinear(c.B), c.A}
+}
+
+// SRGB returns the linear color c converted into sRGB-encoded space. The
+// alpha component is not converted.
+func (c Color) SRGB() Color {
+	return Color{linearToSRGB(c.R), linearToSRGB(c.G), linearToSRGB(c.B), c.A}
+}
+
+func srgbToLinear(v float32) float32 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return float32(math.Pow((float64(v)+0.055)/1.055, 2.4))
+}
+
+func linearToSRGB(v float32) float32 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return float32(1.055*math.Pow(float64(v), 1/2.4) - 0.055)
+}
+
 func colorModel(c color.Color) color.Color {
 	if _, ok := c.(Color); ok {
 		return c
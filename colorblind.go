@@ -0,0 +1,215 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "fmt"
+
+// ColorBlindness represents a single type of color vision deficiency, which
+// can be simulated (e.g. to audit the color accessibility of a scene) or
+// corrected for via daltonization.
+type ColorBlindness uint8
+
+// String returns a string representation of this ColorBlindness.
+// e.g. Protanopia -> "Protanopia"
+func (c ColorBlindness) String() string {
+	switch c {
+	case NoColorBlindness:
+		return "NoColorBlindness"
+	case Protanopia:
+		return "Protanopia"
+	case Deuteranopia:
+		return "Deuteranopia"
+	case Tritanopia:
+		return "Tritanopia"
+	}
+	return fmt.Sprintf("ColorBlindness(%d)", c)
+}
+
+const (
+	// NoColorBlindness represents normal color vision.
+	NoColorBlindness ColorBlindness = iota
+
+	// Protanopia represents the absence of red (long-wavelength) cones.
+	Protanopia
+
+	// Deuteranopia represents the absence of green (medium-wavelength) cones.
+	Deuteranopia
+
+	// Tritanopia represents the absence of blue (short-wavelength) cones.
+	Tritanopia
+)
+
+// colorMat3 is a 3x3 color matrix in [output][input] order, i.e. the red
+// component of the result is the dot product of the first row and the input
+// color.
+type colorMat3 [3][3]float32
+
+func (m colorMat3) apply(c Color) Color {
+	return Color{
+		R: m[0][0]*c.R + m[0][1]*c.G + m[0][2]*c.B,
+		G: m[1][0]*c.R + m[1][1]*c.G + m[1][2]*c.B,
+		B: m[2][0]*c.R + m[2][1]*c.G + m[2][2]*c.B,
+		A: c.A,
+	}
+}
+
+// mat4 converts the color matrix into a Mat4 suitable for use as a shader
+// input, such that in GLSL:
+//  vec4 result = M * color;
+func (m colorMat3) mat4() Mat4 {
+	var r Mat4
+	for out := 0; out < 3; out++ {
+		for in := 0; in < 3; in++ {
+			r[in][out] = m[out][in]
+		}
+	}
+	r[3][3] = 1
+	return r
+}
+
+// Simulation matrices (in linear RGB) from Machado, Oliveira, and Fernandes,
+// "A Physiologically-based Model for Simulation of Color Vision Deficiency"
+// (2009), at full severity.
+var colorBlindnessMats = [...]colorMat3{
+	NoColorBlindness: {
+		{1, 0, 0},
+		{0, 1, 0},
+		{0, 0, 1},
+	},
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// The error-shifting matrix used for daltonization, which redistributes the
+// color information lost to the deficiency into the channels that can still
+// be perceived.
+var daltonizeShift = colorMat3{
+	{0, 0, 0},
+	{0.7, 1, 0},
+	{0.7, 0, 1},
+}
+
+func (c ColorBlindness) mat() colorMat3 {
+	if int(c) >= len(colorBlindnessMats) {
+		panic("invalid color blindness")
+	}
+	return colorBlindnessMats[c]
+}
+
+// Simulate returns the given linear RGB color as it would be perceived by
+// someone with this color vision deficiency. The alpha component is left
+// untouched.
+//
+// A panic will occur if c is not one of the predefined constants in this
+// package.
+func (c ColorBlindness) Simulate(col Color) Color {
+	return c.mat().apply(col)
+}
+
+// Daltonize returns the given linear RGB color corrected such that someone
+// with this color vision deficiency can better distinguish it from others.
+// The alpha component is left untouched.
+//
+// A panic will occur if c is not one of the predefined constants in this
+// package.
+func (c ColorBlindness) Daltonize(col Color) Color {
+	sim := c.Simulate(col)
+	shift := daltonizeShift.apply(Color{
+		R: col.R - sim.R,
+		G: col.G - sim.G,
+		B: col.B - sim.B,
+	})
+	return Color{
+		R: col.R + shift.R,
+		G: col.G + shift.G,
+		B: col.B + shift.B,
+		A: col.A,
+	}
+}
+
+var colorBlindnessVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec2 TexCoord0;
+
+uniform mat4 MVP;
+
+varying vec2 tc0;
+
+void main()
+{
+	tc0 = TexCoord0;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+var colorBlindnessFrag = []byte(`
+#version 120
+
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+uniform mat4 Simulate;
+uniform mat4 Shift;
+uniform bool Daltonize;
+
+vec3 toLinear(vec3 c)
+{
+	return mix(c / 12.92, pow((c + 0.055) / 1.055, vec3(2.4)), step(0.04045, c));
+}
+
+vec3 toSRGB(vec3 c)
+{
+	return mix(c * 12.92, 1.055 * pow(c, vec3(1.0 / 2.4)) - 0.055, step(0.0031308, c));
+}
+
+void main()
+{
+	vec4 color = texture2D(Texture0, tc0);
+	vec3 lin = toLinear(color.rgb);
+	vec3 sim = (Simulate * vec4(lin, 1.0)).rgb;
+	if(Daltonize) {
+		vec3 shift = (Shift * vec4(lin - sim, 1.0)).rgb;
+		sim = lin + shift;
+	}
+	gl_FragColor = vec4(toSRGB(clamp(sim, 0.0, 1.0)), color.a);
+}
+`)
+
+// NewColorBlindnessShader returns a new post-processing shader which, when
+// used to draw a quad textured with the rendered scene (as the first texture
+// of the object), simulates the given color vision deficiency.
+//
+// If daltonize is true then instead of simulating the deficiency the shader
+// corrects the colors of the scene for it (see the Daltonize method).
+//
+// The scene texture is expected to hold sRGB-encoded colors, conversion to and
+// from linear RGB happens inside the shader.
+//
+// A panic will occur if c is not one of the predefined constants in this
+// package.
+func NewColorBlindnessShader(c ColorBlindness, daltonize bool) *Shader {
+	s := NewShader("ColorBlindness-" + c.String())
+	s.GLSLVert = append(s.GLSLVert, colorBlindnessVert...)
+	s.GLSLFrag = append(s.GLSLFrag, colorBlindnessFrag...)
+	s.Inputs["Simulate"] = c.mat().mat4()
+	s.Inputs["Shift"] = daltonizeShift.mat4()
+	s.Inputs["Daltonize"] = daltonize
+	return s
+}
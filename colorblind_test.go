@@ -0,0 +1,87 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+)
+
+func colorNear(a, b Color) bool {
+	const eps = 1e-5
+	return math.Abs(float64(a.R-b.R)) < eps &&
+		math.Abs(float64(a.G-b.G)) < eps &&
+		math.Abs(float64(a.B-b.B)) < eps &&
+		math.Abs(float64(a.A-b.A)) < eps
+}
+
+func TestColorBlindnessSimulate(t *testing.T) {
+	for _, c := range []struct {
+		cb       ColorBlindness
+		in, want Color
+	}{
+		// Primaries map onto the columns of the Machado et al. matrices.
+		{Protanopia, Color{1, 0, 0, 1}, Color{0.152286, 0.114503, -0.003882, 1}},
+		{Deuteranopia, Color{0, 1, 0, 1}, Color{0.860646, 0.672501, 0.042940, 1}},
+		{Tritanopia, Color{0, 0, 1, 1}, Color{-0.178779, 0.147602, 0.303900, 1}},
+
+		// Normal vision is the identity, and the alpha is left untouched.
+		{NoColorBlindness, Color{0.2, 0.4, 0.6, 0.5}, Color{0.2, 0.4, 0.6, 0.5}},
+		{Protanopia, Color{0, 0, 0, 0.25}, Color{0, 0, 0, 0.25}},
+	} {
+		if got := c.cb.Simulate(c.in); !colorNear(got, c.want) {
+			t.Errorf("%v.Simulate(%v) = %v, want %v", c.cb, c.in, got, c.want)
+		}
+	}
+
+	// Achromatic colors are perceived identically by everyone.
+	for cb := NoColorBlindness; cb <= Tritanopia; cb++ {
+		for _, gray := range []Color{{1, 1, 1, 1}, {0.5, 0.5, 0.5, 1}} {
+			if got := cb.Simulate(gray); !colorNear(got, gray) {
+				t.Errorf("%v.Simulate(%v) = %v, want it unchanged", cb, gray, got)
+			}
+		}
+	}
+}
+
+func TestColorBlindnessDaltonize(t *testing.T) {
+	// The red lost to protanopia is shifted into the green and blue channels.
+	got := Protanopia.Daltonize(Color{1, 0, 0, 1})
+	want := Color{1, 0.478897, 0.597282, 1}
+	if !colorNear(got, want) {
+		t.Errorf("Protanopia.Daltonize(red) = %v, want %v", got, want)
+	}
+
+	// Colors which lose nothing to the deficiency are left unchanged.
+	for cb := NoColorBlindness; cb <= Tritanopia; cb++ {
+		gray := Color{0.5, 0.5, 0.5, 0.75}
+		if got := cb.Daltonize(gray); !colorNear(got, gray) {
+			t.Errorf("%v.Daltonize(%v) = %v, want it unchanged", cb, gray, got)
+		}
+	}
+}
+
+func TestColorBlindnessShaderMatrix(t *testing.T) {
+	// The shader input must compute the same result as Simulate, as GLSL's
+	// M * color (where each Mat4 row is a GLSL column).
+	col := Color{0.9, 0.3, 0.1, 1}
+	m := Deuteranopia.mat().mat4()
+	in := [4]float32{col.R, col.G, col.B, 1}
+	var out [4]float32
+	for i := range out {
+		for j := range in {
+			out[i] += m[j][i] * in[j]
+		}
+	}
+	got := Color{out[0], out[1], out[2], out[3]}
+	if want := Deuteranopia.Simulate(col); !colorNear(got, want) {
+		t.Errorf("shader matrix gives %v, want %v", got, want)
+	}
+
+	s := NewColorBlindnessShader(Tritanopia, true)
+	if s.Name != "ColorBlindness-Tritanopia" || s.Inputs["Daltonize"] != true {
+		t.Errorf("got shader %q with inputs %v", s.Name, s.Inputs)
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CommandLog records the last N renderer commands (e.g. "Draw", "LoadMesh")
+// such that they can be included in crash reports. Renderers append to it as
+// they process commands.
+//
+// It is safe to use from multiple goroutines concurrently.
+type CommandLog struct {
+	access      sync.Mutex
+	cmds        []string
+	start, size int
+}
+
+// Add formats the command according to the format specifier (see
+// fmt.Sprintf) and adds it to the log, discarding the oldest command if the
+// log is full.
+func (l *CommandLog) Add(format string, args ...interface{}) {
+	cmd := fmt.Sprintf(format, args...)
+	l.access.Lock()
+	if len(l.cmds) > 0 {
+		l.cmds[(l.start+l.size)%len(l.cmds)] = cmd
+		if l.size < len(l.cmds) {
+			l.size++
+		} else {
+			l.start = (l.start + 1) % len(l.cmds)
+		}
+	}
+	l.access.Unlock()
+}
+
+// Commands returns a copy of the commands in the log, oldest first.
+func (l *CommandLog) Commands() []string {
+	l.access.Lock()
+	cmds := make([]string, l.size)
+	for i := range cmds {
+		cmds[i] = l.cmds[(l.start+i)%len(l.cmds)]
+	}
+	l.access.Unlock()
+	return cmds
+}
+
+// NewCommandLog returns a new command log which keeps the last n commands.
+func NewCommandLog(n int) *CommandLog {
+	return &CommandLog{
+		cmds: make([]string, n),
+	}
+}
+
+// CrashReport represents the information gathered about a crash (i.e. a
+// panic) of the application.
+type CrashReport struct {
+	// The time at which the crash occurred.
+	Time time.Time
+
+	// The value that was passed to panic.
+	Panic interface{}
+
+	// The stack trace of the panicking goroutine.
+	Stack []byte
+
+	// Information about the graphics hardware, or nil if not available.
+	GPUInfo *GPUInfo
+
+	// The last renderer commands (see CommandLog), oldest first.
+	Commands []string
+
+	// Arbitrary application-defined properties (e.g. version, level name).
+	Props map[string]string
+}
+
+// WriteTo writes a human-readable form of the report to w.
+func (r *CrashReport) WriteTo(w io.Writer) (n int64, err error) {
+	var total int64
+	p := func(format string, args ...interface{}) {
+		if err != nil {
+			return
+		}
+		var c int
+		c, err = fmt.Fprintf(w, format, args...)
+		total += int64(c)
+	}
+
+	p("Crash report (%s)\n\n", r.Time.Format(time.RFC3339))
+	p("panic: %v\n\n%s\n", r.Panic, r.Stack)
+	if r.GPUInfo != nil {
+		g := r.GPUInfo
+		p("GPU:\n")
+		p("  Name: %s\n  Vendor: %s\n", g.Name, g.Vendor)
+		p("  OpenGL: %d.%d\n  GLSL: %d.%d\n", g.GLMajor, g.GLMinor, g.GLSLMajor, g.GLSLMinor)
+		p("  MaxTextureSize: %d\n  NPOT: %t\n", g.MaxTextureSize, g.NPOT)
+		p("  Extensions: %v\n\n", g.GLExtensions)
+	}
+	if len(r.Commands) > 0 {
+		p("Last %d renderer commands:\n", len(r.Commands))
+		for _, c := range r.Commands {
+			p("  %s\n", c)
+		}
+		p("\n")
+	}
+	if len(r.Props) > 0 {
+		keys := make([]string, 0, len(r.Props))
+		for k := range r.Props {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		p("Properties:\n")
+		for _, k := range keys {
+			p("  %s: %s\n", k, r.Props[k])
+		}
+	}
+	return total, err
+}
+
+// CrashHandler writes crash reports to disk when a panic occurs. It is used
+// by deferring a call to it's Recover method at the top of a goroutine (e.g.
+// the main loop):
+//  h := &gfx.CrashHandler{
+//      Renderer: r,
+//      OnCrash: func(report *gfx.CrashReport, path string) {
+//          log.Println("crash report written to", path)
+//      },
+//  }
+//  defer h.Recover()
+type CrashHandler struct {
+	// The directory to write crash reports to, or an empty string for the
+	// system's temporary directory.
+	Dir string
+
+	// The renderer whose GPU information is included in the report, or nil.
+	Renderer Renderer
+
+	// The log of renderer commands to include in the report, or nil.
+	Log *CommandLog
+
+	// Arbitrary properties to include in the report.
+	Props map[string]string
+
+	// An optional function called after the report is written (path is the
+	// empty string if writing the report failed), before re-panicking.
+	OnCrash func(report *CrashReport, path string)
+}
+
+// Recover recovers from a panic (if any), writes a crash report to disk,
+// invokes the OnCrash callback, and then panics again with the original
+// value. It must be called directly via defer.
+func (h *CrashHandler) Recover() {
+	v := recover()
+	if v == nil {
+		return
+	}
+	report := &CrashReport{
+		Time:  time.Now(),
+		Panic: v,
+		Stack: debug.Stack(),
+		Props: h.Props,
+	}
+	if h.Log != nil {
+		report.Commands = h.Log.Commands()
+	}
+
+	// Querying the renderer may itself panic (e.g. if it is the cause of the
+	// crash), in which case the GPU information is left out.
+	func() {
+		defer func() {
+			recover()
+		}()
+		if h.Renderer != nil {
+			info := h.Renderer.GPUInfo()
+			report.GPUInfo = &info
+		}
+	}()
+
+	path, err := h.write(report)
+	if err != nil {
+		path = ""
+	}
+	if h.OnCrash != nil {
+		h.OnCrash(report, path)
+	}
+	panic(v)
+}
+
+func (h *CrashHandler) write(r *CrashReport) (string, error) {
+	dir := h.Dir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	name := fmt.Sprintf("azul3d-crash-%s.txt", r.Time.Format("20060102-150405"))
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	_, err = r.WriteTo(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return path, err
+}
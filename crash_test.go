@@ -0,0 +1,58 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCrashHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gfx-crash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	log := NewCommandLog(2)
+	log.Add("Draw %d", 1)
+	log.Add("Draw %d", 2)
+	log.Add("Draw %d", 3)
+
+	var reportPath string
+	h := &CrashHandler{
+		Dir:      dir,
+		Renderer: Nil(),
+		Log:      log,
+		Props:    map[string]string{"version": "1.0"},
+		OnCrash: func(r *CrashReport, path string) {
+			reportPath = path
+		},
+	}
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Fatal("expected re-panic, got", v)
+			}
+		}()
+		defer h.Recover()
+		panic("boom")
+	}()
+
+	data, err := ioutil.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"panic: boom", "Draw 2", "Draw 3", "version: 1.0", "MaxTextureSize: 8096"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+	if strings.Contains(string(data), "Draw 1") {
+		t.Error("report contains discarded command")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+
+	"azul3d.org/lmath.v1"
+)
+
+// Cull appends each object whose world space bounds are at least partially
+// inside the frustum to dst, and returns the extended slice. Objects entirely
+// outside of the frustum are skipped.
+//
+// The world space bounds of each object are the bounds of it's meshes (see
+// Object.Bounds) transformed by it's world matrix using TransformRect3s, such
+// that they remain conservative for rotated objects.
+//
+// The method properly locks the objects when required.
+func (f Frustum) Cull(dst, objs []*Object) []*Object {
+	boxes := make([]lmath.Rect3, len(objs))
+	mats := make([]lmath.Mat4, len(objs))
+	for i, o := range objs {
+		o.Lock()
+		boxes[i] = o.localBounds()
+		mats[i] = lmath.Mat4Identity
+		if o.Transform != nil {
+			mats[i] = o.Transform.Mat4()
+		}
+		o.Unlock()
+	}
+	TransformRect3s(boxes, boxes, mats)
+	visible := make([]bool, len(objs))
+	CullRect3s(visible, boxes, f.Coeffs())
+	for i, o := range objs {
+		if visible[i] {
+			dst = append(dst, o)
+		}
+	}
+	return dst
+}
+
+// DrawCulled draws each object onto the given rectangle of the canvas, as seen
+// by the camera, skipping the objects which are entirely outside of the
+// camera's viewing frustum (see Frustum.Cull). It returns the number of
+// objects that were drawn.
+//
+// Large scenes should use it (or Frustum.Cull) instead of drawing every
+// object each frame, as objects outside of the frustum still cost a draw call
+// even though they do not produce any pixels.
+//
+// The function properly locks the camera and objects when required.
+func DrawCulled(c Canvas, r image.Rectangle, cam *Camera, objs []*Object) int {
+	cam.RLock()
+	f := cam.Frustum()
+	cam.RUnlock()
+
+	visible := f.Cull(nil, objs)
+	for _, o := range visible {
+		c.Draw(r, o, cam)
+	}
+	return len(visible)
+}
@@ -0,0 +1,427 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	"azul3d.org/clock.v1"
+)
+
+const (
+	// EnvTrace is the name of the environment variable which enables API
+	// tracing (see DebugConfigFromEnv).
+	EnvTrace = "AZUL3D_TRACE"
+
+	// EnvValidate is the name of the environment variable which enables
+	// validation (see DebugConfigFromEnv).
+	EnvValidate = "AZUL3D_VALIDATE"
+)
+
+// DebugConfig configures the debugging wrapper returned by the Debug
+// function.
+type DebugConfig struct {
+	// If non-nil, a trace of every renderer call is written to it.
+	Trace io.Writer
+
+	// If non-nil, every renderer call is added to it (e.g. such that the last
+	// calls appear in crash reports, see CrashHandler).
+	Log *CommandLog
+
+	// Whether or not renderer calls should be validated. Validation detects
+	// API misuse (such as drawing an object which will be silently skipped,
+	// or ending a timer that was never begun) and reports it via OnError.
+	//
+	// Renderer backends may additionally enable their own validation (e.g. GL
+	// error checks) when this is true.
+	Validate bool
+
+	// The function called for each validation error, or nil to log them using
+	// the standard log package.
+	OnError func(err error)
+}
+
+// Enabled tells if any debugging option in the configuration is enabled.
+func (c DebugConfig) Enabled() bool {
+	return c.Trace != nil || c.Log != nil || c.Validate
+}
+
+// DebugConfigFromEnv returns the debug configuration specified by the
+// environment, such that diagnostics may be captured from release builds
+// without code changes:
+//  AZUL3D_TRACE=stderr     Write an API trace to standard error.
+//  AZUL3D_TRACE=trace.txt  Write an API trace to the named file.
+//  AZUL3D_VALIDATE=1       Enable validation.
+//
+// An error is returned if the trace file cannot be created or if
+// AZUL3D_VALIDATE is not a valid boolean.
+func DebugConfigFromEnv() (DebugConfig, error) {
+	var c DebugConfig
+	switch trace := os.Getenv(EnvTrace); trace {
+	case "", "0":
+	case "1", "stderr":
+		c.Trace = os.Stderr
+	case "stdout":
+		c.Trace = os.Stdout
+	default:
+		f, err := os.Create(trace)
+		if err != nil {
+			return c, err
+		}
+		c.Trace = f
+	}
+	if v := os.Getenv(EnvValidate); v != "" {
+		validate, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, fmt.Errorf("%s: %v", EnvValidate, err)
+		}
+		c.Validate = validate
+	}
+	return c, nil
+}
+
+// Debug returns a renderer which wraps r, tracing and validating calls to it
+// according to the given configuration. If no option in the configuration is
+// enabled then r is returned as-is.
+//
+// Canvases returned by the wrapper's RenderToTexture method are wrapped as
+// well.
+func Debug(r Renderer, cfg DebugConfig) Renderer {
+	if !cfg.Enabled() {
+		return r
+	}
+	d := &debugRenderer{r: r}
+	d.debugCanvas = debugCanvas{
+		Canvas: r,
+		name:   "Renderer",
+		shared: &debugShared{cfg: cfg},
+		timers: make(map[string]bool),
+	}
+	return d
+}
+
+// debugShared is the state shared by a debug renderer and all of it's
+// canvases.
+type debugShared struct {
+	sync.Mutex
+	cfg DebugConfig
+}
+
+func (s *debugShared) trace(format string, args ...interface{}) {
+	if s.cfg.Log != nil {
+		s.cfg.Log.Add(format, args...)
+	}
+	if s.cfg.Trace != nil {
+		s.Lock()
+		fmt.Fprintf(s.cfg.Trace, format+"\n", args...)
+		s.Unlock()
+	}
+}
+
+func (s *debugShared) errorf(format string, args ...interface{}) {
+	if !s.cfg.Validate {
+		return
+	}
+	err := errors.New("gfx: " + fmt.Sprintf(format, args...))
+	if s.cfg.OnError != nil {
+		s.cfg.OnError(err)
+		return
+	}
+	log.Println(err)
+}
+
+// debugCanvas wraps a canvas, tracing and validating calls to it.
+type debugCanvas struct {
+	Canvas
+	name   string
+	shared *debugShared
+
+	timersAccess sync.Mutex
+	timers       map[string]bool
+}
+
+func (d *debugCanvas) Download(r image.Rectangle, complete chan image.Image) {
+	d.shared.trace("%s.Download(%v)", d.name, r)
+	d.Canvas.Download(r, complete)
+}
+
+func (d *debugCanvas) DownloadDepth(r image.Rectangle, complete chan []float32) {
+	d.shared.trace("%s.DownloadDepth(%v)", d.name, r)
+	d.Canvas.DownloadDepth(r, complete)
+}
+
+func (d *debugCanvas) SetMSAA(enabled bool) {
+	d.shared.trace("%s.SetMSAA(%t)", d.name, enabled)
+	d.Canvas.SetMSAA(enabled)
+}
+
+func (d *debugCanvas) SetSRGB(enabled bool) {
+	d.shared.trace("%s.SetSRGB(%t)", d.name, enabled)
+	d.Canvas.SetSRGB(enabled)
+}
+
+func (d *debugCanvas) SetOutputTransform(t OutputTransform) {
+	d.shared.trace("%s.SetOutputTransform(%+v)", d.name, t)
+	if t.Encoding == EncodeGamma && t.Gamma <= 0 {
+		d.shared.errorf("%s.SetOutputTransform: invalid gamma %v", d.name, t.Gamma)
+	}
+	d.Canvas.SetOutputTransform(t)
+}
+
+func (d *debugCanvas) Clear(r image.Rectangle, bg Color) {
+	d.shared.trace("%s.Clear(%v, %v)", d.name, r, bg)
+	d.Canvas.Clear(r, bg)
+}
+
+func (d *debugCanvas) ClearDepth(r image.Rectangle, depth float64) {
+	d.shared.trace("%s.ClearDepth(%v, %v)", d.name, r, depth)
+	if depth < 0 || depth > 1 {
+		d.shared.errorf("%s.ClearDepth: depth %v outside of range [0, 1]", d.name, depth)
+	}
+	d.Canvas.ClearDepth(r, depth)
+}
+
+func (d *debugCanvas) ClearStencil(r image.Rectangle, stencil int) {
+	d.shared.trace("%s.ClearStencil(%v, %v)", d.name, r, stencil)
+	d.Canvas.ClearStencil(r, stencil)
+}
+
+func (d *debugCanvas) Draw(r image.Rectangle, o *Object, c *Camera) {
+	d.shared.trace("%s.Draw(%v, %p, %p)", d.name, r, o, c)
+	if o == nil {
+		d.shared.errorf("%s.Draw: nil object", d.name)
+		return
+	}
+	if d.shared.cfg.Validate {
+		o.RLock()
+		d.validateObject(o)
+		o.RUnlock()
+	}
+	d.Canvas.Draw(r, o, c)
+}
+
+// validateObject validates the given object, whose read lock must be held.
+func (d *debugCanvas) validateObject(o *Object) {
+	switch {
+	case o.Shader == nil:
+		d.shared.errorf("%s.Draw: object has no shader and will not be drawn", d.name)
+	case len(o.Shader.Error) > 0:
+		d.shared.errorf("%s.Draw: object's shader %q has errors and will not be drawn", d.name, o.Shader.Name)
+	case len(o.Meshes) == 0:
+		d.shared.errorf("%s.Draw: object has no meshes and will not be drawn", d.name)
+	}
+	for i, m := range o.Meshes {
+		if m == nil {
+			d.shared.errorf("%s.Draw: object's mesh %d is nil", d.name, i)
+		}
+	}
+	for i, t := range o.Textures {
+		if t == nil {
+			d.shared.errorf("%s.Draw: object's texture %d is nil", d.name, i)
+		}
+	}
+	if o.Condition != nil && o.Condition != o {
+		o.Condition.RLock()
+		if !o.Condition.OcclusionTest {
+			d.shared.errorf("%s.Draw: object's condition object does not have OcclusionTest enabled", d.name)
+		}
+		o.Condition.RUnlock()
+	}
+}
+
+func (d *debugCanvas) QueryWait() {
+	d.shared.trace("%s.QueryWait()", d.name)
+	d.Canvas.QueryWait()
+}
+
+func (d *debugCanvas) InsertFence() Fence {
+	d.shared.trace("%s.InsertFence()", d.name)
+	return d.Canvas.InsertFence()
+}
+
+func (d *debugCanvas) WaitFence(f Fence) {
+	d.shared.trace("%s.WaitFence(%v)", d.name, f)
+	if f == nil {
+		d.shared.errorf("%s.WaitFence: nil fence", d.name)
+		return
+	}
+	d.Canvas.WaitFence(f)
+}
+
+func (d *debugCanvas) BeginTimer(name string) {
+	d.shared.trace("%s.BeginTimer(%q)", d.name, name)
+	d.timersAccess.Lock()
+	if d.timers[name] {
+		d.shared.errorf("%s.BeginTimer: timer %q already begun", d.name, name)
+	}
+	d.timers[name] = true
+	d.timersAccess.Unlock()
+	d.Canvas.BeginTimer(name)
+}
+
+func (d *debugCanvas) EndTimer(name string) {
+	d.shared.trace("%s.EndTimer(%q)", d.name, name)
+	d.timersAccess.Lock()
+	if !d.timers[name] {
+		d.shared.errorf("%s.EndTimer: timer %q was never begun", d.name, name)
+	}
+	delete(d.timers, name)
+	d.timersAccess.Unlock()
+	d.Canvas.EndTimer(name)
+}
+
+func (d *debugCanvas) Feedback(o *Object, c *Camera, complete chan []Vec3) {
+	d.shared.trace("%s.Feedback(%p, %p)", d.name, o, c)
+	if o == nil {
+		d.shared.errorf("%s.Feedback: nil object", d.name)
+		complete <- nil
+		return
+	}
+	if d.shared.cfg.Validate {
+		o.RLock()
+		d.validateObject(o)
+		o.RUnlock()
+	}
+	d.Canvas.Feedback(o, c, complete)
+}
+
+func (d *debugCanvas) ResolveTo(dst Canvas) {
+	d.shared.trace("%s.ResolveTo(%v)", d.name, dst)
+	if dc, ok := dst.(*debugCanvas); ok {
+		dst = dc.Canvas
+	}
+	d.Canvas.ResolveTo(dst)
+}
+
+func (d *debugCanvas) Render() {
+	d.shared.trace("%s.Render()", d.name)
+	d.timersAccess.Lock()
+	for name := range d.timers {
+		d.shared.errorf("%s.Render: timer %q was never ended", d.name, name)
+	}
+	d.timersAccess.Unlock()
+	d.Canvas.Render()
+}
+
+// debugRenderer wraps a renderer, tracing and validating calls to it.
+type debugRenderer struct {
+	debugCanvas
+	r Renderer
+
+	canvasesAccess sync.Mutex
+	canvases       int
+}
+
+func (d *debugRenderer) Clock() *clock.Clock {
+	return d.r.Clock()
+}
+
+func (d *debugRenderer) GPUInfo() GPUInfo {
+	return d.r.GPUInfo()
+}
+
+func (d *debugRenderer) PipelineStats() PipelineStats {
+	return d.r.PipelineStats()
+}
+
+func (d *debugRenderer) SwapChain() SwapChainInfo {
+	return d.r.SwapChain()
+}
+
+func (d *debugRenderer) Hooks() *FrameHooks {
+	return d.r.Hooks()
+}
+
+func (d *debugRenderer) Events() *FrameEvents {
+	return d.r.Events()
+}
+
+func (d *debugRenderer) LoadMesh(m *Mesh, done chan *Mesh) {
+	d.shared.trace("Renderer.LoadMesh(%p)", m)
+	if m == nil {
+		d.shared.errorf("Renderer.LoadMesh: nil mesh")
+		return
+	}
+	if d.shared.cfg.Validate {
+		m.RLock()
+		if m.Shared() {
+			d.shared.errorf("Renderer.LoadMesh: mesh is shared (call Mesh.Unshare first)")
+		}
+		m.RUnlock()
+	}
+	d.r.LoadMesh(m, done)
+}
+
+func (d *debugRenderer) LoadTexture(t *Texture, done chan *Texture) {
+	d.shared.trace("Renderer.LoadTexture(%p)", t)
+	if t == nil {
+		d.shared.errorf("Renderer.LoadTexture: nil texture")
+		return
+	}
+	if d.shared.cfg.Validate {
+		t.RLock()
+		_, compressed := t.Source.(*CompressedImage)
+		s := d.r.GPUInfo().NegotiateTexFormat(t.Format, compressed)
+		if s.Conversion == Unsupported {
+			d.shared.errorf("Renderer.LoadTexture: compressed format %v is not supported", t.Format)
+		}
+		t.RUnlock()
+	}
+	d.r.LoadTexture(t, done)
+}
+
+func (d *debugRenderer) DownloadTexture(t *Texture, complete chan image.Image) {
+	d.shared.trace("Renderer.DownloadTexture(%p)", t)
+	if t == nil {
+		d.shared.errorf("Renderer.DownloadTexture: nil texture")
+		complete <- nil
+		return
+	}
+	if d.shared.cfg.Validate {
+		t.RLock()
+		if !t.Loaded {
+			d.shared.errorf("Renderer.DownloadTexture: texture is not loaded")
+		}
+		t.RUnlock()
+	}
+	d.r.DownloadTexture(t, complete)
+}
+
+func (d *debugRenderer) LoadShader(s *Shader, done chan *Shader) {
+	d.shared.trace("Renderer.LoadShader(%p)", s)
+	if s == nil {
+		d.shared.errorf("Renderer.LoadShader: nil shader")
+		return
+	}
+	d.r.LoadShader(s, done)
+}
+
+func (d *debugRenderer) RenderToTexture(cfg RTTConfig) Canvas {
+	d.shared.trace("Renderer.RenderToTexture(%v)", cfg.Bounds)
+	if !cfg.Valid() {
+		d.shared.errorf("Renderer.RenderToTexture: invalid configuration")
+	}
+	c := d.r.RenderToTexture(cfg)
+	if c == nil {
+		return nil
+	}
+	d.canvasesAccess.Lock()
+	d.canvases++
+	name := fmt.Sprintf("Canvas%d", d.canvases)
+	d.canvasesAccess.Unlock()
+	return &debugCanvas{
+		Canvas: c,
+		name:   name,
+		shared: d.shared,
+		timers: make(map[string]bool),
+	}
+}
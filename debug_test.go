@@ -0,0 +1,69 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDebugDisabled(t *testing.T) {
+	r := Nil()
+	if Debug(r, DebugConfig{}) != r {
+		t.Fatal("expected renderer to be returned as-is")
+	}
+}
+
+func TestDebugTraceValidate(t *testing.T) {
+	var (
+		trace bytes.Buffer
+		errs  []error
+	)
+	r := Debug(Nil(), DebugConfig{
+		Trace:    &trace,
+		Validate: true,
+		OnError: func(err error) {
+			errs = append(errs, err)
+		},
+	})
+
+	r.Clear(image.Rectangle{}, Color{A: 1})
+	r.Draw(image.Rectangle{}, NewObject(), nil)
+	r.EndTimer("shadows")
+	r.BeginTimer("main")
+	r.Render()
+
+	for _, want := range []string{"Renderer.Clear(", "Renderer.Draw(", "Renderer.Render()"} {
+		if !strings.Contains(trace.String(), want) {
+			t.Errorf("trace missing %q:\n%s", want, trace.String())
+		}
+	}
+	if len(errs) != 3 {
+		t.Fatalf("got %d validation errors, want 3: %v", len(errs), errs)
+	}
+}
+
+func TestDebugConfigFromEnv(t *testing.T) {
+	defer os.Setenv(EnvTrace, os.Getenv(EnvTrace))
+	defer os.Setenv(EnvValidate, os.Getenv(EnvValidate))
+	os.Setenv(EnvTrace, "stderr")
+	os.Setenv(EnvValidate, "1")
+
+	c, err := DebugConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Trace != os.Stderr || !c.Validate {
+		t.Fatalf("got %+v", c)
+	}
+
+	os.Setenv(EnvValidate, "maybe")
+	if _, err := DebugConfigFromEnv(); err == nil {
+		t.Fatal("expected error for invalid AZUL3D_VALIDATE")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// DrawDistance describes the maximum draw distance of a category of objects.
+type DrawDistance struct {
+	// The maximum distance from the camera at which objects are drawn, or
+	// zero for no limit.
+	Max float64
+
+	// The width of the region before the maximum distance over which objects
+	// fade out, instead of popping out of view abruptly. Zero disables
+	// fading.
+	Fade float64
+}
+
+// Factor returns the fade factor of an object at the given distance from the
+// camera: 1.0 if it is fully visible, 0.0 if it is beyond the maximum draw
+// distance, and a linear ramp between the two inside the fade region.
+func (d DrawDistance) Factor(dist float64) float64 {
+	switch {
+	case d.Max <= 0 || dist <= d.Max-d.Fade:
+		return 1
+	case dist >= d.Max:
+		return 0
+	}
+	return (d.Max - dist) / d.Fade
+}
+
+// DrawDistances maps object categories (see Object.Category) to their draw
+// distances, allowing open-world scenes to tune performance per category
+// (e.g. small props disappear sooner than buildings) without touching every
+// object:
+//  distances := gfx.DrawDistances{
+//      "foliage": {Max: 150, Fade: 20},
+//      "props":   {Max: 300, Fade: 30},
+//  }
+//
+// Objects whose category is not in the map have no draw distance limit.
+type DrawDistances map[string]DrawDistance
+
+// rectDist returns the distance from p to the closest point of r.
+func rectDist(r lmath.Rect3, p lmath.Vec3) float64 {
+	dx := math.Max(0, math.Max(r.Min.X-p.X, p.X-r.Max.X))
+	dy := math.Max(0, math.Max(r.Min.Y-p.Y, p.Y-r.Max.Y))
+	dz := math.Max(0, math.Max(r.Min.Z-p.Z, p.Z-r.Max.Z))
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// Cull appends each object which is within it's category's draw distance of
+// the eye position (typically the camera's world position) to dst, and
+// returns the extended slice. The distance is measured to the closest point
+// of the object's bounds.
+//
+// The Fade field of each object is set according to the fade factor (see the
+// DrawDistance.Factor method), such that objects fade out smoothly at the
+// boundary.
+//
+// The method properly locks the objects when required.
+func (d DrawDistances) Cull(dst, objs []*Object, eye lmath.Vec3) []*Object {
+	for _, o := range objs {
+		o.RLock()
+		dd, ok := d[o.Category]
+		o.RUnlock()
+
+		fade := 1.0
+		if ok && dd.Max > 0 {
+			fade = dd.Factor(rectDist(o.Bounds(), eye))
+		}
+
+		o.Lock()
+		o.Fade = float32(fade)
+		o.Unlock()
+		if fade > 0 {
+			dst = append(dst, o)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,53 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestDrawDistanceFactor(t *testing.T) {
+	d := DrawDistance{Max: 100, Fade: 20}
+	tests := []struct {
+		dist, want float64
+	}{
+		{0, 1},
+		{80, 1},
+		{90, 0.5},
+		{100, 0},
+		{150, 0},
+	}
+	for _, tst := range tests {
+		if got := d.Factor(tst.dist); got != tst.want {
+			t.Errorf("Factor(%v) = %v, want %v", tst.dist, got, tst.want)
+		}
+	}
+}
+
+func TestDrawDistancesCull(t *testing.T) {
+	newObj := func(category string, x float64) *Object {
+		o := NewObject()
+		o.Category = category
+		b := lmath.Rect3{Min: lmath.Vec3{-1, -1, -1}, Max: lmath.Vec3{1, 1, 1}}
+		o.CachedBounds = &b
+		o.Transform.SetPos(lmath.Vec3{x, 0, 0})
+		return o
+	}
+	near := newObj("props", 50)
+	fading := newObj("props", 91) // Closest point is at x=90.
+	far := newObj("props", 200)
+	unlimited := newObj("", 1000)
+
+	d := DrawDistances{"props": {Max: 100, Fade: 20}}
+	visible := d.Cull(nil, []*Object{near, fading, far, unlimited}, lmath.Vec3{})
+	if len(visible) != 3 || visible[0] != near || visible[1] != fading || visible[2] != unlimited {
+		t.Fatalf("unexpected visible objects %v", visible)
+	}
+	if near.Fade != 1 || fading.Fade != 0.5 || far.Fade != 0 || unlimited.Fade != 1 {
+		t.Fatalf("fades %v %v %v %v", near.Fade, fading.Fade, far.Fade, unlimited.Fade)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FrameEventKind specifies which point of a frame's lifecycle a frame event
+// describes, see the FrameEvents type.
+type FrameEventKind uint8
+
+// String returns a string representation of this frame event kind.
+// e.g. FrameBegin -> "FrameBegin"
+func (k FrameEventKind) String() string {
+	switch k {
+	case FrameBegin:
+		return "FrameBegin"
+	case FramePrePresent:
+		return "FramePrePresent"
+	case FrameEnd:
+		return "FrameEnd"
+	}
+	return fmt.Sprintf("FrameEventKind(%d)", k)
+}
+
+const (
+	// FrameBegin is emitted when the renderer begins executing the operations
+	// of a frame (i.e. once Render is called).
+	FrameBegin FrameEventKind = iota
+
+	// FramePrePresent is emitted after every operation of the frame has been
+	// executed, immediately before the frame is presented.
+	FramePrePresent
+
+	// FrameEnd is emitted once the frame has been presented.
+	FrameEnd
+)
+
+// FrameEvent describes a point in the lifecycle of a single frame.
+type FrameEvent struct {
+	// The kind of event.
+	Kind FrameEventKind
+
+	// The index of the frame, starting at zero and incremented by one for
+	// each frame rendered.
+	Frame uint64
+
+	// The time at which the event occurred.
+	Time time.Time
+
+	// The time elapsed since the FrameBegin event of this frame (i.e. zero for
+	// FrameBegin events).
+	Elapsed time.Duration
+
+	// The duration of the previous frame, from it's FrameBegin event to this
+	// frame's FrameBegin event, or zero for the first frame.
+	FrameTime time.Duration
+}
+
+// FrameEvents emits frame lifecycle events to subscribers, such that systems
+// like audio synchronization, network send batching, and profilers can align
+// their work with the renderer. Unlike FrameHooks, events are delivered over
+// channels to subscribers running on their own goroutines, and the renderer
+// never waits for subscribers: if a subscriber's channel is full the event is
+// dropped for that subscriber.
+//
+// It is safe to use from multiple goroutines concurrently.
+type FrameEvents struct {
+	access      sync.Mutex
+	subscribers []chan FrameEvent
+	frame       uint64
+	begin       time.Time
+	started     bool
+}
+
+// Subscribe subscribes to frame events, returning a channel over which they
+// are sent, with the given buffer size (a buffer of at least three holds
+// every event of a single frame). The returned function cancels the
+// subscription and closes the channel when called.
+func (e *FrameEvents) Subscribe(buffer int) (events <-chan FrameEvent, cancel func()) {
+	ch := make(chan FrameEvent, buffer)
+	e.access.Lock()
+	e.subscribers = append(e.subscribers, ch)
+	e.access.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			e.access.Lock()
+			for i, other := range e.subscribers {
+				if other == ch {
+					e.subscribers = append(e.subscribers[:i:i], e.subscribers[i+1:]...)
+					break
+				}
+			}
+			e.access.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Emit emits an event of the given kind to every subscriber. It is called by
+// renderers, once for each kind of event each frame and in order (FrameBegin,
+// FramePrePresent, then FrameEnd).
+func (e *FrameEvents) Emit(kind FrameEventKind) {
+	now := time.Now()
+	e.access.Lock()
+	defer e.access.Unlock()
+	ev := FrameEvent{
+		Kind: kind,
+		Time: now,
+	}
+	if kind == FrameBegin {
+		if e.started {
+			ev.FrameTime = now.Sub(e.begin)
+			e.frame++
+		}
+		e.begin = now
+		e.started = true
+	} else {
+		ev.Elapsed = now.Sub(e.begin)
+	}
+	ev.Frame = e.frame
+	for _, ch := range e.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
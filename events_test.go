@@ -0,0 +1,56 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestFrameEvents(t *testing.T) {
+	r := Nil()
+	events, cancel := r.Events().Subscribe(6)
+	r.Render()
+	r.Render()
+	cancel()
+	cancel()
+	r.Render()
+
+	want := []struct {
+		kind  FrameEventKind
+		frame uint64
+	}{
+		{FrameBegin, 0}, {FramePrePresent, 0}, {FrameEnd, 0},
+		{FrameBegin, 1}, {FramePrePresent, 1}, {FrameEnd, 1},
+	}
+	i := 0
+	for ev := range events {
+		if i >= len(want) {
+			t.Fatalf("unexpected event %+v", ev)
+		}
+		if ev.Kind != want[i].kind || ev.Frame != want[i].frame {
+			t.Fatalf("event %d is %v of frame %d, want %v of frame %d", i, ev.Kind, ev.Frame, want[i].kind, want[i].frame)
+		}
+		if ev.Kind == FrameBegin && ev.Frame == 1 && ev.FrameTime < 0 {
+			t.Fatal("negative frame time")
+		}
+		i++
+	}
+	if i != len(want) {
+		t.Fatalf("got %d events, want %d", i, len(want))
+	}
+}
+
+func TestFrameEventsFull(t *testing.T) {
+	r := Nil()
+	events, cancel := r.Events().Subscribe(1)
+	defer cancel()
+	r.Render()
+	if ev := <-events; ev.Kind != FrameBegin {
+		t.Fatalf("got %v, want FrameBegin", ev.Kind)
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("got %v, want dropped events", ev.Kind)
+	default:
+	}
+}
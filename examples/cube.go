@@ -0,0 +1,143 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package examples
+
+import (
+	"image"
+	"image/color"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/gfx.v1/gfxutil/shapes"
+	"azul3d.org/lmath.v1"
+)
+
+var texturedVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec2 TexCoord0;
+
+uniform mat4 MVP;
+
+varying vec2 tc0;
+
+void main()
+{
+	tc0 = TexCoord0;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+var texturedFrag = []byte(`
+#version 120
+
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+
+void main()
+{
+	gl_FragColor = texture2D(Texture0, tc0);
+}
+`)
+
+var litVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec3 Normal;
+
+uniform mat4 MVP;
+uniform mat4 Model;
+
+varying vec3 normal;
+
+void main()
+{
+	normal = mat3(Model) * Normal;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+var litFrag = []byte(`
+#version 120
+
+varying vec3 normal;
+
+uniform vec3 LightDir;
+uniform vec4 Tint;
+
+void main()
+{
+	float diffuse = max(dot(normalize(normal), -normalize(LightDir)), 0.0);
+	gl_FragColor = vec4(Tint.rgb * (0.15 + 0.85*diffuse), Tint.a);
+}
+`)
+
+// checker returns a new checkerboard image of the given size in pixels, with
+// the given number of squares along each axis.
+func checker(size, squares int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	light := color.RGBA{220, 220, 220, 255}
+	dark := color.RGBA{40, 90, 160, 255}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if (x*squares/size+y*squares/size)%2 == 0 {
+				img.SetRGBA(x, y, light)
+			} else {
+				img.SetRGBA(x, y, dark)
+			}
+		}
+	}
+	return img
+}
+
+func init() {
+	Register(Example{
+		Name:        "textured-cube",
+		Description: "A spinning cube textured with a mipmapped checkerboard.",
+		New: func(r gfx.Renderer) (Scene, error) {
+			shader := gfx.NewShader("textured-cube")
+			shader.GLSLVert = texturedVert
+			shader.GLSLFrag = texturedFrag
+
+			tex := gfx.NewTexture()
+			tex.Source = checker(256, 8)
+			tex.MinFilter = gfx.LinearMipmapLinear
+			tex.MagFilter = gfx.Linear
+
+			o := gfx.NewObject()
+			o.Shader = shader
+			o.Meshes = []*gfx.Mesh{shapes.Box(lmath.Vec3{2, 2, 2})}
+			o.Textures = []*gfx.Texture{tex}
+			s, err := newScene(r, o, gfx.Color{0.1, 0.1, 0.1, 1}, true)
+			if err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	})
+
+	Register(Example{
+		Name:        "lighting",
+		Description: "A spinning cube lit by a directional light.",
+		New: func(r gfx.Renderer) (Scene, error) {
+			shader := gfx.NewShader("lighting")
+			shader.GLSLVert = litVert
+			shader.GLSLFrag = litFrag
+			shader.Inputs["LightDir"] = gfx.Vec3{-0.5, 1, -1}
+
+			o := gfx.NewObject()
+			o.Shader = shader
+			o.Meshes = []*gfx.Mesh{shapes.Box(lmath.Vec3{2, 2, 2})}
+			o.Tint = gfx.Color{1, 0.6, 0.2, 1}
+			s, err := newScene(r, o, gfx.Color{0.05, 0.05, 0.1, 1}, true)
+			if err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	})
+}
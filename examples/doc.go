@@ -0,0 +1,23 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package examples provides a registry of small runnable example scenes.
+//
+// Each example demonstrates a single feature of package gfx (e.g. drawing a
+// triangle, texturing, or lighting) and is renderer agnostic, such that the
+// same registry can drive a graphical picker (e.g. one binary which lets the
+// user choose any example from a list) as well as serve as an integration test
+// of a renderer:
+//  func TestExamples(t *testing.T) {
+//      r := newMyRenderer()
+//      for _, e := range examples.All() {
+//          if err := examples.Run(r, e, 10); err != nil {
+//              t.Errorf("%s: %v", e.Name, err)
+//          }
+//      }
+//  }
+//
+// New subsystems should register an example demonstrating them, such that
+// they are covered by the tests of every renderer.
+package examples
@@ -0,0 +1,146 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package examples
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"azul3d.org/gfx.v1"
+)
+
+// Scene represents a single running instance of an example.
+type Scene interface {
+	// Draw draws a single frame of the scene to the given canvas. It does not
+	// invoke the canvas's Render method, as that is left up to the caller.
+	Draw(c gfx.Canvas)
+
+	// Destroy destroys the resources of the scene.
+	Destroy()
+}
+
+// Example represents a single registered example.
+type Example struct {
+	// The unique name of the example, e.g. "triangle".
+	Name string
+
+	// A short human-readable description of what the example demonstrates.
+	Description string
+
+	// New creates a new scene of the example, loading it's resources using
+	// the given renderer.
+	New func(r gfx.Renderer) (Scene, error)
+}
+
+var registry struct {
+	sync.RWMutex
+	examples map[string]Example
+}
+
+// Register registers the given example. It panics if the example has no name
+// or constructor, or if an example with the same name is already registered.
+//
+// This function is safe to invoke from multiple goroutines concurrently.
+func Register(e Example) {
+	if e.Name == "" || e.New == nil {
+		panic("Register(): example must have a name and constructor")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.examples == nil {
+		registry.examples = make(map[string]Example)
+	}
+	if _, dup := registry.examples[e.Name]; dup {
+		panic(fmt.Sprintf("Register(): example %q already registered", e.Name))
+	}
+	registry.examples[e.Name] = e
+}
+
+// Lookup returns the example registered under the given name.
+//
+// This function is safe to invoke from multiple goroutines concurrently.
+func Lookup(name string) (e Example, ok bool) {
+	registry.RLock()
+	e, ok = registry.examples[name]
+	registry.RUnlock()
+	return
+}
+
+// All returns all of the registered examples, sorted by name.
+//
+// This function is safe to invoke from multiple goroutines concurrently.
+func All() []Example {
+	registry.RLock()
+	all := make([]Example, 0, len(registry.examples))
+	for _, e := range registry.examples {
+		all = append(all, e)
+	}
+	registry.RUnlock()
+	sort.Sort(byName(all))
+	return all
+}
+
+type byName []Example
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+// Run creates a scene of the given example, draws and renders the given
+// number of frames (or forever if frames <= 0), and then destroys the scene.
+//
+// Any error creating the scene is returned, as well as any panic which occurs
+// while it is running, such that a faulty example (or renderer) fails the
+// caller's test instead of crashing it.
+func Run(r gfx.Renderer, e Example, frames int) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	s, err := e.New(r)
+	if err != nil {
+		return err
+	}
+	defer s.Destroy()
+	for i := 0; frames <= 0 || i < frames; i++ {
+		s.Draw(r)
+		r.Render()
+	}
+	return nil
+}
+
+// load loads the given shader, meshes, and textures using the renderer and
+// waits for them to finish loading. An error is returned if the shader failed
+// to compile.
+func load(r gfx.Renderer, s *gfx.Shader, meshes []*gfx.Mesh, textures []*gfx.Texture) error {
+	shaderDone := make(chan *gfx.Shader, 1)
+	r.LoadShader(s, shaderDone)
+	meshDone := make(chan *gfx.Mesh, len(meshes))
+	for _, m := range meshes {
+		r.LoadMesh(m, meshDone)
+	}
+	texDone := make(chan *gfx.Texture, len(textures))
+	for _, t := range textures {
+		r.LoadTexture(t, texDone)
+	}
+
+	<-shaderDone
+	for _ = range meshes {
+		<-meshDone
+	}
+	for _ = range textures {
+		<-texDone
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+	if len(s.Error) > 0 {
+		return errors.New(string(s.Error))
+	}
+	return nil
+}
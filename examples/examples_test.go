@@ -0,0 +1,34 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package examples
+
+import (
+	"testing"
+
+	"azul3d.org/gfx.v1"
+)
+
+func TestExamplesNil(t *testing.T) {
+	all := All()
+	if len(all) == 0 {
+		t.Fatal("no examples registered")
+	}
+	r := gfx.Nil()
+	for _, e := range all {
+		if err := Run(r, e, 3); err != nil {
+			t.Errorf("%s: %v", e.Name, err)
+		}
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic registering a duplicate example")
+		}
+	}()
+	e, _ := Lookup("triangle")
+	Register(e)
+}
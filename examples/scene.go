@@ -0,0 +1,75 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package examples
+
+import (
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+// scene is a simple Scene which draws a single object, optionally spinning it
+// about the Z axis by one degree each frame.
+type scene struct {
+	obj   *gfx.Object
+	cam   *gfx.Camera
+	bg    gfx.Color
+	spin  bool
+	frame int
+}
+
+// newScene creates a new scene drawing the given object, which must already
+// have it's shader, meshes and textures assigned. The resources of the object
+// are loaded using the renderer.
+func newScene(r gfx.Renderer, o *gfx.Object, bg gfx.Color, spin bool) (*scene, error) {
+	if err := load(r, o.Shader, o.Meshes, o.Textures); err != nil {
+		return nil, err
+	}
+	cam := gfx.NewCamera()
+	cam.SetPos(lmath.Vec3{0, -3, 0})
+	return &scene{obj: o, cam: cam, bg: bg, spin: spin}, nil
+}
+
+// Draw implements the Scene interface.
+func (s *scene) Draw(c gfx.Canvas) {
+	b := c.Bounds()
+	s.cam.Lock()
+	s.cam.SetPersp(b, 75, 0.1, 100)
+	s.cam.Unlock()
+
+	if s.spin {
+		s.obj.Lock()
+		s.obj.SetRot(lmath.Vec3{30, 0, float64(s.frame)})
+		s.obj.Unlock()
+	}
+	s.frame++
+
+	c.Clear(b, s.bg)
+	c.ClearDepth(b, 1.0)
+	c.Draw(b, s.obj, s.cam)
+}
+
+// Destroy implements the Scene interface.
+func (s *scene) Destroy() {
+	s.obj.Lock()
+	s.obj.Shader.Lock()
+	s.obj.Shader.Destroy()
+	s.obj.Shader.Unlock()
+	for _, m := range s.obj.Meshes {
+		m.Lock()
+		m.Destroy()
+		m.Unlock()
+	}
+	for _, t := range s.obj.Textures {
+		t.Lock()
+		t.Destroy()
+		t.Unlock()
+	}
+	s.obj.Destroy()
+	s.obj.Unlock()
+
+	s.cam.Lock()
+	s.cam.Destroy()
+	s.cam.Unlock()
+}
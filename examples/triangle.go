@@ -0,0 +1,68 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package examples
+
+import "azul3d.org/gfx.v1"
+
+var triangleVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec4 Color;
+
+uniform mat4 MVP;
+
+varying vec4 frontColor;
+
+void main()
+{
+	frontColor = Color;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+var triangleFrag = []byte(`
+#version 120
+
+varying vec4 frontColor;
+
+void main()
+{
+	gl_FragColor = frontColor;
+}
+`)
+
+func init() {
+	Register(Example{
+		Name:        "triangle",
+		Description: "A single triangle with per-vertex colors.",
+		New: func(r gfx.Renderer) (Scene, error) {
+			shader := gfx.NewShader("triangle")
+			shader.GLSLVert = triangleVert
+			shader.GLSLFrag = triangleFrag
+
+			mesh := gfx.NewMesh()
+			mesh.Vertices = []gfx.Vec3{
+				{-1, 0, -1},
+				{1, 0, -1},
+				{0, 0, 1},
+			}
+			mesh.Colors = []gfx.Color{
+				{1, 0, 0, 1},
+				{0, 1, 0, 1},
+				{0, 0, 1, 1},
+			}
+
+			o := gfx.NewObject()
+			o.Shader = shader
+			o.Meshes = []*gfx.Mesh{mesh}
+			s, err := newScene(r, o, gfx.Color{0, 0, 0, 1}, false)
+			if err != nil {
+				return nil, err
+			}
+			return s, nil
+		},
+	})
+}
@@ -0,0 +1,366 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+)
+
+// exprFunc describes a single function that may be called from an expression.
+type exprFunc struct {
+	args int
+	fn   func(a []float64) float64
+}
+
+var exprFuncs = map[string]exprFunc{
+	"sin":   {1, func(a []float64) float64 { return math.Sin(a[0]) }},
+	"cos":   {1, func(a []float64) float64 { return math.Cos(a[0]) }},
+	"tan":   {1, func(a []float64) float64 { return math.Tan(a[0]) }},
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"sqrt":  {1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"fract": {1, func(a []float64) float64 { return a[0] - math.Floor(a[0]) }},
+	"min":   {2, func(a []float64) float64 { return math.Min(a[0], a[1]) }},
+	"max":   {2, func(a []float64) float64 { return math.Max(a[0], a[1]) }},
+	"pow":   {2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+	"step":  {2, func(a []float64) float64 { return exprStep(a[0], a[1]) }},
+	"clamp": {3, func(a []float64) float64 { return math.Max(a[1], math.Min(a[0], a[2])) }},
+	"mix":   {3, func(a []float64) float64 { return a[0] + (a[1]-a[0])*a[2] }},
+}
+
+func exprStep(edge, x float64) float64 {
+	if x < edge {
+		return 0
+	}
+	return 1
+}
+
+// exprNode is a single node of a parsed expression tree.
+type exprNode interface {
+	eval(vars map[string]float64) (float64, error)
+}
+
+type exprNum float64
+
+func (n exprNum) eval(vars map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+type exprVar string
+
+func (n exprVar) eval(vars map[string]float64) (float64, error) {
+	v, ok := vars[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("undefined variable %q", string(n))
+	}
+	return v, nil
+}
+
+type exprNeg struct {
+	x exprNode
+}
+
+func (n exprNeg) eval(vars map[string]float64) (float64, error) {
+	x, err := n.x.eval(vars)
+	return -x, err
+}
+
+type exprBinary struct {
+	op   byte
+	a, b exprNode
+}
+
+func (n exprBinary) eval(vars map[string]float64) (float64, error) {
+	a, err := n.a.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	b, err := n.b.eval(vars)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return a + b, nil
+	case '-':
+		return a - b, nil
+	case '*':
+		return a * b, nil
+	case '/':
+		return a / b, nil
+	case '%':
+		return math.Mod(a, b), nil
+	case '^':
+		return math.Pow(a, b), nil
+	}
+	panic("invalid operator")
+}
+
+type exprCall struct {
+	fn   exprFunc
+	args []exprNode
+}
+
+func (n exprCall) eval(vars map[string]float64) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	return n.fn.fn(args), nil
+}
+
+// exprParser is a simple recursive-descent parser for expressions.
+type exprParser struct {
+	src string
+	pos int
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("expr %q: offset %d: %s", p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space byte, or zero at the end of the source.
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// expr := term (('+' | '-') term)*
+func (p *exprParser) expr() (exprNode, error) {
+	n, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return n, nil
+		}
+		p.pos++
+		b, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		n = exprBinary{op, n, b}
+	}
+}
+
+// term := unary (('*' | '/' | '%') unary)*
+func (p *exprParser) term() (exprNode, error) {
+	n, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return n, nil
+		}
+		p.pos++
+		b, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		n = exprBinary{op, n, b}
+	}
+}
+
+// unary := ('-' | '+') unary | power
+func (p *exprParser) unary() (exprNode, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return exprNeg{x}, nil
+	case '+':
+		p.pos++
+		return p.unary()
+	}
+	return p.power()
+}
+
+// power := primary ('^' unary)?
+func (p *exprParser) power() (exprNode, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() == '^' {
+		p.pos++
+		b, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		n = exprBinary{'^', n, b}
+	}
+	return n, nil
+}
+
+// primary := number | ident | ident '(' args ')' | '(' expr ')'
+func (p *exprParser) primary() (exprNode, error) {
+	c := p.peek()
+	switch {
+	case c == '(':
+		p.pos++
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		return n, nil
+
+	case c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' || (p.src[p.pos] >= '0' && p.src[p.pos] <= '9')) {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.src[start:p.pos])
+		}
+		return exprNum(v), nil
+
+	case c == '_' || unicode.IsLetter(rune(c)):
+		start := p.pos
+		for p.pos < len(p.src) {
+			r := rune(p.src[p.pos])
+			if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		if p.peek() != '(' {
+			return exprVar(name), nil
+		}
+		p.pos++
+		fn, ok := exprFuncs[name]
+		if !ok {
+			return nil, p.errorf("undefined function %q", name)
+		}
+		var args []exprNode
+		if p.peek() != ')' {
+			for {
+				a, err := p.expr()
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, a)
+				if p.peek() != ',' {
+					break
+				}
+				p.pos++
+			}
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("expected ')'")
+		}
+		p.pos++
+		if len(args) != fn.args {
+			return nil, p.errorf("%s expects %d arguments, got %d", name, fn.args, len(args))
+		}
+		return exprCall{fn, args}, nil
+
+	case c == 0:
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected character %q", c)
+}
+
+// Expr represents a single parsed mathematical expression, such as:
+//  sin(t * 2) * 0.5 + 0.5
+//
+// Expressions may contain numbers, variables, parentheses, the binary
+// operators + - * / % ^ (where ^ is exponentiation), unary minus, and calls to
+// the following functions (named after their GLSL equivalents):
+//  sin(x), cos(x), tan(x), abs(x), sqrt(x), floor(x), ceil(x), fract(x)
+//  min(x, y), max(x, y), pow(x, y), step(edge, x)
+//  clamp(x, min, max), mix(x, y, a)
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// String returns the source string of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression using the given variable values. An error is
+// returned if the expression references a variable that is not in the map.
+func (e *Expr) Eval(vars map[string]float64) (float64, error) {
+	return e.root.eval(vars)
+}
+
+// ParseExpr parses the given expression string. See the Expr type for the
+// supported syntax.
+func ParseExpr(s string) (*Expr, error) {
+	p := &exprParser{src: s}
+	root, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != 0 {
+		return nil, p.errorf("unexpected character %q", p.src[p.pos])
+	}
+	return &Expr{src: s, root: root}, nil
+}
+
+// ExprInputs maps shader input names to expressions, allowing shader inputs
+// to be animated (e.g. by artists) without changes to Go code. Typically the
+// expressions are evaluated once per frame using the renderer's clock:
+//  inputs := gfx.ExprInputs{
+//      "Glow": glowExpr, // e.g. "sin(t*2)*0.5+0.5"
+//  }
+//  ...
+//  vars["t"] = r.Clock().Time().Seconds()
+//  shader.Lock()
+//  err := inputs.Update(shader, vars)
+//  shader.Unlock()
+type ExprInputs map[string]*Expr
+
+// Update evaluates each expression with the given variable values and stores
+// the results as float32 values in the shader's Inputs map. If an expression
+// fails to evaluate, it's input is left unchanged and the first such error is
+// returned after all other inputs are updated.
+//
+// The shader's write lock must be held for this method to operate safely.
+func (e ExprInputs) Update(s *Shader, vars map[string]float64) error {
+	var first error
+	for name, expr := range e {
+		v, err := expr.Eval(vars)
+		if err != nil {
+			if first == nil {
+				first = fmt.Errorf("input %q: %v", name, err)
+			}
+			continue
+		}
+		s.Inputs[name] = float32(v)
+	}
+	return first
+}
@@ -0,0 +1,58 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExprEval(t *testing.T) {
+	vars := map[string]float64{"t": 2, "speed": 0.5}
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"-2 ^ 2", -4},
+		{"2 ^ 3 ^ 2", 512},
+		{"7 % 4", 3},
+		{"sin(t*speed)*0.5+0.5", math.Sin(1)*0.5 + 0.5},
+		{"clamp(t, 0, 1)", 1},
+		{"mix(0, 10, .25)", 2.5},
+		{"fract(-t * 0.25)", 0.5},
+	}
+	for _, tst := range tests {
+		e, err := ParseExpr(tst.src)
+		if err != nil {
+			t.Errorf("%q: %v", tst.src, err)
+			continue
+		}
+		got, err := e.Eval(vars)
+		if err != nil {
+			t.Errorf("%q: %v", tst.src, err)
+			continue
+		}
+		if math.Abs(got-tst.want) > 1e-9 {
+			t.Errorf("%q: got %v want %v", tst.src, got, tst.want)
+		}
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	for _, src := range []string{"", "1 +", "(1", "foo(1)", "min(1)", "1 2", "1 $ 2"} {
+		if _, err := ParseExpr(src); err == nil {
+			t.Errorf("%q: expected parse error", src)
+		}
+	}
+	e, err := ParseExpr("x + 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Eval(nil); err == nil {
+		t.Error("expected undefined variable error")
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "time"
+
+// Fence represents a synchronization point in the stream of operations
+// submitted to a canvas (see Canvas.InsertFence). The fence becomes signaled
+// once the graphics hardware has completed all operations submitted before
+// it.
+//
+// Fences allow precise synchronization, for instance to know when the GPU has
+// finished reading a region of a persistently mapped buffer such that it may
+// be written to again, or when an asynchronous download has completed.
+//
+// The renderer is responsible for creating fences and fulfilling the
+// interface. All methods must be safe to call from multiple goroutines.
+type Fence interface {
+	// Destroy releases the native resources of the fence. It must not be used
+	// after calling this method.
+	Destroyable
+
+	// Signaled tells if the fence is signaled, i.e. whether or not all of the
+	// operations submitted before it have completed. It never blocks.
+	Signaled() bool
+
+	// Wait blocks until the fence is signaled or until the timeout elapses,
+	// whichever occurs first. It returns whether or not the fence was
+	// signaled. A timeout of zero is equivalent to calling Signaled.
+	Wait(timeout time.Duration) bool
+}
@@ -0,0 +1,259 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+
+	"azul3d.org/lmath.v1"
+)
+
+// fixedShift is the number of fractional bits in a Fixed number.
+const fixedShift = 16
+
+// Fixed represents a signed 48.16 fixed-point number. Because all operations
+// on fixed-point numbers are integer operations, they produce bit-identical
+// results on every CPU, unlike floating-point operations whose results may
+// differ slightly (e.g. due to fused multiply-add or extended precision).
+//
+// This makes it suitable for simulation state of lockstep-networked games,
+// where every peer must compute exactly the same result. Such state should be
+// kept in fixed-point form and only converted to floating-point at the render
+// boundary, for example:
+//  o.Transform.SetPos(simPos.Vec3())
+// Or, for entire transformations (see FixedTransform):
+//  simTransform.Apply(o.Transform)
+//
+// Multiplication and division are exact for values whose magnitude is below
+// 2^15 (i.e. 32768), larger values may overflow.
+type Fixed int64
+
+// FixedOne is the fixed-point representation of the number one.
+const FixedOne Fixed = 1 << fixedShift
+
+// FixedFromInt returns the fixed-point representation of the integer i.
+func FixedFromInt(i int) Fixed {
+	return Fixed(i) << fixedShift
+}
+
+// FixedFromFloat returns the fixed-point number closest to f. The conversion
+// is deterministic, but it should only be used on values that are themselves
+// deterministic (e.g. constants), not the results of floating-point math.
+func FixedFromFloat(f float64) Fixed {
+	if f < 0 {
+		return Fixed(f*float64(FixedOne) - 0.5)
+	}
+	return Fixed(f*float64(FixedOne) + 0.5)
+}
+
+// Float64 returns the floating-point representation of f.
+func (f Fixed) Float64() float64 {
+	return float64(f) / float64(FixedOne)
+}
+
+// Int returns the integer part of f, rounded towards negative infinity.
+func (f Fixed) Int() int {
+	return int(f >> fixedShift)
+}
+
+// String returns a string representation of this fixed-point number.
+func (f Fixed) String() string {
+	return fmt.Sprint(f.Float64())
+}
+
+// Mul returns the result of f * g.
+func (f Fixed) Mul(g Fixed) Fixed {
+	return (f * g) >> fixedShift
+}
+
+// Div returns the result of f / g. A panic will occur if g is zero.
+func (f Fixed) Div(g Fixed) Fixed {
+	return (f << fixedShift) / g
+}
+
+// FixedVec3 represents a three-component fixed-point vector, see Fixed for
+// more information.
+type FixedVec3 struct {
+	X, Y, Z Fixed
+}
+
+// ConvertFixedVec3 converts the 64-bit lmath.Vec3 to the closest fixed-point
+// vector (see FixedFromFloat).
+func ConvertFixedVec3(v lmath.Vec3) FixedVec3 {
+	return FixedVec3{
+		X: FixedFromFloat(v.X),
+		Y: FixedFromFloat(v.Y),
+		Z: FixedFromFloat(v.Z),
+	}
+}
+
+// Vec3 converts this fixed-point vector to a 64-bit lmath.Vec3 vector.
+func (v FixedVec3) Vec3() lmath.Vec3 {
+	return lmath.Vec3{X: v.X.Float64(), Y: v.Y.Float64(), Z: v.Z.Float64()}
+}
+
+// Add returns the result of v + b.
+func (v FixedVec3) Add(b FixedVec3) FixedVec3 {
+	return FixedVec3{v.X + b.X, v.Y + b.Y, v.Z + b.Z}
+}
+
+// Sub returns the result of v - b.
+func (v FixedVec3) Sub(b FixedVec3) FixedVec3 {
+	return FixedVec3{v.X - b.X, v.Y - b.Y, v.Z - b.Z}
+}
+
+// MulScalar returns the result of v * s.
+func (v FixedVec3) MulScalar(s Fixed) FixedVec3 {
+	return FixedVec3{v.X.Mul(s), v.Y.Mul(s), v.Z.Mul(s)}
+}
+
+// Dot returns the dot product of v and b.
+func (v FixedVec3) Dot(b FixedVec3) Fixed {
+	return v.X.Mul(b.X) + v.Y.Mul(b.Y) + v.Z.Mul(b.Z)
+}
+
+// fixedPi is the fixed-point number closest to Pi.
+const fixedPi Fixed = 205887
+
+// FixedSinCos returns the sine and cosine of the angle given in degrees. They
+// are computed using integer operations only (i.e. deterministically), and
+// are exact for multiples of 90 degrees.
+func FixedSinCos(degrees Fixed) (sin, cos Fixed) {
+	const (
+		full    = 360 << fixedShift
+		quarter = 90 << fixedShift
+	)
+	degrees %= full
+	if degrees < 0 {
+		degrees += full
+	}
+
+	// Reduce the angle to the first quadrant, where the series converge
+	// quickly.
+	x := (degrees % quarter).Mul(fixedPi) / 180
+	x2 := x.Mul(x)
+	s := x.Mul(FixedOne - x2.Mul(FixedOne-x2.Mul(FixedOne-x2.Mul(FixedOne-x2/72)/42)/20)/6)
+	c := FixedOne - x2.Mul(FixedOne-x2.Mul(FixedOne-x2.Mul(FixedOne-x2.Mul(FixedOne-x2/90)/56)/30)/12)/2
+	switch degrees / quarter {
+	case 1:
+		return c, -s
+	case 2:
+		return -s, -c
+	case 3:
+		return -c, s
+	}
+	return s, c
+}
+
+// FixedMat4 represents a 4x4 fixed-point matrix, see Fixed for more
+// information. Like lmath.Mat4 it transforms row vectors, i.e. the translation
+// is stored in the fourth row and matrices are composed from left (local) to
+// right (parent).
+type FixedMat4 [4][4]Fixed
+
+// FixedMat4Identity is the fixed-point identity matrix.
+var FixedMat4Identity = FixedMat4{
+	{FixedOne, 0, 0, 0},
+	{0, FixedOne, 0, 0},
+	{0, 0, FixedOne, 0},
+	{0, 0, 0, FixedOne},
+}
+
+// ConvertFixedMat4 converts the 64-bit lmath.Mat4 to the closest fixed-point
+// matrix (see FixedFromFloat).
+func ConvertFixedMat4(m lmath.Mat4) FixedMat4 {
+	var r FixedMat4
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			r[row][col] = FixedFromFloat(m[row][col])
+		}
+	}
+	return r
+}
+
+// Mat4 converts this fixed-point matrix to a 64-bit lmath.Mat4 matrix.
+func (m FixedMat4) Mat4() lmath.Mat4 {
+	var r lmath.Mat4
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			r[row][col] = m[row][col].Float64()
+		}
+	}
+	return r
+}
+
+// Mul returns the result of m * b.
+func (m FixedMat4) Mul(b FixedMat4) FixedMat4 {
+	var r FixedMat4
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			for i := 0; i < 4; i++ {
+				r[row][col] += m[row][i].Mul(b[i][col])
+			}
+		}
+	}
+	return r
+}
+
+// TransformPos returns the point p transformed by this matrix (i.e. p * m,
+// including the translation).
+func (m FixedMat4) TransformPos(p FixedVec3) FixedVec3 {
+	return FixedVec3{
+		X: p.X.Mul(m[0][0]) + p.Y.Mul(m[1][0]) + p.Z.Mul(m[2][0]) + m[3][0],
+		Y: p.X.Mul(m[0][1]) + p.Y.Mul(m[1][1]) + p.Z.Mul(m[2][1]) + m[3][1],
+		Z: p.X.Mul(m[0][2]) + p.Y.Mul(m[1][2]) + p.Z.Mul(m[2][2]) + m[3][2],
+	}
+}
+
+// FixedTransform represents a deterministic fixed-point transformation for
+// simulation state (see Fixed), with the same components as Transform.
+//
+// Hierarchies are composed by multiplying the matrices of the transforms:
+//  childToWorld := child.Mat4().Mul(parent.Mat4())
+// And are converted at the render boundary using the Apply method.
+type FixedTransform struct {
+	// The position, in parent space.
+	Pos FixedVec3
+
+	// The euler rotation in degrees, with the same meaning as the rotation of
+	// a Transform (see Transform.SetRot).
+	Rot FixedVec3
+
+	// The scale.
+	Scale FixedVec3
+}
+
+// IdentityFixedTransform is the identity fixed-point transformation.
+var IdentityFixedTransform = FixedTransform{
+	Scale: FixedVec3{FixedOne, FixedOne, FixedOne},
+}
+
+// Mat4 returns the matrix of this transformation, composed in the same order
+// as the local matrix of a Transform (see Transform.LocalMat4): scale, then
+// rotation about the Y, X, and Z axes, then translation.
+func (t FixedTransform) Mat4() FixedMat4 {
+	rot := func(degrees Fixed, a, b int) FixedMat4 {
+		s, c := FixedSinCos(degrees)
+		m := FixedMat4Identity
+		m[a][a], m[a][b] = c, s
+		m[b][a], m[b][b] = -s, c
+		return m
+	}
+	m := FixedMat4Identity
+	m[0][0], m[1][1], m[2][2] = t.Scale.X, t.Scale.Y, t.Scale.Z
+	m = m.Mul(rot(t.Rot.Y, 2, 0))
+	m = m.Mul(rot(t.Rot.X, 1, 2))
+	m = m.Mul(rot(t.Rot.Z, 0, 1))
+	m[3] = [4]Fixed{t.Pos.X, t.Pos.Y, t.Pos.Z, FixedOne}
+	return m
+}
+
+// Apply sets the position, rotation, and scale of the render transform dst
+// to those of this transformation, converted to floating-point.
+func (t FixedTransform) Apply(dst *Transform) {
+	dst.SetPos(t.Pos.Vec3())
+	dst.SetRot(t.Rot.Vec3())
+	dst.SetScale(t.Scale.Vec3())
+}
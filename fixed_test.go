@@ -0,0 +1,88 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestFixed(t *testing.T) {
+	a := FixedFromFloat(1.5)
+	b := FixedFromInt(-3)
+	if got := a.Mul(b); got != FixedFromFloat(-4.5) {
+		t.Fatal("Mul: got", got)
+	}
+	if got := b.Div(a); got != FixedFromInt(-2) {
+		t.Fatal("Div: got", got)
+	}
+	if got := FixedFromFloat(-0.5).Int(); got != -1 {
+		t.Fatal("Int: got", got)
+	}
+
+	v := FixedVec3{a, b, FixedOne}
+	if got := v.Add(v).Sub(v); got != v {
+		t.Fatal("Add/Sub: got", got)
+	}
+	if got := v.Dot(v); got != FixedFromFloat(12.25) {
+		t.Fatal("Dot: got", got)
+	}
+}
+
+func TestFixedSinCos(t *testing.T) {
+	for _, deg := range []int{0, 90, 180, 270, 360, -90, 450} {
+		s, c := FixedSinCos(FixedFromInt(deg))
+		rad := float64(deg) * math.Pi / 180
+		if s != FixedFromFloat(math.Sin(rad)) || c != FixedFromFloat(math.Cos(rad)) {
+			t.Fatalf("FixedSinCos(%d) = %v, %v, want exact", deg, s, c)
+		}
+	}
+	for deg := -720; deg <= 720; deg += 15 {
+		s, c := FixedSinCos(FixedFromInt(deg))
+		rad := float64(deg) * math.Pi / 180
+		if math.Abs(s.Float64()-math.Sin(rad)) > 1e-4 || math.Abs(c.Float64()-math.Cos(rad)) > 1e-4 {
+			t.Fatalf("FixedSinCos(%d) = %v, %v, want %v, %v", deg, s, c, math.Sin(rad), math.Cos(rad))
+		}
+	}
+}
+
+func TestFixedTransform(t *testing.T) {
+	ft := IdentityFixedTransform
+	if ft.Mat4() != FixedMat4Identity {
+		t.Fatal("identity transform matrix is not identity, got", ft.Mat4())
+	}
+
+	// Rotating 90 degrees about Z maps X onto Y exactly.
+	ft.Pos = FixedVec3{FixedFromInt(10), 0, 0}
+	ft.Rot = FixedVec3{0, 0, FixedFromInt(90)}
+	ft.Scale = FixedVec3{FixedFromInt(2), FixedFromInt(2), FixedFromInt(2)}
+	got := ft.Mat4().TransformPos(FixedVec3{FixedOne, 0, 0})
+	if want := (FixedVec3{FixedFromInt(10), FixedFromInt(2), 0}); got != want {
+		t.Fatal("TransformPos: got", got, "want", want)
+	}
+
+	// Composing a hierarchy matches the equivalent render transforms.
+	child := IdentityFixedTransform
+	child.Pos = FixedVec3{0, FixedFromInt(3), FixedOne}
+	child.Rot = FixedVec3{FixedFromInt(30), FixedFromInt(15), FixedFromInt(45)}
+
+	parentT := NewTransform()
+	ft.Apply(parentT)
+	childT := NewTransform()
+	child.Apply(childT)
+	childT.SetParent(parentT)
+
+	p := FixedVec3{FixedOne, FixedFromInt(2), FixedFromInt(3)}
+	fixedWorld := child.Mat4().Mul(ft.Mat4()).TransformPos(p).Vec3()
+	world := childT.ConvertPos(p.Vec3(), LocalToWorld)
+	if fixedWorld.Sub(world).Length() > 1e-3 {
+		t.Fatal("fixed-point hierarchy got", fixedWorld, "render transforms got", world)
+	}
+	if m := ConvertFixedMat4(lmath.Mat4Identity); m != FixedMat4Identity {
+		t.Fatal("ConvertFixedMat4: got", m)
+	}
+}
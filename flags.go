@@ -0,0 +1,105 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// onOff is a flag.Value for booleans that additionally accepts "on" and
+// "off" as values.
+type onOff struct {
+	v *bool
+}
+
+func (o onOff) String() string {
+	if o.v == nil || *o.v {
+		return "on"
+	}
+	return "off"
+}
+
+func (o onOff) Set(s string) error {
+	switch s {
+	case "on":
+		*o.v = true
+	case "off":
+		*o.v = false
+	default:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("expected on or off, got %q", s)
+		}
+		*o.v = b
+	}
+	return nil
+}
+
+func (o onOff) IsBoolFlag() bool {
+	return true
+}
+
+// DiagFlags represents the standard set of diagnostic command-line flags,
+// such that every application gets consistent switches that can be used when
+// reporting bugs. The flags are:
+//  -gfx.backend=name     Use the named renderer backend (e.g. "gl2").
+//  -gfx.validate         Enable renderer validation (e.g. GL error checks).
+//  -gfx.capture-frame=N  Capture the N'th frame for debugging.
+//  -gfx.windowed         Force windowed (i.e. not fullscreen) mode.
+//  -gfx.vsync=off        Enable or disable vertical sync.
+//
+// The flags are opt-in, i.e. they are only registered when an application
+// calls RegisterDiagFlags.
+type DiagFlags struct {
+	// The name of the renderer backend to use, or an empty string for the
+	// default one.
+	Backend string
+
+	// Whether or not renderer validation should be enabled.
+	Validate bool
+
+	// The frame number to capture, or -1 for none.
+	CaptureFrame int
+
+	// Whether or not windowed mode should be forced.
+	Windowed bool
+
+	// Whether or not vertical sync should be enabled.
+	VSync bool
+}
+
+// DefaultDiagFlags is the set of diagnostic flag values used when no flags
+// are specified on the command line.
+var DefaultDiagFlags = DiagFlags{
+	Backend:      "",
+	Validate:     false,
+	CaptureFrame: -1,
+	Windowed:     false,
+	VSync:        true,
+}
+
+// RegisterDiagFlags registers the standard diagnostic flags (see DiagFlags)
+// with the given flag set, or flag.CommandLine if it is nil. The returned
+// values are populated once the flag set is parsed, for example:
+//  diag := gfx.RegisterDiagFlags(nil)
+//  flag.Parse()
+//  if diag.Validate {
+//      ...
+//  }
+func RegisterDiagFlags(fs *flag.FlagSet) *DiagFlags {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	d := new(DiagFlags)
+	*d = DefaultDiagFlags
+	fs.StringVar(&d.Backend, "gfx.backend", d.Backend, "renderer backend to use")
+	fs.BoolVar(&d.Validate, "gfx.validate", d.Validate, "enable renderer validation")
+	fs.IntVar(&d.CaptureFrame, "gfx.capture-frame", d.CaptureFrame, "frame number to capture (-1 for none)")
+	fs.BoolVar(&d.Windowed, "gfx.windowed", d.Windowed, "force windowed mode")
+	fs.Var(onOff{&d.VSync}, "gfx.vsync", "vertical sync (on or off)")
+	return d
+}
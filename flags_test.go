@@ -0,0 +1,29 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestDiagFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	d := RegisterDiagFlags(fs)
+	err := fs.Parse([]string{"-gfx.backend=gl2", "-gfx.validate", "-gfx.capture-frame=10", "-gfx.vsync=off"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DiagFlags{
+		Backend:      "gl2",
+		Validate:     true,
+		CaptureFrame: 10,
+		Windowed:     false,
+		VSync:        false,
+	}
+	if *d != want {
+		t.Fatalf("got %+v want %+v", *d, want)
+	}
+}
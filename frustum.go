@@ -0,0 +1,164 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// Plane represents a plane in 3D space, consisting of all points p for which
+// Normal.Dot(p) + D == 0.
+type Plane struct {
+	Normal lmath.Vec3
+	D      float64
+}
+
+// Dist returns the signed distance from the plane to the point p. The
+// distance is positive on the side that the normal points towards.
+func (p Plane) Dist(v lmath.Vec3) float64 {
+	return p.Normal.X*v.X + p.Normal.Y*v.Y + p.Normal.Z*v.Z + p.D
+}
+
+// Frustum represents a viewing frustum, i.e. the volume of space visible to a
+// camera, as six planes whose normals point towards the inside of the
+// frustum. It is the basic primitive for culling, level-of-detail selection,
+// and other spatial queries.
+type Frustum struct {
+	// The planes of the frustum, in the order left, right, bottom, top, near,
+	// far. Their normals are normalized.
+	Planes [6]Plane
+}
+
+// NewFrustum returns the frustum described by the given view-projection
+// matrix (e.g. see Camera.ViewProjection), whose planes are in the same
+// space that the matrix transforms from (e.g. world space).
+func NewFrustum(viewProj lmath.Mat4) Frustum {
+	m := viewProj
+	col := func(j int) [4]float64 {
+		return [4]float64{m[0][j], m[1][j], m[2][j], m[3][j]}
+	}
+	plane := func(a, b [4]float64, sign float64) Plane {
+		n := lmath.Vec3{a[0] + sign*b[0], a[1] + sign*b[1], a[2] + sign*b[2]}
+		d := a[3] + sign*b[3]
+		l := n.Length()
+		return Plane{
+			Normal: n.DivScalar(l),
+			D:      d / l,
+		}
+	}
+	w := col(3)
+	return Frustum{[6]Plane{
+		plane(w, col(0), 1),
+		plane(w, col(0), -1),
+		plane(w, col(1), 1),
+		plane(w, col(1), -1),
+		plane(w, col(2), 1),
+		plane(w, col(2), -1),
+	}}
+}
+
+// Frustum returns the world space viewing frustum of the camera.
+//
+// The camera's read lock must be held for this method to operate safely.
+func (c *Camera) Frustum() Frustum {
+	return NewFrustum(c.ViewProjection())
+}
+
+// ContainsPoint tells if the point is inside the frustum (or on it's
+// boundary).
+func (f Frustum) ContainsPoint(p lmath.Vec3) bool {
+	for _, pl := range f.Planes {
+		if pl.Dist(p) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsSphere tells if the sphere with the given center and radius is
+// (at least partially) inside the frustum.
+//
+// The test is conservative: spheres near the corners of the frustum may be
+// reported as intersecting it although they are just outside of it.
+func (f Frustum) IntersectsSphere(center lmath.Vec3, radius float64) bool {
+	for _, pl := range f.Planes {
+		if pl.Dist(center) < -radius {
+			return false
+		}
+	}
+	return true
+}
+
+// IntersectsRect3 tells if the axis-aligned bounding box is (at least
+// partially) inside the frustum.
+//
+// The test is conservative: boxes near the corners of the frustum may be
+// reported as intersecting it although they are just outside of it.
+func (f Frustum) IntersectsRect3(b lmath.Rect3) bool {
+	for _, pl := range f.Planes {
+		// Test the corner of the box furthest along the plane's normal (the
+		// "positive vertex"); if it is outside then so is the box.
+		p := b.Min
+		if pl.Normal.X >= 0 {
+			p.X = b.Max.X
+		}
+		if pl.Normal.Y >= 0 {
+			p.Y = b.Max.Y
+		}
+		if pl.Normal.Z >= 0 {
+			p.Z = b.Max.Z
+		}
+		if pl.Dist(p) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsRect3 tells if the axis-aligned bounding box is entirely inside the
+// frustum.
+func (f Frustum) ContainsRect3(b lmath.Rect3) bool {
+	for _, pl := range f.Planes {
+		// Test the corner of the box nearest along the plane's normal (the
+		// "negative vertex"); if it is inside then so is the box.
+		p := b.Max
+		if pl.Normal.X >= 0 {
+			p.X = b.Min.X
+		}
+		if pl.Normal.Y >= 0 {
+			p.Y = b.Min.Y
+		}
+		if pl.Normal.Z >= 0 {
+			p.Z = b.Min.Z
+		}
+		if pl.Dist(p) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Coeffs returns the equation coefficients of the frustum's planes, in the
+// form used by CullRect3s.
+func (f Frustum) Coeffs() [][4]float64 {
+	c := make([][4]float64, len(f.Planes))
+	for i, pl := range f.Planes {
+		c[i] = [4]float64{pl.Normal.X, pl.Normal.Y, pl.Normal.Z, pl.D}
+	}
+	return c
+}
+
+// Valid tells if the frustum's planes are valid, i.e. none of them are
+// degenerate (as occurs when extracting them from e.g. a zero matrix).
+func (f Frustum) Valid() bool {
+	for _, pl := range f.Planes {
+		if math.IsNaN(pl.D) || math.IsInf(pl.D, 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestFrustum(t *testing.T) {
+	c := NewCamera()
+	c.SetPersp(image.Rect(0, 0, 100, 100), 90, 1, 100)
+	f := c.Frustum()
+	if !f.Valid() {
+		t.Fatal("frustum is not valid")
+	}
+
+	// The camera looks down the +Y axis.
+	tests := []struct {
+		p      lmath.Vec3
+		inside bool
+	}{
+		{lmath.Vec3{0, 10, 0}, true},
+		{lmath.Vec3{0, 0.5, 0}, false},
+		{lmath.Vec3{0, 101, 0}, false},
+		{lmath.Vec3{0, -10, 0}, false},
+		{lmath.Vec3{9, 10, 9}, true},
+		{lmath.Vec3{11, 10, 0}, false},
+		{lmath.Vec3{0, 10, -11}, false},
+	}
+	for _, tst := range tests {
+		if got := f.ContainsPoint(tst.p); got != tst.inside {
+			t.Errorf("ContainsPoint(%v) = %v, want %v", tst.p, got, tst.inside)
+		}
+	}
+
+	if !f.IntersectsSphere(lmath.Vec3{12, 10, 0}, 2) {
+		t.Error("sphere straddling the right plane does not intersect")
+	}
+	if f.IntersectsSphere(lmath.Vec3{0, -10, 0}, 2) {
+		t.Error("sphere behind the camera intersects")
+	}
+
+	box := lmath.Rect3{Min: lmath.Vec3{-1, 9, -1}, Max: lmath.Vec3{1, 11, 1}}
+	if !f.IntersectsRect3(box) || !f.ContainsRect3(box) {
+		t.Error("box in view is not contained")
+	}
+	straddle := lmath.Rect3{Min: lmath.Vec3{5, 9, -1}, Max: lmath.Vec3{15, 11, 1}}
+	if !f.IntersectsRect3(straddle) || f.ContainsRect3(straddle) {
+		t.Error("box straddling the right plane is misclassified")
+	}
+	behind := lmath.Rect3{Min: lmath.Vec3{-1, -11, -1}, Max: lmath.Vec3{1, -9, 1}}
+	if f.IntersectsRect3(behind) {
+		t.Error("box behind the camera intersects")
+	}
+
+	visible := make([]bool, 3)
+	if n := CullRect3s(visible, []lmath.Rect3{box, straddle, behind}, f.Coeffs()); n != 2 || visible[2] {
+		t.Errorf("CullRect3s with frustum coefficients = %d, %v", n, visible)
+	}
+
+	if NewFrustum(lmath.Mat4{}).Valid() {
+		t.Error("frustum of zero matrix is valid")
+	}
+}
+
+func TestFrustumCull(t *testing.T) {
+	c := NewCamera()
+	c.SetPersp(image.Rect(0, 0, 100, 100), 90, 1, 100)
+
+	object := func(pos lmath.Vec3) *Object {
+		m := NewMesh()
+		m.Vertices = []Vec3{{-1, -1, -1}, {1, 1, 1}}
+		o := NewObject()
+		o.Meshes = []*Mesh{m}
+		o.SetPos(pos)
+		return o
+	}
+	ahead := object(lmath.Vec3{0, 10, 0})
+	behind := object(lmath.Vec3{0, -10, 0})
+	edge := object(lmath.Vec3{10.5, 10, 0})
+
+	// A rotated object straddling the edge of the frustum.
+	rotated := object(lmath.Vec3{-10.5, 10, 0})
+	rotated.SetRot(lmath.Vec3{0, 0, 90})
+	objs := []*Object{ahead, behind, edge, rotated}
+
+	c.RLock()
+	visible := c.Frustum().Cull(nil, objs)
+	c.RUnlock()
+	if len(visible) != 3 || visible[0] != ahead || visible[1] != edge || visible[2] != rotated {
+		t.Errorf("Cull returned %d objects", len(visible))
+	}
+	if n := DrawCulled(Nil(), image.Rect(0, 0, 100, 100), c, objs); n != 3 {
+		t.Errorf("DrawCulled drew %d objects, want 3", n)
+	}
+}
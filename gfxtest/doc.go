@@ -0,0 +1,23 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gfxtest provides utilities for testing renderers and math code
+// against the conventions of package gfx.
+//
+// It contains simple (and deliberately slow) reference implementations of
+// transform composition, projection, and frustum plane extraction, as well as
+// property checks which can be run from tests of contributed backends or user
+// math code:
+//  func TestConventions(t *testing.T) {
+//      r := rand.New(rand.NewSource(1))
+//      gfxtest.CheckTransforms(t, r, 100)
+//      gfxtest.CheckProjection(t, myPerspective, myOrtho)
+//      gfxtest.CheckFrustum(t, r, 100, myExtractPlanes)
+//  }
+//
+// The conventions are those of package gfx: matrices are row-major and
+// operate on row vectors (i.e. a point p is transformed by a matrix m as p*m,
+// so translation is stored in the fourth row) and projection matrices map into
+// the OpenGL clip space cube (-1 to +1 on all axes).
+package gfxtest
@@ -0,0 +1,305 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfxtest
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+// Epsilon is the tolerance used when comparing floating-point values.
+const Epsilon = 1e-6
+
+// MatEqual tells if each element of the two matrices is within eps of the
+// other.
+func MatEqual(a, b lmath.Mat4, eps float64) bool {
+	for i := range a {
+		for j := range a[i] {
+			if math.Abs(a[i][j]-b[i][j]) > eps {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// RefMul is the reference implementation of matrix multiplication. Because
+// row vectors are used, RefMul(a, b) applies a first and then b.
+func RefMul(a, b lmath.Mat4) lmath.Mat4 {
+	var r lmath.Mat4
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				r[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return r
+}
+
+// RefTransform is the reference implementation of transforming the
+// homogeneous point p by the matrix m.
+func RefTransform(p [4]float64, m lmath.Mat4) [4]float64 {
+	var r [4]float64
+	for j := 0; j < 4; j++ {
+		for k := 0; k < 4; k++ {
+			r[j] += p[k] * m[k][j]
+		}
+	}
+	return r
+}
+
+// RefTranslate returns the reference translation matrix for v.
+func RefTranslate(v lmath.Vec3) lmath.Mat4 {
+	return lmath.Mat4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{v.X, v.Y, v.Z, 1},
+	}
+}
+
+// RefScale returns the reference scaling matrix for v.
+func RefScale(v lmath.Vec3) lmath.Mat4 {
+	return lmath.Mat4{
+		{v.X, 0, 0, 0},
+		{0, v.Y, 0, 0},
+		{0, 0, v.Z, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// RefPerspective returns the reference perspective projection matrix, where
+// fovy is the Y axis field of view in degrees.
+func RefPerspective(fovy, aspect, near, far float64) lmath.Mat4 {
+	f := 1 / math.Tan(fovy*math.Pi/360)
+	return lmath.Mat4{
+		{f / aspect, 0, 0, 0},
+		{0, f, 0, 0},
+		{0, 0, (far + near) / (near - far), -1},
+		{0, 0, 2 * far * near / (near - far), 0},
+	}
+}
+
+// RefOrtho returns the reference orthographic projection matrix.
+func RefOrtho(left, right, bottom, top, near, far float64) lmath.Mat4 {
+	return lmath.Mat4{
+		{2 / (right - left), 0, 0, 0},
+		{0, 2 / (top - bottom), 0, 0},
+		{0, 0, -2 / (far - near), 0},
+		{
+			-(right + left) / (right - left),
+			-(top + bottom) / (top - bottom),
+			-(far + near) / (far - near),
+			1,
+		},
+	}
+}
+
+// RefProject is the reference implementation of projecting the point p by
+// the matrix m into normalized device coordinates. If ok=false is returned
+// then the point is outside of the clip space cube (or the projected W
+// coordinate is zero).
+func RefProject(m lmath.Mat4, p lmath.Vec3) (ndc lmath.Vec3, ok bool) {
+	c := RefTransform([4]float64{p.X, p.Y, p.Z, 1}, m)
+	if c[3] == 0 {
+		return lmath.Vec3{}, false
+	}
+	ndc = lmath.Vec3{c[0] / c[3], c[1] / c[3], c[2] / c[3]}
+	ok = math.Abs(ndc.X) <= 1 && math.Abs(ndc.Y) <= 1 && math.Abs(ndc.Z) <= 1
+	return
+}
+
+// Plane represents a plane in 3D space, consisting of all points p for which
+// Normal.Dot(p) + D == 0.
+type Plane struct {
+	Normal lmath.Vec3
+	D      float64
+}
+
+// Dist returns the signed distance from the plane to the point p. The
+// distance is positive on the side that the normal points towards.
+func (p Plane) Dist(v lmath.Vec3) float64 {
+	return p.Normal.X*v.X + p.Normal.Y*v.Y + p.Normal.Z*v.Z + p.D
+}
+
+// The order of planes returned by frustum plane extraction functions.
+const (
+	Left = iota
+	Right
+	Bottom
+	Top
+	Near
+	Far
+)
+
+// RefFrustumPlanes is the reference implementation of extracting the six
+// planes of the viewing frustum described by the given view-projection
+// matrix. The planes are in the order Left, Right, Bottom, Top, Near, Far;
+// their normals are normalized and point towards the inside of the frustum.
+func RefFrustumPlanes(m lmath.Mat4) [6]Plane {
+	col := func(j int) [4]float64 {
+		return [4]float64{m[0][j], m[1][j], m[2][j], m[3][j]}
+	}
+	plane := func(a, b [4]float64, sign float64) Plane {
+		n := lmath.Vec3{a[0] + sign*b[0], a[1] + sign*b[1], a[2] + sign*b[2]}
+		d := a[3] + sign*b[3]
+		l := math.Sqrt(n.X*n.X + n.Y*n.Y + n.Z*n.Z)
+		return Plane{
+			Normal: lmath.Vec3{n.X / l, n.Y / l, n.Z / l},
+			D:      d / l,
+		}
+	}
+	w := col(3)
+	return [6]Plane{
+		Left:   plane(w, col(0), 1),
+		Right:  plane(w, col(0), -1),
+		Bottom: plane(w, col(1), 1),
+		Top:    plane(w, col(1), -1),
+		Near:   plane(w, col(2), 1),
+		Far:    plane(w, col(2), -1),
+	}
+}
+
+// randVec3 returns a random vector with components in the range [min, max).
+func randVec3(r *rand.Rand, min, max float64) lmath.Vec3 {
+	f := func() float64 {
+		return min + r.Float64()*(max-min)
+	}
+	return lmath.Vec3{f(), f(), f()}
+}
+
+// CheckTransforms checks n random transform hierarchies of package gfx
+// against the reference implementations:
+//  - Local matrices built from position and scale equal RefScale*RefTranslate.
+//  - LocalToWorld equals the local matrix times the parent's LocalToWorld.
+//  - WorldToLocal is the inverse of LocalToWorld, including with rotation.
+//  - WorldToParent equals the parent's WorldToLocal.
+func CheckTransforms(t testing.TB, r *rand.Rand, n int) {
+	for i := 0; i < n; i++ {
+		parent := gfx.NewTransform()
+		parent.SetPos(randVec3(r, -100, 100))
+		parent.SetScale(randVec3(r, 0.5, 2))
+
+		child := gfx.NewTransform()
+		pos, scale := randVec3(r, -100, 100), randVec3(r, 0.5, 2)
+		child.SetPos(pos)
+		child.SetScale(scale)
+
+		want := RefMul(RefScale(scale), RefTranslate(pos))
+		if got := child.LocalMat4(); !MatEqual(got, want, Epsilon) {
+			t.Errorf("LocalMat4 (pos %v, scale %v)\ngot  %v\nwant %v", pos, scale, got, want)
+		}
+
+		child.SetParent(parent)
+		want = RefMul(child.LocalMat4(), parent.Convert(gfx.LocalToWorld))
+		if got := child.Convert(gfx.LocalToWorld); !MatEqual(got, want, Epsilon) {
+			t.Errorf("LocalToWorld composition\ngot  %v\nwant %v", got, want)
+		}
+
+		child.SetRot(randVec3(r, -180, 180))
+		ltw := child.Convert(gfx.LocalToWorld)
+		wtl := child.Convert(gfx.WorldToLocal)
+		if got := RefMul(ltw, wtl); !MatEqual(got, lmath.Mat4Identity, 1e-4) {
+			t.Errorf("LocalToWorld*WorldToLocal is not identity (rot %v)\ngot %v", child.Rot(), got)
+		}
+		if got, want := child.Convert(gfx.WorldToParent), parent.Convert(gfx.WorldToLocal); !MatEqual(got, want, 1e-4) {
+			t.Errorf("WorldToParent is not the parent's WorldToLocal\ngot  %v\nwant %v", got, want)
+		}
+		child.Destroy()
+		parent.Destroy()
+	}
+}
+
+// CheckProjection checks the given perspective and orthographic projection
+// matrix functions (with the same signatures and conventions as
+// lmath.Mat4Perspective and lmath.Mat4Ortho, respectively) against the
+// reference implementations. Either function may be nil, in which case it is
+// not checked.
+func CheckProjection(t testing.TB, persp func(fovy, aspect, near, far float64) lmath.Mat4, ortho func(left, right, bottom, top, near, far float64) lmath.Mat4) {
+	type frustum struct {
+		fovy, aspect, near, far float64
+	}
+	if persp != nil {
+		for _, f := range []frustum{
+			{75, 16.0 / 9.0, 0.1, 1000},
+			{90, 1, 1, 10},
+			{45, 4.0 / 3.0, 0.01, 100},
+		} {
+			got := persp(f.fovy, f.aspect, f.near, f.far)
+			want := RefPerspective(f.fovy, f.aspect, f.near, f.far)
+			if !MatEqual(got, want, Epsilon) {
+				t.Errorf("perspective %+v\ngot  %v\nwant %v", f, got, want)
+			}
+
+			// Points on the near and far planes along the view axis (-Z)
+			// must map to the near and far sides of the clip space cube.
+			for _, c := range []struct {
+				z, ndcZ float64
+			}{{-f.near, -1}, {-f.far, 1}} {
+				ndc, _ := RefProject(got, lmath.Vec3{0, 0, c.z})
+				if math.Abs(ndc.Z-c.ndcZ) > 1e-4 {
+					t.Errorf("perspective %+v: z=%v maps to NDC z=%v, want %v", f, c.z, ndc.Z, c.ndcZ)
+				}
+			}
+		}
+	}
+	if ortho != nil {
+		got := ortho(0, 640, 0, 480, -1, 1)
+		want := RefOrtho(0, 640, 0, 480, -1, 1)
+		if !MatEqual(got, want, Epsilon) {
+			t.Errorf("ortho\ngot  %v\nwant %v", got, want)
+		}
+		ndc, ok := RefProject(got, lmath.Vec3{640, 480, 0})
+		if !ok || !ndc.Equals(lmath.Vec3{1, 1, 0}) {
+			t.Errorf("ortho: top-right corner maps to NDC %v, want (1, 1, 0)", ndc)
+		}
+	}
+}
+
+// CheckFrustum checks the given frustum plane extraction function (which must
+// return planes in the same order and with the same conventions as
+// RefFrustumPlanes) using n random view-projection matrices: the planes must
+// match the reference ones, points inside the clip space cube must be inside
+// all planes, and points outside of it must be outside at least one plane.
+func CheckFrustum(t testing.TB, r *rand.Rand, n int, extract func(m lmath.Mat4) [6]Plane) {
+	for i := 0; i < n; i++ {
+		view := RefTranslate(randVec3(r, -10, 10))
+		proj := RefPerspective(30+r.Float64()*90, 0.5+r.Float64()*2, 0.1, 100)
+		vp := RefMul(view, proj)
+		inv, ok := vp.Inverse()
+		if !ok {
+			continue
+		}
+
+		got := extract(vp)
+		want := RefFrustumPlanes(vp)
+		for p := range want {
+			if !got[p].Normal.AlmostEquals(want[p].Normal, 1e-4) || math.Abs(got[p].D-want[p].D) > 1e-4 {
+				t.Errorf("plane %d\ngot  %+v\nwant %+v", p, got[p], want[p])
+			}
+		}
+
+		for j := 0; j < 10; j++ {
+			ndc := randVec3(r, -1.5, 1.5)
+			h := RefTransform([4]float64{ndc.X, ndc.Y, ndc.Z, 1}, inv)
+			world := lmath.Vec3{h[0] / h[3], h[1] / h[3], h[2] / h[3]}
+			inside := math.Abs(ndc.X) <= 1 && math.Abs(ndc.Y) <= 1 && math.Abs(ndc.Z) <= 1
+			outside := false
+			for _, p := range got {
+				if p.Dist(world) < -1e-6 {
+					outside = true
+				}
+			}
+			if inside == outside {
+				t.Errorf("NDC point %v (world %v): inside=%t but outside planes=%t", ndc, world, inside, outside)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfxtest
+
+import (
+	"math/rand"
+	"testing"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+func TestGoldenPerspective(t *testing.T) {
+	// 90 degree field of view, square aspect, near=1 and far=3.
+	want := lmath.Mat4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, -2, -1},
+		{0, 0, -3, 0},
+	}
+	if got := RefPerspective(90, 1, 1, 3); !MatEqual(got, want, Epsilon) {
+		t.Fatalf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestGoldenFrustumPlanes(t *testing.T) {
+	// The planes of an identity matrix are those of the clip space cube.
+	planes := RefFrustumPlanes(lmath.Mat4Identity)
+	want := [6]Plane{
+		Left:   {lmath.Vec3{1, 0, 0}, 1},
+		Right:  {lmath.Vec3{-1, 0, 0}, 1},
+		Bottom: {lmath.Vec3{0, 1, 0}, 1},
+		Top:    {lmath.Vec3{0, -1, 0}, 1},
+		Near:   {lmath.Vec3{0, 0, 1}, 1},
+		Far:    {lmath.Vec3{0, 0, -1}, 1},
+	}
+	if planes != want {
+		t.Fatalf("got %+v\nwant %+v", planes, want)
+	}
+}
+
+func TestConventions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	CheckTransforms(t, r, 100)
+	CheckProjection(t, lmath.Mat4Perspective, lmath.Mat4Ortho)
+	CheckFrustum(t, r, 100, RefFrustumPlanes)
+	CheckFrustum(t, r, 100, func(m lmath.Mat4) [6]Plane {
+		var planes [6]Plane
+		for i, p := range gfx.NewFrustum(m).Planes {
+			planes[i] = Plane{p.Normal, p.D}
+		}
+		return planes
+	})
+}
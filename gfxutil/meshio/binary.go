@@ -0,0 +1,257 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+// ErrFormat is returned by ReadMesh when the data is not a mesh written by
+// WriteMesh, or is corrupt.
+var ErrFormat = errors.New("meshio: invalid binary mesh")
+
+const (
+	meshMagic   = "AZ3DMESH"
+	meshVersion = 1
+
+	// The maximum number of elements of any slice, to avoid allocating huge
+	// amounts of memory when reading corrupt data.
+	maxElements = 1 << 28
+)
+
+// attribTypes lists the types of custom vertex attribute data (see
+// gfx.VertexAttrib), the index of each is it's tag in the binary format.
+var attribTypes = []reflect.Type{
+	reflect.TypeOf([]float32(nil)),
+	reflect.TypeOf([][]float32(nil)),
+	reflect.TypeOf([]gfx.Vec3(nil)),
+	reflect.TypeOf([][]gfx.Vec3(nil)),
+	reflect.TypeOf([]gfx.Vec4(nil)),
+	reflect.TypeOf([][]gfx.Vec4(nil)),
+	reflect.TypeOf([]gfx.Mat4(nil)),
+	reflect.TypeOf([][]gfx.Mat4(nil)),
+	reflect.TypeOf([]gfx.Half2(nil)),
+	reflect.TypeOf([]gfx.Half4(nil)),
+	reflect.TypeOf([]gfx.UNorm8x4(nil)),
+	reflect.TypeOf([]gfx.SNorm16x2(nil)),
+	reflect.TypeOf([]gfx.SNorm16x4(nil)),
+	reflect.TypeOf([]gfx.Int2101010(nil)),
+}
+
+type meshHeader struct {
+	Magic                 [8]byte
+	Version               uint32
+	Primitive             gfx.Primitive
+	PrimitiveRestart      bool
+	Usage                 gfx.MeshUsage
+	Dynamic, Interleaved  bool
+	AABBMin, AABBMax      [3]float64
+	TexCoordSets, Attribs uint32
+}
+
+// WriteMesh writes the mesh to w in a compact binary format, which is read
+// back using ReadMesh much faster than parsing a text format. It is intended
+// for asset pipelines which convert meshes (e.g. from OBJ or glTF) offline.
+//
+// Every data slice of the mesh is written (indices, vertices, colors,
+// barycentric coordinates, texture coordinate sets, and custom attributes of
+// the types listed in the gfx.VertexAttrib documentation), along with it's
+// primitive, usage hints, and bounding box. Loading state (e.g. the native
+// mesh, KeepDataOnLoad, and the changed flags) is not written.
+//
+// This function properly read-locks the mesh.
+func WriteMesh(w io.Writer, m *gfx.Mesh) error {
+	m.RLock()
+	defer m.RUnlock()
+
+	names := make([]string, 0, len(m.Attribs))
+	tags := make(map[string]uint8, len(m.Attribs))
+	for name, a := range m.Attribs {
+		tag := -1
+		for i, t := range attribTypes {
+			if reflect.TypeOf(a.Data) == t {
+				tag = i
+			}
+		}
+		if tag < 0 {
+			return fmt.Errorf("meshio: attribute %q has unsupported type %T", name, a.Data)
+		}
+		names = append(names, name)
+		tags[name] = uint8(tag)
+	}
+	sort.Strings(names)
+
+	hdr := meshHeader{
+		Version:          meshVersion,
+		Primitive:        m.Primitive,
+		PrimitiveRestart: m.PrimitiveRestart,
+		Usage:            m.Usage,
+		Dynamic:          m.Dynamic,
+		Interleaved:      m.Interleaved,
+		AABBMin:          [3]float64{m.AABB.Min.X, m.AABB.Min.Y, m.AABB.Min.Z},
+		AABBMax:          [3]float64{m.AABB.Max.X, m.AABB.Max.Y, m.AABB.Max.Z},
+		TexCoordSets:     uint32(len(m.TexCoords)),
+		Attribs:          uint32(len(names)),
+	}
+	copy(hdr.Magic[:], meshMagic)
+
+	bw := bufio.NewWriter(w)
+	le := binary.LittleEndian
+	binary.Write(bw, le, hdr)
+	writeSlice(bw, m.Indices)
+	writeSlice(bw, m.Vertices)
+	writeSlice(bw, m.Colors)
+	writeSlice(bw, m.Bary)
+	for _, set := range m.TexCoords {
+		writeSlice(bw, set.Slice)
+	}
+	for _, name := range names {
+		binary.Write(bw, le, uint16(len(name)))
+		bw.WriteString(name)
+		bw.WriteByte(tags[name])
+		data := reflect.ValueOf(m.Attribs[name].Data)
+		if data.Type().Elem().Kind() != reflect.Slice {
+			writeSlice(bw, data.Interface())
+			continue
+		}
+		binary.Write(bw, le, uint32(data.Len()))
+		for i := 0; i < data.Len(); i++ {
+			writeSlice(bw, data.Index(i).Interface())
+		}
+	}
+	return bw.Flush()
+}
+
+// writeSlice writes the length of the slice followed by it's elements.
+func writeSlice(w io.Writer, s interface{}) {
+	binary.Write(w, binary.LittleEndian, uint32(reflect.ValueOf(s).Len()))
+	binary.Write(w, binary.LittleEndian, s)
+}
+
+// ReadMesh reads a mesh written by WriteMesh from r. ErrFormat is returned if
+// the data is not a valid binary mesh.
+func ReadMesh(r io.Reader) (*gfx.Mesh, error) {
+	br := bufio.NewReader(r)
+	var hdr meshHeader
+	if err := binary.Read(br, binary.LittleEndian, &hdr); err != nil {
+		return nil, readErr(err)
+	}
+	if string(hdr.Magic[:]) != meshMagic {
+		return nil, ErrFormat
+	}
+	if hdr.Version != meshVersion {
+		return nil, fmt.Errorf("meshio: unsupported binary mesh version %d", hdr.Version)
+	}
+	if hdr.TexCoordSets > maxElements || hdr.Attribs > maxElements {
+		return nil, ErrFormat
+	}
+
+	m := gfx.NewMesh()
+	m.Primitive = hdr.Primitive
+	m.PrimitiveRestart = hdr.PrimitiveRestart
+	m.Usage = hdr.Usage
+	m.Dynamic = hdr.Dynamic
+	m.Interleaved = hdr.Interleaved
+	m.AABB = lmath.Rect3{
+		Min: lmath.Vec3{hdr.AABBMin[0], hdr.AABBMin[1], hdr.AABBMin[2]},
+		Max: lmath.Vec3{hdr.AABBMax[0], hdr.AABBMax[1], hdr.AABBMax[2]},
+	}
+
+	for _, dst := range []interface{}{&m.Indices, &m.Vertices, &m.Colors, &m.Bary} {
+		if err := readSlice(br, reflect.ValueOf(dst).Elem()); err != nil {
+			return nil, err
+		}
+	}
+	m.TexCoords = make([]gfx.TexCoordSet, hdr.TexCoordSets)
+	for i := range m.TexCoords {
+		if err := readSlice(br, reflect.ValueOf(&m.TexCoords[i].Slice).Elem()); err != nil {
+			return nil, err
+		}
+	}
+	for i := uint32(0); i < hdr.Attribs; i++ {
+		var nameLen uint16
+		if err := binary.Read(br, binary.LittleEndian, &nameLen); err != nil {
+			return nil, readErr(err)
+		}
+		name := make([]byte, nameLen)
+		if _, err := io.ReadFull(br, name); err != nil {
+			return nil, readErr(err)
+		}
+		tag, err := br.ReadByte()
+		if err != nil {
+			return nil, readErr(err)
+		}
+		if int(tag) >= len(attribTypes) {
+			return nil, ErrFormat
+		}
+		data := reflect.New(attribTypes[tag]).Elem()
+		if data.Type().Elem().Kind() != reflect.Slice {
+			err = readSlice(br, data)
+		} else {
+			err = readSlices(br, data)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.Attribs[string(name)] = gfx.VertexAttrib{Data: data.Interface()}
+	}
+	return m, nil
+}
+
+// readSlice reads a slice written by writeSlice into the settable slice value.
+func readSlice(r io.Reader, dst reflect.Value) error {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return readErr(err)
+	}
+	if n > maxElements {
+		return ErrFormat
+	}
+	if n == 0 {
+		return nil
+	}
+	s := reflect.MakeSlice(dst.Type(), int(n), int(n))
+	if err := binary.Read(r, binary.LittleEndian, s.Interface()); err != nil {
+		return readErr(err)
+	}
+	dst.Set(s)
+	return nil
+}
+
+// readSlices reads a slice of slices into the settable value.
+func readSlices(r io.Reader, dst reflect.Value) error {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return readErr(err)
+	}
+	if n > maxElements {
+		return ErrFormat
+	}
+	s := reflect.MakeSlice(dst.Type(), int(n), int(n))
+	for i := 0; i < int(n); i++ {
+		if err := readSlice(r, s.Index(i)); err != nil {
+			return err
+		}
+	}
+	dst.Set(s)
+	return nil
+}
+
+// readErr converts unexpected EOF errors (i.e. truncated data) into ErrFormat.
+func readErr(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrFormat
+	}
+	return err
+}
@@ -0,0 +1,19 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package meshio implements exporting of meshes to common interchange
+// formats, and a compact binary format for fast loading (see WriteMesh and
+// ReadMesh).
+//
+// The interchange formats are intended for procedurally generated geometry:
+// inspecting it in an external viewer (OBJ and PLY), or 3D-printing it
+// (binary STL). The binary format is intended for asset pipelines, which
+// convert meshes offline such that they load quickly at runtime. The data of
+// the meshes must be present, i.e. they must either not be loaded yet or have
+// been loaded with KeepDataOnLoad set to true.
+//
+// Only meshes whose primitive is Triangles, TriangleStrip, or TriangleFan may
+// be exported to the interchange formats, strips and fans (including those
+// using primitive restart) are converted into independent triangles.
+package meshio
@@ -0,0 +1,98 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshio
+
+import (
+	"errors"
+	"fmt"
+
+	"azul3d.org/gfx.v1"
+)
+
+var (
+	// ErrNoData is returned when a mesh has no vertex data, e.g. because it
+	// was loaded without KeepDataOnLoad set to true.
+	ErrNoData = errors.New("meshio: mesh has no vertex data")
+)
+
+// triangles returns the vertex indices of each triangle of the mesh, whose
+// read lock must be held.
+func triangles(m *gfx.Mesh) ([][3]uint32, error) {
+	if len(m.Vertices) == 0 {
+		return nil, ErrNoData
+	}
+	indices := m.Indices
+	if len(indices) == 0 {
+		indices = make([]uint32, len(m.Vertices))
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+	for _, idx := range indices {
+		if int(idx) >= len(m.Vertices) && !(m.PrimitiveRestart && idx == gfx.RestartIndex) {
+			return nil, fmt.Errorf("meshio: index %d out of range", idx)
+		}
+	}
+
+	var tris [][3]uint32
+	switch m.Primitive {
+	case gfx.Triangles:
+		for i := 0; i+2 < len(indices); i += 3 {
+			tris = append(tris, [3]uint32{indices[i], indices[i+1], indices[i+2]})
+		}
+	case gfx.TriangleStrip, gfx.TriangleFan:
+		// Split the indices into separate strips or fans at each restart
+		// index.
+		start := 0
+		for i := 0; i <= len(indices); i++ {
+			if i < len(indices) && !(m.PrimitiveRestart && indices[i] == gfx.RestartIndex) {
+				continue
+			}
+			run := indices[start:i]
+			start = i + 1
+			for j := 2; j < len(run); j++ {
+				switch {
+				case m.Primitive == gfx.TriangleFan:
+					tris = append(tris, [3]uint32{run[0], run[j-1], run[j]})
+				case j%2 == 0:
+					tris = append(tris, [3]uint32{run[j-2], run[j-1], run[j]})
+				default:
+					// Odd triangles of a strip have reversed winding.
+					tris = append(tris, [3]uint32{run[j-1], run[j-2], run[j]})
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("meshio: unsupported primitive %v", m.Primitive)
+	}
+	return tris, nil
+}
+
+// normals returns the "Normal" custom attribute of the mesh (see
+// Mesh.GenerateNormals) or nil if it has none.
+func normals(m *gfx.Mesh) []gfx.Vec3 {
+	n, _ := m.Attribs["Normal"].Data.([]gfx.Vec3)
+	if len(n) != len(m.Vertices) {
+		return nil
+	}
+	return n
+}
+
+// texCoords returns the first texture coordinate set of the mesh or nil if it
+// has none.
+func texCoords(m *gfx.Mesh) []gfx.TexCoord {
+	if len(m.TexCoords) == 0 || len(m.TexCoords[0].Slice) != len(m.Vertices) {
+		return nil
+	}
+	return m.TexCoords[0].Slice
+}
+
+// colors returns the colors of the mesh or nil if it has none.
+func colors(m *gfx.Mesh) []gfx.Color {
+	if len(m.Colors) != len(m.Vertices) {
+		return nil
+	}
+	return m.Colors
+}
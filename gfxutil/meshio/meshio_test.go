@@ -0,0 +1,124 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshio
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"azul3d.org/gfx.v1"
+)
+
+func quad() *gfx.Mesh {
+	m := gfx.NewMesh()
+	m.Vertices = []gfx.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {0, 0, 1}}
+	m.TexCoords = []gfx.TexCoordSet{{Slice: []gfx.TexCoord{{0, 1}, {1, 1}, {1, 0}, {0, 0}}}}
+	m.Indices = []uint32{0, 1, 2, 0, 2, 3}
+	return m
+}
+
+func TestTrianglesStrip(t *testing.T) {
+	m := gfx.NewMesh()
+	m.Vertices = make([]gfx.Vec3, 6)
+	m.Primitive = gfx.TriangleStrip
+	m.PrimitiveRestart = true
+	m.Indices = []uint32{0, 1, 2, 3, gfx.RestartIndex, 3, 4, 5}
+	tris, err := triangles(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][3]uint32{{0, 1, 2}, {2, 1, 3}, {3, 4, 5}}
+	if !reflect.DeepEqual(tris, want) {
+		t.Fatalf("got %v, want %v", tris, want)
+	}
+}
+
+func TestWriteOBJ(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteOBJ(&buf, quad()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{"v 1 1 0\n", "vt 0 0\n", "f 1/1 2/2 3/3\n", "f 1/1 3/3 4/4\n"} {
+		if !strings.Contains(out, line) {
+			t.Fatalf("output missing %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestWritePLY(t *testing.T) {
+	m := quad()
+	m.GenerateNormals(180)
+	var buf bytes.Buffer
+	if err := WritePLY(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	end := bytes.Index(out, []byte("end_header\n")) + len("end_header\n")
+	header := string(out[:end])
+	if !strings.Contains(header, "element vertex 4\n") || !strings.Contains(header, "property float nx\n") {
+		t.Fatalf("bad header:\n%s", header)
+	}
+	// 4 vertices of 8 floats, 2 faces of 1 byte and 3 ints.
+	if n := len(out) - end; n != 4*8*4+2*13 {
+		t.Fatalf("got %d bytes of body, want %d", n, 4*8*4+2*13)
+	}
+}
+
+func TestWriteSTL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSTL(&buf, quad()); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 84+2*50 {
+		t.Fatalf("got %d bytes, want %d", buf.Len(), 84+2*50)
+	}
+}
+
+func TestNoData(t *testing.T) {
+	if err := WriteSTL(new(bytes.Buffer), gfx.NewMesh()); err != ErrNoData {
+		t.Fatalf("got error %v, want ErrNoData", err)
+	}
+}
+
+func TestMeshRoundTrip(t *testing.T) {
+	m := quad()
+	m.Primitive = gfx.TriangleStrip
+	m.Usage = gfx.UsageDynamic
+	m.Colors = []gfx.Color{{1, 0, 0, 1}, {0, 1, 0, 1}, {0, 0, 1, 1}, {1, 1, 1, 1}}
+	m.Attribs["Wind"] = gfx.VertexAttrib{Data: []float32{0, 1, 2, 3}}
+	m.Attribs["Bones"] = gfx.VertexAttrib{Data: [][]gfx.Vec4{
+		{{1, 2, 3, 4}, {0, 0, 0, 0}, {1, 1, 1, 1}, {5, 6, 7, 8}},
+		{{9, 9, 9, 9}, {0, 0, 0, 0}, {1, 1, 1, 1}, {2, 2, 2, 2}},
+	}}
+	m.Attribs["Packed"] = gfx.VertexAttrib{Data: []gfx.UNorm8x4{{1, 2, 3, 4}, {}, {}, {255, 0, 0, 0}}}
+	m.CalculateBounds()
+
+	var buf bytes.Buffer
+	if err := WriteMesh(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadMesh(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Primitive != m.Primitive || got.Usage != m.Usage || got.AABB != m.AABB {
+		t.Fatalf("got primitive %v usage %v bounds %v", got.Primitive, got.Usage, got.AABB)
+	}
+	if !reflect.DeepEqual(got.Indices, m.Indices) || !reflect.DeepEqual(got.Vertices, m.Vertices) ||
+		!reflect.DeepEqual(got.Colors, m.Colors) || !reflect.DeepEqual(got.TexCoords, m.TexCoords) {
+		t.Fatal("data slices differ")
+	}
+	if !reflect.DeepEqual(got.Attribs, m.Attribs) {
+		t.Fatalf("got attribs %v, want %v", got.Attribs, m.Attribs)
+	}
+
+	// Truncated data must fail cleanly.
+	if _, err := ReadMesh(bytes.NewReader(buf.Bytes()[:buf.Len()-3])); err != ErrFormat {
+		t.Fatalf("got error %v, want ErrFormat", err)
+	}
+}
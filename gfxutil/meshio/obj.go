@@ -0,0 +1,77 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"azul3d.org/gfx.v1"
+)
+
+// WriteOBJ writes the mesh to w in the Wavefront OBJ format.
+//
+// Since gfx is Z-up and OBJ files are by convention Y-up, vertex positions and
+// normals are rotated such that the mesh appears upright in other tools. The
+// V texture coordinate is flipped, as the origin of OBJ texture coordinates is
+// the bottom-left of the image rather than the top-left. Vertex colors are
+// written using the common extension of three additional components on each
+// vertex position.
+//
+// This function properly read-locks the mesh.
+func WriteOBJ(w io.Writer, m *gfx.Mesh) error {
+	m.RLock()
+	defer m.RUnlock()
+	tris, err := triangles(m)
+	if err != nil {
+		return err
+	}
+	norms, tcs, cols := normals(m), texCoords(m), colors(m)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %d vertices, %d triangles\n", len(m.Vertices), len(tris))
+	for i, v := range m.Vertices {
+		v = yUp(v)
+		if cols != nil {
+			c := cols[i]
+			fmt.Fprintf(bw, "v %g %g %g %g %g %g\n", v.X, v.Y, v.Z, c.R, c.G, c.B)
+		} else {
+			fmt.Fprintf(bw, "v %g %g %g\n", v.X, v.Y, v.Z)
+		}
+	}
+	for _, tc := range tcs {
+		fmt.Fprintf(bw, "vt %g %g\n", tc.U, 1-tc.V)
+	}
+	for _, n := range norms {
+		n = yUp(n)
+		fmt.Fprintf(bw, "vn %g %g %g\n", n.X, n.Y, n.Z)
+	}
+	for _, t := range tris {
+		bw.WriteString("f")
+		for _, idx := range t {
+			// OBJ indices are one-based.
+			idx++
+			switch {
+			case tcs != nil && norms != nil:
+				fmt.Fprintf(bw, " %d/%d/%d", idx, idx, idx)
+			case tcs != nil:
+				fmt.Fprintf(bw, " %d/%d", idx, idx)
+			case norms != nil:
+				fmt.Fprintf(bw, " %d//%d", idx, idx)
+			default:
+				fmt.Fprintf(bw, " %d", idx)
+			}
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// yUp converts the Z-up vector v into a Y-up one.
+func yUp(v gfx.Vec3) gfx.Vec3 {
+	// Adding zero avoids writing negative zero.
+	return gfx.Vec3{v.X, v.Z, -v.Y + 0}
+}
@@ -0,0 +1,80 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"azul3d.org/gfx.v1"
+)
+
+// WritePLY writes the mesh to w in the binary little-endian PLY format.
+//
+// Each vertex has x, y, and z float properties and, if present in the mesh,
+// nx, ny, and nz float normal properties (see Mesh.GenerateNormals), s and t
+// float texture coordinate properties, and red, green, blue, and alpha uchar
+// color properties. Each face is a list of three int vertex indices.
+//
+// This function properly read-locks the mesh.
+func WritePLY(w io.Writer, m *gfx.Mesh) error {
+	m.RLock()
+	defer m.RUnlock()
+	tris, err := triangles(m)
+	if err != nil {
+		return err
+	}
+	norms, tcs, cols := normals(m), texCoords(m), colors(m)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "ply\nformat binary_little_endian 1.0\n")
+	fmt.Fprintf(bw, "element vertex %d\n", len(m.Vertices))
+	bw.WriteString("property float x\nproperty float y\nproperty float z\n")
+	if norms != nil {
+		bw.WriteString("property float nx\nproperty float ny\nproperty float nz\n")
+	}
+	if tcs != nil {
+		bw.WriteString("property float s\nproperty float t\n")
+	}
+	if cols != nil {
+		bw.WriteString("property uchar red\nproperty uchar green\nproperty uchar blue\nproperty uchar alpha\n")
+	}
+	fmt.Fprintf(bw, "element face %d\n", len(tris))
+	bw.WriteString("property list uchar int vertex_indices\nend_header\n")
+
+	le := binary.LittleEndian
+	for i, v := range m.Vertices {
+		binary.Write(bw, le, v)
+		if norms != nil {
+			binary.Write(bw, le, norms[i])
+		}
+		if tcs != nil {
+			binary.Write(bw, le, tcs[i])
+		}
+		if cols != nil {
+			c := cols[i]
+			bw.Write([]byte{unorm8(c.R), unorm8(c.G), unorm8(c.B), unorm8(c.A)})
+		}
+	}
+	for _, t := range tris {
+		bw.WriteByte(3)
+		binary.Write(bw, le, [3]int32{int32(t[0]), int32(t[1]), int32(t[2])})
+	}
+	return bw.Flush()
+}
+
+// unorm8 converts the [0, 1] color component to an 8-bit unsigned normalized
+// integer.
+func unorm8(v float32) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 255
+	}
+	return uint8(v*255 + 0.5)
+}
@@ -0,0 +1,55 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package meshio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"azul3d.org/gfx.v1"
+)
+
+// stlTriangle is a single binary STL triangle record.
+type stlTriangle struct {
+	Normal   gfx.Vec3
+	Vertices [3]gfx.Vec3
+	Attrib   uint16
+}
+
+// WriteSTL writes the mesh to w in the binary STL format, as used by most
+// 3D-printing software.
+//
+// The facet normal of each triangle is calculated from it's vertices
+// (counter-clockwise winding), vertex normals, colors, and texture
+// coordinates are not written. Coordinates are written as-is, STL has no
+// units but slicers typically interpret them as millimeters.
+//
+// This function properly read-locks the mesh.
+func WriteSTL(w io.Writer, m *gfx.Mesh) error {
+	m.RLock()
+	defer m.RUnlock()
+	tris, err := triangles(m)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	var header [80]byte
+	copy(header[:], "azul3d.org/gfx.v1/gfxutil/meshio")
+	bw.Write(header[:])
+	binary.Write(bw, binary.LittleEndian, uint32(len(tris)))
+	for _, t := range tris {
+		p0 := m.Vertices[t[0]].Vec3()
+		p1 := m.Vertices[t[1]].Vec3()
+		p2 := m.Vertices[t[2]].Vec3()
+		n, _ := p1.Sub(p0).Cross(p2.Sub(p0)).Normalized()
+		binary.Write(bw, binary.LittleEndian, stlTriangle{
+			Normal:   gfx.ConvertVec3(n),
+			Vertices: [3]gfx.Vec3{m.Vertices[t[0]], m.Vertices[t[1]], m.Vertices[t[2]]},
+		})
+	}
+	return bw.Flush()
+}
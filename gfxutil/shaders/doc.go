@@ -0,0 +1,38 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package shaders provides ready-made shaders for common rendering tasks,
+// such that meshes can be rendered without writing any GLSL.
+//
+// Each constructor returns a new GLSL 1.20 shader (see gfx.NewShader) whose
+// inputs are set to sensible defaults. The shaders use the following vertex
+// attributes, where present, of the meshes they render:
+//  Vertex     vec3  The vertex position (Mesh.Vertices).
+//  Color      vec4  The sRGB-encoded vertex color (Mesh.Colors).
+//  TexCoord0  vec2  The first texture coordinate set (Mesh.TexCoords).
+//  Normal     vec3  The vertex normal (see Mesh.GenerateNormals).
+//  Tangent    vec4  The vertex tangent (see Mesh.GenerateTangents).
+//
+// And the following uniforms, which renderers supply for each object:
+//  MVP          mat4       The model-view-projection matrix.
+//  Model        mat4       The model (i.e. local-to-world) matrix.
+//  UVTransform  mat4       The texture coordinate transform (Object.UVTransform).
+//  Tint         vec4       The linear per-instance tint (Object.Tint).
+//  Texture0     sampler2D  The first texture of the object (Object.Textures).
+//  Texture1     sampler2D  The second texture of the object.
+//
+// Lighting is computed in world space for a single directional light, using
+// the following inputs (see the Shader.Inputs map):
+//  LightDir    gfx.Vec3  The direction the light travels in, in world space.
+//  LightColor  gfx.Vec3  The linear color (and intensity) of the light.
+//  Ambient     gfx.Vec3  The linear color of the ambient light.
+//  Specular    gfx.Vec3  The linear specular color (BlinnPhong, NormalMapped).
+//  Shininess   float32   The specular exponent (BlinnPhong, NormalMapped).
+//  EyePos      gfx.Vec3  The world space position of the camera (BlinnPhong,
+//                        NormalMapped), which should be updated as the camera
+//                        moves.
+//
+// All colors follow the tinting convention of package gfx (see
+// gfx.ApplyTint), and the shaders output linear colors.
+package shaders
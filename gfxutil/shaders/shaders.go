@@ -0,0 +1,258 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shaders
+
+import "azul3d.org/gfx.v1"
+
+const version = "#version 120\n"
+
+// glsl concatenates the given GLSL source fragments.
+func glsl(parts ...string) []byte {
+	var src []byte
+	for _, p := range parts {
+		src = append(src, p...)
+	}
+	return src
+}
+
+var texturedVert = `
+attribute vec3 Vertex;
+attribute vec2 TexCoord0;
+
+uniform mat4 MVP;
+uniform mat4 UVTransform;
+
+varying vec2 tc0;
+
+void main()
+{
+	tc0 = (UVTransform * vec4(TexCoord0, 0.0, 1.0)).xy;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`
+
+var unlitFrag = `
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+
+void main()
+{
+	gl_FragColor = tint(texture2D(Texture0, tc0), vec4(1.0));
+}
+`
+
+// Unlit returns a new shader which renders the object's first texture
+// (Texture0), unaffected by lighting.
+func Unlit() *gfx.Shader {
+	s := gfx.NewShader("Unlit")
+	s.GLSLVert = glsl(version, texturedVert)
+	s.GLSLFrag = glsl(version, string(gfx.TintGLSL), unlitFrag)
+	return s
+}
+
+var vertexColorVert = `
+attribute vec3 Vertex;
+attribute vec4 Color;
+
+uniform mat4 MVP;
+
+varying vec4 vColor;
+
+void main()
+{
+	vColor = Color;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`
+
+var vertexColorFrag = `
+varying vec4 vColor;
+
+void main()
+{
+	gl_FragColor = tint(vec4(1.0), vColor);
+}
+`
+
+// VertexColor returns a new shader which renders the vertex colors of the
+// meshes (Mesh.Colors), unaffected by lighting and without textures.
+func VertexColor() *gfx.Shader {
+	s := gfx.NewShader("VertexColor")
+	s.GLSLVert = glsl(version, vertexColorVert)
+	s.GLSLFrag = glsl(version, string(gfx.TintGLSL), vertexColorFrag)
+	return s
+}
+
+var litVert = `
+attribute vec3 Vertex;
+attribute vec3 Normal;
+attribute vec2 TexCoord0;
+
+uniform mat4 MVP;
+uniform mat4 Model;
+uniform mat4 UVTransform;
+
+varying vec3 worldPos;
+varying vec3 normal;
+varying vec2 tc0;
+
+void main()
+{
+	worldPos = (Model * vec4(Vertex, 1.0)).xyz;
+	normal = mat3(Model) * Normal;
+	tc0 = (UVTransform * vec4(TexCoord0, 0.0, 1.0)).xy;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`
+
+var lambertFrag = `
+varying vec3 worldPos;
+varying vec3 normal;
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+uniform vec3 LightDir;
+uniform vec3 LightColor;
+uniform vec3 Ambient;
+
+void main()
+{
+	vec4 albedo = tint(texture2D(Texture0, tc0), vec4(1.0));
+	float diffuse = max(dot(normalize(normal), -normalize(LightDir)), 0.0);
+	gl_FragColor = vec4(albedo.rgb * (Ambient + LightColor*diffuse), albedo.a);
+}
+`
+
+// blinnPhongGLSL implements Blinn-Phong shading of a surface with the given
+// albedo and world space normal.
+var blinnPhongGLSL = `
+uniform vec3 LightDir;
+uniform vec3 LightColor;
+uniform vec3 Ambient;
+uniform vec3 Specular;
+uniform float Shininess;
+uniform vec3 EyePos;
+
+vec4 blinnPhong(vec4 albedo, vec3 n, vec3 pos)
+{
+	vec3 l = -normalize(LightDir);
+	vec3 h = normalize(l + normalize(EyePos - pos));
+	float diffuse = max(dot(n, l), 0.0);
+	float spec = 0.0;
+	if(diffuse > 0.0) {
+		spec = pow(max(dot(n, h), 0.0), Shininess);
+	}
+	vec3 c = albedo.rgb * (Ambient + LightColor*diffuse) + Specular*LightColor*spec;
+	return vec4(c, albedo.a);
+}
+`
+
+var blinnPhongFrag = `
+varying vec3 worldPos;
+varying vec3 normal;
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+
+void main()
+{
+	vec4 albedo = tint(texture2D(Texture0, tc0), vec4(1.0));
+	gl_FragColor = blinnPhong(albedo, normalize(normal), worldPos);
+}
+`
+
+// setLightInputs sets the default lighting inputs of the shader: a white
+// light shining diagonally downwards.
+func setLightInputs(s *gfx.Shader, specular bool) {
+	s.Inputs["LightDir"] = gfx.Vec3{-0.5, 1, -1}
+	s.Inputs["LightColor"] = gfx.Vec3{1, 1, 1}
+	s.Inputs["Ambient"] = gfx.Vec3{0.15, 0.15, 0.15}
+	if specular {
+		s.Inputs["Specular"] = gfx.Vec3{0.5, 0.5, 0.5}
+		s.Inputs["Shininess"] = float32(32)
+		s.Inputs["EyePos"] = gfx.Vec3{0, -10, 0}
+	}
+}
+
+// Lambert returns a new shader which renders the object's first texture
+// (Texture0) with diffuse (Lambertian) lighting from a directional light.
+func Lambert() *gfx.Shader {
+	s := gfx.NewShader("Lambert")
+	s.GLSLVert = glsl(version, litVert)
+	s.GLSLFrag = glsl(version, string(gfx.TintGLSL), lambertFrag)
+	setLightInputs(s, false)
+	return s
+}
+
+// BlinnPhong returns a new shader which renders the object's first texture
+// (Texture0) with diffuse and specular (Blinn-Phong) lighting from a
+// directional light. The EyePos input must be updated as the camera moves.
+func BlinnPhong() *gfx.Shader {
+	s := gfx.NewShader("BlinnPhong")
+	s.GLSLVert = glsl(version, litVert)
+	s.GLSLFrag = glsl(version, string(gfx.TintGLSL), blinnPhongGLSL, blinnPhongFrag)
+	setLightInputs(s, true)
+	return s
+}
+
+var normalMappedVert = `
+attribute vec3 Vertex;
+attribute vec3 Normal;
+attribute vec4 Tangent;
+attribute vec2 TexCoord0;
+
+uniform mat4 MVP;
+uniform mat4 Model;
+uniform mat4 UVTransform;
+
+varying vec3 worldPos;
+varying vec3 normal;
+varying vec3 tangent;
+varying vec3 bitangent;
+varying vec2 tc0;
+
+void main()
+{
+	worldPos = (Model * vec4(Vertex, 1.0)).xyz;
+	normal = mat3(Model) * Normal;
+	tangent = mat3(Model) * Tangent.xyz;
+	bitangent = cross(normal, tangent) * Tangent.w;
+	tc0 = (UVTransform * vec4(TexCoord0, 0.0, 1.0)).xy;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`
+
+var normalMappedFrag = `
+varying vec3 worldPos;
+varying vec3 normal;
+varying vec3 tangent;
+varying vec3 bitangent;
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+uniform sampler2D Texture1;
+
+void main()
+{
+	vec3 t = texture2D(Texture1, tc0).xyz*2.0 - 1.0;
+	mat3 tbn = mat3(normalize(tangent), normalize(bitangent), normalize(normal));
+	vec4 albedo = tint(texture2D(Texture0, tc0), vec4(1.0));
+	gl_FragColor = blinnPhong(albedo, normalize(tbn * t), worldPos);
+}
+`
+
+// NormalMapped returns a new shader which renders the object's first texture
+// (Texture0) with Blinn-Phong lighting (see BlinnPhong), using the object's
+// second texture (Texture1) as a tangent-space normal map. The meshes must
+// have tangents (see Mesh.GenerateTangents), and the normal map texture
+// should not be sRGB-encoded (see Texture.SRGB).
+func NormalMapped() *gfx.Shader {
+	s := gfx.NewShader("NormalMapped")
+	s.GLSLVert = glsl(version, normalMappedVert)
+	s.GLSLFrag = glsl(version, string(gfx.TintGLSL), blinnPhongGLSL, normalMappedFrag)
+	setLightInputs(s, true)
+	return s
+}
@@ -0,0 +1,53 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shaders
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"azul3d.org/gfx.v1"
+)
+
+// inputTypes maps the Go types of shader inputs to their GLSL types.
+var inputTypes = map[string]string{
+	"gfx.Vec3": "vec3",
+	"float32":  "float",
+}
+
+var uniformDecl = regexp.MustCompile(`uniform (\w+) (\w+);`)
+
+func TestShaders(t *testing.T) {
+	for _, s := range []*gfx.Shader{Unlit(), VertexColor(), Lambert(), BlinnPhong(), NormalMapped()} {
+		if !bytes.HasPrefix(s.GLSLVert, []byte("#version 120\n")) || !bytes.HasPrefix(s.GLSLFrag, []byte("#version 120\n")) {
+			t.Errorf("%s: sources do not begin with a #version directive", s.Name)
+		}
+		if !bytes.Contains(s.GLSLVert, []byte("uniform mat4 MVP;")) {
+			t.Errorf("%s: vertex shader does not declare MVP", s.Name)
+		}
+
+		// Every uniform of the fragment shader must be declared only once,
+		// and inputs must have matching types.
+		decls := map[string]string{}
+		for _, m := range uniformDecl.FindAllSubmatch(s.GLSLFrag, -1) {
+			name := string(m[2])
+			if _, ok := decls[name]; ok {
+				t.Errorf("%s: uniform %s declared twice", s.Name, name)
+			}
+			decls[name] = string(m[1])
+		}
+		for name, v := range s.Inputs {
+			typ := inputTypes[fmt.Sprintf("%T", v)]
+			if decls[name] != typ {
+				t.Errorf("%s: input %s has GLSL type %q, declared as %q", s.Name, name, typ, decls[name])
+			}
+		}
+		if _, ok := decls["Tint"]; !ok {
+			t.Errorf("%s: fragment shader does not declare Tint", s.Name)
+		}
+	}
+}
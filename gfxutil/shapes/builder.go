@@ -0,0 +1,144 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shapes
+
+import (
+	"math"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+// builder accumulates vertex data for a mesh.
+type builder struct {
+	vertices  []gfx.Vec3
+	normals   []gfx.Vec3
+	texCoords []gfx.TexCoord
+	indices   []uint32
+}
+
+// vertex adds a vertex and returns it's index.
+func (b *builder) vertex(p, n lmath.Vec3, u, v float64) uint32 {
+	b.vertices = append(b.vertices, gfx.ConvertVec3(p))
+	b.normals = append(b.normals, gfx.ConvertVec3(n))
+	b.texCoords = append(b.texCoords, gfx.TexCoord{float32(u), float32(v)})
+	return uint32(len(b.vertices) - 1)
+}
+
+// tri adds a triangle of the given vertex indices.
+func (b *builder) tri(i0, i1, i2 uint32) {
+	b.indices = append(b.indices, i0, i1, i2)
+}
+
+// quad adds two triangles forming the quad of the given vertex indices, which
+// are (in counter-clockwise order) it's top-left, bottom-left, bottom-right,
+// and top-right corners.
+func (b *builder) quad(tl, bl, br, tr uint32) {
+	b.tri(tl, bl, br)
+	b.tri(tl, br, tr)
+}
+
+// grid adds a grid of (cols+1)*(rows+1) vertices, whose position and normal at
+// the normalized grid coordinates s and t (where s increases to the right and
+// t increases downward) are given by the function f, and the quads between
+// them. Texture coordinates are simply s and t.
+//
+// If skipDegenerate is true then triangles which would have two vertices at
+// the same position (e.g. at the poles of a sphere) are omitted.
+func (b *builder) grid(cols, rows int, skipDegenerate bool, f func(s, t float64) (p, n lmath.Vec3)) {
+	base := uint32(len(b.vertices))
+	for r := 0; r <= rows; r++ {
+		for c := 0; c <= cols; c++ {
+			s, t := float64(c)/float64(cols), float64(r)/float64(rows)
+			p, n := f(s, t)
+			b.vertex(p, n, s, t)
+		}
+	}
+	index := func(c, r int) uint32 {
+		return base + uint32(r*(cols+1)+c)
+	}
+	same := func(i, j uint32) bool {
+		return skipDegenerate && b.vertices[i] == b.vertices[j]
+	}
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			tl, bl := index(c, r), index(c, r+1)
+			br, tr := index(c+1, r+1), index(c+1, r)
+			if !same(bl, br) {
+				b.tri(tl, bl, br)
+			}
+			if !same(tl, tr) {
+				b.tri(tl, br, tr)
+			}
+		}
+	}
+}
+
+// lathe adds a surface of revolution about the Z axis, with the given number
+// of segments. The profile function returns the distance from the axis, the
+// height, and the (radial and vertical) normal components of the profile at
+// the normalized coordinate t, which increases downward along the outside of
+// the surface.
+func (b *builder) lathe(segments, rows int, profile func(t float64) (r, z, nr, nz float64)) {
+	b.grid(segments, rows, true, func(s, t float64) (p, n lmath.Vec3) {
+		r, z, nr, nz := profile(t)
+		phi := s * 2 * math.Pi
+		cos, sin := math.Cos(phi), math.Sin(phi)
+		// Avoid a seam due to floating point error at the end of the
+		// revolution.
+		if s == 1 {
+			cos, sin = 1, 0
+		}
+		p = lmath.Vec3{r * cos, r * sin, z}
+		n = lmath.Vec3{nr * cos, nr * sin, nz}
+		return
+	})
+}
+
+// disc adds a disc (e.g. the cap of a cylinder) at the given height facing up
+// (or down, if up is false), with the given radius and number of segments.
+func (b *builder) disc(z, radius float64, segments int, up bool) {
+	n := lmath.Vec3{0, 0, 1}
+	if !up {
+		n.Z = -1
+	}
+	center := b.vertex(lmath.Vec3{0, 0, z}, n, 0.5, 0.5)
+	for i := 0; i <= segments; i++ {
+		phi := float64(i) / float64(segments) * 2 * math.Pi
+		cos, sin := math.Cos(phi), math.Sin(phi)
+		v := 0.5 - 0.5*sin
+		if !up {
+			v = 0.5 + 0.5*sin
+		}
+		b.vertex(lmath.Vec3{radius * cos, radius * sin, z}, n, 0.5+0.5*cos, v)
+		if i > 0 {
+			cur := center + uint32(i) + 1
+			if up {
+				b.tri(center, cur-1, cur)
+			} else {
+				b.tri(center, cur, cur-1)
+			}
+		}
+	}
+}
+
+// mesh returns a new mesh with the accumulated data.
+func (b *builder) mesh() *gfx.Mesh {
+	m := gfx.NewMesh()
+	m.Vertices = b.vertices
+	m.Indices = b.indices
+	m.TexCoords = []gfx.TexCoordSet{{Slice: b.texCoords}}
+	m.Attribs["Normal"] = gfx.VertexAttrib{Data: b.normals}
+	m.CalculateBounds()
+	return m
+}
+
+// atLeast returns v or min, whichever is larger.
+func atLeast(v, min int) int {
+	if v < min {
+		return min
+	}
+	return v
+}
@@ -0,0 +1,17 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package shapes generates meshes of common geometric shapes.
+//
+// Each generator returns a new indexed triangle mesh (see gfx.NewMesh)
+// centered at the origin, whose triangles have counter-clockwise winding when
+// viewed from outside the shape. The meshes have smooth (or per-face, for
+// boxes) normals stored in the "Normal" custom attribute (see
+// Mesh.GenerateNormals) and a single texture coordinate set, whose origin is
+// the top-left of the texture.
+//
+// Following the conventions of package gfx the +Z axis is up, such that e.g.
+// the axis of a cylinder is the Z axis and a plane lies flat on the XY
+// plane.
+package shapes
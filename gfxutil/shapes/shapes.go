@@ -0,0 +1,215 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shapes
+
+import (
+	"math"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+// Box returns a box mesh of the given size (i.e. full extents along each
+// axis). Each face has it's own vertices, with per-face normals, and is mapped
+// to the full texture.
+func Box(size lmath.Vec3) *gfx.Mesh {
+	half := size.MulScalar(0.5)
+	faces := []struct{ n, up lmath.Vec3 }{
+		{lmath.Vec3{1, 0, 0}, lmath.Vec3{0, 0, 1}},
+		{lmath.Vec3{-1, 0, 0}, lmath.Vec3{0, 0, 1}},
+		{lmath.Vec3{0, 1, 0}, lmath.Vec3{0, 0, 1}},
+		{lmath.Vec3{0, -1, 0}, lmath.Vec3{0, 0, 1}},
+		{lmath.Vec3{0, 0, 1}, lmath.Vec3{0, 1, 0}},
+		{lmath.Vec3{0, 0, -1}, lmath.Vec3{0, -1, 0}},
+	}
+	b := new(builder)
+	for _, f := range faces {
+		right := f.up.Cross(f.n)
+		b.grid(1, 1, false, func(s, t float64) (p, n lmath.Vec3) {
+			p = f.n.Add(right.MulScalar(2*s - 1)).Add(f.up.MulScalar(1 - 2*t))
+			p = lmath.Vec3{p.X * half.X, p.Y * half.Y, p.Z * half.Z}
+			return p, f.n
+		})
+	}
+	return b.mesh()
+}
+
+// Plane returns a flat plane mesh of the given width (along the X axis) and
+// depth (along the Y axis), facing +Z. It is subdivided into a grid of the
+// given number of columns and rows (at least one each), e.g. for vertex
+// displacement.
+func Plane(width, depth float64, cols, rows int) *gfx.Mesh {
+	cols, rows = atLeast(cols, 1), atLeast(rows, 1)
+	b := new(builder)
+	b.grid(cols, rows, false, func(s, t float64) (p, n lmath.Vec3) {
+		p = lmath.Vec3{(s - 0.5) * width, (0.5 - t) * depth, 0}
+		return p, lmath.Vec3{0, 0, 1}
+	})
+	return b.mesh()
+}
+
+// UVSphere returns a sphere mesh of the given radius, made up of the given
+// number of segments (around the Z axis, at least three) and rings (from pole
+// to pole, at least two). The texture is mapped using an equirectangular
+// projection, such that the top of the texture is at the north (+Z) pole.
+func UVSphere(radius float64, segments, rings int) *gfx.Mesh {
+	segments, rings = atLeast(segments, 3), atLeast(rings, 2)
+	b := new(builder)
+	b.lathe(segments, rings, func(t float64) (r, z, nr, nz float64) {
+		theta := t * math.Pi
+		nr, nz = math.Sin(theta), math.Cos(theta)
+		if t == 1 {
+			nr = 0
+		}
+		return radius * nr, radius * nz, nr, nz
+	})
+	return b.mesh()
+}
+
+// Icosphere returns a sphere mesh of the given radius, made by subdividing
+// each triangle of an icosahedron into four the given number of times.
+// Icospheres have evenly distributed triangles (unlike UV spheres), which makes
+// them better suited to e.g. vertex displacement.
+//
+// Vertices are shared between triangles, so the texture is mapped using an
+// equirectangular projection which wraps incorrectly at the seam (where the
+// texture coordinates of a triangle span the U=0/U=1 boundary).
+func Icosphere(radius float64, subdivisions int) *gfx.Mesh {
+	phi := (1 + math.Sqrt(5)) / 2
+	points := []lmath.Vec3{
+		{-1, phi, 0}, {1, phi, 0}, {-1, -phi, 0}, {1, -phi, 0},
+		{0, -1, phi}, {0, 1, phi}, {0, -1, -phi}, {0, 1, -phi},
+		{phi, 0, -1}, {phi, 0, 1}, {-phi, 0, -1}, {-phi, 0, 1},
+	}
+	tris := [][3]uint32{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+	for i, p := range points {
+		points[i], _ = p.Normalized()
+	}
+
+	// Subdivide, sharing the midpoint of each edge between both triangles.
+	for s := 0; s < subdivisions; s++ {
+		midpoints := make(map[[2]uint32]uint32)
+		midpoint := func(a, b uint32) uint32 {
+			key := [2]uint32{a, b}
+			if a > b {
+				key = [2]uint32{b, a}
+			}
+			if i, ok := midpoints[key]; ok {
+				return i
+			}
+			p, _ := points[a].Add(points[b]).Normalized()
+			points = append(points, p)
+			midpoints[key] = uint32(len(points) - 1)
+			return midpoints[key]
+		}
+		next := make([][3]uint32, 0, 4*len(tris))
+		for _, t := range tris {
+			a, b, c := midpoint(t[0], t[1]), midpoint(t[1], t[2]), midpoint(t[2], t[0])
+			next = append(next,
+				[3]uint32{t[0], a, c},
+				[3]uint32{t[1], b, a},
+				[3]uint32{t[2], c, b},
+				[3]uint32{a, b, c},
+			)
+		}
+		tris = next
+	}
+
+	b := new(builder)
+	for _, n := range points {
+		u := 0.5 + math.Atan2(n.Y, n.X)/(2*math.Pi)
+		v := math.Acos(math.Max(-1, math.Min(1, n.Z))) / math.Pi
+		b.vertex(n.MulScalar(radius), n, u, v)
+	}
+	for _, t := range tris {
+		b.tri(t[0], t[1], t[2])
+	}
+	return b.mesh()
+}
+
+// Cylinder returns a capped cylinder mesh of the given radius and height,
+// whose axis is the Z axis, made up of the given number of segments (at least
+// three). The texture is wrapped once around the side, and mapped onto each
+// cap as a circle inscribed in the texture.
+func Cylinder(radius, height float64, segments int) *gfx.Mesh {
+	segments = atLeast(segments, 3)
+	b := new(builder)
+	b.lathe(segments, 1, func(t float64) (r, z, nr, nz float64) {
+		return radius, height * (0.5 - t), 1, 0
+	})
+	b.disc(height/2, radius, segments, true)
+	b.disc(-height/2, radius, segments, false)
+	return b.mesh()
+}
+
+// Capsule returns a capsule mesh (a cylinder with hemispherical ends) of the
+// given radius and cylinder height (i.e. the total height is height+2*radius),
+// whose axis is the Z axis. It is made up of the given number of segments
+// (around the Z axis, at least three) and rings per hemisphere (at least one).
+// The texture is wrapped once around the capsule, with V proportional to the
+// distance along it's surface.
+func Capsule(radius, height float64, segments, rings int) *gfx.Mesh {
+	segments, rings = atLeast(segments, 3), atLeast(rings, 1)
+
+	// The profile rows: rings+1 rows for each hemisphere (the equator rows of
+	// which form the cylinder between them).
+	arc := math.Pi / 2 * radius
+	total := 2*arc + height
+	type row struct{ r, z, nr, nz, v float64 }
+	var rows []row
+	for i := 0; i <= rings; i++ {
+		theta := float64(i) / float64(rings) * math.Pi / 2
+		nr, nz := math.Sin(theta), math.Cos(theta)
+		rows = append(rows, row{radius * nr, height/2 + radius*nz, nr, nz, theta * radius / total})
+	}
+	for i := 0; i <= rings; i++ {
+		theta := math.Pi/2 + float64(i)/float64(rings)*math.Pi/2
+		nr, nz := math.Sin(theta), math.Cos(theta)
+		if i == rings {
+			nr = 0
+		}
+		v := (arc + height + (theta-math.Pi/2)*radius) / total
+		rows = append(rows, row{radius * nr, -height/2 + radius*nz, nr, nz, v})
+	}
+
+	b := new(builder)
+	b.lathe(segments, len(rows)-1, func(t float64) (r, z, nr, nz float64) {
+		rw := rows[int(t*float64(len(rows)-1)+0.5)]
+		return rw.r, rw.z, rw.nr, rw.nz
+	})
+
+	// Replace the evenly spaced V coordinates with the arc length ones.
+	for i := range b.texCoords {
+		rw := rows[i/(segments+1)]
+		b.texCoords[i].V = float32(rw.v)
+	}
+	return b.mesh()
+}
+
+// Torus returns a torus mesh lying on the XY plane, whose tube of the given
+// minor radius is swept in a circle of the given major radius about the Z
+// axis. It is made up of the given number of segments (around the Z axis) and
+// sides (around the tube), at least three each. The texture is wrapped once
+// around the Z axis and once around the tube, starting at the top of it.
+func Torus(major, minor float64, segments, sides int) *gfx.Mesh {
+	segments, sides = atLeast(segments, 3), atLeast(sides, 3)
+	b := new(builder)
+	b.lathe(segments, sides, func(t float64) (r, z, nr, nz float64) {
+		// Start at the top of the tube, and proceed downward along the
+		// outside of it.
+		theta := math.Pi/2 - t*2*math.Pi
+		nr, nz = math.Cos(theta), math.Sin(theta)
+		if t == 1 {
+			nr, nz = 0, 1
+		}
+		return major + minor*nr, minor * nz, nr, nz
+	})
+	return b.mesh()
+}
@@ -0,0 +1,89 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package shapes
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+// checkMesh verifies that the mesh has valid indices, unit normals, and that
+// the winding of each triangle agrees with it's vertex normals.
+func checkMesh(t *testing.T, name string, m *gfx.Mesh) {
+	normals := m.Attribs["Normal"].Data.([]gfx.Vec3)
+	if len(normals) != len(m.Vertices) || len(m.TexCoords[0].Slice) != len(m.Vertices) {
+		t.Fatalf("%s: per-vertex data length mismatch", name)
+	}
+	for i, n := range normals {
+		if l := n.Vec3().Length(); math.Abs(l-1) > 1e-5 {
+			t.Fatalf("%s: normal %d has length %v", name, i, l)
+		}
+	}
+	if len(m.Indices) == 0 || len(m.Indices)%3 != 0 {
+		t.Fatalf("%s: got %d indices", name, len(m.Indices))
+	}
+	for i := 0; i < len(m.Indices); i += 3 {
+		var p [3]lmath.Vec3
+		var avg lmath.Vec3
+		for k := range p {
+			idx := m.Indices[i+k]
+			if int(idx) >= len(m.Vertices) {
+				t.Fatalf("%s: index %d out of range", name, idx)
+			}
+			p[k] = m.Vertices[idx].Vec3()
+			avg = avg.Add(normals[idx].Vec3())
+		}
+		face := p[1].Sub(p[0]).Cross(p[2].Sub(p[0]))
+		if face.Length() < 1e-9 {
+			t.Fatalf("%s: triangle %d is degenerate", name, i/3)
+		}
+		if face.Dot(avg) <= 0 {
+			t.Fatalf("%s: triangle %d winding disagrees with normals", name, i/3)
+		}
+	}
+}
+
+func TestShapes(t *testing.T) {
+	shapes := []struct {
+		name string
+		m    *gfx.Mesh
+		size lmath.Vec3
+	}{
+		{"Box", Box(lmath.Vec3{1, 2, 3}), lmath.Vec3{1, 2, 3}},
+		{"Plane", Plane(4, 2, 3, 2), lmath.Vec3{4, 2, 0}},
+		{"UVSphere", UVSphere(1, 16, 8), lmath.Vec3{2, 2, 2}},
+		{"Icosphere", Icosphere(1, 2), lmath.Vec3{2, 2, 2}},
+		{"Cylinder", Cylinder(1, 3, 12), lmath.Vec3{2, 2, 3}},
+		{"Capsule", Capsule(0.5, 1, 12, 4), lmath.Vec3{1, 1, 2}},
+		{"Torus", Torus(2, 0.5, 24, 12), lmath.Vec3{5, 5, 1}},
+	}
+	for _, s := range shapes {
+		checkMesh(t, s.name, s.m)
+		bb := s.m.Bounds()
+		size := bb.Max.Sub(bb.Min)
+		for i, want := range []float64{s.size.X, s.size.Y, s.size.Z} {
+			got := []float64{size.X, size.Y, size.Z}[i]
+			if math.Abs(got-want) > 0.05*want+1e-6 {
+				t.Errorf("%s: size %v, want %v", s.name, size, s.size)
+				break
+			}
+		}
+	}
+}
+
+func TestVertexCounts(t *testing.T) {
+	if n := len(Box(lmath.Vec3{1, 1, 1}).Vertices); n != 24 {
+		t.Errorf("Box has %d vertices, want 24", n)
+	}
+	if n := len(Icosphere(1, 1).Indices) / 3; n != 80 {
+		t.Errorf("Icosphere(1, 1) has %d triangles, want 80", n)
+	}
+	if n := len(Plane(1, 1, 4, 4).Indices) / 3; n != 32 {
+		t.Errorf("Plane(1, 1, 4, 4) has %d triangles, want 32", n)
+	}
+}
@@ -0,0 +1,990 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goimage "image"
+	_ "image/jpeg" // Register the JPEG decoder, PNG is imported by encode.go.
+	"io"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+// ErrExternal is returned by Decode when the document references an external
+// resource (i.e. a buffer or image file), which can only be resolved by Open.
+var ErrExternal = errors.New("gltf: external resource (use Open)")
+
+// Scene is a glTF scene decoded into graphics objects.
+type Scene struct {
+	// The root nodes of the scene.
+	Nodes []*Node
+
+	// Every graphics object of the scene, i.e. those of every node in
+	// depth-first order.
+	Objects []*gfx.Object
+
+	// The materials and skins of the document.
+	Materials []*Material
+	Skins     []*Skin
+}
+
+// Node is a single node of the scene's hierarchy.
+type Node struct {
+	// The name of the node, if any.
+	Name string
+
+	// The transform of the node, whose parent is the transform of the parent
+	// node (or nil for root nodes).
+	Transform *gfx.Transform
+
+	// The parent node, or nil for root nodes, and the child nodes.
+	Parent   *Node
+	Children []*Node
+
+	// The graphics objects of the node, one for each primitive of the node's
+	// glTF mesh, which share the node's transform. Nodes referencing the same
+	// glTF mesh share the same *gfx.Mesh pointers.
+	Objects []*gfx.Object
+
+	// The materials of each object (i.e. parallel to Objects), nil if the
+	// primitive has no material.
+	Materials []*Material
+
+	// The skin of the node's mesh, or nil if it is not skinned.
+	Skin *Skin
+}
+
+// Skin describes the joints of a skinned mesh, whose vertices are influenced
+// by the joints listed in the "Joints" custom attribute of the mesh, with the
+// weights listed in the "Weights" custom attribute (both []gfx.Vec4).
+type Skin struct {
+	// The name of the skin, if any.
+	Name string
+
+	// The joint nodes, indexed by the "Joints" attribute.
+	Joints []*Node
+
+	// The inverse bind matrix of each joint, which transforms vertices into
+	// the local space of the joint.
+	InverseBindMatrices []lmath.Mat4
+}
+
+// Material is a glTF physically based (metallic-roughness) material. Only the
+// base color and alpha mode map onto gfx.Object; the remaining properties are
+// provided for use by the application's shaders.
+type Material struct {
+	// The name of the material, if any.
+	Name string
+
+	// The base color factor, which is sRGB-encoded (like gfx.Object.Tint),
+	// and the base color texture (which has gfx.Texture.SRGB set).
+	BaseColor        gfx.Color
+	BaseColorTexture *gfx.Texture
+
+	// The metallic and roughness factors, and the texture whose blue and
+	// green channels hold the metalness and roughness respectively.
+	Metallic, Roughness      float32
+	MetallicRoughnessTexture *gfx.Texture
+
+	// The tangent space normal map and ambient occlusion textures.
+	NormalTexture    *gfx.Texture
+	OcclusionTexture *gfx.Texture
+
+	// The linear emissive color factor and the emissive texture (which has
+	// gfx.Texture.SRGB set).
+	Emissive        gfx.Color
+	EmissiveTexture *gfx.Texture
+
+	// The alpha mode of the material, and the alpha cutoff used by
+	// gfx.BinaryAlpha materials.
+	AlphaMode   gfx.AlphaMode
+	AlphaCutoff float32
+
+	// Whether back faces are visible.
+	DoubleSided bool
+}
+
+// apply applies the material to the object.
+func (m *Material) apply(o *gfx.Object) {
+	o.Tint = m.BaseColor
+	o.State.AlphaMode = m.AlphaMode
+	if m.DoubleSided {
+		o.State.FaceCulling = gfx.NoFaceCulling
+	}
+	if m.BaseColorTexture != nil {
+		o.Textures = append(o.Textures, m.BaseColorTexture)
+	}
+}
+
+type textureKey struct {
+	index int
+	srgb  bool
+}
+
+type decoder struct {
+	doc      document
+	open     func(uri string) ([]byte, error)
+	buffers  [][]byte
+	textures map[textureKey]*gfx.Texture
+	meshes   map[int][]*gfx.Mesh
+	nodes    []*Node
+	scene    *Scene
+	external bool
+}
+
+// Decode reads a glTF 2.0 scene from r, which may be in either the binary
+// (.glb) or the JSON (.gltf) format.
+//
+// Every buffer and image must be embedded in the file (i.e. within the binary
+// chunk of a .glb file or as a data URI), otherwise ErrExternal is returned.
+// Use Open to decode files referencing external resources.
+//
+// The scene is converted from the Y-up glTF coordinate system into the Z-up
+// gfx one. Objects have no shader assigned. Meshes without normals are
+// decoded as-is (see gfx.Mesh.GenerateNormals), and sparse accessors and
+// morph targets are not supported.
+func Decode(r io.Reader) (*Scene, error) {
+	return decode(r, nil)
+}
+
+// Open opens and decodes the glTF 2.0 file at the given path (see Decode),
+// resolving the URIs of external resources relative to the file's directory.
+func Open(path string) (*Scene, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	dir := filepath.Dir(path)
+	return decode(f, func(uri string) ([]byte, error) {
+		p, err := url.PathUnescape(uri)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(p)))
+	})
+}
+
+func decode(r io.Reader, open func(uri string) ([]byte, error)) (*Scene, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	js, bin, err := splitGLB(data)
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{
+		open:     open,
+		textures: make(map[textureKey]*gfx.Texture),
+		meshes:   make(map[int][]*gfx.Mesh),
+		scene:    new(Scene),
+	}
+	if err := json.Unmarshal(js, &d.doc); err != nil {
+		return nil, fmt.Errorf("gltf: %v", err)
+	}
+	if !strings.HasPrefix(d.doc.Asset.Version, "2.") {
+		return nil, fmt.Errorf("gltf: unsupported version %q", d.doc.Asset.Version)
+	}
+	if err := d.decode(bin); err != nil {
+		if d.external {
+			return nil, ErrExternal
+		}
+		return nil, fmt.Errorf("gltf: %v", err)
+	}
+	return d.scene, nil
+}
+
+// splitGLB splits a binary glTF file into it's JSON and binary chunks. If the
+// data is not a binary glTF file it is returned as the JSON chunk.
+func splitGLB(data []byte) (js, bin []byte, err error) {
+	if len(data) < glbHeaderLen || binary.LittleEndian.Uint32(data) != glbMagic {
+		return data, nil, nil
+	}
+	if v := binary.LittleEndian.Uint32(data[4:]); v != glbVersion {
+		return nil, nil, fmt.Errorf("gltf: unsupported binary version %d", v)
+	}
+	if l := int(binary.LittleEndian.Uint32(data[8:])); l < len(data) {
+		data = data[:l]
+	}
+	for off := glbHeaderLen; off+8 <= len(data); {
+		l := int(binary.LittleEndian.Uint32(data[off:]))
+		typ := binary.LittleEndian.Uint32(data[off+4:])
+		off += 8
+		if l < 0 || off+l > len(data) {
+			return nil, nil, errors.New("gltf: truncated chunk")
+		}
+		switch {
+		case typ == chunkJSON && js == nil:
+			js = data[off : off+l]
+		case typ == chunkBIN && bin == nil:
+			bin = data[off : off+l]
+		}
+		off += l
+	}
+	if js == nil {
+		return nil, nil, errors.New("gltf: missing JSON chunk")
+	}
+	return js, bin, nil
+}
+
+// uri returns the data referenced by the given URI.
+func (d *decoder) uri(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		i := strings.IndexByte(uri, ',')
+		if i < 0 || !strings.HasSuffix(uri[:i], ";base64") {
+			return nil, fmt.Errorf("unsupported data URI %.32q", uri)
+		}
+		return base64.StdEncoding.DecodeString(uri[i+1:])
+	}
+	if d.open == nil {
+		d.external = true
+		return nil, ErrExternal
+	}
+	return d.open(uri)
+}
+
+func (d *decoder) decode(bin []byte) error {
+	doc := &d.doc
+	for i, b := range doc.Buffers {
+		var data []byte
+		switch {
+		case b.URI != "":
+			var err error
+			if data, err = d.uri(b.URI); err != nil {
+				return fmt.Errorf("buffer %d: %v", i, err)
+			}
+		case i == 0 && bin != nil:
+			data = bin
+		}
+		if len(data) < b.ByteLength {
+			return fmt.Errorf("buffer %d: %d bytes, want %d", i, len(data), b.ByteLength)
+		}
+		d.buffers = append(d.buffers, data)
+	}
+
+	for i := range doc.Materials {
+		m, err := d.material(&doc.Materials[i])
+		if err != nil {
+			return fmt.Errorf("material %d: %v", i, err)
+		}
+		d.scene.Materials = append(d.scene.Materials, m)
+	}
+
+	// Create every node, then link them into the hierarchy.
+	for i := range doc.Nodes {
+		n, err := d.node(&doc.Nodes[i])
+		if err != nil {
+			return fmt.Errorf("node %d: %v", i, err)
+		}
+		d.nodes = append(d.nodes, n)
+	}
+	for i, gn := range doc.Nodes {
+		n := d.nodes[i]
+		for _, c := range gn.Children {
+			if c < 0 || c >= len(d.nodes) || d.nodes[c].Parent != nil || c == i {
+				return fmt.Errorf("node %d: invalid child %d", i, c)
+			}
+			child := d.nodes[c]
+			child.Parent = n
+			child.Transform.SetParent(n.Transform)
+			n.Children = append(n.Children, child)
+		}
+	}
+
+	for i, s := range doc.Skins {
+		sk, err := d.skin(s)
+		if err != nil {
+			return fmt.Errorf("skin %d: %v", i, err)
+		}
+		d.scene.Skins = append(d.scene.Skins, sk)
+	}
+	for i, gn := range doc.Nodes {
+		if gn.Skin == nil {
+			continue
+		}
+		if *gn.Skin < 0 || *gn.Skin >= len(d.scene.Skins) {
+			return fmt.Errorf("node %d: invalid skin %d", i, *gn.Skin)
+		}
+		d.nodes[i].Skin = d.scene.Skins[*gn.Skin]
+	}
+
+	// Find the root nodes of the scene.
+	if doc.Scene >= 0 && doc.Scene < len(doc.Scenes) {
+		for _, i := range doc.Scenes[doc.Scene].Nodes {
+			if i < 0 || i >= len(d.nodes) {
+				return fmt.Errorf("scene %d: invalid node %d", doc.Scene, i)
+			}
+			d.scene.Nodes = append(d.scene.Nodes, d.nodes[i])
+		}
+	} else {
+		for _, n := range d.nodes {
+			if n.Parent == nil {
+				d.scene.Nodes = append(d.scene.Nodes, n)
+			}
+		}
+	}
+	var collect func(n *Node)
+	collect = func(n *Node) {
+		d.scene.Objects = append(d.scene.Objects, n.Objects...)
+		for _, c := range n.Children {
+			collect(c)
+		}
+	}
+	for _, n := range d.scene.Nodes {
+		collect(n)
+	}
+	return nil
+}
+
+// yUpToZUp converts a vector from the glTF Y-up coordinate system into the
+// gfx Z-up one.
+func yUpToZUp(x, y, z float32) gfx.Vec3 {
+	return gfx.Vec3{X: x, Y: -z, Z: y}
+}
+
+// convMat4 is the matrix converting row vectors from the glTF Y-up coordinate
+// system into the gfx Z-up one (see yUpToZUp).
+var convMat4 = lmath.Mat4{
+	{1, 0, 0, 0},
+	{0, 0, 1, 0},
+	{0, -1, 0, 0},
+	{0, 0, 0, 1},
+}
+
+// convertMat4 converts a glTF matrix (whose column-major memory layout is the
+// same as that of the row-major matrices operating on row vectors used by
+// gfx) into the gfx Z-up coordinate system.
+func convertMat4(m []float32) lmath.Mat4 {
+	var r lmath.Mat4
+	for i := 0; i < 16; i++ {
+		r[i/4][i%4] = float64(m[i])
+	}
+	return convMat4.Transposed().Mul(r).Mul(convMat4)
+}
+
+func (d *decoder) node(gn *node) (*Node, error) {
+	n := &Node{Name: gn.Name, Transform: gfx.NewTransform()}
+
+	// Build the local matrix and convert it to Z-up.
+	m := lmath.Mat4Identity
+	switch {
+	case len(gn.Matrix) == 16:
+		f := make([]float32, 16)
+		for i, v := range gn.Matrix {
+			f[i] = float32(v)
+		}
+		m = convertMat4(f)
+	case len(gn.Matrix) != 0:
+		return nil, fmt.Errorf("matrix has %d elements", len(gn.Matrix))
+	default:
+		if gn.Scale != nil {
+			s := *gn.Scale
+			m[0][0], m[1][1], m[2][2] = s[0], s[1], s[2]
+		}
+		if gn.Rotation != nil {
+			q := *gn.Rotation
+			m = m.Mul(quatMat4(q[0], q[1], q[2], q[3]))
+		}
+		if gn.Translation != nil {
+			t := *gn.Translation
+			m = m.SetTranslation(lmath.Vec3{X: t[0], Y: t[1], Z: t[2]})
+		}
+		m = convMat4.Transposed().Mul(m).Mul(convMat4)
+	}
+	pos, rot, scale := decompose(m)
+	n.Transform.SetPos(pos)
+	n.Transform.SetRot(rot)
+	n.Transform.SetScale(scale)
+
+	if gn.Mesh != nil {
+		meshes, mats, err := d.mesh(*gn.Mesh)
+		if err != nil {
+			return nil, err
+		}
+		for i, msh := range meshes {
+			o := gfx.NewObject()
+			o.Transform = n.Transform
+			o.Meshes = []*gfx.Mesh{msh}
+			if mats[i] != nil {
+				mats[i].apply(o)
+			}
+			n.Objects = append(n.Objects, o)
+		}
+		n.Materials = mats
+	}
+	return n, nil
+}
+
+// quatMat4 returns the rotation matrix (operating on row vectors) of the
+// given unit quaternion.
+func quatMat4(x, y, z, w float64) lmath.Mat4 {
+	return lmath.Mat4{
+		{1 - 2*(y*y+z*z), 2 * (x*y + w*z), 2 * (x*z - w*y), 0},
+		{2 * (x*y - w*z), 1 - 2*(x*x+z*z), 2 * (y*z + w*x), 0},
+		{2 * (x*z + w*y), 2 * (y*z - w*x), 1 - 2*(x*x+y*y), 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// decompose decomposes the matrix into the position, euler rotation (in
+// degrees, see gfx.Transform.SetRot), and scale of a transform.
+func decompose(m lmath.Mat4) (pos, rot, scale lmath.Vec3) {
+	pos = m.Translation()
+	row := func(i int) lmath.Vec3 { return lmath.Vec3{X: m[i][0], Y: m[i][1], Z: m[i][2]} }
+	x, y, z := row(0), row(1), row(2)
+	scale = lmath.Vec3{X: x.Length(), Y: y.Length(), Z: z.Length()}
+	if x.Cross(y).Dot(z) < 0 {
+		scale.X = -scale.X
+	}
+	if scale.X == 0 || scale.Y == 0 || scale.Z == 0 {
+		return pos, lmath.Vec3{}, scale
+	}
+	x, y, z = x.DivScalar(scale.X), y.DivScalar(scale.Y), z.DivScalar(scale.Z)
+
+	// Convert the rotation matrix into a quaternion.
+	var q lmath.Quat
+	switch tr := x.X + y.Y + z.Z; {
+	case tr > 0:
+		s := math.Sqrt(tr+1) * 2
+		q = lmath.Quat{W: s / 4, X: (y.Z - z.Y) / s, Y: (z.X - x.Z) / s, Z: (x.Y - y.X) / s}
+	case x.X > y.Y && x.X > z.Z:
+		s := math.Sqrt(1+x.X-y.Y-z.Z) * 2
+		q = lmath.Quat{W: (y.Z - z.Y) / s, X: s / 4, Y: (y.X + x.Y) / s, Z: (z.X + x.Z) / s}
+	case y.Y > z.Z:
+		s := math.Sqrt(1+y.Y-x.X-z.Z) * 2
+		q = lmath.Quat{W: (z.X - x.Z) / s, X: (y.X + x.Y) / s, Y: s / 4, Z: (z.Y + y.Z) / s}
+	default:
+		s := math.Sqrt(1+z.Z-x.X-y.Y) * 2
+		q = lmath.Quat{W: (x.Y - y.X) / s, X: (z.X + x.Z) / s, Y: (z.Y + y.Z) / s, Z: s / 4}
+	}
+	rot = q.Hpr(lmath.CoordSysZUpRight).HprToXyz().Degrees()
+	return pos, rot, scale
+}
+
+func (d *decoder) skin(s skin) (*Skin, error) {
+	sk := &Skin{Name: s.Name}
+	for _, j := range s.Joints {
+		if j < 0 || j >= len(d.nodes) {
+			return nil, fmt.Errorf("invalid joint %d", j)
+		}
+		sk.Joints = append(sk.Joints, d.nodes[j])
+		sk.InverseBindMatrices = append(sk.InverseBindMatrices, lmath.Mat4Identity)
+	}
+	if s.InverseBindMatrices == nil {
+		return sk, nil
+	}
+	data, comps, err := d.floats(*s.InverseBindMatrices)
+	if err != nil {
+		return nil, err
+	}
+	if comps != 16 || len(data) < 16*len(sk.Joints) {
+		return nil, errors.New("invalid inverse bind matrices")
+	}
+	for i := range sk.Joints {
+		sk.InverseBindMatrices[i] = convertMat4(data[16*i:])
+	}
+	return sk, nil
+}
+
+// primitives maps glTF primitive modes to gfx primitives.
+var primitives = map[int]gfx.Primitive{
+	modePoints:        gfx.Points,
+	modeLines:         gfx.Lines,
+	modeLineLoop:      gfx.LineLoop,
+	modeLineStrip:     gfx.LineStrip,
+	modeTriangles:     gfx.Triangles,
+	modeTriangleStrip: gfx.TriangleStrip,
+	modeTriangleFan:   gfx.TriangleFan,
+}
+
+// mesh decodes the glTF mesh with the given index, returning one mesh and
+// material for each of it's primitives.
+func (d *decoder) mesh(index int) ([]*gfx.Mesh, []*Material, error) {
+	if index < 0 || index >= len(d.doc.Meshes) {
+		return nil, nil, fmt.Errorf("invalid mesh %d", index)
+	}
+	var mats []*Material
+	for _, p := range d.doc.Meshes[index].Primitives {
+		var mat *Material
+		if p.Material != nil {
+			if *p.Material < 0 || *p.Material >= len(d.scene.Materials) {
+				return nil, nil, fmt.Errorf("mesh %d: invalid material %d", index, *p.Material)
+			}
+			mat = d.scene.Materials[*p.Material]
+		}
+		mats = append(mats, mat)
+	}
+	if meshes, ok := d.meshes[index]; ok {
+		return meshes, mats, nil
+	}
+	var meshes []*gfx.Mesh
+	for i, p := range d.doc.Meshes[index].Primitives {
+		m, err := d.primitive(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("mesh %d: primitive %d: %v", index, i, err)
+		}
+		meshes = append(meshes, m)
+	}
+	d.meshes[index] = meshes
+	return meshes, mats, nil
+}
+
+func (d *decoder) primitive(p primitive) (*gfx.Mesh, error) {
+	m := gfx.NewMesh()
+	mode := modeTriangles
+	if p.Mode != nil {
+		mode = *p.Mode
+	}
+	prim, ok := primitives[mode]
+	if !ok {
+		return nil, fmt.Errorf("invalid mode %d", mode)
+	}
+	m.Primitive = prim
+
+	pos, ok := p.Attributes["POSITION"]
+	if !ok {
+		return nil, errors.New("missing POSITION attribute")
+	}
+	data, comps, err := d.floats(pos)
+	if err != nil {
+		return nil, err
+	}
+	if comps != 3 {
+		return nil, errors.New("POSITION attribute is not VEC3")
+	}
+	n := len(data) / 3
+	m.Vertices = make([]gfx.Vec3, n)
+	for i := range m.Vertices {
+		m.Vertices[i] = yUpToZUp(data[3*i], data[3*i+1], data[3*i+2])
+	}
+
+	// attrib reads the named attribute, which must have one of the given
+	// numbers of components and a value for each vertex.
+	attrib := func(name string, want ...int) ([]float32, int, bool, error) {
+		i, ok := p.Attributes[name]
+		if !ok {
+			return nil, 0, false, nil
+		}
+		data, comps, err := d.floats(i)
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("%s attribute: %v", name, err)
+		}
+		if len(data) != n*comps {
+			return nil, 0, false, fmt.Errorf("%s attribute has %d elements, want %d", name, len(data)/comps, n)
+		}
+		for _, w := range want {
+			if comps == w {
+				return data, comps, true, nil
+			}
+		}
+		return nil, 0, false, fmt.Errorf("%s attribute has %d components", name, comps)
+	}
+
+	if data, _, ok, err := attrib("NORMAL", 3); err != nil {
+		return nil, err
+	} else if ok {
+		normals := make([]gfx.Vec3, n)
+		for i := range normals {
+			normals[i] = yUpToZUp(data[3*i], data[3*i+1], data[3*i+2])
+		}
+		m.Attribs["Normal"] = gfx.VertexAttrib{Data: normals}
+	}
+	if data, _, ok, err := attrib("TANGENT", 4); err != nil {
+		return nil, err
+	} else if ok {
+		tangents := make([]gfx.Vec4, n)
+		for i := range tangents {
+			v := yUpToZUp(data[4*i], data[4*i+1], data[4*i+2])
+			tangents[i] = gfx.Vec4{X: v.X, Y: v.Y, Z: v.Z, W: data[4*i+3]}
+		}
+		m.Attribs["Tangent"] = gfx.VertexAttrib{Data: tangents}
+	}
+	for set := 0; ; set++ {
+		data, _, ok, err := attrib(fmt.Sprintf("TEXCOORD_%d", set), 2)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			break
+		}
+		tcs := make([]gfx.TexCoord, n)
+		for i := range tcs {
+			tcs[i] = gfx.TexCoord{U: data[2*i], V: data[2*i+1]}
+		}
+		m.TexCoords = append(m.TexCoords, gfx.TexCoordSet{Slice: tcs})
+	}
+	if data, comps, ok, err := attrib("COLOR_0", 3, 4); err != nil {
+		return nil, err
+	} else if ok {
+		// glTF vertex colors are linear, but gfx colors are sRGB-encoded.
+		m.Colors = make([]gfx.Color, n)
+		for i := range m.Colors {
+			c := gfx.Color{R: data[comps*i], G: data[comps*i+1], B: data[comps*i+2], A: 1}
+			if comps == 4 {
+				c.A = data[comps*i+3]
+			}
+			m.Colors[i] = c.SRGB()
+		}
+	}
+	for _, a := range [][2]string{{"JOINTS_0", "Joints"}, {"WEIGHTS_0", "Weights"}} {
+		data, _, ok, err := attrib(a[0], 4)
+		if err != nil {
+			return nil, err
+		} else if ok {
+			m.Attribs[a[1]] = gfx.VertexAttrib{Data: vec4s(data)}
+		}
+	}
+
+	// Application-specific attributes keep their glTF name, e.g. "_WIND".
+	for name := range p.Attributes {
+		if !strings.HasPrefix(name, "_") {
+			continue
+		}
+		data, comps, _, err := attrib(name, 1, 3, 4)
+		if err != nil {
+			return nil, err
+		}
+		switch comps {
+		case 1:
+			m.Attribs[name] = gfx.VertexAttrib{Data: data}
+		case 3:
+			v := make([]gfx.Vec3, n)
+			for i := range v {
+				v[i] = gfx.Vec3{X: data[3*i], Y: data[3*i+1], Z: data[3*i+2]}
+			}
+			m.Attribs[name] = gfx.VertexAttrib{Data: v}
+		case 4:
+			m.Attribs[name] = gfx.VertexAttrib{Data: vec4s(data)}
+		}
+	}
+
+	if p.Indices != nil {
+		indices, err := d.indices(*p.Indices)
+		if err != nil {
+			return nil, fmt.Errorf("indices: %v", err)
+		}
+		for _, i := range indices {
+			if int(i) >= n {
+				return nil, fmt.Errorf("index %d out of range", i)
+			}
+		}
+		m.Indices = indices
+	}
+	m.CalculateBounds()
+	return m, nil
+}
+
+func vec4s(data []float32) []gfx.Vec4 {
+	v := make([]gfx.Vec4, len(data)/4)
+	for i := range v {
+		v[i] = gfx.Vec4{X: data[4*i], Y: data[4*i+1], Z: data[4*i+2], W: data[4*i+3]}
+	}
+	return v
+}
+
+// typeComponents maps glTF accessor types to their number of components.
+var typeComponents = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+	"MAT4":   16,
+}
+
+// componentSizes maps glTF component types to their size in bytes.
+var componentSizes = map[int]int{
+	componentInt8:   1,
+	componentUint8:  1,
+	componentInt16:  2,
+	componentUint16: 2,
+	componentUint32: 4,
+	componentFloat:  4,
+}
+
+// elements returns the accessor with the given index, it's number of
+// components, and the data of each of it's elements.
+func (d *decoder) elements(index int) (a accessor, comps int, elems [][]byte, err error) {
+	if index < 0 || index >= len(d.doc.Accessors) {
+		return a, 0, nil, fmt.Errorf("invalid accessor %d", index)
+	}
+	a = d.doc.Accessors[index]
+	comps, ok := typeComponents[a.Type]
+	size, ok2 := componentSizes[a.ComponentType]
+	if !ok || !ok2 || a.Count < 0 {
+		return a, 0, nil, fmt.Errorf("accessor %d: unsupported type %s of %d", index, a.Type, a.ComponentType)
+	}
+	if a.Sparse != nil {
+		return a, 0, nil, fmt.Errorf("accessor %d: sparse accessors are not supported", index)
+	}
+	elemSize := comps * size
+	if a.BufferView == nil {
+		// The elements are all zero.
+		zero := make([]byte, elemSize)
+		elems = make([][]byte, a.Count)
+		for i := range elems {
+			elems[i] = zero
+		}
+		return a, comps, elems, nil
+	}
+
+	v := *a.BufferView
+	if v < 0 || v >= len(d.doc.BufferViews) {
+		return a, 0, nil, fmt.Errorf("accessor %d: invalid buffer view %d", index, v)
+	}
+	bv := d.doc.BufferViews[v]
+	if bv.Buffer < 0 || bv.Buffer >= len(d.buffers) {
+		return a, 0, nil, fmt.Errorf("buffer view %d: invalid buffer %d", v, bv.Buffer)
+	}
+	buf := d.buffers[bv.Buffer]
+	if bv.ByteOffset < 0 || bv.ByteLength < 0 || bv.ByteOffset+bv.ByteLength > len(buf) {
+		return a, 0, nil, fmt.Errorf("buffer view %d: out of range", v)
+	}
+	data := buf[bv.ByteOffset : bv.ByteOffset+bv.ByteLength]
+	stride := elemSize
+	if bv.ByteStride != 0 {
+		stride = bv.ByteStride
+	}
+	if a.Count > 0 && (a.ByteOffset < 0 || a.ByteOffset+stride*(a.Count-1)+elemSize > len(data)) {
+		return a, 0, nil, fmt.Errorf("accessor %d: out of range", index)
+	}
+	elems = make([][]byte, a.Count)
+	for i := range elems {
+		off := a.ByteOffset + stride*i
+		elems[i] = data[off : off+elemSize]
+	}
+	return a, comps, elems, nil
+}
+
+// floats returns the data of the accessor with the given index as floats,
+// and it's number of components. Normalized integers are converted into the
+// range [0, 1] (or [-1, 1] if signed).
+func (d *decoder) floats(index int) ([]float32, int, error) {
+	a, comps, elems, err := d.elements(index)
+	if err != nil {
+		return nil, 0, err
+	}
+	size := componentSizes[a.ComponentType]
+	data := make([]float32, 0, comps*len(elems))
+	for _, e := range elems {
+		for c := 0; c < comps; c++ {
+			b := e[c*size:]
+			var v float32
+			switch a.ComponentType {
+			case componentInt8:
+				v = float32(int8(b[0]))
+				if a.Normalized {
+					v = float32(math.Max(float64(v)/127, -1))
+				}
+			case componentUint8:
+				v = float32(b[0])
+				if a.Normalized {
+					v /= 255
+				}
+			case componentInt16:
+				v = float32(int16(binary.LittleEndian.Uint16(b)))
+				if a.Normalized {
+					v = float32(math.Max(float64(v)/32767, -1))
+				}
+			case componentUint16:
+				v = float32(binary.LittleEndian.Uint16(b))
+				if a.Normalized {
+					v /= 65535
+				}
+			case componentUint32:
+				v = float32(binary.LittleEndian.Uint32(b))
+			case componentFloat:
+				v = math.Float32frombits(binary.LittleEndian.Uint32(b))
+			}
+			data = append(data, v)
+		}
+	}
+	return data, comps, nil
+}
+
+// indices returns the data of the scalar, unsigned integer accessor with the
+// given index.
+func (d *decoder) indices(index int) ([]uint32, error) {
+	a, comps, elems, err := d.elements(index)
+	if err != nil {
+		return nil, err
+	}
+	if comps != 1 {
+		return nil, fmt.Errorf("accessor %d: type %s is not SCALAR", index, a.Type)
+	}
+	data := make([]uint32, len(elems))
+	for i, e := range elems {
+		switch a.ComponentType {
+		case componentUint8:
+			data[i] = uint32(e[0])
+		case componentUint16:
+			data[i] = uint32(binary.LittleEndian.Uint16(e))
+		case componentUint32:
+			data[i] = binary.LittleEndian.Uint32(e)
+		default:
+			return nil, fmt.Errorf("accessor %d: component type %d is not unsigned", index, a.ComponentType)
+		}
+	}
+	return data, nil
+}
+
+func (d *decoder) material(gm *material) (*Material, error) {
+	m := &Material{
+		Name:        gm.Name,
+		BaseColor:   gfx.Color{R: 1, G: 1, B: 1, A: 1},
+		Metallic:    1,
+		Roughness:   1,
+		Emissive:    gfx.Color{R: gm.EmissiveFactor[0], G: gm.EmissiveFactor[1], B: gm.EmissiveFactor[2], A: 1},
+		AlphaCutoff: 0.5,
+		DoubleSided: gm.DoubleSided,
+	}
+	if f := gm.PBR.BaseColorFactor; f != nil {
+		m.BaseColor = gfx.Color{R: f[0], G: f[1], B: f[2], A: f[3]}.SRGB()
+	}
+	if gm.PBR.MetallicFactor != nil {
+		m.Metallic = *gm.PBR.MetallicFactor
+	}
+	if gm.PBR.RoughnessFactor != nil {
+		m.Roughness = *gm.PBR.RoughnessFactor
+	}
+	if gm.AlphaCutoff != nil {
+		m.AlphaCutoff = *gm.AlphaCutoff
+	}
+	switch gm.AlphaMode {
+	case "", "OPAQUE":
+		m.AlphaMode = gfx.NoAlpha
+	case "BLEND":
+		m.AlphaMode = gfx.AlphaBlend
+	case "MASK":
+		m.AlphaMode = gfx.BinaryAlpha
+	default:
+		return nil, fmt.Errorf("invalid alpha mode %q", gm.AlphaMode)
+	}
+
+	textures := []struct {
+		info *textureInfo
+		dst  **gfx.Texture
+		srgb bool
+	}{
+		{gm.PBR.BaseColorTexture, &m.BaseColorTexture, true},
+		{gm.PBR.MetallicRoughnessTexture, &m.MetallicRoughnessTexture, false},
+		{gm.NormalTexture, &m.NormalTexture, false},
+		{gm.OcclusionTexture, &m.OcclusionTexture, false},
+		{gm.EmissiveTexture, &m.EmissiveTexture, true},
+	}
+	for _, t := range textures {
+		if t.info == nil {
+			continue
+		}
+		tex, err := d.texture(t.info.Index, t.srgb)
+		if err != nil {
+			return nil, err
+		}
+		*t.dst = tex
+	}
+	return m, nil
+}
+
+// texture decodes the texture with the given index, decoding each texture
+// only once for each color space.
+func (d *decoder) texture(index int, srgb bool) (*gfx.Texture, error) {
+	key := textureKey{index, srgb}
+	if t, ok := d.textures[key]; ok {
+		return t, nil
+	}
+	if index < 0 || index >= len(d.doc.Textures) {
+		return nil, fmt.Errorf("invalid texture %d", index)
+	}
+	gt := d.doc.Textures[index]
+	if gt.Source == nil || *gt.Source < 0 || *gt.Source >= len(d.doc.Images) {
+		return nil, fmt.Errorf("texture %d: invalid source", index)
+	}
+	gi := d.doc.Images[*gt.Source]
+	var data []byte
+	switch {
+	case gi.URI != "":
+		var err error
+		if data, err = d.uri(gi.URI); err != nil {
+			return nil, fmt.Errorf("image %d: %v", *gt.Source, err)
+		}
+	case gi.BufferView != nil:
+		v := *gi.BufferView
+		if v < 0 || v >= len(d.doc.BufferViews) {
+			return nil, fmt.Errorf("image %d: invalid buffer view %d", *gt.Source, v)
+		}
+		bv := d.doc.BufferViews[v]
+		if bv.Buffer < 0 || bv.Buffer >= len(d.buffers) || bv.ByteOffset < 0 || bv.ByteLength < 0 || bv.ByteOffset+bv.ByteLength > len(d.buffers[bv.Buffer]) {
+			return nil, fmt.Errorf("buffer view %d: out of range", v)
+		}
+		data = d.buffers[bv.Buffer][bv.ByteOffset : bv.ByteOffset+bv.ByteLength]
+	default:
+		return nil, fmt.Errorf("image %d: no data", *gt.Source)
+	}
+	img, _, err := goimage.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("image %d: %v", *gt.Source, err)
+	}
+
+	t := gfx.NewTexture()
+	t.Source = img
+	t.Bounds = img.Bounds()
+	t.SRGB = srgb
+	t.MinFilter = gfx.LinearMipmapLinear
+	t.MagFilter = gfx.Linear
+	if gt.Sampler != nil {
+		if *gt.Sampler < 0 || *gt.Sampler >= len(d.doc.Samplers) {
+			return nil, fmt.Errorf("texture %d: invalid sampler %d", index, *gt.Sampler)
+		}
+		s := d.doc.Samplers[*gt.Sampler]
+		if s.MinFilter != 0 {
+			t.MinFilter = texFilter(s.MinFilter)
+		}
+		if s.MagFilter != 0 {
+			t.MagFilter = texFilter(s.MagFilter)
+		}
+		t.WrapU = texWrap(s.WrapS)
+		t.WrapV = texWrap(s.WrapT)
+	}
+	d.textures[key] = t
+	return t, nil
+}
+
+func texFilter(f int) gfx.TexFilter {
+	switch f {
+	case filterNearest:
+		return gfx.Nearest
+	case filterNearestMipmapNearest:
+		return gfx.NearestMipmapNearest
+	case filterLinearMipmapNearest:
+		return gfx.LinearMipmapNearest
+	case filterNearestMipmapLinear:
+		return gfx.NearestMipmapLinear
+	case filterLinearMipmapLinear:
+		return gfx.LinearMipmapLinear
+	}
+	return gfx.Linear
+}
+
+func texWrap(w int) gfx.TexWrap {
+	switch w {
+	case wrapClampToEdge:
+		return gfx.Clamp
+	case wrapMirroredRepeat:
+		return gfx.Mirror
+	}
+	return gfx.Repeat
+}
@@ -0,0 +1,125 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"azul3d.org/gfx.v1"
+	"azul3d.org/lmath.v1"
+)
+
+func TestDecodeRoundTrip(t *testing.T) {
+	o := testObject()
+	o.Transform.SetPos(lmath.Vec3{X: 1, Y: 2, Z: 3})
+	o.Transform.SetRot(lmath.Vec3{Z: 90})
+	o.Tint = gfx.Color{R: 0.5, G: 0.25, B: 1, A: 1}
+	var buf bytes.Buffer
+	if err := Encode(&buf, []*gfx.Object{o}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Nodes) != 1 || len(s.Nodes[0].Children) != 1 || len(s.Objects) != 1 {
+		t.Fatalf("got %d roots and %d objects, want 1 and 1", len(s.Nodes), len(s.Objects))
+	}
+	got := s.Objects[0]
+	if len(got.Textures) != 1 || !got.Textures[0].SRGB || len(s.Materials) != 1 {
+		t.Fatal("expected base color texture")
+	}
+	if d := got.Tint.R - o.Tint.R; d > 1e-3 || d < -1e-3 {
+		t.Fatalf("got tint %v, want %v", got.Tint, o.Tint)
+	}
+
+	// Each vertex must end up at the same world space position.
+	want, m := o.Meshes[0], got.Meshes[0]
+	if len(m.Vertices) != 3 || len(m.Indices) != 3 || len(m.TexCoords) != 1 {
+		t.Fatalf("got mesh %+v", m)
+	}
+	if _, ok := m.Attribs["Normal"].Data.([]gfx.Vec3); !ok {
+		t.Fatal("missing normals")
+	}
+	for i, v := range m.Vertices {
+		a := v.Vec3().TransformMat4(got.Transform.Mat4())
+		b := want.Vertices[i].Vec3().TransformMat4(o.Transform.Mat4())
+		if !a.AlmostEquals(b, 1e-4) {
+			t.Fatalf("vertex %d at %v, want %v", i, a, b)
+		}
+	}
+	for i, c := range m.Colors {
+		w := want.Colors[i]
+		if d := c.G - w.G; d > 1e-3 || d < -1e-3 {
+			t.Fatalf("color %d is %v, want %v", i, c, w)
+		}
+	}
+}
+
+const testGLTF = `{
+	"asset": {"version": "2.0"},
+	"scene": 0,
+	"scenes": [{"nodes": [0]}],
+	"nodes": [
+		{"name": "parent", "translation": [0, 1, 0], "children": [1]},
+		{"name": "child", "mesh": 0, "skin": 0, "scale": [2, 2, 2]}
+	],
+	"skins": [{"joints": [0, 1]}],
+	"meshes": [{"primitives": [{
+		"attributes": {"POSITION": 0, "JOINTS_0": 1},
+		"material": 0
+	}]}],
+	"materials": [{"alphaMode": "MASK", "doubleSided": true}],
+	"accessors": [
+		{"bufferView": 0, "componentType": 5126, "count": 1, "type": "VEC3"},
+		{"bufferView": 1, "componentType": 5121, "count": 1, "type": "VEC4"}
+	],
+	"bufferViews": [
+		{"buffer": 0, "byteOffset": 0, "byteLength": 12},
+		{"buffer": 0, "byteOffset": 12, "byteLength": 4}
+	],
+	"buffers": [{"byteLength": 16, "uri": "data:application/octet-stream;base64,AACAPwAAAAAAAAAAAAEAAA=="}]
+}`
+
+func TestDecodeJSON(t *testing.T) {
+	s, err := Decode(strings.NewReader(testGLTF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := s.Nodes[0]
+	if parent.Name != "parent" || len(parent.Children) != 1 {
+		t.Fatalf("bad hierarchy %+v", parent)
+	}
+	child := parent.Children[0]
+	if child.Parent != parent || child.Skin != s.Skins[0] || len(child.Skin.Joints) != 2 {
+		t.Fatalf("bad child %+v", child)
+	}
+	o := s.Objects[0]
+	if o.State.AlphaMode != gfx.BinaryAlpha || o.State.FaceCulling != gfx.NoFaceCulling {
+		t.Fatalf("material not applied, got state %+v", o.State)
+	}
+	m := o.Meshes[0]
+	if m.Vertices[0] != (gfx.Vec3{X: 1}) {
+		t.Fatalf("got vertex %v", m.Vertices[0])
+	}
+	if j := m.Attribs["Joints"].Data.([]gfx.Vec4); j[0] != (gfx.Vec4{Y: 1}) {
+		t.Fatalf("got joints %v", j[0])
+	}
+
+	// glTF +Y (up) is gfx +Z.
+	p := m.Vertices[0].Vec3().TransformMat4(o.Transform.Mat4())
+	if !p.AlmostEquals(lmath.Vec3{X: 2, Z: 1}, 1e-6) {
+		t.Fatalf("vertex at %v, want (2, 0, 1)", p)
+	}
+}
+
+func TestDecodeExternal(t *testing.T) {
+	js := strings.Replace(testGLTF, "data:application/octet-stream;base64,AACAPwAAAAAAAAAAAAEAAA==", "mesh.bin", 1)
+	if _, err := Decode(strings.NewReader(js)); err != ErrExternal {
+		t.Fatalf("got error %v, want ErrExternal", err)
+	}
+}
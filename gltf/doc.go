@@ -0,0 +1,24 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gltf implements encoding of graphics objects to the binary glTF 2.0
+// (.glb) format, and decoding of glTF 2.0 scenes (see Decode).
+//
+// Each graphics object becomes a single node (with it's world transformation)
+// and mesh, whose primitives are the meshes of the object. The first texture
+// of each object becomes the base color texture of it's material, which is
+// embedded as a PNG image, and the tint of the object becomes the material's
+// base color factor.
+//
+// Vertex positions, colors, the first texture coordinate set, and the
+// "Normal" and "Tangent" custom attributes (see Mesh.GenerateNormals and
+// Mesh.GenerateTangents) map to the standard glTF attributes. Other custom
+// attributes of type []float32, []gfx.Vec3, or []gfx.Vec4 are written as
+// application-specific attributes, e.g. "Wind" becomes "_WIND".
+//
+// Since gfx is Z-up and glTF is Y-up, the nodes are parented to a single root
+// node which rotates the scene such that it appears upright in other tools.
+// Shaders, render state, and animations have no glTF representation and are
+// not written.
+package gltf
@@ -0,0 +1,372 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"azul3d.org/gfx.v1"
+)
+
+// zUpToYUp is the rotation quaternion (X, Y, Z, W) of the root node, which
+// rotates by -90 degrees about the X axis such that the gfx +Z (up) axis
+// becomes the glTF +Y (up) axis.
+var zUpToYUp = [4]float64{-math.Sqrt2 / 2, 0, 0, math.Sqrt2 / 2}
+
+type encoder struct {
+	doc      document
+	bin      bytes.Buffer
+	textures map[*gfx.Texture]int
+}
+
+// Encode writes the given graphics objects to w in the binary glTF 2.0 (.glb)
+// format.
+//
+// The data of each mesh and texture must be present, i.e. they must either
+// not be loaded yet or have been loaded with KeepDataOnLoad set to true. An
+// error is returned if a mesh has no vertices, if a mesh uses primitive
+// restart (which glTF cannot represent), or if writing to w fails. Textures
+// without a source image are not written.
+//
+// This function properly read-locks the objects, their meshes, and their
+// textures.
+func Encode(w io.Writer, objs []*gfx.Object) error {
+	e := &encoder{
+		doc: document{
+			Asset: asset{Version: "2.0", Generator: "azul3d.org/gfx.v1/gltf"},
+			Nodes: []node{{Name: "root", Rotation: &zUpToYUp}},
+		},
+		textures: make(map[*gfx.Texture]int),
+	}
+	for i, o := range objs {
+		if err := e.object(o); err != nil {
+			return fmt.Errorf("gltf: object %d: %v", i, err)
+		}
+	}
+	e.doc.Scenes = []scene{{Nodes: []int{0}}}
+	if e.bin.Len() > 0 {
+		e.pad(&e.bin, 0)
+		e.doc.Buffers = []buffer{{ByteLength: e.bin.Len()}}
+	}
+
+	js, err := json.Marshal(e.doc)
+	if err != nil {
+		return err
+	}
+	jsBuf := bytes.NewBuffer(js)
+	e.pad(jsBuf, ' ')
+
+	length := glbHeaderLen + 8 + jsBuf.Len()
+	if e.bin.Len() > 0 {
+		length += 8 + e.bin.Len()
+	}
+	hdr := []uint32{glbMagic, glbVersion, uint32(length), uint32(jsBuf.Len()), chunkJSON}
+	if err := binary.Write(w, binary.LittleEndian, hdr); err != nil {
+		return err
+	}
+	if _, err := w.Write(jsBuf.Bytes()); err != nil {
+		return err
+	}
+	if e.bin.Len() == 0 {
+		return nil
+	}
+	if err := binary.Write(w, binary.LittleEndian, []uint32{uint32(e.bin.Len()), chunkBIN}); err != nil {
+		return err
+	}
+	_, err = w.Write(e.bin.Bytes())
+	return err
+}
+
+// pad pads the buffer to a multiple of four bytes using the given byte.
+func (e *encoder) pad(b *bytes.Buffer, with byte) {
+	for b.Len()%4 != 0 {
+		b.WriteByte(with)
+	}
+}
+
+// view appends the data to the binary buffer and returns the index of a new
+// buffer view referencing it.
+func (e *encoder) view(data []byte, target int) int {
+	e.pad(&e.bin, 0)
+	e.doc.BufferViews = append(e.doc.BufferViews, bufferView{
+		ByteOffset: e.bin.Len(),
+		ByteLength: len(data),
+		Target:     target,
+	})
+	e.bin.Write(data)
+	return len(e.doc.BufferViews) - 1
+}
+
+// floats appends the float data, whose elements have the given number of
+// components and glTF type (e.g. "VEC3"), and returns the index of a new
+// accessor for it. If bounds is true the min and max of each component are
+// stored in the accessor (as required for positions).
+func (e *encoder) floats(data []float32, comps int, typ string, bounds bool) int {
+	buf := make([]byte, 4*len(data))
+	for i, v := range data {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	view := e.view(buf, targetArrayBuffer)
+	a := accessor{
+		BufferView:    &view,
+		ComponentType: componentFloat,
+		Count:         len(data) / comps,
+		Type:          typ,
+	}
+	if bounds && len(data) > 0 {
+		a.Min = make([]float64, comps)
+		a.Max = make([]float64, comps)
+		for c := 0; c < comps; c++ {
+			a.Min[c], a.Max[c] = math.Inf(1), math.Inf(-1)
+			for i := c; i < len(data); i += comps {
+				a.Min[c] = math.Min(a.Min[c], float64(data[i]))
+				a.Max[c] = math.Max(a.Max[c], float64(data[i]))
+			}
+		}
+	}
+	e.doc.Accessors = append(e.doc.Accessors, a)
+	return len(e.doc.Accessors) - 1
+}
+
+// indices appends the index data and returns the index of a new accessor for
+// it.
+func (e *encoder) indices(data []uint32) int {
+	buf := make([]byte, 4*len(data))
+	for i, v := range data {
+		binary.LittleEndian.PutUint32(buf[4*i:], v)
+	}
+	view := e.view(buf, targetElementArrayBuffer)
+	e.doc.Accessors = append(e.doc.Accessors, accessor{
+		BufferView:    &view,
+		ComponentType: componentUint32,
+		Count:         len(data),
+		Type:          "SCALAR",
+	})
+	return len(e.doc.Accessors) - 1
+}
+
+func (e *encoder) object(o *gfx.Object) error {
+	o.RLock()
+	defer o.RUnlock()
+
+	// Write the material.
+	tint := o.Tint.Linear()
+	var metallic float32
+	mat := material{
+		PBR: pbr{
+			BaseColorFactor: &[4]float32{tint.R, tint.G, tint.B, tint.A},
+			MetallicFactor:  &metallic,
+		},
+	}
+	if o.State.FaceCulling == gfx.NoFaceCulling {
+		mat.DoubleSided = true
+	}
+	if len(o.Textures) > 0 {
+		idx, ok, err := e.texture(o.Textures[0])
+		if err != nil {
+			return err
+		}
+		if ok {
+			mat.PBR.BaseColorTexture = &textureInfo{Index: idx}
+		}
+	}
+	e.doc.Materials = append(e.doc.Materials, mat)
+	matIndex := len(e.doc.Materials) - 1
+
+	// Write each mesh as a primitive.
+	var msh mesh
+	for i, m := range o.Meshes {
+		p, err := e.primitive(m)
+		if err != nil {
+			return fmt.Errorf("mesh %d: %v", i, err)
+		}
+		p.Material = &matIndex
+		msh.Primitives = append(msh.Primitives, p)
+	}
+	meshIndex := len(e.doc.Meshes)
+	e.doc.Meshes = append(e.doc.Meshes, msh)
+
+	// Write the node, row-major matrices operating on row vectors have the
+	// same memory layout as the column-major matrices operating on column
+	// vectors used by glTF.
+	n := node{Mesh: &meshIndex}
+	if o.Transform != nil {
+		m := o.Transform.Mat4()
+		n.Matrix = make([]float64, 0, 16)
+		for _, row := range m {
+			n.Matrix = append(n.Matrix, row[:]...)
+		}
+	}
+	e.doc.Nodes = append(e.doc.Nodes, n)
+	root := &e.doc.Nodes[0]
+	root.Children = append(root.Children, len(e.doc.Nodes)-1)
+	return nil
+}
+
+// primitiveModes maps gfx primitives to glTF primitive modes.
+var primitiveModes = map[gfx.Primitive]int{
+	gfx.Points:        modePoints,
+	gfx.Lines:         modeLines,
+	gfx.LineLoop:      modeLineLoop,
+	gfx.LineStrip:     modeLineStrip,
+	gfx.Triangles:     modeTriangles,
+	gfx.TriangleStrip: modeTriangleStrip,
+	gfx.TriangleFan:   modeTriangleFan,
+}
+
+func (e *encoder) primitive(m *gfx.Mesh) (primitive, error) {
+	m.RLock()
+	defer m.RUnlock()
+	if len(m.Vertices) == 0 {
+		return primitive{}, fmt.Errorf("no vertex data (see Mesh.KeepDataOnLoad)")
+	}
+	if m.PrimitiveRestart {
+		return primitive{}, fmt.Errorf("primitive restart is not supported")
+	}
+	mode := primitiveModes[m.Primitive]
+	p := primitive{
+		Attributes: make(map[string]int),
+		Mode:       &mode,
+	}
+
+	p.Attributes["POSITION"] = e.floats(vec3s(m.Vertices), 3, "VEC3", true)
+	if len(m.Colors) == len(m.Vertices) {
+		// Colors are sRGB-encoded, but glTF vertex colors are linear.
+		data := make([]float32, 0, 4*len(m.Colors))
+		for _, c := range m.Colors {
+			c = c.Linear()
+			data = append(data, c.R, c.G, c.B, c.A)
+		}
+		p.Attributes["COLOR_0"] = e.floats(data, 4, "VEC4", false)
+	}
+	if len(m.TexCoords) > 0 && len(m.TexCoords[0].Slice) == len(m.Vertices) {
+		data := make([]float32, 0, 2*len(m.Vertices))
+		for _, tc := range m.TexCoords[0].Slice {
+			data = append(data, tc.U, tc.V)
+		}
+		p.Attributes["TEXCOORD_0"] = e.floats(data, 2, "VEC2", false)
+	}
+
+	// Write custom attributes in a deterministic order.
+	names := make([]string, 0, len(m.Attribs))
+	for name := range m.Attribs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := "_" + strings.ToUpper(name)
+		switch name {
+		case "Normal":
+			key = "NORMAL"
+		case "Tangent":
+			key = "TANGENT"
+		}
+		switch d := m.Attribs[name].Data.(type) {
+		case []float32:
+			if len(d) == len(m.Vertices) {
+				p.Attributes[key] = e.floats(d, 1, "SCALAR", false)
+			}
+		case []gfx.Vec3:
+			if len(d) == len(m.Vertices) {
+				p.Attributes[key] = e.floats(vec3s(d), 3, "VEC3", false)
+			}
+		case []gfx.Vec4:
+			if len(d) == len(m.Vertices) {
+				data := make([]float32, 0, 4*len(d))
+				for _, v := range d {
+					data = append(data, v.X, v.Y, v.Z, v.W)
+				}
+				p.Attributes[key] = e.floats(data, 4, "VEC4", false)
+			}
+		}
+	}
+
+	if len(m.Indices) > 0 {
+		idx := e.indices(m.Indices)
+		p.Indices = &idx
+	}
+	return p, nil
+}
+
+// texture writes the texture (if it has not already been written) and returns
+// it's index. If the texture has no source image ok is false.
+func (e *encoder) texture(t *gfx.Texture) (index int, ok bool, err error) {
+	if index, ok = e.textures[t]; ok {
+		return index, true, nil
+	}
+	t.RLock()
+	defer t.RUnlock()
+	if t.Source == nil {
+		return 0, false, nil
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, t.Source); err != nil {
+		return 0, false, err
+	}
+	view := e.view(buf.Bytes(), 0)
+	e.doc.Images = append(e.doc.Images, image{
+		BufferView: &view,
+		MimeType:   "image/png",
+	})
+	e.doc.Samplers = append(e.doc.Samplers, sampler{
+		MagFilter: filter(t.MagFilter),
+		MinFilter: filter(t.MinFilter),
+		WrapS:     wrap(t.WrapU),
+		WrapT:     wrap(t.WrapV),
+	})
+	samplerIndex, sourceIndex := len(e.doc.Samplers)-1, len(e.doc.Images)-1
+	e.doc.Textures = append(e.doc.Textures, texture{
+		Sampler: &samplerIndex,
+		Source:  &sourceIndex,
+	})
+	index = len(e.doc.Textures) - 1
+	e.textures[t] = index
+	return index, true, nil
+}
+
+func vec3s(v []gfx.Vec3) []float32 {
+	data := make([]float32, 0, 3*len(v))
+	for _, p := range v {
+		data = append(data, p.X, p.Y, p.Z)
+	}
+	return data
+}
+
+func filter(f gfx.TexFilter) int {
+	switch f {
+	case gfx.Nearest:
+		return filterNearest
+	case gfx.Linear:
+		return filterLinear
+	case gfx.NearestMipmapNearest:
+		return filterNearestMipmapNearest
+	case gfx.LinearMipmapNearest:
+		return filterLinearMipmapNearest
+	case gfx.NearestMipmapLinear:
+		return filterNearestMipmapLinear
+	case gfx.LinearMipmapLinear:
+		return filterLinearMipmapLinear
+	}
+	return 0
+}
+
+func wrap(w gfx.TexWrap) int {
+	switch w {
+	case gfx.Clamp, gfx.BorderColor:
+		// glTF has no border color wrap mode.
+		return wrapClampToEdge
+	case gfx.Mirror:
+		return wrapMirroredRepeat
+	}
+	return wrapRepeat
+}
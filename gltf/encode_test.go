@@ -0,0 +1,87 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	goimage "image"
+	"testing"
+
+	"azul3d.org/gfx.v1"
+)
+
+func testObject() *gfx.Object {
+	m := gfx.NewMesh()
+	m.Vertices = []gfx.Vec3{{X: -1, Z: -1}, {X: 1, Z: -1}, {Z: 1}}
+	m.Colors = []gfx.Color{{R: 1, A: 1}, {G: 1, A: 1}, {B: 1, A: 1}}
+	m.TexCoords = []gfx.TexCoordSet{{Slice: []gfx.TexCoord{{V: 1}, {U: 1, V: 1}, {U: 0.5}}}}
+	m.Indices = []uint32{0, 1, 2}
+	m.GenerateNormals(180)
+
+	tex := gfx.NewTexture()
+	tex.Source = goimage.NewRGBA(goimage.Rect(0, 0, 4, 4))
+
+	o := gfx.NewObject()
+	o.Meshes = []*gfx.Mesh{m}
+	o.Textures = []*gfx.Texture{tex, tex}
+	return o
+}
+
+func TestEncode(t *testing.T) {
+	var buf bytes.Buffer
+	objs := []*gfx.Object{testObject(), testObject()}
+	if err := Encode(&buf, objs); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	var hdr [5]uint32
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &hdr); err != nil {
+		t.Fatal(err)
+	}
+	if hdr[0] != glbMagic || hdr[1] != glbVersion || int(hdr[2]) != len(data) || hdr[4] != chunkJSON {
+		t.Fatalf("bad header %x", hdr)
+	}
+	jsLen := int(hdr[3])
+	var doc document
+	if err := json.Unmarshal(data[20:20+jsLen], &doc); err != nil {
+		t.Fatal(err)
+	}
+	binLen := binary.LittleEndian.Uint32(data[20+jsLen:])
+	if int(binLen) != doc.Buffers[0].ByteLength || 20+jsLen+8+int(binLen) != len(data) {
+		t.Fatalf("binary chunk length %d mismatch", binLen)
+	}
+
+	if len(doc.Nodes) != 3 || len(doc.Nodes[0].Children) != 2 {
+		t.Fatalf("got %d nodes, want root with 2 children", len(doc.Nodes))
+	}
+	if len(doc.Meshes) != 2 || len(doc.Materials) != 2 || len(doc.Textures) != 2 {
+		t.Fatalf("got %d meshes %d materials %d textures, want 2 each",
+			len(doc.Meshes), len(doc.Materials), len(doc.Textures))
+	}
+	p := doc.Meshes[0].Primitives[0]
+	for _, attr := range []string{"POSITION", "COLOR_0", "TEXCOORD_0", "NORMAL"} {
+		if _, ok := p.Attributes[attr]; !ok {
+			t.Fatalf("missing attribute %s", attr)
+		}
+	}
+	pos := doc.Accessors[p.Attributes["POSITION"]]
+	if pos.Count != 3 || pos.Min[0] != -1 || pos.Max[2] != 1 {
+		t.Fatalf("bad position accessor %+v", pos)
+	}
+	if p.Indices == nil || doc.Accessors[*p.Indices].Count != 3 || *p.Mode != modeTriangles {
+		t.Fatalf("bad indices or mode %+v", p)
+	}
+}
+
+func TestEncodeNoData(t *testing.T) {
+	o := gfx.NewObject()
+	o.Meshes = []*gfx.Mesh{gfx.NewMesh()}
+	if err := Encode(new(bytes.Buffer), []*gfx.Object{o}); err == nil {
+		t.Fatal("expected error encoding a mesh without vertices")
+	}
+}
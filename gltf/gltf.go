@@ -0,0 +1,182 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import "encoding/json"
+
+// The binary glTF container constants.
+const (
+	glbMagic     = 0x46546C67 // "glTF"
+	glbVersion   = 2
+	chunkJSON    = 0x4E4F534A // "JSON"
+	chunkBIN     = 0x004E4942 // "BIN\x00"
+	glbHeaderLen = 12
+)
+
+// The glTF accessor component types.
+const (
+	componentInt8   = 5120
+	componentUint8  = 5121
+	componentInt16  = 5122
+	componentUint16 = 5123
+	componentUint32 = 5125
+	componentFloat  = 5126
+)
+
+// The glTF buffer view targets.
+const (
+	targetArrayBuffer        = 34962
+	targetElementArrayBuffer = 34963
+)
+
+// The glTF sampler filter and wrap modes (which are their OpenGL enums).
+const (
+	filterNearest              = 9728
+	filterLinear               = 9729
+	filterNearestMipmapNearest = 9984
+	filterLinearMipmapNearest  = 9985
+	filterNearestMipmapLinear  = 9986
+	filterLinearMipmapLinear   = 9987
+
+	wrapRepeat         = 10497
+	wrapClampToEdge    = 33071
+	wrapMirroredRepeat = 33648
+)
+
+// The glTF primitive modes.
+const (
+	modePoints = iota
+	modeLines
+	modeLineLoop
+	modeLineStrip
+	modeTriangles
+	modeTriangleStrip
+	modeTriangleFan
+)
+
+// The following types mirror the glTF 2.0 JSON schema, only the properties
+// used by this package are present.
+
+type document struct {
+	Asset       asset        `json:"asset"`
+	Scene       int          `json:"scene"`
+	Scenes      []scene      `json:"scenes"`
+	Nodes       []node       `json:"nodes"`
+	Meshes      []mesh       `json:"meshes,omitempty"`
+	Materials   []material   `json:"materials,omitempty"`
+	Textures    []texture    `json:"textures,omitempty"`
+	Images      []image      `json:"images,omitempty"`
+	Samplers    []sampler    `json:"samplers,omitempty"`
+	Skins       []skin       `json:"skins,omitempty"`
+	Accessors   []accessor   `json:"accessors,omitempty"`
+	BufferViews []bufferView `json:"bufferViews,omitempty"`
+	Buffers     []buffer     `json:"buffers,omitempty"`
+}
+
+type asset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type scene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type node struct {
+	Name        string      `json:"name,omitempty"`
+	Mesh        *int        `json:"mesh,omitempty"`
+	Skin        *int        `json:"skin,omitempty"`
+	Children    []int       `json:"children,omitempty"`
+	Matrix      []float64   `json:"matrix,omitempty"`
+	Translation *[3]float64 `json:"translation,omitempty"`
+	Rotation    *[4]float64 `json:"rotation,omitempty"`
+	Scale       *[3]float64 `json:"scale,omitempty"`
+}
+
+type skin struct {
+	Name                string `json:"name,omitempty"`
+	InverseBindMatrices *int   `json:"inverseBindMatrices,omitempty"`
+	Joints              []int  `json:"joints"`
+}
+
+type mesh struct {
+	Name       string      `json:"name,omitempty"`
+	Primitives []primitive `json:"primitives"`
+}
+
+type primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices,omitempty"`
+	Material   *int           `json:"material,omitempty"`
+	Mode       *int           `json:"mode,omitempty"`
+}
+
+type material struct {
+	Name             string       `json:"name,omitempty"`
+	PBR              pbr          `json:"pbrMetallicRoughness"`
+	NormalTexture    *textureInfo `json:"normalTexture,omitempty"`
+	OcclusionTexture *textureInfo `json:"occlusionTexture,omitempty"`
+	EmissiveTexture  *textureInfo `json:"emissiveTexture,omitempty"`
+	EmissiveFactor   [3]float32   `json:"emissiveFactor,omitempty"`
+	AlphaMode        string       `json:"alphaMode,omitempty"`
+	AlphaCutoff      *float32     `json:"alphaCutoff,omitempty"`
+	DoubleSided      bool         `json:"doubleSided,omitempty"`
+}
+
+type pbr struct {
+	BaseColorFactor          *[4]float32  `json:"baseColorFactor,omitempty"`
+	BaseColorTexture         *textureInfo `json:"baseColorTexture,omitempty"`
+	MetallicFactor           *float32     `json:"metallicFactor,omitempty"`
+	RoughnessFactor          *float32     `json:"roughnessFactor,omitempty"`
+	MetallicRoughnessTexture *textureInfo `json:"metallicRoughnessTexture,omitempty"`
+}
+
+type textureInfo struct {
+	Index    int `json:"index"`
+	TexCoord int `json:"texCoord,omitempty"`
+}
+
+type texture struct {
+	Sampler *int `json:"sampler,omitempty"`
+	Source  *int `json:"source,omitempty"`
+}
+
+type image struct {
+	URI        string `json:"uri,omitempty"`
+	BufferView *int   `json:"bufferView,omitempty"`
+	MimeType   string `json:"mimeType,omitempty"`
+}
+
+type sampler struct {
+	MagFilter int `json:"magFilter,omitempty"`
+	MinFilter int `json:"minFilter,omitempty"`
+	WrapS     int `json:"wrapS"`
+	WrapT     int `json:"wrapT"`
+}
+
+type accessor struct {
+	BufferView    *int             `json:"bufferView,omitempty"`
+	ByteOffset    int              `json:"byteOffset,omitempty"`
+	ComponentType int              `json:"componentType"`
+	Normalized    bool             `json:"normalized,omitempty"`
+	Count         int              `json:"count"`
+	Type          string           `json:"type"`
+	Min           []float64        `json:"min,omitempty"`
+	Max           []float64        `json:"max,omitempty"`
+	Sparse        *json.RawMessage `json:"sparse,omitempty"`
+}
+
+type bufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride,omitempty"`
+	Target     int `json:"target,omitempty"`
+}
+
+type buffer struct {
+	URI        string `json:"uri,omitempty"`
+	ByteLength int    `json:"byteLength"`
+}
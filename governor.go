@@ -0,0 +1,99 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"math"
+)
+
+// Governor is a frame-rate governor preset, which coherently chooses the
+// vertical sync mode, frame rate cap, and event polling strategy of an
+// application from a single setting (see the Settings method).
+type Governor uint8
+
+// String returns a string representation of this governor.
+// e.g. MatchRefresh -> "MatchRefresh"
+func (g Governor) String() string {
+	switch g {
+	case MatchRefresh:
+		return "MatchRefresh"
+	case HalfRefresh:
+		return "HalfRefresh"
+	case Uncapped:
+		return "Uncapped"
+	case BatterySaver:
+		return "BatterySaver"
+	}
+	return fmt.Sprintf("Governor(%d)", g)
+}
+
+const (
+	// MatchRefresh renders one frame per refresh of the monitor, using
+	// vertical sync.
+	MatchRefresh Governor = iota
+
+	// HalfRefresh renders one frame every other refresh of the monitor (e.g.
+	// 30 FPS on a 60hz monitor), using vertical sync.
+	HalfRefresh
+
+	// Uncapped renders frames as fast as possible, without vertical sync.
+	Uncapped
+
+	// BatterySaver renders at most 30 frames per second, using vertical sync,
+	// and blocks waiting for events rather than polling for them.
+	BatterySaver
+)
+
+// GovernorSettings are the settings chosen by a governor preset.
+type GovernorSettings struct {
+	// The number of monitor refreshes per frame when presenting, i.e. 0 for
+	// no vertical sync, 1 for vertical sync, 2 for every other refresh, etc.
+	SwapInterval int
+
+	// The maximum frame rate (see clock.Clock.SetMaxFrameRate), or zero for
+	// no limit other than the swap interval.
+	MaxFrameRate float64
+
+	// Whether or not the application should block waiting for events
+	// instead of polling for them each frame.
+	WaitEvents bool
+}
+
+// Settings returns the settings of this governor for a monitor with the
+// given refresh rate in hertz (see SwapChainInfo.RefreshRate). If the refresh
+// rate is not known (i.e. zero) then 60hz is assumed.
+func (g Governor) Settings(refreshRate float64) GovernorSettings {
+	if refreshRate <= 0 {
+		refreshRate = 60
+	}
+	switch g {
+	case HalfRefresh:
+		return GovernorSettings{
+			SwapInterval: 2,
+			MaxFrameRate: refreshRate / 2,
+		}
+	case Uncapped:
+		return GovernorSettings{}
+	case BatterySaver:
+		interval := int(math.Max(1, math.Floor(refreshRate/30+0.5)))
+		return GovernorSettings{
+			SwapInterval: interval,
+			MaxFrameRate: math.Min(30, refreshRate),
+			WaitEvents:   true,
+		}
+	}
+	return GovernorSettings{SwapInterval: 1}
+}
+
+// ApplyGovernor applies the frame rate cap of the given governor to the clock
+// of the renderer, using the refresh rate of it's swap chain, and returns the
+// settings such that the remaining ones (the swap interval and event polling
+// strategy) may be applied to the window.
+func ApplyGovernor(r Renderer, g Governor) GovernorSettings {
+	s := g.Settings(r.SwapChain().RefreshRate)
+	r.Clock().SetMaxFrameRate(s.MaxFrameRate)
+	return s
+}
@@ -0,0 +1,33 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestGovernorSettings(t *testing.T) {
+	tests := []struct {
+		g       Governor
+		refresh float64
+		want    GovernorSettings
+	}{
+		{MatchRefresh, 144, GovernorSettings{SwapInterval: 1}},
+		{HalfRefresh, 0, GovernorSettings{SwapInterval: 2, MaxFrameRate: 30}},
+		{Uncapped, 60, GovernorSettings{}},
+		{BatterySaver, 60, GovernorSettings{SwapInterval: 2, MaxFrameRate: 30, WaitEvents: true}},
+		{BatterySaver, 120, GovernorSettings{SwapInterval: 4, MaxFrameRate: 30, WaitEvents: true}},
+		{BatterySaver, 24, GovernorSettings{SwapInterval: 1, MaxFrameRate: 24, WaitEvents: true}},
+	}
+	for _, tst := range tests {
+		if got := tst.g.Settings(tst.refresh); got != tst.want {
+			t.Errorf("%v at %vhz: got %+v, want %+v", tst.g, tst.refresh, got, tst.want)
+		}
+	}
+
+	r := Nil()
+	ApplyGovernor(r, BatterySaver)
+	if max := r.Clock().MaxFrameRate(); max != 30 {
+		t.Errorf("clock max frame rate = %v, want 30", max)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"sync"
+)
+
+// HookStage specifies the stage of a frame at which a frame hook is run, see
+// the FrameHooks type.
+type HookStage uint8
+
+// String returns a string representation of this hook stage.
+// e.g. PrePresent -> "PrePresent"
+func (s HookStage) String() string {
+	switch s {
+	case PrePresent:
+		return "PrePresent"
+	case PostPresent:
+		return "PostPresent"
+	}
+	return fmt.Sprintf("HookStage(%d)", s)
+}
+
+const (
+	// PrePresent hooks are run after every operation of the frame has been
+	// executed, immediately before the frame is presented (i.e. before the
+	// buffers are swapped). This is where external overlays (e.g. the Steam
+	// or Discord overlays) draw on top of the frame.
+	PrePresent HookStage = iota
+
+	// PostPresent hooks are run immediately after the frame is presented,
+	// e.g. for profilers measuring the time spent presenting.
+	PostPresent
+)
+
+// FrameHooks is a set of functions run by a renderer at specific stages of
+// every frame, such that external overlay SDKs and custom profilers can
+// interpose on the render loop. Hooks are run on the renderer's graphics
+// thread, with it's graphics context active, so they may make native graphics
+// API calls (provided they restore any state they change).
+//
+// It is safe to use from multiple goroutines concurrently.
+type FrameHooks struct {
+	access sync.Mutex
+	hooks  map[HookStage][]*func(c Canvas)
+}
+
+// Add adds a hook function to be run at the given stage of every frame, with
+// the canvas being presented. Hooks of the same stage are run in the order
+// they were added. The returned function removes the hook when called.
+func (h *FrameHooks) Add(stage HookStage, fn func(c Canvas)) (remove func()) {
+	p := &fn
+	h.access.Lock()
+	if h.hooks == nil {
+		h.hooks = make(map[HookStage][]*func(c Canvas))
+	}
+	h.hooks[stage] = append(h.hooks[stage], p)
+	h.access.Unlock()
+	return func() {
+		h.access.Lock()
+		hooks := h.hooks[stage]
+		for i, other := range hooks {
+			if other == p {
+				h.hooks[stage] = append(hooks[:i:i], hooks[i+1:]...)
+				break
+			}
+		}
+		h.access.Unlock()
+	}
+}
+
+// Run runs each hook function of the given stage with the given canvas. It is
+// called by renderers, hooks added or removed while running take effect the
+// next time Run is called.
+func (h *FrameHooks) Run(stage HookStage, c Canvas) {
+	h.access.Lock()
+	hooks := h.hooks[stage]
+	h.access.Unlock()
+	for _, fn := range hooks {
+		(*fn)(c)
+	}
+}
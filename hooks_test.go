@@ -0,0 +1,29 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFrameHooks(t *testing.T) {
+	r := Nil()
+	var calls []string
+	removePre := r.Hooks().Add(PrePresent, func(c Canvas) {
+		calls = append(calls, "pre")
+	})
+	r.Hooks().Add(PostPresent, func(c Canvas) {
+		calls = append(calls, "post")
+	})
+	r.Render()
+	removePre()
+	r.Render()
+
+	want := []string{"pre", "post", "post"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("got calls %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"image/color"
+)
+
+// The functions in this file convert between image formats by operating
+// directly on pixel slices, row by row, instead of calling At and converting
+// colors one pixel at a time through interfaces (as image/draw does for most
+// image types). The inner loops are simple enough that the compiler can
+// eliminate bounds checks from them.
+
+// ToRGBA converts the given image into a premultiplied 8-bit RGBA image,
+// which is the layout that renderers upload to the graphics hardware. The
+// bounds of the returned image always start at (0, 0).
+//
+// Fast paths exist for *image.RGBA, *image.NRGBA, *image.RGBA64,
+// *image.NRGBA64, and *image.Gray images; others are converted one pixel at a
+// time.
+//
+// If the image is already an *image.RGBA with bounds starting at (0, 0) then
+// it is returned as-is (without copying).
+func ToRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	switch src := img.(type) {
+	case *image.RGBA:
+		if b.Min == (image.Point{}) {
+			return src
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		for y := 0; y < b.Dy(); y++ {
+			copy(dst.Pix[y*dst.Stride:y*dst.Stride+4*b.Dx()], src.Pix[src.PixOffset(b.Min.X, b.Min.Y+y):])
+		}
+		return dst
+
+	case *image.NRGBA:
+		return Premultiply(src)
+
+	case *image.RGBA64:
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		for y := 0; y < b.Dy(); y++ {
+			i := src.PixOffset(b.Min.X, b.Min.Y+y)
+			Convert16To8(dst.Pix[y*dst.Stride:y*dst.Stride+4*b.Dx()], src.Pix[i:i+8*b.Dx()])
+		}
+		return dst
+
+	case *image.NRGBA64:
+		n := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		for y := 0; y < b.Dy(); y++ {
+			i := src.PixOffset(b.Min.X, b.Min.Y+y)
+			Convert16To8(n.Pix[y*n.Stride:y*n.Stride+4*b.Dx()], src.Pix[i:i+8*b.Dx()])
+		}
+		PremultiplyPix(n.Pix)
+		return (*image.RGBA)(n)
+
+	case *image.Gray:
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		for y := 0; y < b.Dy(); y++ {
+			s := src.Pix[src.PixOffset(b.Min.X, b.Min.Y+y):]
+			s = s[:b.Dx()]
+			d := dst.Pix[y*dst.Stride : y*dst.Stride+4*len(s)]
+			for x, v := range s {
+				d[4*x+0] = v
+				d[4*x+1] = v
+				d[4*x+2] = v
+				d[4*x+3] = 0xff
+			}
+		}
+		return dst
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			c := color.RGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.RGBA)
+			i := y*dst.Stride + 4*x
+			dst.Pix[i+0] = c.R
+			dst.Pix[i+1] = c.G
+			dst.Pix[i+2] = c.B
+			dst.Pix[i+3] = c.A
+		}
+	}
+	return dst
+}
+
+// Premultiply returns a premultiplied alpha copy of the given non-premultiplied
+// image. The bounds of the returned image always start at (0, 0).
+func Premultiply(src *image.NRGBA) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		d := dst.Pix[y*dst.Stride : y*dst.Stride+4*b.Dx()]
+		copy(d, src.Pix[src.PixOffset(b.Min.X, b.Min.Y+y):])
+		PremultiplyPix(d)
+	}
+	return dst
+}
+
+// Unpremultiply returns a non-premultiplied alpha copy of the given
+// premultiplied image. The bounds of the returned image always start at
+// (0, 0).
+func Unpremultiply(src *image.RGBA) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		d := dst.Pix[y*dst.Stride : y*dst.Stride+4*b.Dx()]
+		copy(d, src.Pix[src.PixOffset(b.Min.X, b.Min.Y+y):])
+		UnpremultiplyPix(d)
+	}
+	return dst
+}
+
+// PremultiplyPix premultiplies the given RGBA pixel data (four bytes per
+// pixel) in-place, i.e. multiplies the color components by alpha.
+func PremultiplyPix(pix []uint8) {
+	for i := 0; i+3 < len(pix); i += 4 {
+		p := pix[i : i+4 : i+4]
+		a := uint32(p[3])
+		if a == 0xff {
+			continue
+		}
+		// Same arithmetic as the image/color package, such that results are
+		// identical to those of image/draw.
+		a |= a << 8
+		p[0] = uint8((uint32(p[0]) * 0x101 * a / 0xffff) >> 8)
+		p[1] = uint8((uint32(p[1]) * 0x101 * a / 0xffff) >> 8)
+		p[2] = uint8((uint32(p[2]) * 0x101 * a / 0xffff) >> 8)
+	}
+}
+
+// UnpremultiplyPix un-premultiplies the given RGBA pixel data (four bytes per
+// pixel) in-place, i.e. divides the color components by alpha. Fully
+// transparent pixels become transparent black.
+func UnpremultiplyPix(pix []uint8) {
+	for i := 0; i+3 < len(pix); i += 4 {
+		p := pix[i : i+4 : i+4]
+		a := uint32(p[3])
+		switch a {
+		case 0xff:
+			continue
+		case 0:
+			p[0], p[1], p[2] = 0, 0, 0
+			continue
+		}
+		a |= a << 8
+		p[0] = uint8((uint32(p[0]) * 0x101 * 0xffff / a) >> 8)
+		p[1] = uint8((uint32(p[1]) * 0x101 * 0xffff / a) >> 8)
+		p[2] = uint8((uint32(p[2]) * 0x101 * 0xffff / a) >> 8)
+	}
+}
+
+// FlipRows flips the rows of the given pixel data in-place, i.e. turns it
+// upside-down. This converts between the top-left origin of Go images and the
+// bottom-left origin of OpenGL textures. The stride is the number of bytes
+// between the start of each row.
+func FlipRows(pix []uint8, stride, rows int) {
+	tmp := make([]uint8, stride)
+	for top, bottom := 0, rows-1; top < bottom; top, bottom = top+1, bottom-1 {
+		t := pix[top*stride : top*stride+stride]
+		b := pix[bottom*stride : bottom*stride+stride]
+		copy(tmp, t)
+		copy(t, b)
+		copy(b, tmp)
+	}
+}
+
+// Swizzle reorders the four channels of each pixel of the given pixel data
+// (four bytes per pixel) in-place, such that channel i of each resulting pixel
+// is channel order[i] of the source pixel. For example to convert RGBA to
+// BGRA:
+//  gfx.Swizzle(pix, [4]int{2, 1, 0, 3})
+func Swizzle(pix []uint8, order [4]int) {
+	for i := 0; i+3 < len(pix); i += 4 {
+		p := pix[i : i+4 : i+4]
+		s := [4]uint8{p[0], p[1], p[2], p[3]}
+		p[0] = s[order[0]&3]
+		p[1] = s[order[1]&3]
+		p[2] = s[order[2]&3]
+		p[3] = s[order[3]&3]
+	}
+}
+
+// Convert16To8 converts the given 16-bit big-endian component data (as used
+// by *image.RGBA64 and *image.Gray16, for example) into 8-bit components by
+// keeping the most significant byte of each. The dst slice must be at least
+// half the length of src.
+func Convert16To8(dst, src []uint8) {
+	dst = dst[:len(src)/2]
+	for i := range dst {
+		dst[i] = src[2*i]
+	}
+}
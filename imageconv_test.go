@@ -0,0 +1,87 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func testImageNRGBA() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7)
+	}
+	return img
+}
+
+func TestToRGBAMatchesDraw(t *testing.T) {
+	srcs := []image.Image{
+		testImageNRGBA(),
+		testImageNRGBA().SubImage(image.Rect(3, 4, 11, 9)),
+		image.NewGray(image.Rect(0, 0, 5, 3)),
+		image.NewRGBA64(image.Rect(2, 2, 7, 9)),
+		image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.RGBA{10, 20, 30, 40}}),
+	}
+	for i, src := range srcs {
+		b := src.Bounds()
+		want := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(want, want.Bounds(), src, b.Min, draw.Src)
+
+		got := ToRGBA(src)
+		if got.Bounds() != want.Bounds() {
+			t.Fatalf("%d: bounds %v, want %v", i, got.Bounds(), want.Bounds())
+		}
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Fatalf("%d: %T pixels differ from image/draw", i, src)
+		}
+	}
+}
+
+func TestPremultiplyRoundTrip(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	copy(src.Pix, []uint8{200, 100, 50, 255, 200, 100, 50, 0})
+	got := Unpremultiply(Premultiply(src))
+	want := []uint8{200, 100, 50, 255, 0, 0, 0, 0}
+	if !bytes.Equal(got.Pix, want) {
+		t.Fatalf("got %v, want %v", got.Pix, want)
+	}
+}
+
+func TestFlipRows(t *testing.T) {
+	pix := []uint8{1, 1, 2, 2, 3, 3}
+	FlipRows(pix, 2, 3)
+	if want := []uint8{3, 3, 2, 2, 1, 1}; !bytes.Equal(pix, want) {
+		t.Fatalf("got %v, want %v", pix, want)
+	}
+}
+
+func TestSwizzle(t *testing.T) {
+	pix := []uint8{1, 2, 3, 4}
+	Swizzle(pix, [4]int{2, 1, 0, 3})
+	if want := []uint8{3, 2, 1, 4}; !bytes.Equal(pix, want) {
+		t.Fatalf("got %v, want %v", pix, want)
+	}
+}
+
+func BenchmarkToRGBA(b *testing.B) {
+	src := image.NewNRGBA(image.Rect(0, 0, 512, 512))
+	b.SetBytes(int64(len(src.Pix)))
+	for i := 0; i < b.N; i++ {
+		ToRGBA(src)
+	}
+}
+
+func BenchmarkDrawRGBA(b *testing.B) {
+	src := image.NewNRGBA(image.Rect(0, 0, 512, 512))
+	b.SetBytes(int64(len(src.Pix)))
+	for i := 0; i < b.N; i++ {
+		dst := image.NewRGBA(src.Bounds())
+		draw.Draw(dst, dst.Bounds(), src, image.ZP, draw.Src)
+	}
+}
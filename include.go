@@ -0,0 +1,264 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// IncludeFunc returns the source of the named GLSL include file, see the
+// Shader.Include field. Names are slash-separated paths, relative to the root
+// of the include files (i.e. includes within included files are resolved
+// relative to the directory of the including file).
+type IncludeFunc func(name string) ([]byte, error)
+
+// IncludeMap returns an include function which resolves includes from the
+// given map of file names to sources, i.e. a virtual filesystem.
+func IncludeMap(files map[string]string) IncludeFunc {
+	return func(name string) ([]byte, error) {
+		src, ok := files[name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(src), nil
+	}
+}
+
+// IncludeDir returns an include function which resolves includes from files
+// within the given directory on disk.
+func IncludeDir(dir string) IncludeFunc {
+	return func(name string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	}
+}
+
+type sourceLine struct {
+	file, line int
+}
+
+// SourceMap maps each line of a preprocessed GLSL source back to the file and
+// line it originated from, see the Shader.Preprocess method.
+type SourceMap struct {
+	// The names of the files, the first being the shader source itself.
+	Files []string
+
+	lines []sourceLine
+}
+
+// Lookup returns the file name and line number that the given line number of
+// the preprocessed source originated from. Line numbers begin at one. If the
+// line is out of range, ok is false.
+func (m *SourceMap) Lookup(line int) (file string, fileLine int, ok bool) {
+	if m == nil || line < 1 || line > len(m.lines) {
+		return "", 0, false
+	}
+	l := m.lines[line-1]
+	return m.Files[l.file], l.line, true
+}
+
+// logLocation matches the locations of lines in the compiler logs of common
+// GLSL compilers, e.g. "0(12)" (NVIDIA) or "0:12" (Mesa and AMD).
+var logLocation = regexp.MustCompile(`\b0(?:\((\d+)\)|:(\d+))`)
+
+// Remap rewrites the line locations of a GLSL compiler's error log for the
+// preprocessed source (e.g. "0(12)" or "0:12") into the file names and line
+// numbers they originated from (e.g. "lighting.glsl:3"). If m is nil the log
+// is returned unchanged.
+func (m *SourceMap) Remap(log []byte) []byte {
+	if m == nil {
+		return log
+	}
+	return logLocation.ReplaceAllFunc(log, func(loc []byte) []byte {
+		sub := logLocation.FindSubmatch(loc)
+		num := sub[1]
+		if num == nil {
+			num = sub[2]
+		}
+		line, _ := strconv.Atoi(string(num))
+		file, fileLine, ok := m.Lookup(line)
+		if !ok {
+			return loc
+		}
+		return []byte(fmt.Sprintf("%s:%d", file, fileLine))
+	})
+}
+
+// includeDirective matches a GLSL #include directive line.
+var includeDirective = regexp.MustCompile(`^\s*#\s*include\s+["<]([^">]+)[">]\s*$`)
+
+type preprocessor struct {
+	include  IncludeFunc
+	out      bytes.Buffer
+	m        *SourceMap
+	included map[string]bool
+}
+
+func (p *preprocessor) file(name string, file int, src []byte) error {
+	lines := bytes.Split(src, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		match := includeDirective.FindSubmatch(bytes.TrimRight(line, "\r"))
+		if match == nil {
+			p.out.Write(line)
+			p.out.WriteByte('\n')
+			p.m.lines = append(p.m.lines, sourceLine{file, i + 1})
+			continue
+		}
+		inc := path.Join(path.Dir(name), string(match[1]))
+		if file == 0 {
+			inc = path.Clean(string(match[1]))
+		}
+		if p.included[inc] {
+			// Each file is included only once.
+			continue
+		}
+		if p.include == nil {
+			return fmt.Errorf("%s:%d: #include %q without an include function", p.m.Files[file], i+1, match[1])
+		}
+		incSrc, err := p.include(inc)
+		if err != nil {
+			return fmt.Errorf("%s:%d: #include %q: %v", p.m.Files[file], i+1, match[1], err)
+		}
+		p.included[inc] = true
+		p.m.Files = append(p.m.Files, inc)
+		if err := p.file(inc, len(p.m.Files)-1, incSrc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PreprocessGLSL resolves the #include directives of the given GLSL source
+// (e.g. #include "lighting.glsl") using the include function, returning the
+// preprocessed source and it's source map. The name of the source is used in
+// the source map and error messages.
+//
+// Each file is included at most once (i.e. later includes of the same file,
+// including cyclic ones, are ignored). Other preprocessor directives are left
+// for the GLSL compiler.
+func PreprocessGLSL(name string, src []byte, include IncludeFunc) ([]byte, *SourceMap, error) {
+	p := &preprocessor{
+		include:  include,
+		m:        &SourceMap{Files: []string{name}},
+		included: make(map[string]bool),
+	}
+	if err := p.file(name, 0, src); err != nil {
+		return nil, nil, err
+	}
+	return p.out.Bytes(), p.m, nil
+}
+
+// versionDirective matches a GLSL #version directive line.
+var versionDirective = regexp.MustCompile(`^\s*#\s*version\b`)
+
+// definesFile is the file name that injected #define lines originate from in
+// source maps.
+const definesFile = "<defines>"
+
+// DefineGLSL injects a #define line for each of the given macros into the
+// GLSL source, sorted by name, directly after it's #version directive (or at
+// the start of the source if it has none). For example:
+//  DefineGLSL(src, m, map[string]string{"NUM_LIGHTS": "4", "USE_FOG": ""})
+// Injects:
+//  #define NUM_LIGHTS 4
+//  #define USE_FOG
+//
+// If m is the source map of src, the source map of the returned source is
+// also returned, in which the injected lines originate from the file named
+// "<defines>". Otherwise a nil source map is returned.
+func DefineGLSL(src []byte, m *SourceMap, defines map[string]string) ([]byte, *SourceMap) {
+	if len(defines) == 0 {
+		return src, m
+	}
+	names := make([]string, 0, len(defines))
+	for name := range defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	at := 0
+	for i, line := range lines {
+		if versionDirective.Match(line) {
+			at = i + 1
+			break
+		}
+	}
+
+	var out bytes.Buffer
+	for _, line := range lines[:at] {
+		out.Write(line)
+	}
+	if at > 0 && !bytes.HasSuffix(lines[at-1], []byte("\n")) {
+		out.WriteByte('\n')
+	}
+	for _, name := range names {
+		out.WriteString("#define " + name)
+		if v := defines[name]; v != "" {
+			out.WriteString(" " + v)
+		}
+		out.WriteByte('\n')
+	}
+	for _, line := range lines[at:] {
+		out.Write(line)
+	}
+	if m == nil {
+		return out.Bytes(), nil
+	}
+
+	// Insert the define lines into a copy of the source map.
+	if at > len(m.lines) {
+		at = len(m.lines)
+	}
+	dm := &SourceMap{
+		Files: append(append([]string(nil), m.Files...), definesFile),
+		lines: make([]sourceLine, 0, len(m.lines)+len(names)),
+	}
+	dm.lines = append(dm.lines, m.lines[:at]...)
+	for i := range names {
+		dm.lines = append(dm.lines, sourceLine{len(m.Files), i + 1})
+	}
+	dm.lines = append(dm.lines, m.lines[at:]...)
+	return out.Bytes(), dm
+}
+
+// Preprocess resolves the #include directives of the shader's GLSL sources
+// using it's Include function (see PreprocessGLSL) and injects it's Defines
+// (see DefineGLSL), replacing the sources with the preprocessed ones and
+// setting the VertMap and FragMap source maps. Renderers call it before
+// compiling the shader, and use the source maps to remap the compiler's error
+// log (see SourceMap.Remap).
+//
+// Preprocessing preprocessed sources injects the defines again (which GLSL
+// permits, as they are identical redefinitions) and resets the source maps.
+// If an include cannot be resolved an error is returned and the shader is
+// left unchanged.
+//
+// The shader's write lock must be held for this method to operate safely.
+func (s *Shader) Preprocess() error {
+	vert, vertMap, err := PreprocessGLSL(s.Name+".vert", s.GLSLVert, s.Include)
+	if err != nil {
+		return err
+	}
+	frag, fragMap, err := PreprocessGLSL(s.Name+".frag", s.GLSLFrag, s.Include)
+	if err != nil {
+		return err
+	}
+	vert, vertMap = DefineGLSL(vert, vertMap, s.Defines)
+	frag, fragMap = DefineGLSL(frag, fragMap, s.Defines)
+	s.GLSLVert, s.VertMap = vert, vertMap
+	s.GLSLFrag, s.FragMap = frag, fragMap
+	return nil
+}
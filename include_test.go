@@ -0,0 +1,88 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"testing"
+)
+
+var testIncludes = IncludeMap(map[string]string{
+	"lighting.glsl":    "#include \"common/math.glsl\"\nvec3 light() { return vec3(1); }\n",
+	"common/math.glsl": "float sq(float x) { return x * x; }\n",
+	"cycle.glsl":       "#include \"cycle.glsl\"\nint x;\n",
+})
+
+func TestShaderPreprocessCycle(t *testing.T) {
+	src, _, err := PreprocessGLSL("test", []byte("#include \"cycle.glsl\"\n"), testIncludes)
+	if err != nil || string(src) != "int x;\n" {
+		t.Fatalf("got %q, %v", src, err)
+	}
+}
+
+func TestShaderPreprocess(t *testing.T) {
+	s := NewShader("test")
+	s.Include = testIncludes
+	s.GLSLVert = []byte("#version 120\n#include \"lighting.glsl\"\n#include <lighting.glsl>\nvoid main() {}\n")
+	s.GLSLFrag = []byte("void main() {}\n")
+	if err := s.Preprocess(); err != nil {
+		t.Fatal(err)
+	}
+	want := "#version 120\nfloat sq(float x) { return x * x; }\nvec3 light() { return vec3(1); }\nvoid main() {}\n"
+	if string(s.GLSLVert) != want {
+		t.Fatalf("got source:\n%s\nwant:\n%s", s.GLSLVert, want)
+	}
+	for line, want := range map[int]string{1: "test.vert:1", 2: "common/math.glsl:1", 3: "lighting.glsl:2", 4: "test.vert:4"} {
+		file, l, ok := s.VertMap.Lookup(line)
+		if got := fmt.Sprintf("%s:%d", file, l); !ok || got != want {
+			t.Errorf("line %d maps to %s, want %s", line, got, want)
+		}
+	}
+
+	log := "0(3) : error C0000: syntax error\nERROR: 0:2: 'x' : undeclared\n"
+	got := string(s.VertMap.Remap([]byte(log)))
+	wantLog := "lighting.glsl:2 : error C0000: syntax error\nERROR: common/math.glsl:1: 'x' : undeclared\n"
+	if got != wantLog {
+		t.Fatalf("got log:\n%s\nwant:\n%s", got, wantLog)
+	}
+}
+
+func TestShaderPreprocessErrors(t *testing.T) {
+	if _, _, err := PreprocessGLSL("test", []byte("#include \"missing.glsl\"\n"), testIncludes); err == nil {
+		t.Error("expected error including a missing file")
+	}
+	if _, _, err := PreprocessGLSL("test", []byte("#include \"a.glsl\"\n"), nil); err == nil {
+		t.Error("expected error without an include function")
+	}
+}
+
+func TestShaderDefines(t *testing.T) {
+	s := NewShader("test")
+	s.Include = testIncludes
+	s.Defines["USE_FOG"] = ""
+	s.Defines["NUM_LIGHTS"] = "4"
+	s.GLSLVert = []byte("// comment\n#version 120\n#include \"common/math.glsl\"\nvoid main() {}\n")
+	s.GLSLFrag = []byte("void main() {}")
+	if err := s.Preprocess(); err != nil {
+		t.Fatal(err)
+	}
+	want := "// comment\n#version 120\n#define NUM_LIGHTS 4\n#define USE_FOG\nfloat sq(float x) { return x * x; }\nvoid main() {}\n"
+	if string(s.GLSLVert) != want {
+		t.Fatalf("got source:\n%s\nwant:\n%s", s.GLSLVert, want)
+	}
+	for line, want := range map[int]string{2: "test.vert:2", 3: "<defines>:1", 4: "<defines>:2", 5: "common/math.glsl:1", 6: "test.vert:4"} {
+		file, l, ok := s.VertMap.Lookup(line)
+		if got := fmt.Sprintf("%s:%d", file, l); !ok || got != want {
+			t.Errorf("line %d maps to %s, want %s", line, got, want)
+		}
+	}
+	wantFrag := "#define NUM_LIGHTS 4\n#define USE_FOG\nvoid main() {}\n"
+	if string(s.GLSLFrag) != wantFrag {
+		t.Fatalf("got source:\n%s\nwant:\n%s", s.GLSLFrag, wantFrag)
+	}
+	if cpy := s.Copy(); cpy.Defines["NUM_LIGHTS"] != "4" {
+		t.Error("Copy did not copy defines")
+	}
+}
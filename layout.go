@@ -0,0 +1,221 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// LayoutAttrib describes a single attribute within an interleaved vertex
+// buffer, see VertexLayout.
+type LayoutAttrib struct {
+	// The name of the attribute as seen by shaders (e.g. "Vertex", "Color",
+	// "TexCoord0", or the name of a custom attribute).
+	Name string
+
+	// The offset, in bytes, of the attribute from the start of each vertex.
+	Offset int
+
+	// The size, in bytes, of the attribute.
+	Size int
+}
+
+// VertexLayout describes how the per-vertex data of a mesh is interleaved
+// into a single buffer: the attributes of each vertex are stored
+// consecutively, and each vertex is Stride bytes apart.
+type VertexLayout struct {
+	// The number of bytes between the start of consecutive vertices.
+	Stride int
+
+	// The attributes of each vertex, in order of their offsets.
+	Attribs []LayoutAttrib
+}
+
+// Layout returns the interleaved layout of the per-vertex data of this mesh
+// (i.e. the vertices, colors, barycentric coordinates, texture coordinate
+// sets, and custom attributes, in that order, with custom attributes sorted by
+// name). Only data slices whose length equals that of the Vertices slice are
+// included, custom attributes holding arrays of data (e.g. [][]gfx.Vec3) are
+// not, and must be stored in buffers of their own.
+//
+// The mesh's read lock must be held for this method to operate safely.
+func (m *Mesh) Layout() VertexLayout {
+	l, _ := m.layout()
+	return l
+}
+
+// attribWriter writes the attribute of vertex v at offset off of w.
+type attribWriter func(w interleaveWriter, off, v int)
+
+// layout implements the Layout method, additionally returning a writer for
+// each attribute of the layout.
+func (m *Mesh) layout() (VertexLayout, []attribWriter) {
+	var (
+		l       VertexLayout
+		writers []attribWriter
+	)
+	n := len(m.Vertices)
+	add := func(name string, size int, fn attribWriter) {
+		l.Attribs = append(l.Attribs, LayoutAttrib{
+			Name:   name,
+			Offset: l.Stride,
+			Size:   size,
+		})
+		l.Stride += size
+		writers = append(writers, fn)
+	}
+	if n == 0 {
+		return l, nil
+	}
+	add("Vertex", 12, func(w interleaveWriter, off, v int) {
+		p := m.Vertices[v]
+		w.f32(off, p.X, p.Y, p.Z)
+	})
+	if len(m.Colors) == n {
+		add("Color", 16, func(w interleaveWriter, off, v int) {
+			c := m.Colors[v]
+			w.f32(off, c.R, c.G, c.B, c.A)
+		})
+	}
+	if len(m.Bary) == n {
+		add("Bary", 12, func(w interleaveWriter, off, v int) {
+			b := m.Bary[v]
+			w.f32(off, b.X, b.Y, b.Z)
+		})
+	}
+	for i, set := range m.TexCoords {
+		if len(set.Slice) == n {
+			tcs := set.Slice
+			add(fmt.Sprintf("TexCoord%d", i), 8, func(w interleaveWriter, off, v int) {
+				w.f32(off, tcs[v].U, tcs[v].V)
+			})
+		}
+	}
+	names := make([]string, 0, len(m.Attribs))
+	for name := range m.Attribs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if size, fn := attribWriterFor(m.Attribs[name].Data, n); fn != nil {
+			add(name, size, fn)
+		}
+	}
+	return l, writers
+}
+
+// attribWriterFor returns the size in bytes of a single element of the given
+// custom attribute data and a writer for it, or a nil writer if the data
+// cannot be interleaved (e.g. arrays of data, which occupy multiple
+// attributes, or data whose length is not n).
+func attribWriterFor(data interface{}, n int) (int, attribWriter) {
+	switch t := data.(type) {
+	case []float32:
+		if len(t) == n {
+			return 4, func(w interleaveWriter, off, v int) {
+				w.f32(off, t[v])
+			}
+		}
+	case []Vec3:
+		if len(t) == n {
+			return 12, func(w interleaveWriter, off, v int) {
+				w.f32(off, t[v].X, t[v].Y, t[v].Z)
+			}
+		}
+	case []Vec4:
+		if len(t) == n {
+			return 16, func(w interleaveWriter, off, v int) {
+				w.f32(off, t[v].X, t[v].Y, t[v].Z, t[v].W)
+			}
+		}
+	case []Mat4:
+		if len(t) == n {
+			return 64, func(w interleaveWriter, off, v int) {
+				for r, row := range t[v] {
+					w.f32(off+16*r, row[:]...)
+				}
+			}
+		}
+	case []Half2:
+		if len(t) == n {
+			return 4, func(w interleaveWriter, off, v int) {
+				w.u16(off, uint16(t[v][0]), uint16(t[v][1]))
+			}
+		}
+	case []Half4:
+		if len(t) == n {
+			return 8, func(w interleaveWriter, off, v int) {
+				w.u16(off, uint16(t[v][0]), uint16(t[v][1]), uint16(t[v][2]), uint16(t[v][3]))
+			}
+		}
+	case []UNorm8x4:
+		if len(t) == n {
+			return 4, func(w interleaveWriter, off, v int) {
+				copy(w[off:], t[v][:])
+			}
+		}
+	case []SNorm16x2:
+		if len(t) == n {
+			return 4, func(w interleaveWriter, off, v int) {
+				w.u16(off, uint16(t[v][0]), uint16(t[v][1]))
+			}
+		}
+	case []SNorm16x4:
+		if len(t) == n {
+			return 8, func(w interleaveWriter, off, v int) {
+				w.u16(off, uint16(t[v][0]), uint16(t[v][1]), uint16(t[v][2]), uint16(t[v][3]))
+			}
+		}
+	case []Int2101010:
+		if len(t) == n {
+			return 4, func(w interleaveWriter, off, v int) {
+				binary.LittleEndian.PutUint32(w[off:], uint32(t[v]))
+			}
+		}
+	}
+	return 0, nil
+}
+
+// interleaveWriter writes little-endian values into a byte slice.
+type interleaveWriter []byte
+
+func (w interleaveWriter) f32(off int, fs ...float32) {
+	for i, f := range fs {
+		binary.LittleEndian.PutUint32(w[off+4*i:], math.Float32bits(f))
+	}
+}
+
+func (w interleaveWriter) u16(off int, vs ...uint16) {
+	for i, v := range vs {
+		binary.LittleEndian.PutUint16(w[off+2*i:], v)
+	}
+}
+
+// Interleave appends the per-vertex data of this mesh, interleaved according
+// to the layout returned by the Layout method, to dst and returns the
+// extended slice. All values are stored in little-endian byte order.
+//
+// The mesh's read lock must be held for this method to operate safely.
+func (m *Mesh) Interleave(dst []byte) []byte {
+	l, writers := m.layout()
+	start := len(dst)
+	need := l.Stride * len(m.Vertices)
+	if cap(dst)-start < need {
+		grown := make([]byte, start, start+need)
+		copy(grown, dst)
+		dst = grown
+	}
+	dst = dst[:start+need]
+	buf := interleaveWriter(dst[start:])
+	for i, a := range l.Attribs {
+		for v := range m.Vertices {
+			writers[i](buf, v*l.Stride+a.Offset, v)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,48 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMeshInterleave(t *testing.T) {
+	m := NewMesh()
+	m.Vertices = []Vec3{{1, 2, 3}, {4, 5, 6}}
+	m.TexCoords = []TexCoordSet{{Slice: []TexCoord{{0.5, 0.25}, {1, 0}}}}
+	m.Attribs["Normal"] = VertexAttrib{Data: []Int2101010{7, 8}}
+	m.Attribs["Bones"] = VertexAttrib{Data: [][]Vec4{{{}, {}}}}
+
+	l := m.Layout()
+	want := VertexLayout{
+		Stride: 24,
+		Attribs: []LayoutAttrib{
+			{Name: "Vertex", Offset: 0, Size: 12},
+			{Name: "TexCoord0", Offset: 12, Size: 8},
+			{Name: "Normal", Offset: 20, Size: 4},
+		},
+	}
+	if !reflect.DeepEqual(l, want) {
+		t.Fatalf("got layout %+v, want %+v", l, want)
+	}
+
+	buf := m.Interleave([]byte{0xff})
+	if len(buf) != 1+48 || buf[0] != 0xff {
+		t.Fatalf("got %d bytes, want 49 with the prefix kept", len(buf))
+	}
+	buf = buf[1:]
+	f32 := func(off int) float32 {
+		return math.Float32frombits(binary.LittleEndian.Uint32(buf[off:]))
+	}
+	if f32(24) != 4 || f32(24+8) != 6 || f32(24+12) != 1 {
+		t.Errorf("second vertex has position %v %v and U %v", f32(24), f32(32), f32(36))
+	}
+	if n := binary.LittleEndian.Uint32(buf[24+20:]); n != 8 {
+		t.Errorf("second vertex has normal %d, want 8", n)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// LODLevel represents a single level of detail of a mesh.
+type LODLevel struct {
+	// The mesh of this level.
+	Mesh *Mesh
+
+	// The geometric error of this level, i.e. the approximate maximum
+	// distance (in the mesh's local space) between it's surface and that of
+	// the most detailed level. See Mesh.Simplify.
+	Error float64
+}
+
+// LOD represents a mesh at multiple levels of detail, which are selected by
+// their error as projected onto the screen.
+type LOD struct {
+	// The levels of detail, ordered from the most detailed (typically the
+	// original mesh with zero error) to the least detailed.
+	Levels []LODLevel
+}
+
+// GenerateLOD generates levels of detail for the given mesh by simplifying
+// copies of it (see Mesh.Simplify). The first level is the mesh itself, and
+// each following level has the given ratio (e.g. 0.5) of the triangles of
+// the level before it. Generation stops after the given number of levels, or
+// once simplification makes no further progress.
+//
+// The mesh's read lock must be held for this function to operate safely.
+func GenerateLOD(m *Mesh, levels int, ratio float64) *LOD {
+	lod := &LOD{Levels: []LODLevel{{Mesh: m}}}
+	prev := m
+	var prevError float64
+	for len(lod.Levels) < levels {
+		tris := len(prev.Indices) / 3
+		target := int(float64(tris) * ratio)
+		if target < 1 {
+			break
+		}
+		cpy := prev.Copy()
+		cpy.KeepDataOnLoad = true
+		err := math.Max(cpy.Simplify(target), prevError)
+		if len(cpy.Indices)/3 >= tris {
+			break
+		}
+		lod.Levels = append(lod.Levels, LODLevel{Mesh: cpy, Error: err})
+		prev, prevError = cpy, err
+	}
+	return lod
+}
+
+// ScreenError returns the size in pixels of the given geometric error at the
+// given distance from a perspective camera with the given vertical field of
+// view (in degrees) and viewport height (in pixels).
+func ScreenError(err, distance, fovY float64, viewportHeight int) float64 {
+	if distance <= 0 {
+		return math.Inf(1)
+	}
+	return err * float64(viewportHeight) / (2 * distance * math.Tan(lmath.Radians(fovY)/2))
+}
+
+// Select returns the least detailed level whose error, as projected onto the
+// screen (see ScreenError), is at most the given number of pixels (e.g. one).
+// If no levels are present nil is returned.
+func (l *LOD) Select(distance, fovY float64, viewportHeight int, maxPixelError float64) *Mesh {
+	if len(l.Levels) == 0 {
+		return nil
+	}
+	return l.Levels[l.selectLevel(distance, fovY, viewportHeight, maxPixelError)].Mesh
+}
+
+// SelectFrom is like Select, except that it avoids switching back and forth
+// between two levels (popping) while the distance hovers around the point at
+// which they are switched. The current level (i.e. the mesh previously
+// returned) is kept while it's projected error is within the given
+// hysteresis ratio (e.g. 0.1 for ten percent) of maxPixelError:
+//  - A more detailed level is only selected once the current level's error
+//    exceeds maxPixelError * (1 + hysteresis).
+//  - A less detailed level is only selected once it's error is at most
+//    maxPixelError / (1 + hysteresis).
+// If current is not one of the levels (e.g. nil) then SelectFrom is identical
+// to Select.
+func (l *LOD) SelectFrom(current *Mesh, distance, fovY float64, viewportHeight int, maxPixelError, hysteresis float64) *Mesh {
+	if len(l.Levels) == 0 {
+		return nil
+	}
+	i := -1
+	for j, level := range l.Levels {
+		if level.Mesh == current {
+			i = j
+			break
+		}
+	}
+	if i == -1 {
+		return l.Select(distance, fovY, viewportHeight, maxPixelError)
+	}
+	finest := l.selectLevel(distance, fovY, viewportHeight, maxPixelError/(1+hysteresis))
+	coarsest := l.selectLevel(distance, fovY, viewportHeight, maxPixelError*(1+hysteresis))
+	switch {
+	case i < finest:
+		i = finest
+	case i > coarsest:
+		i = coarsest
+	}
+	return l.Levels[i].Mesh
+}
+
+// selectLevel returns the index of the level that Select returns, there must
+// be at least one level.
+func (l *LOD) selectLevel(distance, fovY float64, viewportHeight int, maxPixelError float64) int {
+	best := 0
+	for i, level := range l.Levels[1:] {
+		if ScreenError(level.Error, distance, fovY, viewportHeight) > maxPixelError {
+			break
+		}
+		best = i + 1
+	}
+	return best
+}
@@ -0,0 +1,90 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+// testLOD returns an LOD with three levels whose errors are 0, 1, and 2. With
+// a 90 degree field of view and a viewport height of 100 pixels, a level's
+// screen error is 50 * Error / distance pixels.
+func testLOD() *LOD {
+	return &LOD{Levels: []LODLevel{
+		{Mesh: NewMesh(), Error: 0},
+		{Mesh: NewMesh(), Error: 1},
+		{Mesh: NewMesh(), Error: 2},
+	}}
+}
+
+func TestLODSelect(t *testing.T) {
+	lod := testLOD()
+	tests := []struct {
+		distance float64
+		want     int
+	}{
+		{0, 0},
+		{49, 0},
+		{50, 1}, // Exactly one pixel of error is acceptable.
+		{99, 1},
+		{100, 2},
+		{1e6, 2},
+	}
+	for _, tst := range tests {
+		if got := lod.Select(tst.distance, 90, 100, 1); got != lod.Levels[tst.want].Mesh {
+			t.Errorf("Select at distance %v did not select level %d", tst.distance, tst.want)
+		}
+	}
+	if (&LOD{}).Select(10, 90, 100, 1) != nil {
+		t.Error("Select without levels returned a mesh")
+	}
+}
+
+func TestLODSelectFrom(t *testing.T) {
+	lod := testLOD()
+	tests := []struct {
+		current  int
+		distance float64
+		want     int
+	}{
+		// Switching to a less detailed level requires it's error to be at
+		// most 1/1.25 = 0.8 pixels, i.e. a distance of at least 62.5.
+		{0, 55, 0},
+		{0, 63, 1},
+		{1, 120, 1},
+		{1, 130, 2},
+		{0, 130, 2},
+
+		// Switching to a more detailed level requires the current level's
+		// error to exceed 1.25 pixels.
+		{1, 45, 1},
+		{1, 39, 0},
+		{2, 90, 2},
+		{2, 70, 1},
+		{2, 30, 0},
+
+		// Without a current level, SelectFrom is Select.
+		{-1, 55, 1},
+	}
+	for _, tst := range tests {
+		var current *Mesh
+		if tst.current >= 0 {
+			current = lod.Levels[tst.current].Mesh
+		}
+		if got := lod.SelectFrom(current, tst.distance, 90, 100, 1, 0.25); got != lod.Levels[tst.want].Mesh {
+			t.Errorf("SelectFrom level %d at distance %v did not select level %d", tst.current, tst.distance, tst.want)
+		}
+	}
+
+	// Hovering around the distance at which Select switches levels must not
+	// switch back and forth.
+	m := lod.Select(49, 90, 100, 1)
+	for _, d := range []float64{51, 49, 51, 49, 51} {
+		if next := lod.SelectFrom(m, d, 90, 100, 1, 0.25); next != m {
+			t.Fatalf("switched levels at distance %v", d)
+		}
+	}
+	if (&LOD{}).SelectFrom(nil, 10, 90, 100, 1, 0.25) != nil {
+		t.Error("SelectFrom without levels returned a mesh")
+	}
+}
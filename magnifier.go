@@ -0,0 +1,70 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "image"
+
+// Magnifier describes an accessibility screen magnifier, which presents a
+// magnified region of the scene that follows the cursor.
+//
+// The scene is rendered at it's native resolution into a render-to-texture
+// canvas, and then the texture is drawn onto the screen using a quad whose
+// texture coordinates are given by the TexCoords method:
+//  min, max := m.TexCoords(cursor)
+//  // ... draw a full-screen quad with texture coordinates min and max ...
+type Magnifier struct {
+	// The zoom factor, e.g. 2.0 makes everything appear twice as large.
+	// Values less than 1.0 are treated as 1.0 (no magnification).
+	Zoom float64
+
+	// The bounds of the canvas that the scene is rendered to.
+	Bounds image.Rectangle
+}
+
+// Region returns the region of the scene, within m.Bounds, which is presented
+// magnified to the user for the given cursor position. The region is centered
+// on the cursor, except near the edges of the bounds where it is clamped to
+// remain fully inside of them.
+func (m Magnifier) Region(cursor image.Point) image.Rectangle {
+	zoom := m.Zoom
+	if zoom < 1 {
+		zoom = 1
+	}
+	size := image.Pt(
+		int(float64(m.Bounds.Dx())/zoom),
+		int(float64(m.Bounds.Dy())/zoom),
+	)
+	min := cursor.Sub(size.Div(2))
+
+	// Clamp the region to the bounds.
+	if min.X < m.Bounds.Min.X {
+		min.X = m.Bounds.Min.X
+	}
+	if min.Y < m.Bounds.Min.Y {
+		min.Y = m.Bounds.Min.Y
+	}
+	if min.X+size.X > m.Bounds.Max.X {
+		min.X = m.Bounds.Max.X - size.X
+	}
+	if min.Y+size.Y > m.Bounds.Max.Y {
+		min.Y = m.Bounds.Max.Y - size.Y
+	}
+	return image.Rectangle{Min: min, Max: min.Add(size)}
+}
+
+// TexCoords returns the minimum (top-left) and maximum (bottom-right) texture
+// coordinates of the magnified region (see Region) for the given cursor
+// position, relative to a texture covering m.Bounds.
+func (m Magnifier) TexCoords(cursor image.Point) (min, max TexCoord) {
+	r := m.Region(cursor).Sub(m.Bounds.Min)
+	w := float32(m.Bounds.Dx())
+	h := float32(m.Bounds.Dy())
+	if w == 0 || h == 0 {
+		return
+	}
+	min = TexCoord{U: float32(r.Min.X) / w, V: float32(r.Min.Y) / h}
+	max = TexCoord{U: float32(r.Max.X) / w, V: float32(r.Max.Y) / h}
+	return
+}
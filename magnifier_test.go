@@ -0,0 +1,59 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"testing"
+)
+
+func TestMagnifierRegion(t *testing.T) {
+	bounds := image.Rect(0, 0, 800, 600)
+	tests := []struct {
+		zoom   float64
+		bounds image.Rectangle
+		cursor image.Point
+		want   image.Rectangle
+	}{
+		// Centered on the cursor.
+		{2, bounds, image.Pt(400, 300), image.Rect(200, 150, 600, 450)},
+		{4, bounds, image.Pt(300, 200), image.Rect(200, 125, 400, 275)},
+
+		// Clamped to the bounds near the edges, and when the cursor is
+		// outside of them.
+		{2, bounds, image.Pt(10, 10), image.Rect(0, 0, 400, 300)},
+		{2, bounds, image.Pt(790, 590), image.Rect(400, 300, 800, 600)},
+		{2, bounds, image.Pt(-50, 1000), image.Rect(0, 300, 400, 600)},
+
+		// Bounds which do not start at the origin.
+		{2, image.Rect(100, 100, 900, 700), image.Pt(100, 100), image.Rect(100, 100, 500, 400)},
+
+		// Zoom factors below one present the whole bounds.
+		{1, bounds, image.Pt(10, 10), bounds},
+		{0.5, bounds, image.Pt(10, 10), bounds},
+		{0, bounds, image.Pt(10, 10), bounds},
+	}
+	for _, tst := range tests {
+		m := Magnifier{Zoom: tst.zoom, Bounds: tst.bounds}
+		if got := m.Region(tst.cursor); got != tst.want {
+			t.Errorf("zoom %v bounds %v: Region(%v) = %v, want %v", tst.zoom, tst.bounds, tst.cursor, got, tst.want)
+		}
+	}
+}
+
+func TestMagnifierTexCoords(t *testing.T) {
+	m := Magnifier{Zoom: 2, Bounds: image.Rect(100, 100, 900, 700)}
+	min, max := m.TexCoords(image.Pt(500, 400))
+	if min != (TexCoord{0.25, 0.25}) || max != (TexCoord{0.75, 0.75}) {
+		t.Errorf("got texture coordinates %v to %v, want 0.25 to 0.75", min, max)
+	}
+
+	// Empty bounds give zero texture coordinates, instead of dividing by
+	// zero.
+	m.Bounds = image.Rectangle{}
+	if min, max := m.TexCoords(image.Pt(5, 5)); min != (TexCoord{}) || max != (TexCoord{}) {
+		t.Errorf("got texture coordinates %v to %v for empty bounds", min, max)
+	}
+}
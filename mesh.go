@@ -5,6 +5,10 @@
 package gfx
 
 import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 
 	"azul3d.org/lmath.v1"
@@ -50,6 +54,16 @@ type VertexAttrib struct {
 	//  [][]gfx.Mat4
 	//  []gfx.Vec4
 	//  [][]gfx.Vec4
+	//
+	// To reduce memory and bandwidth, the data may also be stored in one of
+	// the following compact types, which shaders see as float vectors (e.g.
+	// a []gfx.Int2101010 attribute is accessed as a vec4 in GLSL):
+	//  []gfx.Half2       // Half-precision floats.
+	//  []gfx.Half4
+	//  []gfx.UNorm8x4    // Unsigned normalized 8-bit integers.
+	//  []gfx.SNorm16x2   // Signed normalized 16-bit integers.
+	//  []gfx.SNorm16x4
+	//  []gfx.Int2101010  // Packed signed normalized 2_10_10_10 integers.
 	Data interface{}
 
 	// Weather or not the per-vertex data (see the Data field) has changed
@@ -90,6 +104,7 @@ func (a VertexAttrib) Copy() VertexAttrib {
 			c[i] = make([]float32, len(s))
 			copy(c[i], t[i])
 		}
+		cpy = c
 
 	case [][]Vec3:
 		c := make([][]Vec3, len(t))
@@ -97,6 +112,7 @@ func (a VertexAttrib) Copy() VertexAttrib {
 			c[i] = make([]Vec3, len(s))
 			copy(c[i], t[i])
 		}
+		cpy = c
 
 	case [][]Vec4:
 		c := make([][]Vec4, len(t))
@@ -104,6 +120,7 @@ func (a VertexAttrib) Copy() VertexAttrib {
 			c[i] = make([]Vec4, len(s))
 			copy(c[i], t[i])
 		}
+		cpy = c
 
 	case [][]Mat4:
 		c := make([][]Mat4, len(t))
@@ -111,6 +128,37 @@ func (a VertexAttrib) Copy() VertexAttrib {
 			c[i] = make([]Mat4, len(s))
 			copy(c[i], t[i])
 		}
+		cpy = c
+
+	case []Half2:
+		c := make([]Half2, len(t))
+		copy(c, t)
+		cpy = c
+
+	case []Half4:
+		c := make([]Half4, len(t))
+		copy(c, t)
+		cpy = c
+
+	case []UNorm8x4:
+		c := make([]UNorm8x4, len(t))
+		copy(c, t)
+		cpy = c
+
+	case []SNorm16x2:
+		c := make([]SNorm16x2, len(t))
+		copy(c, t)
+		cpy = c
+
+	case []SNorm16x4:
+		c := make([]SNorm16x4, len(t))
+		copy(c, t)
+		cpy = c
+
+	case []Int2101010:
+		c := make([]Int2101010, len(t))
+		copy(c, t)
+		cpy = c
 
 	default:
 		return VertexAttrib{}
@@ -152,6 +200,34 @@ type Mesh struct {
 	// control whether or not a mesh may be dynamically updated.
 	Dynamic bool
 
+	// A hint of how frequently the mesh's data will be updated, which allows
+	// the renderer to choose the most appropriate storage for it. If Dynamic
+	// is true and the usage is UsageStatic, UsageDynamic is assumed.
+	Usage MeshUsage
+
+	// Whether or not the renderer should store the per-vertex data of the
+	// mesh in a single interleaved buffer (see the Layout and Interleave
+	// methods), rather than one buffer per attribute. This improves vertex
+	// fetch cache behaviour and reduces buffer binds, and is typically used
+	// for static meshes (updating any attribute requires uploading the
+	// entire buffer again).
+	Interleaved bool
+
+	// The primitive topology of this mesh, i.e. how the vertices (or indices)
+	// are assembled into primitives for rendering.
+	Primitive Primitive
+
+	// Whether or not primitive restart is enabled for this mesh. If true and
+	// the mesh is indexed then each occurrence of RestartIndex in the Indices
+	// slice ends the current primitive (e.g. a triangle strip) and begins a
+	// new one. Only useful with TriangleStrip, TriangleFan, LineStrip, and
+	// LineLoop primitives.
+	//
+	// If the GPU does not support primitive restart (see
+	// GPUInfo.PrimitiveRestart) then the renderer is responsible for
+	// emulating it.
+	PrimitiveRestart bool
+
 	// AABB is the axis aligned bounding box of this mesh. There may not be one
 	// if AABB.Empty() == true, but one can be calculate using the
 	// CalculateBounds() method.
@@ -176,7 +252,8 @@ type Mesh struct {
 	// re-upload the data slice to the graphics hardware.
 	VerticesChanged bool
 
-	// The slice of vertex colors for the mesh.
+	// The slice of vertex colors for the mesh. Like Object.Tint, vertex
+	// colors are sRGB-encoded and modulate the texture color (see ApplyTint).
 	Colors []Color
 
 	// Weather or not the vertex colors have changed since the last time
@@ -227,12 +304,148 @@ type Mesh struct {
 	// See the documentation on the VertexAttrib type for more information
 	// regarding what data types may be used.
 	Attribs map[string]VertexAttrib
+
+	// The pending partial updates of the mesh's data, see the UpdateRange
+	// method. The renderer uploads only these ranges the next time the mesh
+	// is loaded and then sets this slice to nil.
+	Ranges []MeshRange
+
+	// The reference count of the native mesh, if it is shared with other
+	// meshes (see the Share method).
+	share *meshShare
+}
+
+// MeshUsage is a hint of how frequently the data of a mesh is updated, see
+// the Mesh.Usage field.
+type MeshUsage uint8
+
+// String returns a string representation of this mesh usage.
+// e.g. UsageStream -> "UsageStream"
+func (u MeshUsage) String() string {
+	switch u {
+	case UsageStatic:
+		return "UsageStatic"
+	case UsageDynamic:
+		return "UsageDynamic"
+	case UsageStream:
+		return "UsageStream"
+	}
+	return fmt.Sprintf("MeshUsage(%d)", u)
+}
+
+const (
+	// UsageStatic is for meshes whose data is loaded once and rarely (if
+	// ever) updated, e.g. level geometry.
+	UsageStatic MeshUsage = iota
+
+	// UsageDynamic is for meshes whose data is updated occasionally, e.g.
+	// when the user edits it.
+	UsageDynamic
+
+	// UsageStream is for meshes whose data is updated every frame, e.g.
+	// particle systems and UI geometry.
+	UsageStream
+)
+
+// MeshRange represents a range of a mesh's data slice to be uploaded to the
+// graphics hardware, see the Mesh.UpdateRange method.
+type MeshRange struct {
+	// The name of the data slice, as given to UpdateRange.
+	Attrib string
+
+	// The index of the first element, and the number of elements.
+	Start, Count int
+}
+
+// UpdateRange marks count elements of the named data slice, starting at
+// start, as changed, such that the renderer uploads only those elements the
+// next time the mesh is loaded instead of the entire slice (as setting the
+// VerticesChanged, etc, fields would).
+//
+// The name is the name of the data slice's field: one of "Indices",
+// "Vertices", "Colors", "Bary", "TexCoordsN" (where N is the index of the
+// texture coordinate set), or the name of a custom attribute (see the Attribs
+// field). Ranges of the same data slice which overlap or are adjacent are
+// merged.
+//
+// If there is no such data slice, or the range is not within it, an error is
+// returned and no range is marked.
+//
+// The mesh's write lock must be held for this method to operate safely.
+func (m *Mesh) UpdateRange(attrib string, start, count int) error {
+	n, ok := m.rangeLen(attrib)
+	if !ok {
+		return fmt.Errorf("UpdateRange(): no data slice named %q", attrib)
+	}
+	if start < 0 || count < 0 || start+count > n {
+		return fmt.Errorf("UpdateRange(): range [%d, %d) out of bounds of %s (length %d)", start, start+count, attrib, n)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	end := start + count
+	for i := 0; i < len(m.Ranges); i++ {
+		r := m.Ranges[i]
+		if r.Attrib != attrib || end < r.Start || start > r.Start+r.Count {
+			continue
+		}
+		// Merge with the overlapping or adjacent range, and remove it (the
+		// merged range may overlap others, which are merged in turn).
+		if r.Start < start {
+			start = r.Start
+		}
+		if r.Start+r.Count > end {
+			end = r.Start + r.Count
+		}
+		m.Ranges = append(m.Ranges[:i], m.Ranges[i+1:]...)
+		i = -1
+	}
+	m.Ranges = append(m.Ranges, MeshRange{
+		Attrib: attrib,
+		Start:  start,
+		Count:  end - start,
+	})
+	return nil
+}
+
+// rangeLen returns the length of the data slice with the given name (see
+// UpdateRange), ok is false if there is no such data slice.
+func (m *Mesh) rangeLen(attrib string) (n int, ok bool) {
+	switch attrib {
+	case "Indices":
+		return len(m.Indices), true
+	case "Vertices":
+		return len(m.Vertices), true
+	case "Colors":
+		return len(m.Colors), true
+	case "Bary":
+		return len(m.Bary), true
+	}
+	if a, ok := m.Attribs[attrib]; ok {
+		v := reflect.ValueOf(a.Data)
+		if v.Kind() != reflect.Slice {
+			return 0, false
+		}
+		return v.Len(), true
+	}
+	if strings.HasPrefix(attrib, "TexCoords") {
+		set, err := strconv.Atoi(attrib[len("TexCoords"):])
+		if err != nil || set < 0 || set >= len(m.TexCoords) {
+			return 0, false
+		}
+		return len(m.TexCoords[set].Slice), true
+	}
+	return 0, false
 }
 
 // Copy returns a new copy of this Mesh. Depending on how large the mesh is
 // this may be an expensive operation. Explicitly not copied over is the native
 // mesh, the OnLoad slice, and the loaded and changed statuses (Loaded,
-// IndicesChanged, VerticesChanged, etc).
+// IndicesChanged, VerticesChanged, Ranges, etc).
+//
+// To draw the same loaded mesh from many objects without duplicating it's data
+// or GPU buffers, use the Share method instead.
 //
 // The mesh's read lock must be held for this method to operate safely.
 func (m *Mesh) Copy() *Mesh {
@@ -242,6 +455,10 @@ func (m *Mesh) Copy() *Mesh {
 		false, // Loaded status -- not copied.
 		m.KeepDataOnLoad,
 		m.Dynamic,
+		m.Usage,
+		m.Interleaved,
+		m.Primitive,
+		m.PrimitiveRestart,
 		m.AABB,
 		make([]uint32, len(m.Indices)),
 		false, // IndicesChanged -- not copied.
@@ -253,6 +470,8 @@ func (m *Mesh) Copy() *Mesh {
 		false, // BaryChanged -- not copied.
 		make([]TexCoordSet, len(m.TexCoords)),
 		make(map[string]VertexAttrib, len(m.Attribs)),
+		nil, // Ranges -- not copied.
+		nil, // Sharing state -- not copied.
 	}
 
 	copy(cpy.Indices, m.Indices)
@@ -331,6 +550,9 @@ func (m *Mesh) HasChanged() bool {
 	if m.IndicesChanged || m.VerticesChanged || m.ColorsChanged || m.BaryChanged {
 		return true
 	}
+	if len(m.Ranges) > 0 {
+		return true
+	}
 	for _, texCoordSet := range m.TexCoords {
 		if texCoordSet.Changed {
 			return true
@@ -367,6 +589,10 @@ func (m *Mesh) Reset() {
 	m.Loaded = false
 	m.KeepDataOnLoad = false
 	m.Dynamic = false
+	m.Usage = UsageStatic
+	m.Interleaved = false
+	m.Primitive = Triangles
+	m.PrimitiveRestart = false
 	m.AABB = lmath.Rect3Zero
 	m.Indices = m.Indices[:0]
 	m.IndicesChanged = false
@@ -382,16 +608,29 @@ func (m *Mesh) Reset() {
 	}
 	m.TexCoords = m.TexCoords[:0]
 	m.Attribs = make(map[string]VertexAttrib)
+	m.Ranges = nil
+	m.share = nil
 }
 
 // Destroy destroys this mesh for use by other callees to NewMesh. You must not
 // use it after calling this method. This makes an implicit call to
-// m.NativeMesh.Destroy.
+// m.NativeMesh.Destroy, unless the native mesh is still shared with other
+// meshes (see the Share method).
 //
 // The mesh's write lock must be held for this method to operate safely.
 func (m *Mesh) Destroy() {
-	if m.NativeMesh != nil {
-		m.NativeMesh.Destroy()
+	if m.release() {
+		if m.NativeMesh != nil {
+			m.NativeMesh.Destroy()
+		}
+	} else {
+		// The data slices are still referenced by the other meshes, so they
+		// must not be reused by the pool.
+		m.Indices = nil
+		m.Vertices = nil
+		m.Colors = nil
+		m.Bary = nil
+		m.TexCoords = nil
 	}
 	m.Reset()
 	meshPool.Put(m)
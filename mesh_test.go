@@ -0,0 +1,111 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMeshUpdateRange(t *testing.T) {
+	m := NewMesh()
+	m.Vertices = make([]Vec3, 50)
+	m.Colors = make([]Color, 10)
+	for _, r := range []MeshRange{
+		{"Vertices", 0, 10},
+		{"Colors", 5, 5},
+		{"Vertices", 20, 5},
+		{"Vertices", 10, 10}, // Bridges the two vertex ranges.
+		{"Vertices", 40, 1},
+	} {
+		if err := m.UpdateRange(r.Attrib, r.Start, r.Count); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []MeshRange{
+		{"Colors", 5, 5},
+		{"Vertices", 0, 25},
+		{"Vertices", 40, 1},
+	}
+	if !reflect.DeepEqual(m.Ranges, want) {
+		t.Fatalf("got ranges %v, want %v", m.Ranges, want)
+	}
+	if !m.HasChanged() {
+		t.Fatal("HasChanged = false with pending ranges")
+	}
+}
+
+func TestMeshUpdateRangeInvalid(t *testing.T) {
+	m := NewMesh()
+	m.Vertices = make([]Vec3, 10)
+	m.TexCoords = []TexCoordSet{{Slice: make([]TexCoord, 10)}}
+	m.Attribs = map[string]VertexAttrib{
+		"Weight": {Data: make([]float32, 10)},
+	}
+	for _, r := range []MeshRange{
+		{"Vertices", 0, 10},
+		{"TexCoords0", 9, 1},
+		{"Weight", 2, 3},
+		{"Vertices", 10, 0},
+	} {
+		if err := m.UpdateRange(r.Attrib, r.Start, r.Count); err != nil {
+			t.Errorf("%v: unexpected error %v", r, err)
+		}
+	}
+	for _, r := range []MeshRange{
+		{"Vertices", 5, 6},
+		{"Vertices", -1, 2},
+		{"Vertices", 2, -1},
+		{"Colors", 0, 1},
+		{"Vertex", 0, 1},
+		{"TexCoords1", 0, 1},
+		{"Normal", 0, 1},
+	} {
+		if err := m.UpdateRange(r.Attrib, r.Start, r.Count); err == nil {
+			t.Errorf("%v: expected an error", r)
+		}
+	}
+	if len(m.Ranges) != 3 {
+		t.Fatalf("got ranges %v, want 3 ranges", m.Ranges)
+	}
+}
+
+func TestVertexAttribDeepCopy(t *testing.T) {
+	attribs := []interface{}{
+		[]float32{1, 2},
+		[]Vec3{{1, 2, 3}},
+		[]Vec4{{1, 2, 3, 4}},
+		[]Mat4{{{1}}},
+		[][]float32{{1, 2}, {3}},
+		[][]Vec3{{{1, 2, 3}}, {{4, 5, 6}}},
+		[][]Vec4{{{1, 2, 3, 4}}, {{5, 6, 7, 8}}},
+		[][]Mat4{{{{1}}}, {{{2}}}},
+		[]Half2{{1, 2}},
+		[]Int2101010{7},
+	}
+	for _, data := range attribs {
+		a := VertexAttrib{Data: data, Changed: true}
+		cpy := a.Copy()
+		if !reflect.DeepEqual(cpy.Data, data) {
+			t.Errorf("%T: copy %v differs from %v", data, cpy.Data, data)
+			continue
+		}
+		if cpy.Changed {
+			t.Errorf("%T: Changed was copied", data)
+		}
+
+		// The copy must be deep: the first element of the copy must not share
+		// memory with the original.
+		v := reflect.ValueOf(cpy.Data).Index(0)
+		if v.Kind() == reflect.Slice {
+			v = v.Index(0)
+		}
+		v.Set(reflect.Zero(v.Type()))
+		if reflect.DeepEqual(cpy.Data, data) {
+			t.Errorf("%T: copy shares memory with the original", data)
+		}
+	}
+}
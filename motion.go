@@ -0,0 +1,118 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "azul3d.org/lmath.v1"
+
+// UpdateMotion records the current world matrix of the object as it's
+// previous one (see the PrevModel field). It should be invoked once per frame
+// after the object has been drawn, such that the next frame's motion vectors
+// reflect the movement in between.
+//
+// The object's write lock must be held for this method to operate safely.
+func (o *Object) UpdateMotion() {
+	m := ConvertMat4(o.Transform.Mat4())
+	o.PrevModel = &m
+}
+
+// prevModel returns the previous world matrix of the object, or the current
+// one if it has none.
+func (o *Object) prevModel() lmath.Mat4 {
+	if o.PrevModel != nil {
+		return o.PrevModel.Mat4()
+	}
+	return o.Transform.Mat4()
+}
+
+// PrevMVP returns the previous model-view-projection matrix of the object as
+// viewed by the given camera, as supplied to shaders by renderers in the
+// PrevMVP uniform. It is the product of the previous world matrix of the
+// object and the previous view-projection matrix of the camera (see
+// Camera.ViewProjection), which is built from the camera's previous world
+// matrix and projection (or it's current ones, if the camera has no previous
+// world matrix).
+//
+// The read lock of both the object and the camera must be held for this
+// method to operate safely.
+func (o *Object) PrevMVP(c *Camera) lmath.Mat4 {
+	return o.prevModel().Mul(c.prevViewProjection())
+}
+
+// prevViewProjection returns the previous view-projection matrix of the
+// camera (see ViewProjection), or the current one if it has no previous world
+// matrix.
+func (c *Camera) prevViewProjection() lmath.Mat4 {
+	if c.PrevModel == nil {
+		return c.ViewProjection()
+	}
+	return viewProjection(c.Object.prevModel(), c.PrevProjection)
+}
+
+// UpdateMotion records the current world matrix and projection of the camera
+// as it's previous ones (see the PrevModel and PrevProjection fields). It
+// should be invoked once per frame after all objects have been drawn.
+//
+// The camera's write lock must be held for this method to operate safely.
+func (c *Camera) UpdateMotion() {
+	c.Object.UpdateMotion()
+	c.PrevProjection = c.Projection
+}
+
+// MotionVector returns the screen-space motion vector of the given point (in
+// the object's local space) between the previous and current frame, as
+// written to velocity buffers (see VelocityGLSLFrag). It is the difference
+// of the current and previous normalized device coordinates divided by two,
+// such that it is measured in texture coordinates (i.e. the width and height
+// of the screen are one).
+//
+// The read lock of both the object and the camera must be held for this
+// method to operate safely.
+func (o *Object) MotionVector(c *Camera, p lmath.Vec3) lmath.Vec2 {
+	mvp := o.Transform.Mat4().Mul(c.ViewProjection())
+	project := func(m lmath.Mat4) lmath.Vec2 {
+		clip := lmath.Vec4{p.X, p.Y, p.Z, 1}.Transform(m)
+		return lmath.Vec2{clip.X / clip.W, clip.Y / clip.W}
+	}
+	cur, prev := project(mvp), project(o.PrevMVP(c))
+	return cur.Sub(prev).MulScalar(0.5)
+}
+
+// VelocityGLSLVert is a GLSL 1.20 snippet for vertex shaders which output
+// motion vectors into a velocity buffer (see RTTConfig.Velocity), for
+// inclusion after the #version directive. It declares the PrevMVP uniform
+// (see Object.PrevMVP) and the velocityVertex function, which must be called
+// with the current clip-space position and the local vertex position:
+//  gl_Position = MVP * vec4(Vertex, 1.0);
+//  velocityVertex(gl_Position, Vertex);
+var VelocityGLSLVert = []byte(`
+uniform mat4 PrevMVP;
+
+varying vec4 velocityCurClip;
+varying vec4 velocityPrevClip;
+
+void velocityVertex(vec4 curClip, vec3 vertex)
+{
+	velocityCurClip = curClip;
+	velocityPrevClip = PrevMVP * vec4(vertex, 1.0);
+}
+`)
+
+// VelocityGLSLFrag is a GLSL 1.20 snippet for fragment shaders which output
+// motion vectors into a velocity buffer, for inclusion after the #version
+// directive. It declares the velocity function, which returns the motion
+// vector of the fragment (see Object.MotionVector), to be written to the
+// velocity output:
+//  gl_FragData[1] = vec4(velocity(), 0.0, 1.0);
+var VelocityGLSLFrag = []byte(`
+varying vec4 velocityCurClip;
+varying vec4 velocityPrevClip;
+
+vec2 velocity()
+{
+	vec2 cur = velocityCurClip.xy / velocityCurClip.w;
+	vec2 prev = velocityPrevClip.xy / velocityPrevClip.w;
+	return (cur - prev) * 0.5;
+}
+`)
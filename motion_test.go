@@ -0,0 +1,87 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestMotionVector(t *testing.T) {
+	cam := NewCamera()
+	cam.SetOrtho(image.Rect(0, 0, 100, 100), 0.1, 100)
+	cam.SetPos(lmath.Vec3{0, -10, 0})
+	o := NewObject()
+
+	// Without history nothing has moved.
+	if v := o.MotionVector(cam, lmath.Vec3{}); v != (lmath.Vec2{}) {
+		t.Fatalf("got motion %v without history, want zero", v)
+	}
+
+	cam.UpdateMotion()
+	o.UpdateMotion()
+	o.SetPos(lmath.Vec3{10, 0, 0})
+
+	// Moving the object 10 units right across a 100 unit wide orthographic
+	// view is a tenth of the screen.
+	v := o.MotionVector(cam, lmath.Vec3{})
+	if math.Abs(v.X-0.1) > 1e-6 || math.Abs(v.Y) > 1e-6 {
+		t.Fatalf("got motion %v, want {0.1 0}", v)
+	}
+
+	// Moving the camera along with the object cancels the motion.
+	cam.SetPos(lmath.Vec3{10, -10, 0})
+	if v := o.MotionVector(cam, lmath.Vec3{}); math.Abs(v.X) > 1e-6 || math.Abs(v.Y) > 1e-6 {
+		t.Fatalf("got motion %v following the object, want zero", v)
+	}
+
+	o.Reset()
+	if o.PrevModel != nil {
+		t.Fatal("Reset did not clear PrevModel")
+	}
+}
+
+func TestMotionVectorPersp(t *testing.T) {
+	cam := NewCamera()
+	cam.SetPersp(image.Rect(0, 0, 100, 100), 75, 0.1, 100)
+	cam.SetPos(lmath.Vec3{0, -10, 0})
+	o := NewObject()
+	o.SetPos(lmath.Vec3{1, 0, 1})
+	p := lmath.Vec3{0.5, 0.5, 0.5}
+
+	// A stationary object under a static camera has no motion, and it's
+	// previous position must project to the same point as it's current one.
+	cam.UpdateMotion()
+	o.UpdateMotion()
+	if v := o.MotionVector(cam, p); math.Abs(v.X) > 1e-6 || math.Abs(v.Y) > 1e-6 {
+		t.Fatalf("got motion %v of a stationary object, want zero", v)
+	}
+	want, _ := cam.Project(o.Transform.ConvertPos(p, LocalToWorld))
+	prev := lmath.Vec4{p.X, p.Y, p.Z, 1}.Transform(o.PrevMVP(cam))
+	got := lmath.Vec2{prev.X / prev.W, prev.Y / prev.W}
+	if math.Abs(got.X-want.X) > 1e-6 || math.Abs(got.Y-want.Y) > 1e-6 {
+		t.Fatalf("PrevMVP projects to %v, want %v", got, want)
+	}
+
+	// Moving the object right (and up) moves it right (and up) on screen.
+	o.SetPos(lmath.Vec3{2, 0, 2})
+	if v := o.MotionVector(cam, p); v.X <= 0 || v.Y <= 0 {
+		t.Fatalf("got motion %v of an object moving right and up, want positive", v)
+	}
+}
+
+func TestRTTConfigVelocity(t *testing.T) {
+	cfg := RTTConfig{Velocity: NewTexture(), Depth: NewTexture(), DepthFormat: Depth24}
+	if cfg.Valid() {
+		t.Fatal("Velocity without Color is valid")
+	}
+	cfg.Color, cfg.ColorFormat = NewTexture(), RGBA
+	if !cfg.Valid() {
+		t.Fatal("Velocity with Color is invalid")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"math"
+	"time"
+)
+
+// MotionSafety represents a set of options for reducing motion and flashing,
+// for players that are sensitive to them (e.g. those prone to motion
+// sickness or photosensitive epilepsy).
+//
+// All limits are driven by the single ReducedMotion flag: when it is false
+// each method returns it's input unchanged, and when it is true each value is
+// clamped to the respective limit.
+type MotionSafety struct {
+	// Whether or not reduced motion is enabled.
+	ReducedMotion bool
+
+	// The maximum amplitude of camera shake effects, in world units.
+	MaxShake float64
+
+	// The maximum number of flashes per second (e.g. lightning or muzzle
+	// flashes).
+	MaxFlashRate float64
+
+	// The maximum intensity of post-processing effects (e.g. motion blur,
+	// chromatic aberration, screen distortion) in the range of 0.0 to 1.0.
+	MaxEffectIntensity float64
+}
+
+// DefaultMotionSafety is the default set of motion safety options. Reduced
+// motion is disabled, and the limits follow the WCAG general flash threshold
+// of at most three flashes per second.
+var DefaultMotionSafety = MotionSafety{
+	ReducedMotion:      false,
+	MaxShake:           0.05,
+	MaxFlashRate:       3,
+	MaxEffectIntensity: 0.25,
+}
+
+// Shake returns the given camera shake amplitude, clamped to m.MaxShake if
+// reduced motion is enabled.
+func (m MotionSafety) Shake(amplitude float64) float64 {
+	if !m.ReducedMotion {
+		return amplitude
+	}
+	return math.Max(-m.MaxShake, math.Min(amplitude, m.MaxShake))
+}
+
+// EffectIntensity returns the given post-processing effect intensity, clamped
+// to m.MaxEffectIntensity if reduced motion is enabled.
+func (m MotionSafety) EffectIntensity(intensity float64) float64 {
+	if !m.ReducedMotion {
+		return intensity
+	}
+	return math.Min(intensity, m.MaxEffectIntensity)
+}
+
+// FlashInterval returns the given interval between flashes, raised to the
+// minimum interval implied by m.MaxFlashRate if reduced motion is enabled.
+func (m MotionSafety) FlashInterval(interval time.Duration) time.Duration {
+	if !m.ReducedMotion || m.MaxFlashRate <= 0 {
+		return interval
+	}
+	min := time.Duration(float64(time.Second) / m.MaxFlashRate)
+	if interval < min {
+		return min
+	}
+	return interval
+}
+
+// linearize converts a single sRGB-encoded color component in the range of 0.0
+// to 1.0 into linear RGB.
+func linearize(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// RelativeLuminance returns the relative luminance (in the range of 0.0 to
+// 1.0) of the given sRGB-encoded color, as defined by WCAG.
+func RelativeLuminance(c Color) float64 {
+	r := linearize(float64(c.R))
+	g := linearize(float64(c.G))
+	b := linearize(float64(c.B))
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
+
+// AverageLuminance returns the average relative luminance (see
+// RelativeLuminance) of all pixels in the given sRGB-encoded image, or zero
+// if the image is empty.
+func AverageLuminance(img image.Image) float64 {
+	b := img.Bounds()
+	if b.Empty() {
+		return 0
+	}
+	var sum float64
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			sum += RelativeLuminance(ColorModel.Convert(img.At(x, y)).(Color))
+		}
+	}
+	return sum / float64(b.Dx()*b.Dy())
+}
+
+// FlashAnalyzer detects sequences of frames which flash at a rate that may be
+// harmful to photosensitive viewers. It follows the WCAG definition of a
+// flash: a pair of opposing changes in relative luminance of Threshold or
+// more, where the darker of the two is below 0.80.
+//
+// Typically frames are downloaded from a canvas (see the Downloadable
+// interface) and passed into the AddFrame method along with the time they
+// were rendered at (e.g. from the renderer's clock).
+type FlashAnalyzer struct {
+	// The minimum change in relative luminance that is considered a
+	// transition.
+	Threshold float64
+
+	// The maximum number of flashes that may occur within Window before the
+	// sequence is considered risky.
+	MaxFlashes int
+
+	// The time window over which flashes are counted.
+	Window time.Duration
+
+	started     bool
+	ref         float64
+	dir         int
+	transitions []time.Duration
+}
+
+// NewFlashAnalyzer returns a new flash analyzer using the WCAG general flash
+// threshold (a luminance change of 10%, at most three flashes in any one
+// second period).
+func NewFlashAnalyzer() *FlashAnalyzer {
+	return &FlashAnalyzer{
+		Threshold:  0.1,
+		MaxFlashes: 3,
+		Window:     time.Second,
+	}
+}
+
+// AddFrame is short-hand for:
+//  a.AddLuminance(AverageLuminance(img), t)
+func (a *FlashAnalyzer) AddFrame(img image.Image, t time.Duration) bool {
+	return a.AddLuminance(AverageLuminance(img), t)
+}
+
+// AddLuminance adds a frame, with the given average relative luminance and
+// rendered at time t, to the analyzed sequence. It returns true if the
+// sequence of frames within a.Window up to and including this one contains
+// more than a.MaxFlashes flashes.
+//
+// Frames must be added in chronological order.
+func (a *FlashAnalyzer) AddLuminance(l float64, t time.Duration) bool {
+	if !a.started {
+		a.started = true
+		a.ref = l
+		return false
+	}
+
+	// Extend the current transition if the luminance keeps moving in the same
+	// direction.
+	if (a.dir > 0 && l > a.ref) || (a.dir < 0 && l < a.ref) {
+		a.ref = l
+	}
+
+	delta := l - a.ref
+	darker := math.Min(l, a.ref)
+	if math.Abs(delta) >= a.Threshold && darker < 0.80 {
+		dir := 1
+		if delta < 0 {
+			dir = -1
+		}
+		if dir != a.dir {
+			a.transitions = append(a.transitions, t)
+			a.dir = dir
+		}
+		a.ref = l
+	}
+
+	// Forget transitions that are outside of the window.
+	n := 0
+	for _, tt := range a.transitions {
+		if t-tt < a.Window {
+			a.transitions[n] = tt
+			n++
+		}
+	}
+	a.transitions = a.transitions[:n]
+
+	// Each flash is a pair of opposing transitions.
+	return len(a.transitions)/2 > a.MaxFlashes
+}
+
+// Reset resets the analyzer such that it begins analyzing a new sequence of
+// frames.
+func (a *FlashAnalyzer) Reset() {
+	a.started = false
+	a.ref = 0
+	a.dir = 0
+	a.transitions = a.transitions[:0]
+}
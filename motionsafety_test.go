@@ -0,0 +1,38 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlashAnalyzer(t *testing.T) {
+	frame := time.Second / 30
+
+	// Alternating black and white frames at 30 FPS is a risky sequence.
+	a := NewFlashAnalyzer()
+	risky := false
+	for i := 0; i < 30; i++ {
+		l := 0.0
+		if i%2 == 0 {
+			l = 1.0
+		}
+		if a.AddLuminance(l, time.Duration(i)*frame) {
+			risky = true
+		}
+	}
+	if !risky {
+		t.Fatal("expected alternating frames to be risky")
+	}
+
+	// A slow fade is not.
+	a.Reset()
+	for i := 0; i < 30; i++ {
+		if a.AddLuminance(float64(i)/30, time.Duration(i)*frame) {
+			t.Fatal("expected fade to not be risky")
+		}
+	}
+}
@@ -7,6 +7,7 @@ package gfx
 import (
 	"image"
 	"sync"
+	"time"
 
 	"azul3d.org/clock.v1"
 )
@@ -38,6 +39,16 @@ type nilNativeShader struct{}
 
 func (n nilNativeShader) Destroy() {}
 
+type nilFence struct{}
+
+func (n nilFence) Destroy() {}
+func (n nilFence) Signaled() bool {
+	return true
+}
+func (n nilFence) Wait(timeout time.Duration) bool {
+	return true
+}
+
 type nilRenderer struct {
 	// The MSAA state.
 	msaa struct {
@@ -45,8 +56,26 @@ type nilRenderer struct {
 		enabled bool
 	}
 
+	// The sRGB state.
+	srgb struct {
+		sync.RWMutex
+		enabled bool
+	}
+
+	// The output transform.
+	output struct {
+		sync.RWMutex
+		transform OutputTransform
+	}
+
 	precision Precision
 
+	// The frame hooks.
+	hooks FrameHooks
+
+	// The frame lifecycle events.
+	events FrameEvents
+
 	// The graphics clock.
 	clock *clock.Clock
 }
@@ -65,14 +94,45 @@ func (n *nilRenderer) Precision() Precision {
 
 func (n *nilRenderer) GPUInfo() GPUInfo {
 	return GPUInfo{
-		MaxTextureSize:  8096,
-		AlphaToCoverage: true,
-		OcclusionQuery:  false,
+		MaxTextureSize:   8096,
+		AlphaToCoverage:  true,
+		OcclusionQuery:   false,
+		MaxDrawBuffers:   1,
+		SRGB:             true,
+		MaxClipDistances: 8,
+		MaxTextureLayers: 256,
+		MaxTexture3DSize: 256,
+		SeamlessCubeMap:  true,
+		MaxAnisotropy:    16,
+		TexSwizzle:       true,
+
+		MaxBufferTextureSize: 65536,
+		TexFormats: []TexFormat{
+			RGBA, RGB,
+			DXT1, DXT1RGBA, DXT3, DXT5,
+			ETC2, ETC2RGBA, ASTC4x4, ASTC8x8,
+			RGBA16F, RGBA32F, R11G11B10F,
+		},
 	}
 }
+func (n *nilRenderer) PipelineStats() PipelineStats {
+	return PipelineStats{}
+}
+func (n *nilRenderer) SwapChain() SwapChainInfo {
+	return SwapChainInfo{}
+}
+func (n *nilRenderer) Hooks() *FrameHooks {
+	return &n.hooks
+}
+func (n *nilRenderer) Events() *FrameEvents {
+	return &n.events
+}
 func (n *nilRenderer) Download(r image.Rectangle, complete chan image.Image) {
 	complete <- nil
 }
+func (n *nilRenderer) DownloadDepth(r image.Rectangle, complete chan []float32) {
+	complete <- nil
+}
 func (n *nilRenderer) SetMSAA(msaa bool) {
 	n.msaa.Lock()
 	n.msaa.enabled = msaa
@@ -84,6 +144,28 @@ func (n *nilRenderer) MSAA() (msaa bool) {
 	n.msaa.RUnlock()
 	return
 }
+func (n *nilRenderer) SetSRGB(srgb bool) {
+	n.srgb.Lock()
+	n.srgb.enabled = srgb
+	n.srgb.Unlock()
+}
+func (n *nilRenderer) SRGB() (srgb bool) {
+	n.srgb.RLock()
+	srgb = n.srgb.enabled
+	n.srgb.RUnlock()
+	return
+}
+func (n *nilRenderer) SetOutputTransform(t OutputTransform) {
+	n.output.Lock()
+	n.output.transform = t
+	n.output.Unlock()
+}
+func (n *nilRenderer) OutputTransform() (t OutputTransform) {
+	n.output.RLock()
+	t = n.output.transform
+	n.output.RUnlock()
+	return
+}
 func (n *nilRenderer) Clear(r image.Rectangle, bg Color)           {}
 func (n *nilRenderer) ClearDepth(r image.Rectangle, depth float64) {}
 func (n *nilRenderer) ClearStencil(r image.Rectangle, stencil int) {}
@@ -94,7 +176,25 @@ func (n *nilRenderer) Draw(r image.Rectangle, o *Object, c *Camera) {
 	o.Unlock()
 }
 func (n *nilRenderer) QueryWait() {}
+func (n *nilRenderer) InsertFence() Fence {
+	return nilFence{}
+}
+func (n *nilRenderer) WaitFence(f Fence)      {}
+func (n *nilRenderer) BeginTimer(name string) {}
+func (n *nilRenderer) EndTimer(name string)   {}
+func (n *nilRenderer) Timers() map[string]time.Duration {
+	return nil
+}
+func (n *nilRenderer) Feedback(o *Object, c *Camera, complete chan []Vec3) {
+	complete <- nil
+}
+func (n *nilRenderer) ResolveTo(dst Canvas) {}
 func (n *nilRenderer) Render() {
+	n.events.Emit(FrameBegin)
+	n.events.Emit(FramePrePresent)
+	n.hooks.Run(PrePresent, n)
+	n.hooks.Run(PostPresent, n)
+	n.events.Emit(FrameEnd)
 	n.clock.Tick()
 }
 
@@ -122,6 +222,9 @@ func (n *nilRenderer) LoadTexture(t *Texture, done chan *Texture) {
 	default:
 	}
 }
+func (n *nilRenderer) DownloadTexture(t *Texture, complete chan image.Image) {
+	complete <- nil
+}
 func (n *nilRenderer) LoadShader(s *Shader, done chan *Shader) {
 	s.Lock()
 	s.Loaded = true
@@ -150,6 +253,7 @@ func Nil() Renderer {
 		StencilBits: 255,
 	}
 	r.msaa.enabled = true
+	r.output.transform = DefaultOutputTransform
 	r.clock = clock.New()
 	return r
 }
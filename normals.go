@@ -0,0 +1,172 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"reflect"
+
+	"azul3d.org/lmath.v1"
+)
+
+// triangleCorners returns the vertex indices of each corner of each triangle
+// of the mesh, three per triangle, or nil if the mesh's primitive is not
+// Triangles.
+func (m *Mesh) triangleCorners() []uint32 {
+	if m.Primitive != Triangles {
+		return nil
+	}
+	if len(m.Indices) > 0 {
+		return m.Indices[:len(m.Indices)-len(m.Indices)%3]
+	}
+	n := len(m.Vertices) - len(m.Vertices)%3
+	corners := make([]uint32, n)
+	for i := range corners {
+		corners[i] = uint32(i)
+	}
+	return corners
+}
+
+// duplicateVertex appends a copy of the data of vertex i to each per-vertex
+// data slice of the mesh (marking them as changed), and returns the index of
+// the new vertex.
+func (m *Mesh) duplicateVertex(i uint32) uint32 {
+	m.Vertices = append(m.Vertices, m.Vertices[i])
+	m.VerticesChanged = true
+	if int(i) < len(m.Colors) {
+		m.Colors = append(m.Colors, m.Colors[i])
+		m.ColorsChanged = true
+	}
+	if int(i) < len(m.Bary) {
+		m.Bary = append(m.Bary, m.Bary[i])
+		m.BaryChanged = true
+	}
+	for s := range m.TexCoords {
+		set := &m.TexCoords[s]
+		if int(i) < len(set.Slice) {
+			set.Slice = append(set.Slice, set.Slice[i])
+			set.Changed = true
+		}
+	}
+	dup := func(data reflect.Value) reflect.Value {
+		if int(i) < data.Len() {
+			data = reflect.Append(data, data.Index(int(i)))
+		}
+		return data
+	}
+	for name, a := range m.Attribs {
+		v := reflect.ValueOf(a.Data)
+		if v.Kind() != reflect.Slice {
+			continue
+		}
+		if v.Type().Elem().Kind() == reflect.Slice {
+			// Arrays of data, e.g. [][]gfx.Vec3.
+			for j := 0; j < v.Len(); j++ {
+				v.Index(j).Set(dup(v.Index(j)))
+			}
+		} else {
+			v = dup(v)
+		}
+		m.Attribs[name] = VertexAttrib{Data: v.Interface(), Changed: true}
+	}
+	return uint32(len(m.Vertices) - 1)
+}
+
+// GenerateNormals generates per-vertex normals for this mesh and stores them
+// as the []gfx.Vec3 custom attribute named "Normal" (see the Attribs field),
+// accessed in GLSL using:
+//  attribute vec3 Normal;
+//
+// The normal of each vertex is the area-weighted average of the normals of
+// the triangles sharing it's position whose normals are within the given
+// smoothing angle (in degrees) of each other. For example an angle of zero
+// produces flat shading, 180 smooths across every edge, and a typical value
+// like 60 keeps the edges of a cube sharp while smoothing a sphere.
+//
+// If a vertex of an indexed mesh is shared by triangles that require
+// different normals (i.e. it lies on a sharp edge) then the vertex is
+// duplicated, the indices are updated, and all per-vertex data slices are
+// marked as changed.
+//
+// Only meshes whose primitive is Triangles are supported, any other mesh is
+// left unchanged.
+//
+// The mesh's write lock must be held for this method to operate safely.
+func (m *Mesh) GenerateNormals(smoothingAngle float64) {
+	corners := m.triangleCorners()
+	if corners == nil {
+		return
+	}
+
+	// Calculate the area-weighted and unit normals of each triangle.
+	nFaces := len(corners) / 3
+	weighted := make([]lmath.Vec3, nFaces)
+	unit := make([]lmath.Vec3, nFaces)
+	for f := range weighted {
+		p0 := m.Vertices[corners[3*f]].Vec3()
+		p1 := m.Vertices[corners[3*f+1]].Vec3()
+		p2 := m.Vertices[corners[3*f+2]].Vec3()
+		weighted[f] = p1.Sub(p0).Cross(p2.Sub(p0))
+		if l := weighted[f].Length(); l > 0 {
+			unit[f] = weighted[f].DivScalar(l)
+		}
+	}
+
+	// Group the corners by position, such that normals are smoothed across
+	// vertices which are split (e.g. due to differing texture coordinates).
+	byPos := make(map[Vec3][]int, len(m.Vertices))
+	for c, vi := range corners {
+		p := m.Vertices[vi]
+		byPos[p] = append(byPos[p], c/3)
+	}
+
+	cosAngle := math.Cos(lmath.Radians(smoothingAngle)) - 1e-9
+	normals := make([]Vec3, len(m.Vertices))
+	assigned := make([]bool, len(m.Vertices))
+	splits := make(map[uint32][]uint32)
+	for c, vi := range corners {
+		f := c / 3
+		var sum lmath.Vec3
+		for _, other := range byPos[m.Vertices[vi]] {
+			if other == f || unit[f].Dot(unit[other]) >= cosAngle {
+				sum = sum.Add(weighted[other])
+			}
+		}
+		if l := sum.Length(); l > 0 {
+			sum = sum.DivScalar(l)
+		}
+		n := ConvertVec3(sum)
+
+		switch {
+		case !assigned[vi]:
+			normals[vi] = n
+			assigned[vi] = true
+		case normals[vi] != n:
+			// The vertex requires a different normal for this triangle, find
+			// or create a copy of it with this normal.
+			var dup uint32
+			found := false
+			for _, s := range splits[vi] {
+				if normals[s] == n {
+					dup, found = s, true
+					break
+				}
+			}
+			if !found {
+				dup = m.duplicateVertex(vi)
+				normals = append(normals, n)
+				assigned = append(assigned, true)
+				splits[vi] = append(splits[vi], dup)
+			}
+			m.Indices[c] = dup
+			m.IndicesChanged = true
+		}
+	}
+
+	if m.Attribs == nil {
+		m.Attribs = make(map[string]VertexAttrib)
+	}
+	m.Attribs["Normal"] = VertexAttrib{Data: normals, Changed: true}
+}
@@ -0,0 +1,68 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+)
+
+// foldMesh returns an indexed mesh of two triangles sharing the edge along the
+// Y axis, folded by 90 degrees.
+func foldMesh() *Mesh {
+	m := NewMesh()
+	m.Vertices = []Vec3{
+		{0, 0, 0}, {0, 1, 0}, // Shared edge.
+		{1, 0, 0}, // In the Z=0 plane.
+		{0, 0, 1}, // In the X=0 plane.
+	}
+	m.Colors = []Color{{1, 0, 0, 1}, {0, 1, 0, 1}, {0, 0, 1, 1}, {1, 1, 1, 1}}
+	m.Indices = []uint32{0, 2, 1, 0, 1, 3}
+	return m
+}
+
+func vec3Near(a, b Vec3) bool {
+	const eps = 1e-6
+	return math.Abs(float64(a.X-b.X)) < eps &&
+		math.Abs(float64(a.Y-b.Y)) < eps &&
+		math.Abs(float64(a.Z-b.Z)) < eps
+}
+
+func TestGenerateNormalsSmooth(t *testing.T) {
+	m := foldMesh()
+	m.GenerateNormals(180)
+	if len(m.Vertices) != 4 {
+		t.Fatalf("got %d vertices, want 4", len(m.Vertices))
+	}
+	normals := m.Attribs["Normal"].Data.([]Vec3)
+	s := float32(1 / math.Sqrt2)
+	if !vec3Near(normals[0], Vec3{s, 0, s}) {
+		t.Fatalf("shared vertex normal %v, want %v", normals[0], Vec3{s, 0, s})
+	}
+	if !vec3Near(normals[2], Vec3{0, 0, 1}) {
+		t.Fatalf("vertex 2 normal %v, want +Z", normals[2])
+	}
+}
+
+func TestGenerateNormalsSharp(t *testing.T) {
+	m := foldMesh()
+	m.GenerateNormals(60)
+	if len(m.Vertices) != 6 || len(m.Colors) != 6 {
+		t.Fatalf("got %d vertices %d colors, want 6", len(m.Vertices), len(m.Colors))
+	}
+	if !m.IndicesChanged {
+		t.Fatal("IndicesChanged = false after splitting vertices")
+	}
+	normals := m.Attribs["Normal"].Data.([]Vec3)
+	want := []Vec3{{0, 0, 1}, {0, 0, 1}, {0, 0, 1}, {1, 0, 0}, {1, 0, 0}, {1, 0, 0}}
+	for c, vi := range m.Indices {
+		if !vec3Near(normals[vi], want[c]) {
+			t.Fatalf("corner %d normal %v, want %v", c, normals[vi], want[c])
+		}
+		if m.Vertices[vi] != foldMesh().Vertices[foldMesh().Indices[c]] {
+			t.Fatalf("corner %d moved to %v", c, m.Vertices[vi])
+		}
+	}
+}
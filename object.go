@@ -58,12 +58,60 @@ type Object struct {
 	// SampleCount() method of NativeObject.
 	OcclusionTest bool
 
+	// If non-nil, the object is drawn conditionally based on the result of
+	// the most recent occlusion query of the condition object (which must have
+	// OcclusionTest set to true): if no samples of the condition object passed
+	// the depth and stencil tests, this object is not drawn.
+	//
+	// The decision is made entirely on the GPU (i.e. conditional rendering),
+	// avoiding the latency of reading back the query result on the CPU. A
+	// typical use is to draw a cheap bounding box as the condition object and
+	// the expensive object conditionally on it.
+	//
+	// If the GPU does not support conditional rendering (see
+	// GPUInfo.ConditionalRender) then the renderer falls back to using the
+	// condition object's last available sample count (see SampleCount).
+	Condition *Object
+
+	// The category of the object (e.g. "foliage" or "props"), which
+	// determines it's maximum draw distance (see DrawDistances).
+	Category string
+
+	// The fade factor of the object, from 0.0 (invisible) to 1.0 (fully
+	// visible), which renderers supply to the object's shader as the float
+	// uniform named Fade. Shaders may implement fading by alpha blending or by
+	// discarding fragments using a dither pattern. It is typically set by
+	// DrawDistances.Cull as objects approach their maximum draw distance.
+	Fade float32
+
+	// The transformation of the texture coordinates of the object, which
+	// renderers supply to the object's shader as the mat4 uniform named
+	// UVTransform (see the UVTransform.Mat4 method), such that scrolling,
+	// tiling, and atlas frame selection are applied in the vertex shader.
+	UVTransform UVTransform
+
+	// The per-instance tint color of the object, which is sRGB-encoded (i.e.
+	// as chosen with a color picker). Renderers convert it into linear space
+	// and supply it to the object's shader as the vec4 uniform named Tint, see
+	// ApplyTint for how it is applied.
+	Tint Color
+
 	// The render state of this object.
 	State
 
 	// The transformation of the object.
 	*Transform
 
+	// The world matrix of the object as of the previous frame, which is used
+	// to calculate per-pixel motion vectors (e.g. for temporal anti-aliasing
+	// and motion blur). Renderers supply the previous model-view-projection
+	// matrix to the object's shader as the mat4 uniform named PrevMVP (see
+	// the PrevMVP method and VelocityGLSLVert).
+	//
+	// If nil the object is considered to not have moved. It is typically
+	// updated once per frame, after drawing, using UpdateMotion.
+	PrevModel *Mat4
+
 	// The shader program to be used during rendering the object.
 	*Shader
 
@@ -82,6 +130,12 @@ type Object struct {
 	// in which they are sent to the graphics card.
 	Textures []*Texture
 
+	// An optional slice of samplers, parallel to the Textures slice, which
+	// override the sampling state of the texture at the same index. A nil
+	// sampler (or a texture whose index is beyond the length of this slice)
+	// is sampled using the texture's own sampling state.
+	Samplers []*Sampler
+
 	// CachedBounds represents the pre-calculated cached bounding box of this
 	// object. Note that the bounds are only calculated once Object.Bounds() is
 	// invoked.
@@ -99,7 +153,9 @@ type Object struct {
 }
 
 // Bounds implements the Boundable interface. The returned bounding box takes
-// into account all of the mesh's bounding boxes, transformed into world space.
+// into account all of the mesh's bounding boxes, transformed into world space
+// using TransformRect3s (such that it contains every corner of the rotated
+// box).
 //
 // The bounding box is cached (see o.CachedBounds) so that multiple calls to
 // this method are fast. If you make changes to the vertices, or add/remove
@@ -114,34 +170,41 @@ type Object struct {
 //
 // This method properly write-locks the object.
 func (o *Object) Bounds() lmath.Rect3 {
-	var b lmath.Rect3
 	o.Lock()
+	b := []lmath.Rect3{o.localBounds()}
+	if o.Transform != nil {
+		TransformRect3s(b, b, []lmath.Mat4{o.Transform.Mat4()})
+	}
+	o.Unlock()
+	return b[0]
+}
+
+// localBounds returns the untransformed bounding box of the object, which is
+// calculated from it's meshes and cached (see o.CachedBounds) if needed.
+//
+// The object's write lock must be held for this method to operate safely.
+func (o *Object) localBounds() lmath.Rect3 {
 	// Do we have a cached bounding box? If so, use it.
 	if o.CachedBounds != nil {
-		b = *o.CachedBounds
-	} else {
-		// Calculate the bounding box then.
-		for i, m := range o.Meshes {
-			if i == 0 {
-				b = m.Bounds()
-			} else {
-				b = b.Union(m.Bounds())
-			}
-		}
-
-		// Make a copy of the untransformed bounding box and cache it for
-		// later. We don't cache the transformed bounding box because otherwise
-		// we would need to recalculate the bounding box every time the object
-		// is moved, scaled, etc.
-		cpy := b
-		o.CachedBounds = &cpy
+		return *o.CachedBounds
 	}
-	if o.Transform != nil {
-		b.Min = o.Transform.ConvertPos(b.Min, LocalToWorld)
-		b.Max = o.Transform.ConvertPos(b.Max, LocalToWorld)
-		b = b.Union(b)
+
+	// Calculate the bounding box then.
+	var b lmath.Rect3
+	for i, m := range o.Meshes {
+		if i == 0 {
+			b = m.Bounds()
+		} else {
+			b = b.Union(m.Bounds())
+		}
 	}
-	o.Unlock()
+
+	// Make a copy of the untransformed bounding box and cache it for later.
+	// We don't cache the transformed bounding box because otherwise we would
+	// need to recalculate the bounding box every time the object is moved,
+	// scaled, etc.
+	cpy := b
+	o.CachedBounds = &cpy
 	return b
 }
 
@@ -177,18 +240,35 @@ func (o *Object) Compare(other *Object) bool {
 //
 // The object's read lock must be held for this method to operate safely.
 func (o *Object) Copy() *Object {
-	cpyCachedBounds := *o.CachedBounds
+	var cpyCachedBounds *lmath.Rect3
+	if o.CachedBounds != nil {
+		b := *o.CachedBounds
+		cpyCachedBounds = &b
+	}
+	var cpyPrevModel *Mat4
+	if o.PrevModel != nil {
+		m := *o.PrevModel
+		cpyPrevModel = &m
+	}
 	cpy := &Object{
 		OcclusionTest: o.OcclusionTest,
+		Condition:     o.Condition,
+		Category:      o.Category,
+		Fade:          o.Fade,
+		UVTransform:   o.UVTransform,
+		Tint:          o.Tint,
 		State:         o.State,
 		Transform:     o.Transform.Copy(),
+		PrevModel:     cpyPrevModel,
 		Shader:        o.Shader,
 		Meshes:        make([]*Mesh, len(o.Meshes)),
 		Textures:      make([]*Texture, len(o.Textures)),
-		CachedBounds:  &cpyCachedBounds,
+		Samplers:      make([]*Sampler, len(o.Samplers)),
+		CachedBounds:  cpyCachedBounds,
 	}
 	copy(cpy.Meshes, o.Meshes)
 	copy(cpy.Textures, o.Textures)
+	copy(cpy.Samplers, o.Samplers)
 	return cpy
 }
 
@@ -198,8 +278,14 @@ func (o *Object) Copy() *Object {
 func (o *Object) Reset() {
 	o.NativeObject = nil
 	o.OcclusionTest = false
+	o.Condition = nil
+	o.Category = ""
+	o.Fade = 1
+	o.UVTransform = IdentityUV
+	o.Tint = Color{1, 1, 1, 1}
 	o.State = DefaultState
 	o.Transform = NewTransform()
+	o.PrevModel = nil
 	o.Shader = nil
 	o.CachedBounds = nil
 
@@ -214,6 +300,12 @@ func (o *Object) Reset() {
 		o.Textures[i] = nil
 	}
 	o.Textures = o.Textures[:0]
+
+	// Nil out each sampler pointer.
+	for i := 0; i < len(o.Samplers); i++ {
+		o.Samplers[i] = nil
+	}
+	o.Samplers = o.Samplers[:0]
 }
 
 // Destroy destroys this object for use by other callees to NewObject. You must
@@ -232,13 +324,19 @@ func (o *Object) Destroy() {
 var objPool = sync.Pool{
 	New: func() interface{} {
 		return &Object{
-			State:     DefaultState,
-			Transform: NewTransform(),
+			Fade:        1,
+			UVTransform: IdentityUV,
+			Tint:        Color{1, 1, 1, 1},
+			State:       DefaultState,
+			Transform:   NewTransform(),
 		}
 	},
 }
 
 // NewObject creates and returns a new object with:
+//  o.Fade == 1
+//  o.UVTransform == IdentityUV
+//  o.Tint == Color{1, 1, 1, 1}
 //  o.State == DefaultState
 //  o.Transform == DefaultTransform
 func NewObject() *Object {
@@ -0,0 +1,34 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestObjectBoundsRotated(t *testing.T) {
+	m := NewMesh()
+	m.Vertices = []Vec3{{-1, -1, -1}, {1, 1, 1}}
+	o := NewObject()
+	o.Meshes = []*Mesh{m}
+	o.SetPos(lmath.Vec3{5, 0, 0})
+	o.SetRot(lmath.Vec3{0, 0, 45})
+
+	// Rotating the cube by 45 degrees moves it's corners out to sqrt(2) along
+	// the X and Y axes, which the transformed Min and Max alone do not reach.
+	r := math.Sqrt2
+	want := lmath.Rect3{
+		Min: lmath.Vec3{5 - r, -r, -1},
+		Max: lmath.Vec3{5 + r, r, 1},
+	}
+	if got := o.Bounds(); !got.Min.Equals(want.Min) || !got.Max.Equals(want.Max) {
+		t.Log("got", got)
+		t.Log("want", want)
+		t.Fail()
+	}
+}
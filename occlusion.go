@@ -0,0 +1,199 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// Occluder is a baked (i.e. world-space) set of triangles that occlude other
+// objects, typically a low-poly version of large static geometry like walls
+// and terrain. See the OcclusionBuffer type.
+type Occluder struct {
+	// The world-space triangles of the occluder, three vertices per triangle.
+	Triangles []lmath.Vec3
+}
+
+// BakeOccluder bakes the triangles of the given mesh, transformed by the
+// given model (local-to-world) matrix, into an occluder. Only meshes whose
+// primitive is Triangles are supported, any other mesh results in an empty
+// occluder.
+//
+// The mesh's read lock must be held for this function to operate safely.
+func BakeOccluder(m *Mesh, model lmath.Mat4) *Occluder {
+	o := new(Occluder)
+	if m.Primitive != Triangles {
+		return o
+	}
+	vert := func(v Vec3) lmath.Vec3 {
+		return lmath.Vec3{float64(v.X), float64(v.Y), float64(v.Z)}.TransformMat4(model)
+	}
+	if len(m.Indices) > 0 {
+		n := len(m.Indices) - len(m.Indices)%3
+		o.Triangles = make([]lmath.Vec3, 0, n)
+		for _, i := range m.Indices[:n] {
+			o.Triangles = append(o.Triangles, vert(m.Vertices[i]))
+		}
+		return o
+	}
+	n := len(m.Vertices) - len(m.Vertices)%3
+	o.Triangles = make([]lmath.Vec3, 0, n)
+	for _, v := range m.Vertices[:n] {
+		o.Triangles = append(o.Triangles, vert(v))
+	}
+	return o
+}
+
+// OcclusionBuffer is a small CPU-side depth buffer into which occluders are
+// rasterized, such that objects hidden behind them can be culled before they
+// are submitted for drawing. Unlike hardware occlusion queries (see
+// Object.OcclusionTest) the results are available immediately, without any
+// latency.
+//
+// The buffer is conservative: objects are only reported as occluded if they
+// are certainly hidden behind the occluders.
+type OcclusionBuffer struct {
+	// The width and height of the buffer in pixels, typically much smaller
+	// than the screen (e.g. 256x128).
+	Width, Height int
+
+	depth []float64
+}
+
+// NewOcclusionBuffer returns a new cleared occlusion buffer of the given
+// size.
+func NewOcclusionBuffer(width, height int) *OcclusionBuffer {
+	b := &OcclusionBuffer{
+		Width:  width,
+		Height: height,
+		depth:  make([]float64, width*height),
+	}
+	b.Clear()
+	return b
+}
+
+// Clear clears the buffer, such that nothing is occluded. It should be called
+// once per frame before drawing the occluders.
+func (b *OcclusionBuffer) Clear() {
+	for i := range b.depth {
+		b.depth[i] = math.Inf(1)
+	}
+}
+
+// project projects p by the view-projection matrix into window coordinates
+// of the buffer (x and y in pixels, z in the range of -1 to 1). If ok is false
+// then the point is behind the eye.
+func (b *OcclusionBuffer) project(vp lmath.Mat4, p lmath.Vec3) (win lmath.Vec3, ok bool) {
+	x := p.X*vp[0][0] + p.Y*vp[1][0] + p.Z*vp[2][0] + vp[3][0]
+	y := p.X*vp[0][1] + p.Y*vp[1][1] + p.Z*vp[2][1] + vp[3][1]
+	z := p.X*vp[0][2] + p.Y*vp[1][2] + p.Z*vp[2][2] + vp[3][2]
+	w := p.X*vp[0][3] + p.Y*vp[1][3] + p.Z*vp[2][3] + vp[3][3]
+	if w <= 0 || z < -w {
+		return win, false
+	}
+	win.X = (x/w + 1) * 0.5 * float64(b.Width)
+	win.Y = (y/w + 1) * 0.5 * float64(b.Height)
+	win.Z = z / w
+	return win, true
+}
+
+// Draw rasterizes the triangles of the given occluder into the buffer using
+// the given view-projection matrix. Triangles that cross the near plane are
+// skipped (which is conservative, as they occlude nothing).
+func (b *OcclusionBuffer) Draw(o *Occluder, vp lmath.Mat4) {
+	for i := 0; i+2 < len(o.Triangles); i += 3 {
+		v0, ok0 := b.project(vp, o.Triangles[i])
+		v1, ok1 := b.project(vp, o.Triangles[i+1])
+		v2, ok2 := b.project(vp, o.Triangles[i+2])
+		if ok0 && ok1 && ok2 {
+			b.triangle(v0, v1, v2)
+		}
+	}
+}
+
+func edge(a, b lmath.Vec3, x, y float64) float64 {
+	return (b.X-a.X)*(y-a.Y) - (b.Y-a.Y)*(x-a.X)
+}
+
+// triangle rasterizes a single window-space triangle, writing the nearest
+// depth of each pixel whose center is covered.
+func (b *OcclusionBuffer) triangle(v0, v1, v2 lmath.Vec3) {
+	area := edge(v0, v1, v2.X, v2.Y)
+	if area == 0 {
+		return
+	}
+	minX := int(math.Max(0, math.Floor(math.Min(v0.X, math.Min(v1.X, v2.X)))))
+	minY := int(math.Max(0, math.Floor(math.Min(v0.Y, math.Min(v1.Y, v2.Y)))))
+	maxX := int(math.Min(float64(b.Width-1), math.Ceil(math.Max(v0.X, math.Max(v1.X, v2.X)))))
+	maxY := int(math.Min(float64(b.Height-1), math.Ceil(math.Max(v0.Y, math.Max(v1.Y, v2.Y)))))
+	for y := minY; y <= maxY; y++ {
+		py := float64(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := float64(x) + 0.5
+			w0 := edge(v1, v2, px, py) / area
+			w1 := edge(v2, v0, px, py) / area
+			w2 := edge(v0, v1, px, py) / area
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+			z := w0*v0.Z + w1*v1.Z + w2*v2.Z
+			if i := y*b.Width + x; z < b.depth[i] {
+				b.depth[i] = z
+			}
+		}
+	}
+}
+
+// Visible tells if the given world-space bounding box is (potentially)
+// visible, i.e. not entirely hidden behind the occluders drawn into the buffer
+// using the same view-projection matrix. Boxes entirely outside of the buffer
+// are not visible.
+func (b *OcclusionBuffer) Visible(r lmath.Rect3, vp lmath.Mat4) bool {
+	minX, minY, minZ := math.Inf(1), math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	corners := rect3Corners(r)
+	behind := 0
+	for _, c := range corners {
+		win, ok := b.project(vp, c)
+		if !ok {
+			behind++
+			continue
+		}
+		minX, maxX = math.Min(minX, win.X), math.Max(maxX, win.X)
+		minY, maxY = math.Min(minY, win.Y), math.Max(maxY, win.Y)
+		minZ = math.Min(minZ, win.Z)
+	}
+	if behind == len(corners) {
+		return false
+	} else if behind > 0 {
+		// Crosses the near plane, so it may cover the entire screen.
+		return true
+	}
+	x0 := int(math.Max(0, math.Floor(minX)))
+	y0 := int(math.Max(0, math.Floor(minY)))
+	x1 := int(math.Min(float64(b.Width-1), math.Ceil(maxX)))
+	y1 := int(math.Min(float64(b.Height-1), math.Ceil(maxY)))
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			if minZ <= b.depth[y*b.Width+x] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Cull appends the objects that are visible according to the Visible method
+// to dst and returns the extended slice.
+func (b *OcclusionBuffer) Cull(dst, objs []*Object, vp lmath.Mat4) []*Object {
+	for _, o := range objs {
+		if b.Visible(o.Bounds(), vp) {
+			dst = append(dst, o)
+		}
+	}
+	return dst
+}
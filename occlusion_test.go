@@ -0,0 +1,53 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestOcclusionBuffer(t *testing.T) {
+	// A 4x4 wall at z=-5 in front of the eye (at the origin, looking down -Z).
+	wall := NewMesh()
+	wall.Vertices = []Vec3{
+		{-2, -2, -5}, {2, -2, -5}, {2, 2, -5},
+		{-2, -2, -5}, {2, 2, -5}, {-2, 2, -5},
+	}
+	occ := BakeOccluder(wall, lmath.Mat4Identity)
+
+	vp := lmath.Mat4Perspective(90, 1, 0.1, 100)
+	b := NewOcclusionBuffer(64, 64)
+	b.Draw(occ, vp)
+
+	box := func(x, z float64) lmath.Rect3 {
+		return lmath.Rect3{
+			Min: lmath.Vec3{x - 0.5, -0.5, z - 0.5},
+			Max: lmath.Vec3{x + 0.5, 0.5, z + 0.5},
+		}
+	}
+	tests := []struct {
+		name    string
+		r       lmath.Rect3
+		visible bool
+	}{
+		{"behind wall", box(0, -10), false},
+		{"in front of wall", box(0, -3), true},
+		{"beside wall", box(8, -10), true},
+		{"behind eye", box(0, 10), false},
+		{"around eye", box(0, 0), true},
+	}
+	for _, tst := range tests {
+		if got := b.Visible(tst.r, vp); got != tst.visible {
+			t.Errorf("%s: Visible = %t, want %t", tst.name, got, tst.visible)
+		}
+	}
+
+	b.Clear()
+	if !b.Visible(box(0, -10), vp) {
+		t.Error("box visible after Clear")
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// shuffledGrid returns an indexed grid mesh of n*n quads, whose triangles are
+// in a random order.
+func shuffledGrid(n int) *Mesh {
+	m := NewMesh()
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			m.Vertices = append(m.Vertices, Vec3{float32(x), float32(y), 0})
+			m.Colors = append(m.Colors, Color{float32(x), float32(y), 0, 1})
+		}
+	}
+	var tris [][3]uint32
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			i := uint32(y*(n+1) + x)
+			tris = append(tris, [3]uint32{i, i + 1, i + uint32(n) + 2}, [3]uint32{i, i + uint32(n) + 2, i + uint32(n) + 1})
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := range tris {
+		j := r.Intn(i + 1)
+		tris[i], tris[j] = tris[j], tris[i]
+	}
+	for _, t := range tris {
+		m.Indices = append(m.Indices, t[:]...)
+	}
+	return m
+}
+
+// triangleSet returns the sorted triangles of the mesh as positions, each
+// rotated such that it's smallest vertex comes first (preserving winding).
+func triangleSet(m *Mesh) []string {
+	var set []string
+	for i := 0; i < len(m.Indices); i += 3 {
+		var p [3]Vec3
+		for k := range p {
+			p[k] = m.Vertices[m.Indices[i+k]]
+		}
+		for p[0].X > p[1].X || p[0].X == p[1].X && p[0].Y > p[1].Y || p[0].X > p[2].X || p[0].X == p[2].X && p[0].Y > p[2].Y {
+			p[0], p[1], p[2] = p[1], p[2], p[0]
+		}
+		set = append(set, fmt.Sprint(p))
+	}
+	sort.Strings(set)
+	return set
+}
+
+func TestMeshOptimize(t *testing.T) {
+	m := shuffledGrid(32)
+	before := m.ACMR(16)
+	want := triangleSet(m)
+	m.Optimize()
+	after := m.ACMR(16)
+	if after > 0.8 || after >= before {
+		t.Fatalf("ACMR %v -> %v, want a substantial improvement", before, after)
+	}
+	if !reflect.DeepEqual(triangleSet(m), want) {
+		t.Fatal("Optimize changed the set of triangles")
+	}
+
+	m.OptimizeVertices()
+	if !reflect.DeepEqual(triangleSet(m), want) {
+		t.Fatal("OptimizeVertices changed the set of triangles")
+	}
+	for i, v := range m.Vertices {
+		if m.Colors[i] != (Color{v.X, v.Y, 0, 1}) {
+			t.Fatalf("vertex %d color %v does not match position %v", i, m.Colors[i], v)
+		}
+	}
+	if m.Indices[0] != 0 || m.Indices[1] != 1 || m.Indices[2] != 2 {
+		t.Fatalf("first triangle %v, want vertices in first-use order", m.Indices[:3])
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"math"
+)
+
+// OutputEncoding specifies how the linear colors written to a canvas are
+// encoded for display, see OutputTransform.
+type OutputEncoding uint8
+
+// String returns a string representation of this output encoding.
+// e.g. EncodeSRGB -> "EncodeSRGB"
+func (e OutputEncoding) String() string {
+	switch e {
+	case EncodeNone:
+		return "EncodeNone"
+	case EncodeSRGB:
+		return "EncodeSRGB"
+	case EncodeGamma:
+		return "EncodeGamma"
+	case EncodeHDR10:
+		return "EncodeHDR10"
+	case EncodeScRGB:
+		return "EncodeScRGB"
+	}
+	return fmt.Sprintf("OutputEncoding(%d)", e)
+}
+
+const (
+	// EncodeNone writes colors as-is, i.e. shaders are responsible for any
+	// encoding.
+	EncodeNone OutputEncoding = iota
+
+	// EncodeSRGB encodes linear colors as sRGB (see Canvas.SetSRGB).
+	EncodeSRGB
+
+	// EncodeGamma encodes linear colors with a simple power-law gamma curve
+	// (see OutputTransform.Gamma).
+	EncodeGamma
+
+	// EncodeHDR10 encodes linear colors for HDR10 displays using the SMPTE ST
+	// 2084 (PQ) curve, with a 10-bit swap chain.
+	EncodeHDR10
+
+	// EncodeScRGB writes linear colors to a 16-bit floating-point swap chain
+	// in the extended scRGB color space, where 1.0 is 80 nits and values
+	// above 1.0 are brighter than standard white.
+	EncodeScRGB
+)
+
+// OutputTransform describes how the linear colors written to a canvas are
+// transformed for display.
+type OutputTransform struct {
+	// The encoding of the output.
+	Encoding OutputEncoding
+
+	// The gamma value of the EncodeGamma encoding, typically 2.2.
+	Gamma float64
+
+	// The brightness, in nits, of a linear color of 1.0 (i.e. "paper white")
+	// for the HDR encodings, typically 80 to 200. Zero means 80 nits.
+	WhiteNits float64
+}
+
+// DefaultOutputTransform is the default output transform of canvases, which
+// writes colors as-is.
+var DefaultOutputTransform = OutputTransform{
+	Encoding:  EncodeNone,
+	Gamma:     2.2,
+	WhiteNits: 80,
+}
+
+// HDR tells if the output transform's encoding is an HDR one.
+func (t OutputTransform) HDR() bool {
+	return t.Encoding == EncodeHDR10 || t.Encoding == EncodeScRGB
+}
+
+func (t OutputTransform) whiteNits() float64 {
+	if t.WhiteNits == 0 {
+		return 80
+	}
+	return t.WhiteNits
+}
+
+// Encode returns the linear color c encoded according to the output
+// transform, as it would be written to the canvas. The alpha component is
+// not encoded.
+//
+// It serves as the reference for renderers implementing the transform in
+// shaders, and for encoding colors on the CPU (e.g. of downloaded images).
+func (t OutputTransform) Encode(c Color) Color {
+	var f func(v float64) float64
+	switch t.Encoding {
+	case EncodeSRGB:
+		return c.SRGB()
+	case EncodeGamma:
+		inv := 1 / t.Gamma
+		f = func(v float64) float64 {
+			return math.Pow(math.Max(v, 0), inv)
+		}
+	case EncodeHDR10:
+		const (
+			m1 = 2610.0 / 16384
+			m2 = 2523.0 / 4096 * 128
+			c1 = 3424.0 / 4096
+			c2 = 2413.0 / 4096 * 32
+			c3 = 2392.0 / 4096 * 32
+		)
+		scale := t.whiteNits() / 10000
+		f = func(v float64) float64 {
+			y := math.Pow(math.Min(math.Max(v*scale, 0), 1), m1)
+			return math.Pow((c1+c2*y)/(1+c3*y), m2)
+		}
+	case EncodeScRGB:
+		scale := t.whiteNits() / 80
+		f = func(v float64) float64 {
+			return v * scale
+		}
+	default:
+		return c
+	}
+	return Color{
+		R: float32(f(float64(c.R))),
+		G: float32(f(float64(c.G))),
+		B: float32(f(float64(c.B))),
+		A: c.A,
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOutputTransformEncode(t *testing.T) {
+	c := Color{0.5, 1, 0, 0.25}
+	tests := []struct {
+		t    OutputTransform
+		want Color
+	}{
+		{DefaultOutputTransform, c},
+		{OutputTransform{Encoding: EncodeSRGB}, c.SRGB()},
+		{OutputTransform{Encoding: EncodeGamma, Gamma: 2}, Color{0.70710677, 1, 0, 0.25}},
+		{OutputTransform{Encoding: EncodeScRGB, WhiteNits: 160}, Color{1, 2, 0, 0.25}},
+		// ST 2084 maps 50 and 100 nits to approximately 0.440 and 0.508.
+		{OutputTransform{Encoding: EncodeHDR10, WhiteNits: 100}, Color{0.4403, 0.5081, 0, 0.25}},
+	}
+	for _, tst := range tests {
+		got := tst.t.Encode(c)
+		d := math.Max(math.Abs(float64(got.R-tst.want.R)), math.Abs(float64(got.G-tst.want.G)))
+		d = math.Max(d, math.Abs(float64(got.B-tst.want.B)))
+		if d > 1e-3 || got.A != tst.want.A {
+			t.Errorf("%v: got %v, want %v", tst.t.Encoding, got, tst.want)
+		}
+	}
+}
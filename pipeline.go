@@ -0,0 +1,299 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"time"
+)
+
+// TargetConfig describes a single render target of a data-driven pipeline,
+// i.e. a render-to-texture canvas that passes may render into.
+type TargetConfig struct {
+	// The unique name of the target, which passes refer to it by.
+	Name string `json:"name"`
+
+	// The resolution of the target relative to the screen, e.g. 0.5 for a
+	// half-resolution target. Zero means full resolution.
+	Scale float64 `json:"scale"`
+
+	// The number of samples to use for multisampling (see
+	// RTTConfig.Samples).
+	Samples int `json:"samples"`
+
+	// The names of the color and depth formats of the target (e.g. "RGBA"
+	// and "Depth24"), or empty strings if the target has no such buffer.
+	Color string `json:"color"`
+	Depth string `json:"depth"`
+}
+
+// RTTConfig returns the render-to-texture configuration of this target for
+// the given screen bounds. New textures are created for the color and depth
+// buffers, if any.
+func (t TargetConfig) RTTConfig(screen image.Rectangle) (RTTConfig, error) {
+	var cfg RTTConfig
+	scale := t.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	cfg.Bounds = image.Rect(0, 0,
+		int(float64(screen.Dx())*scale),
+		int(float64(screen.Dy())*scale),
+	)
+	cfg.Samples = t.Samples
+	if t.Color != "" {
+		f, ok := parseTexFormat(t.Color)
+		if !ok {
+			return cfg, fmt.Errorf("target %q: unknown color format %q", t.Name, t.Color)
+		}
+		cfg.Color = NewTexture()
+		cfg.ColorFormat = f
+	}
+	if t.Depth != "" {
+		f, ok := parseDSFormat(t.Depth)
+		if !ok {
+			return cfg, fmt.Errorf("target %q: unknown depth format %q", t.Name, t.Depth)
+		}
+		cfg.Depth = NewTexture()
+		cfg.DepthFormat = f
+		if f.IsCombined() {
+			cfg.StencilFormat = f
+		}
+	}
+	return cfg, nil
+}
+
+// PassConfig describes a single rendering pass of a data-driven pipeline.
+type PassConfig struct {
+	// The name of the pass.
+	Name string `json:"name"`
+
+	// The name of the target that the pass renders into, or an empty string
+	// for the screen.
+	Target string `json:"target"`
+
+	// The color to clear the target to before the pass, if any.
+	Clear *Color `json:"clear"`
+
+	// The depth to clear the target to before the pass, if any.
+	ClearDepth *float64 `json:"clearDepth"`
+
+	// The effect parameters of the pass, see the Apply method.
+	Params map[string]interface{} `json:"params"`
+}
+
+// Apply stores the effect parameters of this pass as inputs of the given
+// shader. Parameters are converted as follows:
+//  number             -> float32
+//  bool               -> bool
+//  array of 3 numbers -> gfx.Vec3
+//  array of 4 numbers -> gfx.Vec4
+// Parameters of any other type cause an error to be returned, after all other
+// parameters are stored.
+//
+// The shader's write lock must be held for this method to operate safely.
+func (p PassConfig) Apply(s *Shader) error {
+	var first error
+	for name, v := range p.Params {
+		in, ok := pipelineInput(v)
+		if !ok {
+			if first == nil {
+				first = fmt.Errorf("pass %q: param %q: unsupported value %v", p.Name, name, v)
+			}
+			continue
+		}
+		s.Inputs[name] = in
+	}
+	return first
+}
+
+func pipelineInput(v interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case float64:
+		return float32(t), true
+	case bool:
+		return t, true
+	case []interface{}:
+		var f [4]float32
+		if len(t) != 3 && len(t) != 4 {
+			return nil, false
+		}
+		for i, c := range t {
+			n, ok := c.(float64)
+			if !ok {
+				return nil, false
+			}
+			f[i] = float32(n)
+		}
+		if len(t) == 3 {
+			return Vec3{f[0], f[1], f[2]}, true
+		}
+		return Vec4{f[0], f[1], f[2], f[3]}, true
+	}
+	return nil, false
+}
+
+// PipelineConfig describes the structure of a frame (the render targets and
+// the order of passes that render into them), such that it can be loaded from
+// a file at runtime instead of being hard-coded in Go. For example:
+//  {
+//      "targets": [
+//          {"name": "scene", "color": "RGBA", "depth": "Depth24", "samples": 4},
+//          {"name": "bloom", "color": "RGBA", "scale": 0.5}
+//      ],
+//      "passes": [
+//          {"name": "opaque", "target": "scene", "clear": {"R": 0, "G": 0, "B": 0, "A": 1}},
+//          {"name": "bloom", "target": "bloom", "params": {"Threshold": 0.8}},
+//          {"name": "composite", "params": {"Exposure": 1.2}}
+//      ]
+//  }
+type PipelineConfig struct {
+	Targets []TargetConfig `json:"targets"`
+	Passes  []PassConfig   `json:"passes"`
+}
+
+// Target returns the target with the given name, or nil if there is none.
+func (c *PipelineConfig) Target(name string) *TargetConfig {
+	for i := range c.Targets {
+		if c.Targets[i].Name == name {
+			return &c.Targets[i]
+		}
+	}
+	return nil
+}
+
+// Validate validates the configuration, it returns an error if any target
+// name is not unique, any format name is unknown, or any pass renders into an
+// undefined target.
+func (c *PipelineConfig) Validate() error {
+	names := make(map[string]bool, len(c.Targets))
+	for _, t := range c.Targets {
+		if t.Name == "" {
+			return fmt.Errorf("target with empty name")
+		}
+		if names[t.Name] {
+			return fmt.Errorf("duplicate target %q", t.Name)
+		}
+		names[t.Name] = true
+		if _, ok := parseTexFormat(t.Color); t.Color != "" && !ok {
+			return fmt.Errorf("target %q: unknown color format %q", t.Name, t.Color)
+		}
+		if _, ok := parseDSFormat(t.Depth); t.Depth != "" && !ok {
+			return fmt.Errorf("target %q: unknown depth format %q", t.Name, t.Depth)
+		}
+	}
+	for _, p := range c.Passes {
+		if p.Target != "" && !names[p.Target] {
+			return fmt.Errorf("pass %q: undefined target %q", p.Name, p.Target)
+		}
+	}
+	return nil
+}
+
+// LoadPipelineConfig decodes and validates a JSON pipeline configuration from
+// the given reader.
+func LoadPipelineConfig(r io.Reader) (*PipelineConfig, error) {
+	c := new(PipelineConfig)
+	if err := json.NewDecoder(r).Decode(c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadPipelineConfigFile is short-hand for opening the named file and calling
+// LoadPipelineConfig with it.
+func LoadPipelineConfigFile(path string) (*PipelineConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadPipelineConfig(f)
+}
+
+// WatchPipelineConfig loads the named pipeline configuration file and then
+// polls it for modifications at the given interval, loading it again each
+// time it changes. After each load the reload function is called with the
+// result (from a separate goroutine), allowing technical artists to iterate
+// on the structure of a frame while the application is running.
+//
+// If the file cannot be accessed (e.g. while an editor replaces it) the
+// reload function is called with the error once, and not again until the
+// file is accessible and loaded again.
+//
+// The returned function stops watching the file when called.
+func WatchPipelineConfig(path string, interval time.Duration, reload func(c *PipelineConfig, err error)) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		var (
+			lastMod time.Time
+			failing bool
+		)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			fi, err := os.Stat(path)
+			if err != nil {
+				if !failing {
+					reload(nil, err)
+				}
+				failing = true
+			} else if failing || !fi.ModTime().Equal(lastMod) {
+				failing = false
+				lastMod = fi.ModTime()
+				reload(LoadPipelineConfigFile(path))
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+	}
+}
+
+// pipelineTexFormats and pipelineDSFormats are the predefined formats which
+// pipeline configurations may name.
+var (
+	pipelineTexFormats = []TexFormat{
+		RGB, RGBA, DXT1, DXT1RGBA, DXT3, DXT5, ETC2, ETC2RGBA, ASTC4x4,
+		ASTC8x8, RGBA16F, RGBA32F, R11G11B10F,
+	}
+	pipelineDSFormats = []DSFormat{
+		Depth16, Depth24, Depth32, Depth24AndStencil8,
+	}
+)
+
+// parseTexFormat returns the predefined texture format whose String method
+// returns s.
+func parseTexFormat(s string) (TexFormat, bool) {
+	for _, f := range pipelineTexFormats {
+		if f.String() == s {
+			return f, true
+		}
+	}
+	return ZeroTexFormat, false
+}
+
+// parseDSFormat returns the predefined depth/stencil format whose String
+// method returns s.
+func parseDSFormat(s string) (DSFormat, bool) {
+	for _, f := range pipelineDSFormats {
+		if f.String() == s {
+			return f, true
+		}
+	}
+	return ZeroDSFormat, false
+}
@@ -0,0 +1,178 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadPipelineConfig(t *testing.T) {
+	tests := []struct {
+		json string
+		ok   bool
+	}{
+		{`{
+			"targets": [
+				{"name": "scene", "color": "RGBA", "depth": "Depth24", "samples": 4},
+				{"name": "bloom", "color": "R11G11B10F", "scale": 0.5}
+			],
+			"passes": [
+				{"name": "opaque", "target": "scene"},
+				{"name": "bloom", "target": "bloom"},
+				{"name": "composite"}
+			]
+		}`, true},
+		{`{"targets": [{"name": "a", "depth": "Depth24AndStencil8"}]}`, true},
+		{`{}`, true},
+
+		// Malformed JSON.
+		{`{"targets": [`, false},
+
+		// Unknown formats, including names of undefined format values.
+		{`{"targets": [{"name": "a", "color": "RGBA8"}]}`, false},
+		{`{"targets": [{"name": "a", "color": "ZeroTexFormat"}]}`, false},
+		{`{"targets": [{"name": "a", "color": "TexFormat(200)"}]}`, false},
+		{`{"targets": [{"name": "a", "depth": "DSFormat(9)"}]}`, false},
+		{`{"targets": [{"name": "a", "depth": "RGBA"}]}`, false},
+
+		// Invalid target names.
+		{`{"targets": [{"name": ""}]}`, false},
+		{`{"targets": [{"name": "a"}, {"name": "a"}]}`, false},
+
+		// Pass rendering into an undefined target.
+		{`{"passes": [{"name": "p", "target": "missing"}]}`, false},
+	}
+	for _, tst := range tests {
+		c, err := LoadPipelineConfig(strings.NewReader(tst.json))
+		if tst.ok && (err != nil || c == nil) {
+			t.Errorf("%s: got error %v", tst.json, err)
+		}
+		if !tst.ok && err == nil {
+			t.Errorf("%s: expected an error", tst.json)
+		}
+	}
+}
+
+func TestTargetConfigRTTConfig(t *testing.T) {
+	target := TargetConfig{Name: "bloom", Scale: 0.5, Color: "RGBA16F", Depth: "Depth24AndStencil8"}
+	cfg, err := target.RTTConfig(image.Rect(0, 0, 640, 480))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Bounds != image.Rect(0, 0, 320, 240) {
+		t.Errorf("got bounds %v, want 320x240", cfg.Bounds)
+	}
+	if cfg.Color == nil || cfg.ColorFormat != RGBA16F {
+		t.Errorf("got color %v format %v", cfg.Color, cfg.ColorFormat)
+	}
+	if cfg.Depth == nil || cfg.DepthFormat != Depth24AndStencil8 || cfg.StencilFormat != Depth24AndStencil8 {
+		t.Errorf("got depth %v format %v stencil format %v", cfg.Depth, cfg.DepthFormat, cfg.StencilFormat)
+	}
+}
+
+func TestPassConfigApply(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want interface{}
+	}{
+		{float64(0.5), float32(0.5)},
+		{true, true},
+		{[]interface{}{1.0, 2.0, 3.0}, Vec3{1, 2, 3}},
+		{[]interface{}{1.0, 2.0, 3.0, 4.0}, Vec4{1, 2, 3, 4}},
+
+		// Unsupported values.
+		{"string", nil},
+		{[]interface{}{1.0, 2.0}, nil},
+		{[]interface{}{1.0, "2", 3.0}, nil},
+		{map[string]interface{}{}, nil},
+	}
+	for _, tst := range tests {
+		s := NewShader("test")
+		p := PassConfig{Name: "p", Params: map[string]interface{}{"Param": tst.v}}
+		err := p.Apply(s)
+		got, ok := s.Inputs["Param"]
+		if tst.want == nil {
+			if err == nil || ok {
+				t.Errorf("%v: got input %v and error %v, want only an error", tst.v, got, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tst.want) {
+			t.Errorf("%v: got input %#v and error %v, want %#v", tst.v, got, err, tst.want)
+		}
+	}
+
+	// Supported parameters are stored despite unsupported ones.
+	s := NewShader("test")
+	p := PassConfig{Params: map[string]interface{}{"A": 1.0, "B": "bad", "C": false}}
+	if err := p.Apply(s); err == nil {
+		t.Error("expected an error")
+	}
+	if s.Inputs["A"] != float32(1) || s.Inputs["C"] != false {
+		t.Errorf("got inputs %v", s.Inputs)
+	}
+}
+
+func TestWatchPipelineConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pipeline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pipeline.json")
+	write := func() {
+		if err := ioutil.WriteFile(path, []byte(`{"targets": [{"name": "a"}]}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write()
+
+	type result struct {
+		c   *PipelineConfig
+		err error
+	}
+	results := make(chan result, 100)
+	stop := WatchPipelineConfig(path, time.Millisecond, func(c *PipelineConfig, err error) {
+		results <- result{c, err}
+	})
+	defer stop()
+	next := func() (r result) {
+		select {
+		case r = <-results:
+		case <-time.After(5 * time.Second):
+			t.Fatal("reload function not called")
+		}
+		return
+	}
+
+	if r := next(); r.err != nil || r.c.Target("a") == nil {
+		t.Fatalf("got config %v and error %v", r.c, r.err)
+	}
+
+	// A missing file is reported once, however many times it is polled.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if r := next(); r.err == nil {
+		t.Fatalf("got config %v, want an error", r.c)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(results) != 0 {
+		t.Fatalf("error reported %d more times", len(results))
+	}
+
+	// Once the file is back it is loaded again.
+	write()
+	if r := next(); r.err != nil || r.c.Target("a") == nil {
+		t.Fatalf("got config %v and error %v after recreating the file", r.c, r.err)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "fmt"
+
+// PolygonMode represents a single polygon rasterization mode. PolygonFill is
+// the default (zero value).
+type PolygonMode uint8
+
+// String returns a string representation of this PolygonMode.
+// e.g. PolygonLine -> "PolygonLine"
+func (p PolygonMode) String() string {
+	switch p {
+	case PolygonFill:
+		return "PolygonFill"
+	case PolygonLine:
+		return "PolygonLine"
+	case PolygonPoint:
+		return "PolygonPoint"
+	}
+	return fmt.Sprintf("PolygonMode(%d)", p)
+}
+
+const (
+	// Rasterizes the interior of polygons (i.e. solid rendering).
+	PolygonFill PolygonMode = iota
+
+	// Rasterizes only the edges of polygons as lines (i.e. wireframe
+	// rendering). The width of the lines is controlled by State.LineWidth.
+	PolygonLine
+
+	// Rasterizes only the vertices of polygons as points. The size of the
+	// points is controlled by State.PointSize.
+	PolygonPoint
+)
@@ -0,0 +1,188 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Pool is a pool of worker goroutines which run jobs in parallel. Jobs are
+// either frame jobs (e.g. culling or building draw lists for the current
+// frame), which are always run first, or background jobs (e.g. decoding or
+// cooking assets), which are limited to a number of workers that may be
+// lowered during frame spikes such that they do not compete with the renderer
+// for CPU cores (see SetBackgroundLimit).
+//
+// It is safe to use from multiple goroutines concurrently.
+type Pool struct {
+	access  sync.Mutex
+	cond    *sync.Cond
+	workers int
+	closed  bool
+
+	frame, background []func()
+
+	// The maximum and current number of workers running background jobs.
+	backgroundLimit, backgroundRunning int
+}
+
+// NewPool returns a new pool with n worker goroutines. If n <= 0 then one
+// worker per CPU core is used.
+func NewPool(n int) *Pool {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	p := &Pool{
+		workers:         n,
+		backgroundLimit: n,
+	}
+	p.cond = sync.NewCond(&p.access)
+	for i := 0; i < n; i++ {
+		go p.work()
+	}
+	return p
+}
+
+var (
+	workersOnce sync.Once
+	workers     *Pool
+)
+
+// Workers returns the shared worker pool, which is created on first use with
+// one worker per CPU core minus one (leaving a core for the goroutine running
+// the renderer). Renderers and packages built on this one use it for their
+// own parallel work, so applications which schedule their jobs on it too
+// cooperate with them instead of oversubscribing the CPU.
+func Workers() *Pool {
+	workersOnce.Do(func() {
+		n := runtime.NumCPU() - 1
+		if n < 1 {
+			n = 1
+		}
+		workers = NewPool(n)
+	})
+	return workers
+}
+
+func (p *Pool) work() {
+	p.access.Lock()
+	for {
+		switch {
+		case len(p.frame) > 0:
+			job := p.frame[0]
+			p.frame[0] = nil
+			p.frame = p.frame[1:]
+			p.access.Unlock()
+			job()
+			p.access.Lock()
+
+		case len(p.background) > 0 && p.backgroundRunning < p.backgroundLimit:
+			job := p.background[0]
+			p.background[0] = nil
+			p.background = p.background[1:]
+			p.backgroundRunning++
+			p.access.Unlock()
+			job()
+			p.access.Lock()
+			p.backgroundRunning--
+			// A background slot became free.
+			p.cond.Broadcast()
+
+		case p.closed:
+			p.access.Unlock()
+			return
+
+		default:
+			p.cond.Wait()
+		}
+	}
+}
+
+// Workers returns the number of worker goroutines in the pool.
+func (p *Pool) Workers() int {
+	return p.workers
+}
+
+// Go schedules the given frame job to run on the pool. Frame jobs run before
+// any pending background jobs.
+func (p *Pool) Go(job func()) {
+	p.access.Lock()
+	p.frame = append(p.frame, job)
+	p.access.Unlock()
+	p.cond.Signal()
+}
+
+// Background schedules the given background job to run on the pool. See the
+// SetBackgroundLimit method.
+func (p *Pool) Background(job func()) {
+	p.access.Lock()
+	p.background = append(p.background, job)
+	p.access.Unlock()
+	p.cond.Signal()
+}
+
+// SetBackgroundLimit sets the maximum number of workers that may run
+// background jobs at once. Typically the renderer (or application) lowers it
+// while a frame is taking longer than expected and restores it afterwards,
+// such that background jobs soak up idle cores without causing frame spikes:
+//  pool.SetBackgroundLimit(1)
+//  ... expensive frame ...
+//  pool.SetBackgroundLimit(pool.Workers())
+//
+// Running background jobs are never interrupted; the limit only applies to
+// starting new ones. By default the limit is the number of workers.
+func (p *Pool) SetBackgroundLimit(n int) {
+	p.access.Lock()
+	p.backgroundLimit = n
+	p.access.Unlock()
+	p.cond.Broadcast()
+}
+
+// ParallelFor calls fn(i) for each i in the range [0, n) in parallel on the
+// pool (as frame jobs), and blocks until all calls have returned. The calling
+// goroutine takes part in the work, so ParallelFor may be safely called from
+// within a job, even when every worker is busy: indices are claimed from a
+// shared counter and the caller only waits for calls that have already been
+// claimed, never for helper jobs which have not started yet.
+func (p *Pool) ParallelFor(n int, fn func(i int)) {
+	if n <= 0 {
+		return
+	}
+	var (
+		next int64
+		wg   sync.WaitGroup
+	)
+	wg.Add(n)
+	run := func() {
+		for {
+			i := int(atomic.AddInt64(&next, 1) - 1)
+			if i >= n {
+				return
+			}
+			fn(i)
+			wg.Done()
+		}
+	}
+	helpers := p.workers
+	if helpers > n-1 {
+		helpers = n - 1
+	}
+	for h := 0; h < helpers; h++ {
+		p.Go(run)
+	}
+	run()
+	wg.Wait()
+}
+
+// Close stops the pool's worker goroutines once all pending jobs have run.
+// No jobs may be scheduled after calling Close.
+func (p *Pool) Close() {
+	p.access.Lock()
+	p.closed = true
+	p.access.Unlock()
+	p.cond.Broadcast()
+}
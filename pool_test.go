@@ -0,0 +1,91 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolParallelFor(t *testing.T) {
+	p := NewPool(4)
+	defer p.Close()
+
+	var sum int64
+	p.ParallelFor(1000, func(i int) {
+		atomic.AddInt64(&sum, int64(i))
+	})
+	if sum != 999*1000/2 {
+		t.Fatalf("got sum %d", sum)
+	}
+
+	// Nested use from within a job must not deadlock.
+	done := make(chan bool)
+	p.Go(func() {
+		p.ParallelFor(10, func(i int) {})
+		done <- true
+	})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("nested ParallelFor deadlocked")
+	}
+}
+
+func TestPoolParallelForNestedSingleWorker(t *testing.T) {
+	// With one worker, the job calling ParallelFor occupies the only worker,
+	// so none of the helper jobs can start until it returns.
+	p := NewPool(1)
+	defer p.Close()
+
+	var sum int64
+	done := make(chan bool)
+	p.Go(func() {
+		p.ParallelFor(100, func(i int) {
+			atomic.AddInt64(&sum, int64(i))
+		})
+		done <- true
+	})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("nested ParallelFor deadlocked")
+	}
+	if sum != 99*100/2 {
+		t.Fatalf("got sum %d", sum)
+	}
+}
+
+func TestPoolBackgroundLimit(t *testing.T) {
+	p := NewPool(4)
+	defer p.Close()
+	p.SetBackgroundLimit(1)
+
+	var (
+		running, max int32
+		wg           sync.WaitGroup
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		p.Background(func() {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			wg.Done()
+		})
+	}
+	wg.Wait()
+	if max != 1 {
+		t.Fatalf("got %d concurrent background jobs, want 1", max)
+	}
+}
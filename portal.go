@@ -0,0 +1,193 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// Cell represents a single convex region of an indoor scene (e.g. a room or
+// a section of a corridor) for cell-and-portal visibility. See the Cells
+// type.
+type Cell struct {
+	// The world-space bounds of the cell, used to determine which cell the
+	// camera is in.
+	Bounds lmath.Rect3
+
+	// The objects that are inside the cell.
+	Objects []*Object
+
+	// The portals which connect this cell to others.
+	Portals []*Portal
+}
+
+// Portal represents an opening (e.g. a doorway or window) between two cells,
+// through which one cell can be seen from the other.
+type Portal struct {
+	// The world-space points of the portal, forming a convex polygon.
+	Points []lmath.Vec3
+
+	// The two cells that the portal connects. The portal should be in the
+	// Portals slice of both cells.
+	A, B *Cell
+}
+
+// Other returns the cell on the other side of the portal from c.
+func (p *Portal) Other(c *Cell) *Cell {
+	if p.A == c {
+		return p.B
+	}
+	return p.A
+}
+
+// Cells is a set of cells connected by portals, which is used to determine
+// the objects that are visible from the camera in indoor scenes: starting
+// from the cell containing the camera, the viewing frustum is recursively
+// narrowed to the screen-space bounds of each visible portal, such that only
+// cells (and objects) seen through a chain of portals are visible. In
+// corridor-heavy level geometry this rejects the vast majority of objects
+// with very little work.
+type Cells []*Cell
+
+// Find returns the cell whose bounds contain the point p, or nil if there is
+// none.
+func (c Cells) Find(p lmath.Vec3) *Cell {
+	for _, cell := range c {
+		b := cell.Bounds
+		if p.X >= b.Min.X && p.Y >= b.Min.Y && p.Z >= b.Min.Z &&
+			p.X <= b.Max.X && p.Y <= b.Max.Y && p.Z <= b.Max.Z {
+			return cell
+		}
+	}
+	return nil
+}
+
+// ndcRect is a rectangle in normalized device coordinates.
+type ndcRect struct {
+	minX, minY, maxX, maxY float64
+}
+
+var ndcFull = ndcRect{-1, -1, 1, 1}
+
+func (r ndcRect) intersect(o ndcRect) (ndcRect, bool) {
+	r.minX = math.Max(r.minX, o.minX)
+	r.minY = math.Max(r.minY, o.minY)
+	r.maxX = math.Min(r.maxX, o.maxX)
+	r.maxY = math.Min(r.maxY, o.maxY)
+	return r, r.minX < r.maxX && r.minY < r.maxY
+}
+
+// ndcBounds returns the normalized device coordinate bounds of the given
+// points projected by the view-projection matrix. If every point is behind the
+// eye then ok is false, if only some are then the full screen is returned
+// (conservatively).
+func ndcBounds(vp lmath.Mat4, points []lmath.Vec3) (r ndcRect, ok bool) {
+	r = ndcRect{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	behind := 0
+	for _, p := range points {
+		x := p.X*vp[0][0] + p.Y*vp[1][0] + p.Z*vp[2][0] + vp[3][0]
+		y := p.X*vp[0][1] + p.Y*vp[1][1] + p.Z*vp[2][1] + vp[3][1]
+		w := p.X*vp[0][3] + p.Y*vp[1][3] + p.Z*vp[2][3] + vp[3][3]
+		if w <= 0 {
+			behind++
+			continue
+		}
+		x, y = x/w, y/w
+		r.minX, r.maxX = math.Min(r.minX, x), math.Max(r.maxX, x)
+		r.minY, r.maxY = math.Min(r.minY, y), math.Max(r.maxY, y)
+	}
+	switch behind {
+	case 0:
+		return r, true
+	case len(points):
+		return r, false
+	}
+	return ndcFull, true
+}
+
+func rect3Corners(b lmath.Rect3) []lmath.Vec3 {
+	return []lmath.Vec3{
+		{b.Min.X, b.Min.Y, b.Min.Z},
+		{b.Max.X, b.Min.Y, b.Min.Z},
+		{b.Min.X, b.Max.Y, b.Min.Z},
+		{b.Max.X, b.Max.Y, b.Min.Z},
+		{b.Min.X, b.Min.Y, b.Max.Z},
+		{b.Max.X, b.Min.Y, b.Max.Z},
+		{b.Min.X, b.Max.Y, b.Max.Z},
+		{b.Max.X, b.Max.Y, b.Max.Z},
+	}
+}
+
+// portalWalk holds the state of a single visibility query.
+type portalWalk struct {
+	vp     lmath.Mat4
+	dst    []*Object
+	added  map[*Object]bool
+	onPath map[*Portal]bool
+}
+
+func (w *portalWalk) cell(c *Cell, view ndcRect) {
+	for _, o := range c.Objects {
+		if w.added[o] {
+			continue
+		}
+		r, ok := ndcBounds(w.vp, rect3Corners(o.Bounds()))
+		if !ok {
+			continue
+		}
+		if _, ok = r.intersect(view); ok {
+			w.added[o] = true
+			w.dst = append(w.dst, o)
+		}
+	}
+	for _, p := range c.Portals {
+		if w.onPath[p] {
+			continue
+		}
+		r, ok := ndcBounds(w.vp, p.Points)
+		if !ok {
+			continue
+		}
+		narrowed, ok := r.intersect(view)
+		if !ok {
+			continue
+		}
+		w.onPath[p] = true
+		w.cell(p.Other(c), narrowed)
+		w.onPath[p] = false
+	}
+}
+
+// Visible appends the objects that are visible from the given eye position
+// (typically the camera's world position) to dst and returns the extended
+// slice. The view-projection matrix is the one the scene is rendered with
+// (i.e. the camera's inverse world matrix multiplied by it's projection
+// matrix).
+//
+// If the eye is not inside any cell then every object in every cell is
+// appended (i.e. it is assumed the camera is outdoors looking in, and other
+// culling methods should be used).
+//
+// The returned objects may still be outside of the viewing frustum's near and
+// far planes, so they are typically further frustum culled.
+func (c Cells) Visible(dst []*Object, vp lmath.Mat4, eye lmath.Vec3) []*Object {
+	start := c.Find(eye)
+	if start == nil {
+		for _, cell := range c {
+			dst = append(dst, cell.Objects...)
+		}
+		return dst
+	}
+	w := &portalWalk{
+		vp:     vp,
+		dst:    dst,
+		added:  make(map[*Object]bool),
+		onPath: make(map[*Portal]bool),
+	}
+	w.cell(start, ndcFull)
+	return w.dst
+}
@@ -0,0 +1,55 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestCellsVisible(t *testing.T) {
+	newObj := func(x, z float64) *Object {
+		o := NewObject()
+		b := lmath.Rect3{Min: lmath.Vec3{-0.5, -0.5, -0.5}, Max: lmath.Vec3{0.5, 0.5, 0.5}}
+		o.CachedBounds = &b
+		o.Transform.SetPos(lmath.Vec3{x, 0, z})
+		return o
+	}
+
+	// The eye is at the origin looking down -Z, in cell a. Cell b is behind a
+	// small doorway at z=-10, and cell c is connected to a through a doorway
+	// behind the eye.
+	a := &Cell{Bounds: lmath.Rect3{Min: lmath.Vec3{-10, -10, -10}, Max: lmath.Vec3{10, 10, 10}}}
+	b := &Cell{Bounds: lmath.Rect3{Min: lmath.Vec3{-10, -10, -20}, Max: lmath.Vec3{10, 10, -10}}}
+	c := &Cell{Bounds: lmath.Rect3{Min: lmath.Vec3{-10, -10, 10}, Max: lmath.Vec3{10, 10, 20}}}
+	door := &Portal{
+		Points: []lmath.Vec3{{-1, -1, -10}, {1, -1, -10}, {1, 1, -10}, {-1, 1, -10}},
+		A:      a,
+		B:      b,
+	}
+	back := &Portal{
+		Points: []lmath.Vec3{{-1, -1, 10}, {1, -1, 10}, {1, 1, 10}, {-1, 1, 10}},
+		A:      a,
+		B:      c,
+	}
+	a.Portals = []*Portal{door, back}
+	b.Portals = []*Portal{door}
+	c.Portals = []*Portal{back}
+
+	inA := newObj(0, -5)
+	throughDoor := newObj(0, -15)
+	besideDoor := newObj(8, -15)
+	inC := newObj(0, 15)
+	a.Objects = []*Object{inA}
+	b.Objects = []*Object{throughDoor, besideDoor}
+	c.Objects = []*Object{inC}
+
+	vp := lmath.Mat4Perspective(90, 1, 0.1, 100)
+	visible := Cells{a, b, c}.Visible(nil, vp, lmath.Vec3{})
+	if len(visible) != 2 || visible[0] != inA || visible[1] != throughDoor {
+		t.Fatalf("got %d visible objects, want inA and throughDoor", len(visible))
+	}
+}
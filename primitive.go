@@ -0,0 +1,72 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"math"
+)
+
+// Primitive represents a single primitive topology, i.e. how the vertices (or
+// indices) of a mesh are assembled into primitives for rendering. Triangles is
+// the default (zero value).
+type Primitive uint8
+
+// String returns a string representation of this Primitive.
+// e.g. TriangleStrip -> "TriangleStrip"
+func (p Primitive) String() string {
+	switch p {
+	case Triangles:
+		return "Triangles"
+	case TriangleStrip:
+		return "TriangleStrip"
+	case TriangleFan:
+		return "TriangleFan"
+	case Lines:
+		return "Lines"
+	case LineStrip:
+		return "LineStrip"
+	case LineLoop:
+		return "LineLoop"
+	case Points:
+		return "Points"
+	}
+	return fmt.Sprintf("Primitive(%d)", p)
+}
+
+const (
+	// Triangles is a list of independent triangles, each made up of three
+	// consecutive vertices.
+	Triangles Primitive = iota
+
+	// TriangleStrip is a strip of connected triangles, where each vertex
+	// after the first two forms a triangle with the two vertices before it.
+	TriangleStrip
+
+	// TriangleFan is a fan of connected triangles, where each vertex after
+	// the first two forms a triangle with the vertex before it and the first
+	// vertex.
+	TriangleFan
+
+	// Lines is a list of independent line segments, each made up of two
+	// consecutive vertices.
+	Lines
+
+	// LineStrip is a strip of connected line segments, where each vertex
+	// after the first forms a line segment with the vertex before it.
+	LineStrip
+
+	// LineLoop is like LineStrip except that the last vertex is additionally
+	// connected to the first vertex, closing the loop.
+	LineLoop
+
+	// Points is a list of independent points, one per vertex.
+	Points
+)
+
+// RestartIndex is the special index value which, when found in the indices of
+// a mesh whose PrimitiveRestart field is true, ends the current strip, fan, or
+// loop primitive and begins a new one with the next index.
+const RestartIndex uint32 = math.MaxUint32
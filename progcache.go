@@ -0,0 +1,146 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const programMagic = "AZ3DPROG"
+
+// ProgramCache caches linked shader program binaries in a directory, such
+// that shader-heavy applications avoid compiling and linking every shader
+// program on startup.
+//
+// Renderers which support program binaries (see GPUInfo.ProgramBinary) and
+// are given a cache look up each shader they load using ProgramKey: if a
+// binary is found it is loaded directly, otherwise the shader is compiled and
+// linked as usual and it's binary is stored for the next run. If the driver
+// rejects a cached binary (e.g. after a driver update that did not change
+// it's version string) the renderer removes it and compiles the shader.
+//
+// It is safe to use a program cache from multiple goroutines concurrently.
+type ProgramCache struct {
+	access sync.Mutex
+	dir    string
+}
+
+// NewProgramCache returns a new program cache which stores program binaries
+// in the given (typically user-specific) directory. The directory is created
+// once the first binary is stored.
+func NewProgramCache(dir string) *ProgramCache {
+	return &ProgramCache{dir: dir}
+}
+
+// Dir returns the directory of the program cache.
+func (c *ProgramCache) Dir() string {
+	return c.dir
+}
+
+// ProgramKey returns the cache key of the shader program for the given
+// graphics hardware, which is a hash of the shader's GLSL sources and
+// defines, and of the name, vendor, and driver version of the graphics
+// hardware (as binaries are only valid for the driver that produced them).
+//
+// The shader's read lock must be held for this method to operate safely.
+func ProgramKey(s *Shader, info GPUInfo) string {
+	h := sha256.New()
+	write := func(b []byte) {
+		binary.Write(h, binary.LittleEndian, uint64(len(b)))
+		h.Write(b)
+	}
+	write(s.GLSLVert)
+	write(s.GLSLFrag)
+	names := make([]string, 0, len(s.Defines))
+	for name := range s.Defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		write([]byte(name))
+		write([]byte(s.Defines[name]))
+	}
+	write([]byte(info.Name))
+	write([]byte(info.Vendor))
+	write([]byte(info.DriverVersion))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *ProgramCache) path(key string) string {
+	return filepath.Join(c.dir, key+".prog")
+}
+
+// Load returns the program binary and it's driver-specific binary format
+// stored under the given key. If there is no such binary (or it is corrupt)
+// then ok is false.
+func (c *ProgramCache) Load(key string) (format uint32, data []byte, ok bool) {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	f, err := ioutil.ReadFile(c.path(key))
+	if err != nil || len(f) < len(programMagic)+8 || string(f[:len(programMagic)]) != programMagic {
+		return 0, nil, false
+	}
+	r := bytes.NewReader(f[len(programMagic):])
+	var size uint32
+	binary.Read(r, binary.LittleEndian, &format)
+	binary.Read(r, binary.LittleEndian, &size)
+	if int(size) != r.Len() {
+		return 0, nil, false
+	}
+	data = make([]byte, size)
+	io.ReadFull(r, data)
+	return format, data, true
+}
+
+// Store stores the program binary and it's driver-specific binary format
+// under the given key, replacing any existing one. The binary is written to a
+// temporary file first, such that concurrent processes never load partially
+// written binaries.
+func (c *ProgramCache) Store(key string, format uint32, data []byte) error {
+	c.access.Lock()
+	defer c.access.Unlock()
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(c.dir, key+".tmp")
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(programMagic)
+	binary.Write(&buf, binary.LittleEndian, format)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(data)))
+	buf.Write(data)
+	_, err = tmp.Write(buf.Bytes())
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Remove removes the program binary stored under the given key, if any (e.g.
+// because the driver rejected it).
+func (c *ProgramCache) Remove(key string) {
+	c.access.Lock()
+	os.Remove(c.path(key))
+	c.access.Unlock()
+}
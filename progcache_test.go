@@ -0,0 +1,60 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProgramCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "progcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewShader("test")
+	s.GLSLVert = []byte("void main() {}")
+	s.GLSLFrag = []byte("void main() {}")
+	info := GPUInfo{Name: "gpu", Vendor: "vendor", DriverVersion: "1.0"}
+	key := ProgramKey(s, info)
+
+	s.Defines["USE_FOG"] = ""
+	if ProgramKey(s, info) == key {
+		t.Error("defines do not change the key")
+	}
+	delete(s.Defines, "USE_FOG")
+	info.DriverVersion = "1.1"
+	if ProgramKey(s, info) == key {
+		t.Error("driver version does not change the key")
+	}
+
+	c := NewProgramCache(filepath.Join(dir, "cache"))
+	if _, _, ok := c.Load(key); ok {
+		t.Fatal("Load of missing key succeeded")
+	}
+	if err := c.Store(key, 0x8741, []byte{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	format, data, ok := c.Load(key)
+	if !ok || format != 0x8741 || string(data) != "\x01\x02\x03" {
+		t.Fatalf("Load = %v, %v, %v", format, data, ok)
+	}
+
+	// Truncated binaries are not loaded.
+	path := filepath.Join(c.Dir(), key+".prog")
+	f, _ := ioutil.ReadFile(path)
+	ioutil.WriteFile(path, f[:len(f)-1], 0644)
+	if _, _, ok := c.Load(key); ok {
+		t.Error("Load of truncated binary succeeded")
+	}
+	c.Remove(key)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Remove did not remove the binary")
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"sync"
+	"time"
+)
+
+// QualityKnob represents a single quality setting that may be adjusted by a
+// QualityController, such as the resolution scale, the shadow distance, or
+// the number of particles.
+type QualityKnob struct {
+	// The name of the knob, e.g. "Resolution scale".
+	Name string
+
+	// The minimum and maximum values of the knob, where higher values mean
+	// higher quality (and higher cost).
+	Min, Max float64
+
+	// The amount by which the value is changed in each adjustment.
+	Step float64
+
+	// The current value of the knob.
+	Value float64
+
+	// The function called (from within QualityController.Update) each time
+	// the value is changed by the controller, if not nil.
+	OnChange func(v float64)
+}
+
+// QualityState represents the user-visible state of a QualityController,
+// e.g. for display in a debug overlay or graphics settings menu.
+type QualityState struct {
+	// The average frame time over the controller's window.
+	FrameTime time.Duration
+
+	// The number of adjustments currently in effect, i.e. zero if every knob
+	// is at it's maximum value.
+	Level int
+
+	// A copy of each knob, in registration order.
+	Knobs []QualityKnob
+}
+
+// QualityController monitors frame times and automatically adjusts quality
+// knobs in order to hold a target frame rate. When frames take too long it
+// lowers the knobs one step at a time, in the order that they were
+// registered (so the knob whose quality matters least should be registered
+// first), and when frames are fast again it raises them in reverse order.
+//
+// Hysteresis is applied to avoid oscillating between two quality levels: the
+// knobs are lowered once the average frame time exceeds the target by the
+// Degrade factor and raised only once it is below the target by the Improve
+// factor, and at least Cooldown frames must pass between adjustments.
+//
+// It is safe to use from multiple goroutines concurrently.
+type QualityController struct {
+	access sync.Mutex
+
+	// The target frame time, e.g. time.Second / 60.
+	Target time.Duration
+
+	// The factors of the target frame time above and below which quality is
+	// lowered and raised, respectively. For example 1.1 and 0.8.
+	Degrade, Improve float64
+
+	// The number of frames to average frame times over.
+	Window int
+
+	// The minimum number of frames between adjustments.
+	Cooldown int
+
+	knobs   []*QualityKnob
+	times   []time.Duration
+	next    int
+	filled  bool
+	sum     time.Duration
+	waiting int
+}
+
+// NewQualityController returns a new quality controller which targets the
+// given number of frames per second, with default hysteresis settings.
+func NewQualityController(fps float64) *QualityController {
+	return &QualityController{
+		Target:   time.Duration(float64(time.Second) / fps),
+		Degrade:  1.1,
+		Improve:  0.8,
+		Window:   30,
+		Cooldown: 30,
+	}
+}
+
+// Register registers the given knob with the controller. Knobs are lowered in
+// the order that they are registered and raised in the reverse order.
+func (q *QualityController) Register(k *QualityKnob) {
+	q.access.Lock()
+	q.knobs = append(q.knobs, k)
+	q.access.Unlock()
+}
+
+// Update records the duration of the last frame and adjusts the knobs if
+// required. It should be called once per frame, typically with the larger of
+// the CPU and GPU frame times (see Canvas.BeginTimer).
+func (q *QualityController) Update(frameTime time.Duration) {
+	q.access.Lock()
+	defer q.access.Unlock()
+
+	if len(q.times) != q.Window {
+		q.times = make([]time.Duration, q.Window)
+		q.next, q.filled, q.sum = 0, false, 0
+	}
+	q.sum += frameTime - q.times[q.next]
+	q.times[q.next] = frameTime
+	q.next++
+	if q.next == len(q.times) {
+		q.next = 0
+		q.filled = true
+	}
+
+	if q.waiting > 0 {
+		q.waiting--
+		return
+	}
+	if !q.filled {
+		return
+	}
+	avg := float64(q.sum) / float64(len(q.times))
+	switch {
+	case avg > float64(q.Target)*q.Degrade:
+		for _, k := range q.knobs {
+			if q.adjust(k, -k.Step) {
+				break
+			}
+		}
+	case avg < float64(q.Target)*q.Improve:
+		for i := len(q.knobs) - 1; i >= 0; i-- {
+			if q.adjust(q.knobs[i], q.knobs[i].Step) {
+				break
+			}
+		}
+	}
+}
+
+// adjust changes the knob's value by delta (clamped to it's range) and tells
+// if it changed.
+func (q *QualityController) adjust(k *QualityKnob, delta float64) bool {
+	v := k.Value + delta
+	if v < k.Min {
+		v = k.Min
+	}
+	if v > k.Max {
+		v = k.Max
+	}
+	if v == k.Value {
+		return false
+	}
+	k.Value = v
+	if k.OnChange != nil {
+		k.OnChange(v)
+	}
+	q.waiting = q.Cooldown
+	return true
+}
+
+// State returns the current state of the controller.
+func (q *QualityController) State() QualityState {
+	q.access.Lock()
+	defer q.access.Unlock()
+	var s QualityState
+	if n := len(q.times); n > 0 {
+		if !q.filled {
+			n = q.next
+		}
+		if n > 0 {
+			s.FrameTime = q.sum / time.Duration(n)
+		}
+	}
+	s.Knobs = make([]QualityKnob, len(q.knobs))
+	for i, k := range q.knobs {
+		s.Knobs[i] = *k
+		if k.Step > 0 && k.Value < k.Max {
+			s.Level += int((k.Max - k.Value + k.Step/2) / k.Step)
+		}
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQualityController(t *testing.T) {
+	q := NewQualityController(60)
+	q.Window = 4
+	q.Cooldown = 2
+
+	particles := &QualityKnob{Name: "Particles", Min: 0, Max: 1, Step: 0.5, Value: 1}
+	scale := &QualityKnob{Name: "Resolution scale", Min: 0.5, Max: 1, Step: 0.25, Value: 1}
+	q.Register(particles)
+	q.Register(scale)
+
+	slow, fast := time.Second/30, time.Second/120
+	for i := 0; i < 4; i++ {
+		q.Update(slow)
+	}
+	if particles.Value != 0.5 || scale.Value != 1 {
+		t.Fatalf("after degrade: particles=%v scale=%v", particles.Value, scale.Value)
+	}
+
+	// Cooldown prevents immediate further adjustment.
+	q.Update(slow)
+	q.Update(slow)
+	if particles.Value != 0.5 {
+		t.Fatalf("adjusted during cooldown: particles=%v", particles.Value)
+	}
+	for i := 0; i < 4; i++ {
+		q.Update(slow)
+	}
+	if particles.Value != 0 || scale.Value != 0.75 {
+		t.Fatalf("after further degrade: particles=%v scale=%v", particles.Value, scale.Value)
+	}
+	if s := q.State(); s.Level != 3 {
+		t.Fatalf("got level %d, want 3", s.Level)
+	}
+
+	// Improving raises the last-registered knob first.
+	for i := 0; i < 4; i++ {
+		q.Update(fast)
+	}
+	if particles.Value != 0 || scale.Value != 1 {
+		t.Fatalf("after improve: particles=%v scale=%v", particles.Value, scale.Value)
+	}
+}
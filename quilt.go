@@ -0,0 +1,202 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// Quilt describes the layout of a multi-view 'quilt' texture, as used by
+// lenticular (lightfield) displays. A quilt is a single texture divided into
+// a grid of Columns*Rows tiles, where each tile holds the scene as seen from a
+// slightly different horizontal viewing angle.
+//
+// Views are numbered starting at zero with the left-most view, in the
+// bottom-left tile of the quilt, continuing left-to-right and then
+// bottom-to-top.
+//
+// A typical usage is to render each view into a render-to-texture canvas and
+// then draw the quilt texture onto the display using the shader returned by
+// NewLenticularShader:
+//  view := gfx.NewCamera()
+//  for i := 0; i < q.Views(); i++ {
+//      q.ViewCamera(view, cam, i, focalDist)
+//      for _, o := range objects {
+//          quiltCanvas.Draw(q.ViewRect(i), o, view)
+//      }
+//  }
+//  quiltCanvas.Render()
+type Quilt struct {
+	// The number of columns and rows of tiles in the quilt.
+	Columns, Rows int
+
+	// The bounds of the quilt texture. Each tile is of size:
+	//  Bounds.Dx() / Columns
+	//  Bounds.Dy() / Rows
+	Bounds image.Rectangle
+
+	// The total horizontal viewing cone of the display, in degrees. The
+	// left-most and right-most views are rendered at -ViewCone/2 and
+	// +ViewCone/2 degrees, respectively.
+	ViewCone float64
+}
+
+// Views returns the total number of views in the quilt.
+func (q Quilt) Views() int {
+	return q.Columns * q.Rows
+}
+
+// ViewRect returns the rectangle of the quilt texture that the given view
+// should be drawn into. A panic will occur if the view is out of range.
+func (q Quilt) ViewRect(view int) image.Rectangle {
+	if view < 0 || view >= q.Views() {
+		panic("ViewRect(): view out of range")
+	}
+	w := q.Bounds.Dx() / q.Columns
+	h := q.Bounds.Dy() / q.Rows
+	col := view % q.Columns
+	row := view / q.Columns
+
+	// Rows are counted from the bottom, but image coordinates are top-left.
+	x := q.Bounds.Min.X + col*w
+	y := q.Bounds.Max.Y - (row+1)*h
+	return image.Rect(x, y, x+w, y+h)
+}
+
+// ViewOffset returns the horizontal offset (along the camera's local X axis)
+// of the given view, such that the view sees the focal plane, at focalDist
+// units in front of the camera, at it's angle within the viewing cone.
+func (q Quilt) ViewOffset(view int, focalDist float64) float64 {
+	views := q.Views()
+	if views <= 1 {
+		return 0
+	}
+	cone := lmath.Radians(q.ViewCone)
+	angle := (float64(view)/float64(views-1) - 0.5) * cone
+	return focalDist * math.Tan(angle)
+}
+
+// ViewCamera sets up the dst camera to render the given view of the quilt,
+// based on the src camera. The dst camera's transform is parented to the src
+// camera's transform and offset horizontally (see ViewOffset), and it's
+// projection is the src camera's projection, skewed such that all views
+// converge on the focal plane.
+//
+// The write lock of dst and the read lock of src must be held for this method
+// to operate safely.
+func (q Quilt) ViewCamera(dst, src *Camera, view int, focalDist float64) {
+	offset := q.ViewOffset(view, focalDist)
+	dst.Object.Transform.SetParent(src.Object.Transform)
+	dst.Object.Transform.SetPos(lmath.Vec3{X: offset})
+
+	// Skew the projection such that the point at the focal distance remains
+	// at the same position on screen in every view.
+	proj := src.Projection
+	proj[2][0] -= float32(offset / focalDist * float64(proj[0][0]))
+	dst.Projection = proj
+}
+
+// LenticularCalibration represents the per-device calibration values of a
+// lenticular display, which are used to interlace the views of a quilt.
+type LenticularCalibration struct {
+	// The number of lenticular lenses per screen width.
+	Pitch float64
+
+	// The slope of the lenses, as the horizontal shift per screen height.
+	Tilt float64
+
+	// The horizontal offset of the center view, in lens-widths.
+	Center float64
+
+	// The width of a single subpixel, relative to the screen width (i.e.
+	// typically 1.0 / (3 * screenWidth)).
+	Subpixel float64
+
+	// Whether or not the view order is inverted by the lenses.
+	InvertViews bool
+}
+
+var lenticularVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec2 TexCoord0;
+
+uniform mat4 MVP;
+
+varying vec2 tc0;
+
+void main()
+{
+	tc0 = TexCoord0;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+var lenticularFrag = []byte(`
+#version 120
+
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+
+uniform float Pitch;
+uniform float Tilt;
+uniform float Center;
+uniform float Subpixel;
+uniform float InvertViews;
+uniform vec3 QuiltTiles; // Columns, Rows, Views.
+
+vec2 quiltCoords(vec2 pos, float view)
+{
+	float v = floor(view * QuiltTiles.z);
+	vec2 tile = vec2(mod(v, QuiltTiles.x), floor(v / QuiltTiles.x));
+	return (tile + pos) / QuiltTiles.xy;
+}
+
+void main()
+{
+	// Texture coordinates are top-left origin, the display is bottom-left.
+	vec2 uv = vec2(tc0.x, 1.0 - tc0.y);
+	vec3 rgb;
+	for(int i = 0; i < 3; i++) {
+		float view = (uv.x + float(i) * Subpixel + uv.y * Tilt) * Pitch - Center;
+		view = fract(view);
+		view = mix(view, 1.0 - view, InvertViews);
+		vec2 tc = quiltCoords(uv, view);
+		rgb[i] = texture2D(Texture0, vec2(tc.x, 1.0 - tc.y))[i];
+	}
+	gl_FragColor = vec4(rgb, 1.0);
+}
+`)
+
+// NewLenticularShader returns a new shader which, when used to draw a quad
+// textured with the quilt texture (as the first texture of the object) onto
+// a lenticular display, interlaces the views of the quilt according to the
+// given display calibration.
+func NewLenticularShader(q Quilt, c LenticularCalibration) *Shader {
+	s := NewShader("LenticularQuilt")
+	s.GLSLVert = append(s.GLSLVert, lenticularVert...)
+	s.GLSLFrag = append(s.GLSLFrag, lenticularFrag...)
+
+	var invert float32
+	if c.InvertViews {
+		invert = 1
+	}
+	s.Inputs["Pitch"] = float32(c.Pitch)
+	s.Inputs["Tilt"] = float32(c.Tilt)
+	s.Inputs["Center"] = float32(c.Center)
+	s.Inputs["Subpixel"] = float32(c.Subpixel)
+	s.Inputs["InvertViews"] = invert
+	s.Inputs["QuiltTiles"] = Vec3{
+		X: float32(q.Columns),
+		Y: float32(q.Rows),
+		Z: float32(q.Views()),
+	}
+	return s
+}
@@ -0,0 +1,94 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestQuiltViewRect(t *testing.T) {
+	q := Quilt{Columns: 5, Rows: 9, Bounds: image.Rect(0, 0, 4096, 4096)}
+	if q.Views() != 45 {
+		t.Fatalf("got %d views, want 45", q.Views())
+	}
+	w, h := 4096/5, 4096/9
+	for _, c := range []struct {
+		view int
+		want image.Rectangle
+	}{
+		{0, image.Rect(0, 4096-h, w, 4096)},            // Bottom-left.
+		{4, image.Rect(4*w, 4096-h, 5*w, 4096)},        // Bottom-right.
+		{5, image.Rect(0, 4096-2*h, w, 4096-h)},        // Start of the second row.
+		{40, image.Rect(0, 4096-9*h, w, 4096-8*h)},     // Top-left.
+		{44, image.Rect(4*w, 4096-9*h, 5*w, 4096-8*h)}, // Top-right.
+	} {
+		if got := q.ViewRect(c.view); got != c.want {
+			t.Errorf("ViewRect(%d) = %v, want %v", c.view, got, c.want)
+		}
+	}
+
+	// Tiles never overlap nor exceed the bounds, even though the bounds are
+	// not divisible by the number of columns and rows.
+	for i := 0; i < q.Views(); i++ {
+		r := q.ViewRect(i)
+		if !r.In(q.Bounds) {
+			t.Errorf("ViewRect(%d) = %v is out of bounds", i, r)
+		}
+		for j := 0; j < i; j++ {
+			if r.Overlaps(q.ViewRect(j)) {
+				t.Errorf("ViewRect(%d) overlaps ViewRect(%d)", i, j)
+			}
+		}
+	}
+}
+
+func TestQuiltSingleView(t *testing.T) {
+	b := image.Rect(10, 20, 110, 70)
+	q := Quilt{Columns: 1, Rows: 1, Bounds: b, ViewCone: 40}
+	if got := q.ViewRect(0); got != b {
+		t.Fatalf("ViewRect(0) = %v, want the quilt bounds %v", got, b)
+	}
+	if got := q.ViewOffset(0, 10); got != 0 {
+		t.Fatalf("ViewOffset(0) = %v, want zero for a single view", got)
+	}
+}
+
+func TestQuiltViewRectOutOfRange(t *testing.T) {
+	q := Quilt{Columns: 2, Rows: 2, Bounds: image.Rect(0, 0, 100, 100)}
+	for _, view := range []int{-1, 4} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("ViewRect(%d): expected panic", view)
+				}
+			}()
+			q.ViewRect(view)
+		}()
+	}
+}
+
+func TestQuiltViewOffset(t *testing.T) {
+	q := Quilt{Columns: 5, Rows: 1, ViewCone: 40}
+	edge := 10 * math.Tan(20*math.Pi/180)
+	for _, c := range []struct {
+		view int
+		want float64
+	}{
+		{0, -edge}, // Left-most view, at -ViewCone/2.
+		{2, 0},     // Center view.
+		{4, edge},  // Right-most view, at +ViewCone/2.
+	} {
+		if got := q.ViewOffset(c.view, 10); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("ViewOffset(%d) = %v, want %v", c.view, got, c.want)
+		}
+	}
+	for view := 1; view < q.Views(); view++ {
+		if q.ViewOffset(view, 10) <= q.ViewOffset(view-1, 10) {
+			t.Errorf("ViewOffset(%d) is not greater than ViewOffset(%d)", view, view-1)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+
+	"azul3d.org/lmath.v1"
+)
+
+// Rand is a deterministic, seeded, pseudo-random number generator for visual
+// effects and procedural generation (e.g. particles or scattering of foliage).
+//
+// Unlike math/rand, the sequence of numbers generated for a given seed is
+// defined by this package (the SplitMix64 algorithm) and will never change,
+// such that effects are reproducible in replays and tests. Each system should
+// use it's own stream (see the Stream method), such that the numbers it
+// receives do not depend on the order in which other systems consume them.
+//
+// All methods are safe to invoke from multiple goroutines concurrently,
+// although concurrent use of a single generator is only deterministic if the
+// order of the calls is.
+type Rand struct {
+	access sync.Mutex
+	seed   uint64
+	state  uint64
+}
+
+// NewRand returns a new random number generator with the given seed.
+func NewRand(seed uint64) *Rand {
+	return &Rand{seed: seed, state: seed}
+}
+
+// splitMix64 advances the SplitMix64 state and returns the next output.
+func splitMix64(state *uint64) uint64 {
+	*state += 0x9E3779B97F4A7C15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// Stream returns a new generator for the named stream (e.g. "particles" or
+// "grass"). The stream depends only on the seed of this generator and the
+// name, not on how many numbers this generator has produced.
+func (r *Rand) Stream(name string) *Rand {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	r.access.Lock()
+	state := r.seed ^ h.Sum64()
+	r.access.Unlock()
+	return NewRand(splitMix64(&state))
+}
+
+// Seed resets the generator to the start of the sequence of the given seed.
+func (r *Rand) Seed(seed uint64) {
+	r.access.Lock()
+	r.seed, r.state = seed, seed
+	r.access.Unlock()
+}
+
+// State returns the current state of the generator, which can be restored
+// using SetState (e.g. when seeking a replay).
+func (r *Rand) State() uint64 {
+	r.access.Lock()
+	defer r.access.Unlock()
+	return r.state
+}
+
+// SetState restores a state of the generator previously returned by State.
+func (r *Rand) SetState(state uint64) {
+	r.access.Lock()
+	r.state = state
+	r.access.Unlock()
+}
+
+// Uint64 returns a pseudo-random 64-bit integer.
+func (r *Rand) Uint64() uint64 {
+	r.access.Lock()
+	v := splitMix64(&r.state)
+	r.access.Unlock()
+	return v
+}
+
+// Float64 returns a pseudo-random number in the range [0, 1).
+func (r *Rand) Float64() float64 {
+	// Use the top 53 bits, the precision of a float64.
+	return float64(r.Uint64()>>11) / (1 << 53)
+}
+
+// Float32 returns a pseudo-random number in the range [0, 1).
+func (r *Rand) Float32() float32 {
+	return float32(r.Uint64()>>40) / (1 << 24)
+}
+
+// Intn returns a pseudo-random number in the range [0, n). It panics if n <=
+// 0.
+func (r *Rand) Intn(n int) int {
+	if n <= 0 {
+		panic("Intn(): n must be greater than zero")
+	}
+	// Reject values from the incomplete final range, avoiding modulo bias.
+	max := math.MaxUint64 - math.MaxUint64%uint64(n)
+	for {
+		v := r.Uint64()
+		if v < max {
+			return int(v % uint64(n))
+		}
+	}
+}
+
+// Range returns a pseudo-random number in the range [min, max).
+func (r *Rand) Range(min, max float64) float64 {
+	return min + r.Float64()*(max-min)
+}
+
+// UnitVec3 returns a pseudo-random unit vector, uniformly distributed over
+// the sphere.
+func (r *Rand) UnitVec3() lmath.Vec3 {
+	z := r.Range(-1, 1)
+	phi := r.Range(0, 2*math.Pi)
+	s := math.Sqrt(1 - z*z)
+	return lmath.Vec3{s * math.Cos(phi), s * math.Sin(phi), z}
+}
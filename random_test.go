@@ -0,0 +1,61 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRandDeterministic(t *testing.T) {
+	// The reference SplitMix64 outputs for seed zero; these must never change.
+	r := NewRand(0)
+	want := []uint64{0xE220A8397B1DCDAF, 0x6E789E6AA1B965F4, 0x06C45D188009454F}
+	for i, w := range want {
+		if got := r.Uint64(); got != w {
+			t.Fatalf("output %d = %#x, want %#x", i, got, w)
+		}
+	}
+
+	state := r.State()
+	a := r.Float64()
+	r.SetState(state)
+	if b := r.Float64(); a != b {
+		t.Fatalf("SetState did not restore the sequence: %v != %v", a, b)
+	}
+}
+
+func TestRandStream(t *testing.T) {
+	a, b := NewRand(42), NewRand(42)
+	b.Uint64() // Consuming from the parent must not affect streams.
+	sa, sb := a.Stream("particles"), b.Stream("particles")
+	for i := 0; i < 10; i++ {
+		if x, y := sa.Uint64(), sb.Uint64(); x != y {
+			t.Fatalf("streams diverged at %d: %#x != %#x", i, x, y)
+		}
+	}
+	if a.Stream("grass").Uint64() == a.Stream("particles").Uint64() {
+		t.Fatal("differently named streams produced the same output")
+	}
+}
+
+func TestRandRanges(t *testing.T) {
+	r := NewRand(7)
+	counts := make([]int, 5)
+	for i := 0; i < 10000; i++ {
+		if f := r.Float64(); f < 0 || f >= 1 {
+			t.Fatalf("Float64() = %v", f)
+		}
+		counts[r.Intn(5)]++
+		if l := r.UnitVec3().Length(); math.Abs(l-1) > 1e-9 {
+			t.Fatalf("UnitVec3() has length %v", l)
+		}
+	}
+	for i, c := range counts {
+		if c < 1800 || c > 2200 {
+			t.Fatalf("Intn(5) produced %d %d times out of 10000", i, c)
+		}
+	}
+}
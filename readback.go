@@ -0,0 +1,112 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// FeedbackVarying is the name of the vec3 varying which vertex shaders write
+// the (typically world-space) position to, for capture by Canvas.Feedback.
+// For example a skinning vertex shader might declare:
+//  varying vec3 FeedbackPos;
+//  ...
+//  FeedbackPos = (Model * skinned).xyz;
+const FeedbackVarying = "FeedbackPos"
+
+// VertexReadback periodically reads back the vertex positions of an object
+// from the GPU (see Canvas.Feedback), e.g. the positions of a character that
+// is skinned in it's vertex shader, such that they can be used for hit
+// detection on the CPU.
+//
+// Only a single read back is in flight at any given time, and results arrive
+// asynchronously (typically a frame or two after they are requested), so the
+// positions lag slightly behind what is drawn.
+type VertexReadback struct {
+	// The number of frames between read backs, e.g. 4 reads back the
+	// positions at a quarter of the frame rate. Zero or one reads them back
+	// every frame.
+	Interval int
+
+	frame     int
+	pending   chan []Vec3
+	positions []Vec3
+}
+
+// Update should be called once per frame, it requests a new read back of the
+// object's vertex positions as seen by the given camera if one is due, and
+// receives the result of the previous request if it has completed.
+func (r *VertexReadback) Update(c Canvas, o *Object, cam *Camera) {
+	if r.pending != nil {
+		select {
+		case p := <-r.pending:
+			r.pending = nil
+			if p != nil {
+				r.positions = p
+			}
+		default:
+		}
+	}
+	due := r.frame%r.interval() == 0
+	r.frame++
+	if due && r.pending == nil {
+		r.pending = make(chan []Vec3, 1)
+		c.Feedback(o, cam, r.pending)
+	}
+}
+
+func (r *VertexReadback) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// Positions returns the most recently read back vertex positions, three per
+// triangle, or nil if none have been read back yet.
+func (r *VertexReadback) Positions() []Vec3 {
+	return r.positions
+}
+
+// IntersectRay returns the distance along the ray (with the given origin and
+// normalized direction) to the nearest triangle of the read back positions,
+// and whether or not the ray hits any triangle at all.
+func (r *VertexReadback) IntersectRay(origin, dir lmath.Vec3) (dist float64, hit bool) {
+	return intersectTriangles(r.positions, origin, dir)
+}
+
+// intersectTriangles implements the Möller–Trumbore ray-triangle intersection
+// test against each triangle of tris (three vertices per triangle), returning
+// the distance to the nearest one.
+func intersectTriangles(tris []Vec3, origin, dir lmath.Vec3) (dist float64, hit bool) {
+	const epsilon = 1e-9
+	dist = math.Inf(1)
+	for i := 0; i+2 < len(tris); i += 3 {
+		v0, v1, v2 := tris[i].Vec3(), tris[i+1].Vec3(), tris[i+2].Vec3()
+		e1, e2 := v1.Sub(v0), v2.Sub(v0)
+		p := dir.Cross(e2)
+		det := e1.Dot(p)
+		if math.Abs(det) < epsilon {
+			continue
+		}
+		inv := 1 / det
+		s := origin.Sub(v0)
+		u := s.Dot(p) * inv
+		if u < 0 || u > 1 {
+			continue
+		}
+		q := s.Cross(e1)
+		v := dir.Dot(q) * inv
+		if v < 0 || u+v > 1 {
+			continue
+		}
+		if t := e2.Dot(q) * inv; t >= 0 && t < dist {
+			dist, hit = t, true
+		}
+	}
+	return dist, hit
+}
@@ -0,0 +1,50 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+// feedbackCanvas is a canvas whose Feedback method sends a single triangle
+// at z=-5, counting the number of requests.
+type feedbackCanvas struct {
+	Canvas
+	requests int
+}
+
+func (f *feedbackCanvas) Feedback(o *Object, c *Camera, complete chan []Vec3) {
+	f.requests++
+	complete <- []Vec3{{-1, -1, -5}, {1, -1, -5}, {0, 1, -5}}
+}
+
+func TestVertexReadback(t *testing.T) {
+	c := &feedbackCanvas{Canvas: Nil()}
+	r := &VertexReadback{Interval: 4}
+	o := NewObject()
+	for i := 0; i < 8; i++ {
+		r.Update(c, o, nil)
+	}
+	if c.requests != 2 {
+		t.Fatalf("got %d requests over 8 frames, want 2", c.requests)
+	}
+	if len(r.Positions()) != 3 {
+		t.Fatalf("got %d positions, want 3", len(r.Positions()))
+	}
+
+	dist, hit := r.IntersectRay(lmath.Vec3{}, lmath.Vec3{0, 0, -1})
+	if !hit || math.Abs(dist-5) > 1e-9 {
+		t.Fatalf("IntersectRay = %v, %t, want 5, true", dist, hit)
+	}
+	if _, hit := r.IntersectRay(lmath.Vec3{}, lmath.Vec3{0, 0, 1}); hit {
+		t.Fatal("ray pointing away from the triangle hit it")
+	}
+	if _, hit := r.IntersectRay(lmath.Vec3{5, 0, 0}, lmath.Vec3{0, 0, -1}); hit {
+		t.Fatal("ray beside the triangle hit it")
+	}
+}
@@ -5,7 +5,9 @@
 package gfx
 
 import (
+	"fmt"
 	"image"
+	"time"
 
 	"azul3d.org/clock.v1"
 )
@@ -31,6 +33,75 @@ type Precision struct {
 	Samples int
 }
 
+// SwapChainInfo represents the attributes of the framebuffer and swap chain
+// that were actually chosen by the operating system and graphics driver for
+// a window, which may differ from those that were requested.
+type SwapChainInfo struct {
+	// The precision of the window's color, depth, and stencil buffers, and
+	// the number of samples per pixel.
+	Precision
+
+	// Whether or not the framebuffer is sRGB-capable (i.e. whether or not
+	// Canvas.SetSRGB has any effect).
+	SRGB bool
+
+	// The number of buffers in the swap chain, i.e. 1 for single buffering, 2
+	// for double buffering, and 3 for triple buffering.
+	Buffers int
+
+	// The refresh rate, in hertz, of the monitor the window is on, or zero if
+	// it is not known.
+	RefreshRate float64
+
+	// Whether or not vertical sync is active, and the name of the extension
+	// used to control it (e.g. "GLX_EXT_swap_control" or
+	// "WGL_EXT_swap_control_tear"), or an empty string if none is available.
+	VSync          bool
+	VSyncExtension string
+
+	// Whether or not the swap chain and display support HDR output using the
+	// EncodeHDR10 and EncodeScRGB encodings, see Canvas.SetOutputTransform.
+	HDR10, ScRGB bool
+
+	// Whether or not adaptive vertical sync is active, i.e. late frames are
+	// presented immediately instead of waiting for the next vertical blank.
+	AdaptiveVSync bool
+}
+
+// String returns a human-readable summary of the swap chain, e.g. for
+// logging what was actually chosen at startup.
+func (s SwapChainInfo) String() string {
+	p := s.Precision
+	str := fmt.Sprintf("RGBA %d/%d/%d/%d, depth %d, stencil %d, %d samples, sRGB %t, %d buffers, vsync %t",
+		p.RedBits, p.GreenBits, p.BlueBits, p.AlphaBits,
+		p.DepthBits, p.StencilBits, p.Samples,
+		s.SRGB, s.Buffers, s.VSync,
+	)
+	if s.AdaptiveVSync {
+		str += " (adaptive)"
+	}
+	if s.VSyncExtension != "" {
+		str += " via " + s.VSyncExtension
+	}
+	return str
+}
+
+// PipelineStats represents statistics about the work done by the graphics
+// pipeline during a single frame, as counted by the graphics hardware.
+type PipelineStats struct {
+	// The number of vertices submitted to the graphics pipeline.
+	Vertices int64
+
+	// The number of primitives (e.g. triangles) generated by primitive
+	// assembly, before clipping and culling.
+	Primitives int64
+
+	// The number of samples that passed the depth and stencil tests. Comparing
+	// this against the number of pixels in the canvas gives a measure of
+	// overdraw.
+	Samples int64
+}
+
 // Canvas defines a canvas that can be drawn to (i.e. a window that the user
 // will visibly see, or a texture that will store the results for later use).
 //
@@ -38,6 +109,25 @@ type Precision struct {
 type Canvas interface {
 	Downloadable
 
+	// DownloadDepth should download the given intersecting rectangle of this
+	// canvas's depth buffer from the graphics hardware into system memory and
+	// send it to the complete channel when done. It is analogous to the
+	// Download method (which downloads the color buffer).
+	//
+	// The rectangle is first clamped to the canvas's bounds (like all
+	// rectangles given to canvas methods):
+	//  b := r.Intersect(canvas.Bounds())
+	// The depth values are in the range of 0.0 to 1.0 (where 1.0 is furthest
+	// away), in row-major order starting at the top-left of the clamped
+	// rectangle, such that there are b.Dx()*b.Dy() values and the depth of the
+	// pixel at (x, y) is:
+	//  depth[(y-b.Min.Y)*b.Dx() + (x-b.Min.X)]
+	//
+	// If the canvas does not have a depth buffer, or downloading it is
+	// impossible (i.e. hardware does not support it) then nil will be sent
+	// over the channel.
+	DownloadDepth(r image.Rectangle, complete chan []float32)
+
 	// SetMSAA should request that this canvas use multi-sample anti-aliasing
 	// during rendering. By default MSAA is enabled.
 	//
@@ -49,6 +139,37 @@ type Canvas interface {
 	// MSAA returns the last value passed into SetMSAA on this renderer.
 	MSAA() bool
 
+	// SetSRGB should request that this canvas perform gamma-correct writes,
+	// i.e. that colors written to the canvas (which are in linear space) are
+	// encoded as sRGB. By default sRGB writes are disabled.
+	//
+	// This allows lighting to be computed in linear space while the final
+	// output is correctly encoded for display. Blending occurs in linear
+	// space as well.
+	//
+	// Even if sRGB writes are requested to be enabled, there is no guarantee
+	// that they will actually be used. For instance if the graphics hardware
+	// does not support them (see GPUInfo.SRGB).
+	SetSRGB(enabled bool)
+
+	// SRGB returns the last value passed into SetSRGB on this canvas.
+	SRGB() bool
+
+	// SetOutputTransform should request that this canvas transform the
+	// linear colors written to it for display as described by the given
+	// output transform (e.g. a gamma curve, or an HDR encoding). By default
+	// the DefaultOutputTransform is used. Calling SetSRGB(true) is equivalent
+	// to using the EncodeSRGB encoding.
+	//
+	// If the output transform cannot be applied (e.g. an HDR encoding on a
+	// display that does not support it, see SwapChainInfo.HDR10 and
+	// SwapChainInfo.ScRGB) then the renderer falls back to EncodeSRGB.
+	SetOutputTransform(t OutputTransform)
+
+	// OutputTransform returns the last value passed into SetOutputTransform
+	// on this canvas.
+	OutputTransform() OutputTransform
+
 	// Precision should return the precision of the canvas's color, depth, and
 	// stencil buffers.
 	Precision() Precision
@@ -124,6 +245,84 @@ type Canvas interface {
 	// GPUInfo.OcclusionQuery) then this function is no-op.
 	QueryWait()
 
+	// InsertFence submits a fence into the stream of operations of this
+	// canvas and returns it. The fence becomes signaled once all operations
+	// submitted before it have completed on the graphics hardware, which the
+	// CPU may wait for using the fence's Wait method.
+	//
+	// If the GPU does not support fences (see GPUInfo.Fence) then the returned
+	// fence is signaled once the frame it was inserted into has been rendered.
+	InsertFence() Fence
+
+	// WaitFence submits an operation which causes the graphics hardware to
+	// wait until the given fence (which may come from another canvas) is
+	// signaled before processing any operations submitted after it. The CPU
+	// does not block.
+	//
+	// If the GPU does not support fences (see GPUInfo.Fence) then the CPU
+	// waits for the fence instead, once the operation is processed.
+	WaitFence(f Fence)
+
+	// BeginTimer submits an operation that begins timing (on the GPU) all of
+	// the operations submitted after it, until a matching EndTimer call with
+	// the same name. This allows attributing GPU time to parts of a frame,
+	// e.g. the shadow pass, main pass, and post-processing:
+	//  c.BeginTimer("shadows")
+	//  ... draw shadow casters ...
+	//  c.EndTimer("shadows")
+	//
+	// Timers may not be nested or overlap with other timers of the same name.
+	// Timers of different names may overlap.
+	//
+	// If the GPU does not support timer queries (see GPUInfo.TimerQuery) then
+	// this function is no-op.
+	BeginTimer(name string)
+
+	// EndTimer submits an operation that ends timing the operations since the
+	// last BeginTimer call with the same name. If no such timer was begun then
+	// this function is no-op.
+	EndTimer(name string)
+
+	// Timers returns the most recent GPU time spent per named timer (see
+	// BeginTimer). Since waiting for timer results would stall the graphics
+	// pipeline, results become available asynchronously, typically a few
+	// frames after they were measured.
+	//
+	// The returned map is a copy and may be modified freely. If the GPU does
+	// not support timer queries (see GPUInfo.TimerQuery) then nil is
+	// returned.
+	Timers() map[string]time.Duration
+
+	// Feedback submits an operation that processes the meshes of the given
+	// object with the vertex shader of it's shader (as if drawn by the given
+	// camera, see Draw) without rasterizing them, capturing the vec3 varying
+	// named by FeedbackVarying and sending it to the complete channel once
+	// done. One value is captured per vertex of each triangle (i.e. indexed
+	// meshes are expanded), three per triangle.
+	//
+	// This is typically used to read back the positions of meshes skinned on
+	// the GPU at a reduced rate, e.g. for hit detection against animated
+	// characters (see VertexReadback).
+	//
+	// If the GPU does not support transform feedback (see
+	// GPUInfo.TransformFeedback), or the object would not be drawn (see
+	// Draw), then nil is sent over the channel.
+	Feedback(o *Object, c *Camera, complete chan []Vec3)
+
+	// ResolveTo submits a resolve operation to the renderer. Once all pending
+	// operations on this canvas are finalized, it resolves the color and depth
+	// buffers of this canvas into the dst canvas. If this canvas is
+	// multisampled then the samples of each pixel are averaged (i.e. the
+	// result is antialiased), otherwise the buffers are simply copied.
+	//
+	// Typically this is used to resolve a multisampled render-to-texture
+	// canvas (see RTTConfig.Samples) into a non-multisampled one whose
+	// textures can then be sampled in later passes.
+	//
+	// If the bounds of the canvases differ the results are scaled to fit the
+	// bounds of dst.
+	ResolveTo(dst Canvas)
+
 	// Render should finalize all pending clear and draw operations as if they
 	// where all submitted over a single channel like so:
 	//  pending := len(ops)
@@ -158,6 +357,36 @@ type GPUInfo struct {
 	// store then it is generally (but not always) clamped to that value.
 	OcclusionQueryBits int
 
+	// Whether or not fences are natively supported by the GPU (see
+	// Canvas.InsertFence).
+	Fence bool
+
+	// Whether or not GPU timer queries are supported (see Canvas.BeginTimer).
+	TimerQuery bool
+
+	// Whether or not pipeline statistics are supported (see
+	// Renderer.PipelineStats).
+	PipelineStats bool
+
+	// Whether or not pixel transfers (downloads, see Downloadable, and
+	// texture uploads, see Renderer.LoadTexture) are performed asynchronously
+	// using pixel buffer objects. If false then transfers stall rendering
+	// until they complete.
+	AsyncTransfer bool
+
+	// Whether or not transform feedback is supported by the GPU (see
+	// Canvas.Feedback).
+	TransformFeedback bool
+
+	// Whether or not conditional rendering based on occlusion query results is
+	// supported by the GPU (see Object.Condition).
+	ConditionalRender bool
+
+	// Whether or not linked shader program binaries can be retrieved from and
+	// loaded into the driver (e.g. glGetProgramBinary), such that they may be
+	// cached between runs (see ProgramCache).
+	ProgramBinary bool
+
 	// The name of the graphics hardware, or an empty string if not available.
 	// For example it may look something like:
 	//  Mesa DRI Intel(R) Sandybridge Mobile
@@ -168,6 +397,11 @@ type GPUInfo struct {
 	//  Intel Open Source Technology Center
 	Vendor string
 
+	// The version string of the graphics driver, or an empty string if not
+	// available. For example:
+	//  3.0 Mesa 10.1.3
+	DriverVersion string
+
 	// Whether or not the graphics hardware supports Non Power Of Two texture
 	// sizes.
 	//
@@ -180,9 +414,64 @@ type GPUInfo struct {
 	// nearest power-of-two.
 	NPOT bool
 
+	// The texture formats supported by the graphics hardware, in particular
+	// the compressed and floating-point ones (see TexFormat.Compressed and
+	// TexFormat.Float). Textures whose format is not in this list are stored
+	// using a similar supported format (see NegotiateTexFormat), e.g. a
+	// RGBA16F texture may be stored as RGBA32F, or as RGBA (clamping values)
+	// if no float formats are supported.
+	TexFormats []TexFormat
+
+	// Whether or not the graphics hardware supports sRGB textures and sRGB
+	// (i.e. gamma-correct) writes to canvases. See Texture.SRGB and
+	// Canvas.SetSRGB.
+	SRGB bool
+
+	// The maximum number of layers of a TextureArray texture, or zero if
+	// texture arrays are not supported.
+	MaxTextureLayers int
+
+	// Whether or not the graphics hardware supports seamless filtering across
+	// the faces of CubeMap textures.
+	SeamlessCubeMap bool
+
+	// The maximum size of any dimension of a Texture3D texture, or zero if
+	// 3D textures are not supported.
+	MaxTexture3DSize int
+
+	// The maximum number of texels of a BufferTexture texture, or zero if
+	// buffer textures are not supported.
+	MaxBufferTextureSize int
+
+	// The maximum degree of anisotropic filtering (see Texture.Anisotropy),
+	// or one if anisotropic filtering is not supported.
+	MaxAnisotropy float64
+
+	// Whether or not the graphics hardware supports texture swizzling (see
+	// Texture.Swizzle).
+	TexSwizzle bool
+
+	// The maximum number of user-defined clip distances that may be enabled
+	// at once (see State.ClipDistances), or zero if not supported.
+	MaxClipDistances int
+
+	// Whether or not the graphics hardware natively supports primitive
+	// restart (see Mesh.PrimitiveRestart). If false, the renderer emulates it
+	// by splitting the mesh into multiple draw calls.
+	PrimitiveRestart bool
+
 	// The formats available for render-to-texture (RTT).
 	RTTFormats
 
+	// The maximum number of color textures that may be rendered to at once
+	// (see RTTConfig.ExtraColor), or 1 if multiple render targets are not
+	// supported.
+	MaxDrawBuffers int
+
+	// Whether or not the graphics hardware supports a distinct blend state
+	// and color write mask per render target (see State.IndependentBlend).
+	IndependentBlend bool
+
 	// Major and minor versions of the OpenGL version in use, or -1 if not
 	// available. For example:
 	//  3, 0 (for OpenGL 3.0)
@@ -226,6 +515,27 @@ type Renderer interface {
 	// GPUInfo should return information about the graphics hardware.
 	GPUInfo() GPUInfo
 
+	// PipelineStats should return the pipeline statistics of the most
+	// recently completed frame. Since waiting for the results would stall the
+	// graphics pipeline, they typically lag behind by a few frames.
+	//
+	// If the GPU does not support pipeline statistics (see
+	// GPUInfo.PipelineStats) then the zero value is returned.
+	PipelineStats() PipelineStats
+
+	// SwapChain should return the attributes of the framebuffer and swap
+	// chain that were actually chosen for the renderer's window. It returns
+	// the zero value if the renderer is not rendering to a window.
+	SwapChain() SwapChainInfo
+
+	// Hooks should return the frame hooks of the renderer, which it runs
+	// around presenting each frame (i.e. in it's Render method).
+	Hooks() *FrameHooks
+
+	// Events should return the frame lifecycle events of the renderer, which
+	// it emits as it renders each frame (i.e. in it's Render method).
+	Events() *FrameEvents
+
 	// LoadMesh should begin loading the specified mesh asynchronously.
 	//
 	// Additionally, the renderer will set m.Loaded to true, and then invoke
@@ -235,6 +545,10 @@ type Renderer interface {
 	// the future when the load operation completes. The mesh will be sent over
 	// the done channel once the load operation has completed if the channel is
 	// not nil and sending would not block.
+	//
+	// If the mesh is already loaded and has pending partial updates (see the
+	// Mesh.UpdateRange method) then only the given ranges of it's data slices
+	// are uploaded, in-place.
 	LoadMesh(m *Mesh, done chan *Mesh)
 
 	// LoadTexture should begin loading the specified texture asynchronously.
@@ -246,8 +560,38 @@ type Renderer interface {
 	// in the future when the load operation completes. The texture will be
 	// sent over the done channel once the load operation has completed if the
 	// channel is not nil and sending would not block.
+	//
+	// If the graphics hardware supports asynchronous pixel transfers (see
+	// GPUInfo.AsyncTransfer) then the image data is uploaded through a pixel
+	// buffer, such that large uploads do not stall rendering. The texture is
+	// only sent over the done channel once the upload has completed.
+	//
+	// If the texture is a TextureArray, Texture3D, or CubeMap that is already
+	// loaded, only it's non-nil layers are uploaded (see the Texture.Layers
+	// field).
+	//
+	// If the texture is already loaded and has pending partial updates (see
+	// the Texture.UpdateRect method) then they are applied in-place, and the
+	// source image is not uploaded again.
+	//
+	// If the texture's explicitly provided mipmaps are not valid (see the
+	// Texture.MipmapsValid method) then they are ignored and the mipmaps are
+	// generated automatically instead.
 	LoadTexture(t *Texture, done chan *Texture)
 
+	// DownloadTexture should download the entire base level of the given
+	// loaded texture (e.g. the color or depth texture of a render-to-texture
+	// canvas) from the graphics hardware into system memory and send it to
+	// the complete channel when done, such that tools can inspect, save, or
+	// hash textures which reside only on the GPU.
+	//
+	// The renderer will lock the texture and it may stay locked until some
+	// point in the future when the download operation completes.
+	//
+	// If the texture is not loaded, or downloading it is impossible (e.g. it
+	// has a compressed format) then nil will be sent over the channel.
+	DownloadTexture(t *Texture, complete chan image.Image)
+
 	// LoadShader should begin loading the specified shader asynchronously.
 	//
 	// Additionally, if the shader was successfully loaded (no error log was
@@ -275,5 +619,9 @@ type Renderer interface {
 	// Any non-nil texture in the configuration will be set to loaded, will
 	// have ClearData() called on it, and will have it's bounds set to
 	// cfg.Bounds.
+	//
+	// Any texture in the configuration that is already loaded is attached to
+	// the canvas directly, without allocating new storage for it (see the
+	// RTTConfig.Color field).
 	RenderToTexture(cfg RTTConfig) Canvas
 }
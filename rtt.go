@@ -129,6 +129,12 @@ type RTTConfig struct {
 
 	// The number of samples to use for multisampling. It should be one of the
 	// numbers listed in the GPUInfo.RTTFormats structure.
+	//
+	// Textures themselves are never multisampled: if Samples > 1 then the
+	// canvas renders into multisampled buffers, which are resolved into the
+	// textures of the configuration each time the canvas's Render method is
+	// called. To resolve into another canvas explicitly, use the ResolveTo
+	// method of the canvas.
 	Samples int
 
 	// Color, Depth, and Stencil textures, each of these texture's Format
@@ -139,8 +145,46 @@ type RTTConfig struct {
 	// Specify nil for any you do not intend to use as a texture (e.g. if you
 	// want a 16-bit depth buffer but do not intend to use it as a texture, you
 	// could set Depth == nil and DepthFormat == Depth16).
+	//
+	// If a texture is already loaded (e.g. it was previously loaded via
+	// Renderer.LoadTexture or used by another render-to-texture canvas) then
+	// the canvas renders directly into it's existing storage instead of
+	// allocating new storage, so that the results may be sampled in later
+	// passes (e.g. shadow maps, reflections, post effects) without copies. In
+	// this case the texture's bounds must be equal to the Bounds field (see
+	// the Valid method).
+	//
+	// A Depth texture may be sampled like any other texture, or with hardware
+	// depth comparison for shadow mapping (see Texture.DepthCompare).
 	Color, Depth, Stencil *Texture
 
+	// Additional color textures for rendering to multiple render targets at
+	// once (MRT). The results of fragment shader output N (e.g. gl_FragData[N]
+	// in GLSL) are stored into ExtraColor[N-1], while output zero is stored
+	// into the Color texture. Each texture is stored using ColorFormat.
+	//
+	// The total number of color textures (i.e. including Color) may not
+	// exceed GPUInfo.MaxDrawBuffers.
+	ExtraColor []*Texture
+
+	// An optional velocity texture, which receives the screen-space motion
+	// vectors written by shaders (see VelocityGLSLFrag) to the fragment
+	// shader output following the ExtraColor outputs (i.e.
+	// gl_FragData[len(ExtraColor)+1]), for use by e.g. temporal anti-aliasing
+	// and motion blur passes. It is stored in the RGBA16F format (only the
+	// red and green components are used) and counts towards the
+	// GPUInfo.MaxDrawBuffers limit.
+	Velocity *Texture
+
+	// The layer of the textures to render into, for textures whose type is
+	// TextureArray, Texture3D, or CubeMap (e.g. CubePositiveX to render into
+	// the positive X face of a cube map). For any other type of texture it
+	// must be zero.
+	//
+	// Rendering into each face of a cube map thus requires six canvases (one
+	// per face) that share the same textures.
+	Layer int
+
 	// Color format to use for the color buffer, it should be one listed in the
 	// GPUInfo.RTTFormats structure.
 	ColorFormat TexFormat
@@ -162,6 +206,15 @@ type RTTConfig struct {
 //  2. Any non-nil texture is not accompanies by a format.
 //  3. Either DepthFormat.IsCombined() or StencilFormat.IsCombined() and the other
 //     is not.
+//  4. Any ExtraColor texture is nil, or ExtraColor or Velocity is used
+//     without Color.
+//  5. Any loaded texture's bounds differ from a non-empty Bounds field.
+//  6. Layer is negative, non-zero for a Texture2D texture, not a valid face
+//     index for a CubeMap texture, or not less than the number of layers of
+//     a TextureArray or Texture3D texture (i.e. len(Layers)).
+//
+// The read lock of each texture must be held for this method to operate
+// safely.
 func (c RTTConfig) Valid() bool {
 	if c.Color == nil && c.Depth == nil && c.Stencil == nil {
 		return false
@@ -175,6 +228,48 @@ func (c RTTConfig) Valid() bool {
 	if c.Stencil != nil && c.StencilFormat == ZeroDSFormat {
 		return false
 	}
+	if (len(c.ExtraColor) > 0 || c.Velocity != nil) && c.Color == nil {
+		return false
+	}
+	for _, t := range c.ExtraColor {
+		if t == nil {
+			return false
+		}
+	}
+
+	// Loaded (i.e. user-supplied) textures must match the canvas bounds.
+	if !c.Bounds.Empty() {
+		textures := append([]*Texture{c.Color, c.Depth, c.Stencil, c.Velocity}, c.ExtraColor...)
+		for _, t := range textures {
+			if t != nil && t.Loaded && t.Bounds != c.Bounds {
+				return false
+			}
+		}
+	}
+
+	// The layer must exist in each texture.
+	if c.Layer < 0 {
+		return false
+	}
+	for _, t := range append([]*Texture{c.Color, c.Depth, c.Stencil, c.Velocity}, c.ExtraColor...) {
+		if t == nil {
+			continue
+		}
+		switch t.Type {
+		case Texture2D:
+			if c.Layer != 0 {
+				return false
+			}
+		case CubeMap:
+			if c.Layer > CubeNegativeZ {
+				return false
+			}
+		case TextureArray, Texture3D:
+			if c.Layer >= len(t.Layers) {
+				return false
+			}
+		}
+	}
 
 	if c.DepthFormat.IsCombined() != c.StencilFormat.IsCombined() {
 		return false
@@ -221,6 +316,11 @@ func (s chooseTexFormats) Less(ii, jj int) bool {
 	// Choose the closest.
 	iDist := absInt(pb - i)
 	jDist := absInt(pb - j)
+	if iDist == jDist {
+		// Prefer fixed-point formats, e.g. RGBA over R11G11B10F, since float
+		// formats are usually only desired when explicitly requested.
+		return !s.s[ii].Float() && s.s[jj].Float()
+	}
 	return iDist < jDist
 }
 
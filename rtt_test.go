@@ -0,0 +1,41 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"testing"
+)
+
+func TestRTTConfigValidLayer(t *testing.T) {
+	tex := func(typ TexType, layers int) *Texture {
+		t := NewTexture()
+		t.Type = typ
+		t.Layers = make([]image.Image, layers)
+		return t
+	}
+	tests := []struct {
+		tex   *Texture
+		layer int
+		valid bool
+	}{
+		{tex(Texture2D, 0), 0, true},
+		{tex(Texture2D, 0), 1, false},
+		{tex(Texture2D, 0), -1, false},
+		{tex(CubeMap, 6), CubeNegativeZ, true},
+		{tex(CubeMap, 6), CubeNegativeZ + 1, false},
+		{tex(TextureArray, 3), 2, true},
+		{tex(TextureArray, 3), 3, false},
+		{tex(TextureArray, 3), -1, false},
+		{tex(Texture3D, 4), 3, true},
+		{tex(Texture3D, 4), 4, false},
+	}
+	for _, tst := range tests {
+		cfg := RTTConfig{Color: tst.tex, ColorFormat: RGBA, Layer: tst.layer}
+		if got := cfg.Valid(); got != tst.valid {
+			t.Errorf("%v with %d layers, layer %d: Valid() = %t, want %t", tst.tex.Type, len(tst.tex.Layers), tst.layer, got, tst.valid)
+		}
+	}
+}
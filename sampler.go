@@ -0,0 +1,81 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// Sampler represents the state used to sample a texture (wrapping,
+// filtering, level-of-detail, and depth comparison), decoupled from the
+// texture itself such that one texture may be sampled differently in
+// different passes without duplicating it's storage on the GPU. See the
+// Object.Samplers field.
+//
+// The fields have the same meaning as the equally named fields of the Texture
+// type. Samplers are compared by value, renderers typically create a single
+// GPU sampler object for each unique sampler.
+type Sampler struct {
+	// The U, V, and W wrap modes.
+	WrapU, WrapV, WrapW TexWrap
+
+	// The color of the border when a wrap mode is set to BorderColor.
+	BorderColor Color
+
+	// The texture filtering used for minification and magnification.
+	MinFilter, MagFilter TexFilter
+
+	// The degree of anisotropic filtering.
+	Anisotropy float64
+
+	// The minimum and maximum level-of-detail, and the level-of-detail bias.
+	MinLOD, MaxLOD, LODBias float64
+
+	// Whether or not a depth comparison is performed, and it's operator.
+	DepthCompare bool
+	DepthCmp     Cmp
+}
+
+// DefaultSampler is the default sampler state, equal to that of a new
+// texture (see NewTexture).
+var DefaultSampler = Sampler{
+	MinLOD: -1000,
+	MaxLOD: 1000,
+}
+
+// Sampler returns the sampling state of this texture as a sampler.
+//
+// The texture's read lock must be held for this method to operate safely.
+func (t *Texture) Sampler() Sampler {
+	return Sampler{
+		WrapU:        t.WrapU,
+		WrapV:        t.WrapV,
+		WrapW:        t.WrapW,
+		BorderColor:  t.BorderColor,
+		MinFilter:    t.MinFilter,
+		MagFilter:    t.MagFilter,
+		Anisotropy:   t.Anisotropy,
+		MinLOD:       t.MinLOD,
+		MaxLOD:       t.MaxLOD,
+		LODBias:      t.LODBias,
+		DepthCompare: t.DepthCompare,
+		DepthCmp:     t.DepthCmp,
+	}
+}
+
+// SetSampler sets the sampling state of this texture to that of the given
+// sampler.
+//
+// The texture's write lock must be held for this method to operate safely.
+func (t *Texture) SetSampler(s Sampler) {
+	t.WrapU = s.WrapU
+	t.WrapV = s.WrapV
+	t.WrapW = s.WrapW
+	t.BorderColor = s.BorderColor
+	t.MinFilter = s.MinFilter
+	t.MagFilter = s.MagFilter
+	t.Anisotropy = s.Anisotropy
+	t.MinLOD = s.MinLOD
+	t.MaxLOD = s.MaxLOD
+	t.LODBias = s.LODBias
+	t.DepthCompare = s.DepthCompare
+	t.DepthCmp = s.DepthCmp
+}
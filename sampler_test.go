@@ -0,0 +1,28 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestTextureSampler(t *testing.T) {
+	tex := NewTexture()
+	if got := tex.Sampler(); got != DefaultSampler {
+		t.Fatalf("new texture sampler = %+v, want DefaultSampler", got)
+	}
+	s := Sampler{
+		WrapU:      Repeat,
+		WrapV:      Clamp,
+		MinFilter:  LinearMipmapLinear,
+		MagFilter:  Linear,
+		Anisotropy: 8,
+		MaxLOD:     4,
+		LODBias:    -0.5,
+		DepthCmp:   LessOrEqual,
+	}
+	tex.SetSampler(s)
+	if got := tex.Sampler(); got != s {
+		t.Fatalf("got %+v, want %+v", got, s)
+	}
+}
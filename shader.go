@@ -40,6 +40,22 @@ type Shader struct {
 	// The GLSL fragment shader.
 	GLSLFrag []byte
 
+	// The resolver of #include directives within the GLSL sources, or nil if
+	// they do not use any, see the Preprocess method.
+	Include IncludeFunc
+
+	// A map of macro names and values which are injected into the GLSL
+	// sources as #define lines after their #version directive (e.g. NUM_LIGHTS
+	// or USE_FOG), such that quality tiers and feature toggles may reuse a
+	// single source. An empty value defines the macro without a value. They
+	// are injected by the Preprocess method.
+	Defines map[string]string
+
+	// The source maps of the preprocessed GLSL vertex and fragment shader
+	// sources, which map their lines back to the included files. They are set
+	// by the Preprocess method.
+	VertMap, FragMap *SourceMap
+
 	// A map of names and values to use as inputs for the shader program while
 	// rendering. Values must be of the following data types or else they will
 	// be ignored:
@@ -52,6 +68,18 @@ type Shader struct {
 	//  []gfx.Vec4
 	//  gfx.Mat4
 	//  []gfx.Mat4
+	//  int32
+	//  []int32
+	//  gfx.IVec2
+	//  []gfx.IVec2
+	//  gfx.IVec3
+	//  []gfx.IVec3
+	//  gfx.IVec4
+	//  []gfx.IVec4
+	//
+	// Slices are supplied as uniform arrays (e.g. a []gfx.Mat4 as a mat4[]).
+	// Members of struct uniforms (and of arrays of structs) are named as in
+	// GLSL, e.g. "Lights[2].Color", see the SetStruct method.
 	Inputs map[string]interface{}
 
 	// The error log from compiling the shader program, if any. Only set once
@@ -70,11 +98,18 @@ func (s *Shader) Copy() *Shader {
 		s.Name,
 		make([]byte, len(s.GLSLVert)),
 		make([]byte, len(s.GLSLFrag)),
+		s.Include,
+		make(map[string]string, len(s.Defines)),
+		s.VertMap,
+		s.FragMap,
 		make(map[string]interface{}, len(s.Inputs)),
 		nil, // Error slice -- not copied.
 	}
 	copy(cpy.GLSLVert, s.GLSLVert)
 	copy(cpy.GLSLFrag, s.GLSLFrag)
+	for name, value := range s.Defines {
+		cpy.Defines[name] = value
+	}
 	for name := range s.Inputs {
 		cpy.Inputs[name] = s.Inputs[name]
 	}
@@ -101,6 +136,12 @@ func (s *Shader) Reset() {
 	s.Name = ""
 	s.GLSLVert = s.GLSLVert[:0]
 	s.GLSLFrag = s.GLSLFrag[:0]
+	s.Include = nil
+	for k := range s.Defines {
+		delete(s.Defines, k)
+	}
+	s.VertMap = nil
+	s.FragMap = nil
 	for k := range s.Inputs {
 		delete(s.Inputs, k)
 	}
@@ -123,7 +164,8 @@ func (s *Shader) Destroy() {
 var shaderPool = sync.Pool{
 	New: func() interface{} {
 		return &Shader{
-			Inputs: make(map[string]interface{}),
+			Defines: make(map[string]string),
+			Inputs:  make(map[string]interface{}),
 		}
 	},
 }
@@ -0,0 +1,115 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "sync/atomic"
+
+// meshShare is the reference count of a native mesh shared by several meshes.
+type meshShare struct {
+	refs int32
+}
+
+// release releases this mesh's reference to it's native mesh, and reports
+// whether it was the last one (i.e. whether the native mesh should be
+// destroyed).
+func (m *Mesh) release() bool {
+	if m.share == nil {
+		return true
+	}
+	last := atomic.AddInt32(&m.share.refs, -1) == 0
+	m.share = nil
+	return last
+}
+
+// Share returns a new mesh which shares the native mesh (i.e. the GPU buffers)
+// of this loaded mesh, such that many objects may draw the same geometry using
+// distinct transforms, shaders, and textures. Unlike Copy, which duplicates
+// both the data slices and (once loaded) the GPU buffers, nothing is
+// duplicated.
+//
+// The returned mesh is already loaded. It references the same data slices as
+// this mesh (if they were kept, see KeepDataOnLoad), which must be treated as
+// read-only. The native mesh is reference counted and is only destroyed once
+// every mesh sharing it has been destroyed.
+//
+// Shared meshes must not be modified or reloaded, as doing so would modify
+// the GPU buffers of every mesh sharing them. Use Unshare to first give a mesh
+// it's own buffers.
+//
+// Share panics if this mesh is not loaded.
+//
+// The mesh's write lock must be held for this method to operate safely.
+func (m *Mesh) Share() *Mesh {
+	if !m.Loaded || m.NativeMesh == nil {
+		panic("Share(): mesh is not loaded")
+	}
+	if m.share == nil {
+		m.share = &meshShare{refs: 1}
+	}
+	atomic.AddInt32(&m.share.refs, 1)
+
+	s := NewMesh()
+	s.NativeMesh = m.NativeMesh
+	s.Loaded = true
+	s.KeepDataOnLoad = m.KeepDataOnLoad
+	s.Dynamic = m.Dynamic
+	s.Usage = m.Usage
+	s.Interleaved = m.Interleaved
+	s.Primitive = m.Primitive
+	s.PrimitiveRestart = m.PrimitiveRestart
+	s.AABB = m.AABB
+	s.Indices = m.Indices
+	s.Vertices = m.Vertices
+	s.Colors = m.Colors
+	s.Bary = m.Bary
+	s.TexCoords = m.TexCoords
+	for name, a := range m.Attribs {
+		a.Changed = false
+		s.Attribs[name] = a
+	}
+	s.share = m.share
+	return s
+}
+
+// Shared tells if this mesh currently shares it's native mesh with at least
+// one other mesh (see the Share method).
+//
+// The mesh's read lock must be held for this method to operate safely.
+func (m *Mesh) Shared() bool {
+	return m.share != nil && atomic.LoadInt32(&m.share.refs) > 1
+}
+
+// Unshare stops this mesh from sharing it's native mesh with other meshes (see
+// the Share method), such that it may be modified. The data slices are copied
+// (as the other meshes may reference them) and the mesh is marked as not
+// loaded, such that it is loaded into it's own GPU buffers the next time it is
+// loaded or drawn. This requires the mesh's data to have been kept (see
+// KeepDataOnLoad), or new data to be assigned before then.
+//
+// If the mesh is not shared then this method has no effect.
+//
+// The mesh's write lock must be held for this method to operate safely.
+func (m *Mesh) Unshare() {
+	if !m.Shared() {
+		return
+	}
+	m.release()
+	cpy := m.Copy()
+	m.NativeMesh = nil
+	m.Loaded = false
+	m.Indices, m.IndicesChanged = cpy.Indices, true
+	m.Vertices, m.VerticesChanged = cpy.Vertices, true
+	m.Colors, m.ColorsChanged = cpy.Colors, true
+	m.Bary, m.BaryChanged = cpy.Bary, true
+	m.TexCoords = cpy.TexCoords
+	for i := range m.TexCoords {
+		m.TexCoords[i].Changed = true
+	}
+	m.Attribs = cpy.Attribs
+	for name, a := range m.Attribs {
+		a.Changed = true
+		m.Attribs[name] = a
+	}
+}
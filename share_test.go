@@ -0,0 +1,66 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+type countNativeMesh struct {
+	destroyed *int
+}
+
+func (n countNativeMesh) Destroy() { *n.destroyed++ }
+
+func TestMeshShare(t *testing.T) {
+	var destroyed int
+	m := NewMesh()
+	m.KeepDataOnLoad = true
+	m.Vertices = []Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+	m.NativeMesh = countNativeMesh{&destroyed}
+	m.Loaded = true
+
+	if m.Shared() {
+		t.Fatal("mesh is shared before calling Share")
+	}
+	a := m.Share()
+	b := m.Share()
+	if !m.Shared() || !a.Shared() || !b.Shared() {
+		t.Fatal("expected all meshes to be shared")
+	}
+	if !a.Loaded || a.NativeMesh != m.NativeMesh || len(a.Vertices) != 3 {
+		t.Fatal("shared mesh does not reference the original")
+	}
+
+	// Unsharing gives the mesh it's own copy of the data.
+	b.Unshare()
+	if b.Shared() || b.Loaded || b.NativeMesh != nil || !b.VerticesChanged {
+		t.Fatal("Unshare did not detach the mesh")
+	}
+	b.Vertices[0] = Vec3{5, 5, 5}
+	if m.Vertices[0] != (Vec3{0, 0, 0}) {
+		t.Fatal("Unshare did not copy the data")
+	}
+	b.Destroy()
+
+	m.Destroy()
+	if destroyed != 0 {
+		t.Fatal("native mesh destroyed while still shared")
+	}
+	if a.Shared() || len(a.Vertices) != 3 {
+		t.Fatal("remaining mesh lost it's data")
+	}
+	a.Destroy()
+	if destroyed != 1 {
+		t.Fatalf("native mesh destroyed %d times, want 1", destroyed)
+	}
+}
+
+func TestMeshShareNotLoaded(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	NewMesh().Share()
+}
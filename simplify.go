@@ -0,0 +1,333 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"container/heap"
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// quadric is a symmetric 4x4 error quadric matrix, storing only it's upper
+// triangle: a2 ab ac ad b2 bc bd c2 cd d2.
+type quadric [10]float64
+
+// planeQuadric returns the quadric of the plane ax+by+cz+d=0, scaled by w.
+func planeQuadric(a, b, c, d, w float64) quadric {
+	return quadric{
+		w * a * a, w * a * b, w * a * c, w * a * d,
+		w * b * b, w * b * c, w * b * d,
+		w * c * c, w * c * d,
+		w * d * d,
+	}
+}
+
+func (q quadric) add(o quadric) quadric {
+	for i := range q {
+		q[i] += o[i]
+	}
+	return q
+}
+
+// eval returns the sum of the squared distances (weighted) of p to the planes
+// of the quadric.
+func (q quadric) eval(p lmath.Vec3) float64 {
+	x, y, z := p.X, p.Y, p.Z
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x +
+		q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y +
+		q[7]*z*z + 2*q[8]*z +
+		q[9]
+}
+
+// optimal returns the position minimizing the quadric error, or false if the
+// quadric is (nearly) singular.
+func (q quadric) optimal() (lmath.Vec3, bool) {
+	// Solve the 3x3 system A*p = -b using Cramer's rule.
+	a00, a01, a02 := q[0], q[1], q[2]
+	a11, a12, a22 := q[4], q[5], q[7]
+	b0, b1, b2 := -q[3], -q[6], -q[8]
+	det := a00*(a11*a22-a12*a12) - a01*(a01*a22-a12*a02) + a02*(a01*a12-a11*a02)
+	if math.Abs(det) < 1e-12 {
+		return lmath.Vec3{}, false
+	}
+	x := b0*(a11*a22-a12*a12) - a01*(b1*a22-a12*b2) + a02*(b1*a12-a11*b2)
+	y := a00*(b1*a22-a12*b2) - b0*(a01*a22-a12*a02) + a02*(a01*b2-b1*a02)
+	z := a00*(a11*b2-b1*a12) - a01*(a01*b2-b1*a02) + b0*(a01*a12-a11*a02)
+	return lmath.Vec3{x / det, y / det, z / det}, true
+}
+
+// collapse is a candidate edge collapse of vertex v into vertex u.
+type collapse struct {
+	cost     float64
+	u, v     uint32
+	uVer     int
+	vVer     int
+	position lmath.Vec3
+}
+
+type collapseHeap []collapse
+
+func (h collapseHeap) Len() int            { return len(h) }
+func (h collapseHeap) Less(i, j int) bool  { return h[i].cost < h[j].cost }
+func (h collapseHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *collapseHeap) Push(x interface{}) { *h = append(*h, x.(collapse)) }
+func (h *collapseHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// boundaryWeight is the weight of the quadrics constraining boundary edges,
+// which keeps the outline of open meshes intact.
+const boundaryWeight = 1000
+
+// simplifier holds the state of a mesh simplification.
+type simplifier struct {
+	pos      []lmath.Vec3
+	quadrics []quadric
+	version  []int
+	locked   []bool
+	removed  []bool
+	tris     [][3]uint32
+	dead     []bool
+	vertTris [][]int
+	heap     collapseHeap
+	maxError float64
+}
+
+// faceNormal returns the (unnormalized) normal of the triangle.
+func (s *simplifier) faceNormal(t [3]uint32) lmath.Vec3 {
+	p0, p1, p2 := s.pos[t[0]], s.pos[t[1]], s.pos[t[2]]
+	return p1.Sub(p0).Cross(p2.Sub(p0))
+}
+
+// push calculates the cost of collapsing the edge between a and b (in
+// whichever direction is permitted) and adds it to the heap.
+func (s *simplifier) push(a, b uint32) {
+	if s.locked[a] && s.locked[b] {
+		return
+	}
+	u, v := a, b
+	if s.locked[v] {
+		u, v = v, u
+	}
+	q := s.quadrics[u].add(s.quadrics[v])
+
+	// Locked vertices (on seams) may only be collapsed onto, otherwise try the
+	// optimal position and fall back to the best of the endpoints and their
+	// midpoint.
+	var best lmath.Vec3
+	cost := math.Inf(1)
+	candidates := []lmath.Vec3{s.pos[u]}
+	if !s.locked[u] {
+		if p, ok := q.optimal(); ok {
+			candidates = append(candidates, p)
+		}
+		candidates = append(candidates, s.pos[v], s.pos[u].Add(s.pos[v]).MulScalar(0.5))
+	}
+	for _, p := range candidates {
+		if c := q.eval(p); c < cost {
+			best, cost = p, c
+		}
+	}
+	heap.Push(&s.heap, collapse{
+		cost:     math.Max(cost, 0),
+		u:        u,
+		v:        v,
+		uVer:     s.version[u],
+		vVer:     s.version[v],
+		position: best,
+	})
+}
+
+// flips reports whether moving vertex x to p would flip (or degenerate) any of
+// it's triangles which do not also use vertex other.
+func (s *simplifier) flips(x, other uint32, p lmath.Vec3) bool {
+	for _, t := range s.vertTris[x] {
+		if s.dead[t] {
+			continue
+		}
+		tri := s.tris[t]
+		if tri[0] == other || tri[1] == other || tri[2] == other {
+			continue
+		}
+		before := s.faceNormal(tri)
+		old := s.pos[x]
+		s.pos[x] = p
+		after := s.faceNormal(tri)
+		s.pos[x] = old
+		if after.Dot(before) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Simplify reduces the number of triangles of this mesh to (at most, if
+// possible) the given target, by repeatedly collapsing the edge whose
+// collapse introduces the least error as measured by the quadric error metric
+// of Garland and Heckbert. It returns the geometric error of the result, an
+// approximation of the maximum distance (in the mesh's local space) between
+// the simplified and original surfaces, which is suitable for use as the
+// error of an LOD level.
+//
+// Boundary edges of open meshes are preserved, as are vertices sharing their
+// position with other vertices (i.e. seams, where vertices are split due to
+// e.g. differing texture coordinates) such that no cracks are introduced;
+// meshes with many seams may thus not reach the target. Collapses which would
+// flip a triangle are not performed. The surviving vertex of each collapse
+// keeps it's other vertex data (colors, texture coordinates, etc), only it's
+// position is moved.
+//
+// Unreferenced vertices are removed afterwards and all data slices are marked
+// as changed.
+//
+// Only indexed meshes whose primitive is Triangles are supported, any other
+// mesh is left unchanged.
+//
+// The mesh's write lock must be held for this method to operate safely.
+func (m *Mesh) Simplify(targetTriangles int) float64 {
+	if m.Primitive != Triangles || len(m.Indices) < 3 {
+		return 0
+	}
+	nTris := len(m.Indices) / 3
+	if nTris <= targetTriangles {
+		return 0
+	}
+
+	s := &simplifier{
+		pos:      make([]lmath.Vec3, len(m.Vertices)),
+		quadrics: make([]quadric, len(m.Vertices)),
+		version:  make([]int, len(m.Vertices)),
+		locked:   make([]bool, len(m.Vertices)),
+		removed:  make([]bool, len(m.Vertices)),
+		tris:     make([][3]uint32, nTris),
+		dead:     make([]bool, nTris),
+		vertTris: make([][]int, len(m.Vertices)),
+	}
+	byPos := make(map[Vec3]uint32, len(m.Vertices))
+	for i, v := range m.Vertices {
+		s.pos[i] = v.Vec3()
+		if other, ok := byPos[v]; ok {
+			s.locked[i], s.locked[other] = true, true
+		}
+		byPos[v] = uint32(i)
+	}
+
+	// Accumulate the face quadrics, and count the uses of each edge to find
+	// the boundary edges.
+	type edge struct{ a, b uint32 }
+	edges := make(map[edge]int)
+	edgeFace := make(map[edge]int)
+	for t := range s.tris {
+		tri := [3]uint32{m.Indices[3*t], m.Indices[3*t+1], m.Indices[3*t+2]}
+		s.tris[t] = tri
+		var q quadric
+		if n, ok := s.faceNormal(tri).Normalized(); ok {
+			q = planeQuadric(n.X, n.Y, n.Z, -n.Dot(s.pos[tri[0]]), 1)
+		}
+		for k, v := range tri {
+			s.vertTris[v] = append(s.vertTris[v], t)
+			s.quadrics[v] = s.quadrics[v].add(q)
+			e := edge{v, tri[(k+1)%3]}
+			if e.a > e.b {
+				e.a, e.b = e.b, e.a
+			}
+			edges[e]++
+			edgeFace[e] = t
+		}
+	}
+	for e, count := range edges {
+		if count == 1 {
+			// Constrain the boundary edge using a plane perpendicular to it's
+			// face.
+			n, _ := s.faceNormal(s.tris[edgeFace[e]]).Normalized()
+			dir := s.pos[e.b].Sub(s.pos[e.a])
+			perp, ok := dir.Cross(n).Normalized()
+			if ok {
+				q := planeQuadric(perp.X, perp.Y, perp.Z, -perp.Dot(s.pos[e.a]), boundaryWeight)
+				s.quadrics[e.a] = s.quadrics[e.a].add(q)
+				s.quadrics[e.b] = s.quadrics[e.b].add(q)
+			}
+		}
+	}
+	for e := range edges {
+		s.push(e.a, e.b)
+	}
+
+	live := nTris
+	for live > targetTriangles && s.heap.Len() > 0 {
+		c := heap.Pop(&s.heap).(collapse)
+		u, v := c.u, c.v
+		if s.removed[u] || s.removed[v] || s.version[u] != c.uVer || s.version[v] != c.vVer {
+			// Stale candidate.
+			continue
+		}
+		if s.flips(u, v, c.position) || s.flips(v, u, c.position) {
+			continue
+		}
+
+		// Collapse v into u.
+		s.pos[u] = c.position
+		s.quadrics[u] = s.quadrics[u].add(s.quadrics[v])
+		s.removed[v] = true
+		s.version[u]++
+		if e := math.Sqrt(c.cost); e > s.maxError {
+			s.maxError = e
+		}
+		for _, t := range s.vertTris[v] {
+			if s.dead[t] {
+				continue
+			}
+			tri := &s.tris[t]
+			if tri[0] == u || tri[1] == u || tri[2] == u {
+				s.dead[t] = true
+				live--
+				continue
+			}
+			for k := range tri {
+				if tri[k] == v {
+					tri[k] = u
+				}
+			}
+			s.vertTris[u] = append(s.vertTris[u], t)
+		}
+		s.vertTris[v] = nil
+
+		// Recalculate the collapses of the edges around u.
+		neighbors := make(map[uint32]bool)
+		for _, t := range s.vertTris[u] {
+			if s.dead[t] {
+				continue
+			}
+			for _, w := range s.tris[t] {
+				if w != u {
+					neighbors[w] = true
+				}
+			}
+		}
+		for w := range neighbors {
+			s.push(u, w)
+		}
+	}
+
+	// Write back the results.
+	for i, p := range s.pos {
+		if !s.removed[i] {
+			m.Vertices[i] = ConvertVec3(p)
+		}
+	}
+	m.Indices = m.Indices[:0]
+	for t, tri := range s.tris {
+		if !s.dead[t] {
+			m.Indices = append(m.Indices, tri[:]...)
+		}
+	}
+	m.reorderVertices(false)
+	m.AABB = lmath.Rect3Zero
+	return s.maxError
+}
@@ -0,0 +1,92 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+)
+
+// heightGrid returns an indexed grid mesh of n*n quads on the XY plane, whose
+// vertices are displaced along Z by the height function.
+func heightGrid(n int, height func(x, y float64) float64) *Mesh {
+	m := NewMesh()
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			fx, fy := float64(x)/float64(n), float64(y)/float64(n)
+			m.Vertices = append(m.Vertices, Vec3{float32(fx), float32(fy), float32(height(fx, fy))})
+		}
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			i := uint32(y*(n+1) + x)
+			m.Indices = append(m.Indices, i, i+1, i+uint32(n)+2, i, i+uint32(n)+2, i+uint32(n)+1)
+		}
+	}
+	return m
+}
+
+func bumps(x, y float64) float64 {
+	return 0.05 * math.Sin(x*2*math.Pi) * math.Sin(y*2*math.Pi)
+}
+
+func TestMeshSimplifyFlat(t *testing.T) {
+	m := heightGrid(16, func(x, y float64) float64 { return 0 })
+	err := m.Simplify(50)
+	if n := len(m.Indices) / 3; n > 50 {
+		t.Fatalf("got %d triangles, want at most 50", n)
+	}
+	if err > 1e-6 {
+		t.Fatalf("got error %v simplifying a flat grid, want zero", err)
+	}
+	bb := m.Bounds()
+	if bb.Min.X != 0 || bb.Min.Y != 0 || bb.Max.X != 1 || bb.Max.Y != 1 {
+		t.Fatalf("boundary was not preserved, bounds %v", bb)
+	}
+	for _, idx := range m.Indices {
+		if int(idx) >= len(m.Vertices) {
+			t.Fatalf("index %d out of range of %d vertices", idx, len(m.Vertices))
+		}
+	}
+}
+
+func TestMeshSimplifyBumps(t *testing.T) {
+	m := heightGrid(32, bumps)
+	err := m.Simplify(200)
+	if n := len(m.Indices) / 3; n > 200 {
+		t.Fatalf("got %d triangles, want at most 200", n)
+	}
+	if err <= 0 || err > 0.05 {
+		t.Fatalf("got error %v, want within the bump height", err)
+	}
+	for i := 0; i < len(m.Indices); i += 3 {
+		p0 := m.Vertices[m.Indices[i]].Vec3()
+		p1 := m.Vertices[m.Indices[i+1]].Vec3()
+		p2 := m.Vertices[m.Indices[i+2]].Vec3()
+		if p1.Sub(p0).Cross(p2.Sub(p0)).Z <= 0 {
+			t.Fatalf("triangle %d is flipped", i/3)
+		}
+	}
+}
+
+func TestLOD(t *testing.T) {
+	lod := GenerateLOD(heightGrid(32, bumps), 4, 0.25)
+	if len(lod.Levels) != 4 {
+		t.Fatalf("got %d levels, want 4", len(lod.Levels))
+	}
+	for i := 1; i < len(lod.Levels); i++ {
+		prev, cur := lod.Levels[i-1], lod.Levels[i]
+		if len(cur.Mesh.Indices) >= len(prev.Mesh.Indices) || cur.Error < prev.Error {
+			t.Fatalf("level %d (%d indices, error %v) does not follow level %d (%d indices, error %v)",
+				i, len(cur.Mesh.Indices), cur.Error, i-1, len(prev.Mesh.Indices), prev.Error)
+		}
+	}
+	if m := lod.Select(0.1, 75, 1080, 1); m != lod.Levels[0].Mesh {
+		t.Fatal("expected the most detailed level up close")
+	}
+	if m := lod.Select(1e6, 75, 1080, 1); m != lod.Levels[3].Mesh {
+		t.Fatal("expected the least detailed level far away")
+	}
+}
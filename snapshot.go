@@ -0,0 +1,184 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"sync"
+	"time"
+)
+
+// FrameSnapshot represents the render inputs of a single frame, as captured by
+// a SnapshotBuffer. It can be drawn again at any later time to reproduce the
+// frame (e.g. for kill-cams, replays, or debugging).
+//
+// Only the render inputs of each object (i.e. every field except it's native
+// object) are captured. Samplers are copied, but the meshes, textures, and
+// shaders themselves are shared with the original objects and not copied, so
+// changes made to their data are visible in all snapshots.
+type FrameSnapshot struct {
+	// The time at which the frame was captured (e.g. the renderer clock's
+	// time).
+	Time time.Duration
+
+	// The camera that the frame was rendered from, or nil.
+	Camera *Camera
+
+	// The objects that were drawn in the frame, in draw order.
+	Objects []*Object
+
+	// Arbitrary user data captured with the frame (e.g. animation state).
+	User interface{}
+}
+
+// Draw draws each object of the snapshot onto the given rectangle of the
+// canvas, as seen by the snapshot's camera. It does not call Render.
+func (f *FrameSnapshot) Draw(c Canvas, r image.Rectangle) {
+	for _, o := range f.Objects {
+		c.Draw(r, o, f.Camera)
+	}
+}
+
+// snapshotTransform returns a copy of the transform t, whose parents are
+// copied as well such that later changes to any transform in the hierarchy do
+// not affect the copy. The copies map is used to share copies of transforms
+// which are parents to multiple others.
+func snapshotTransform(t *Transform, copies map[*Transform]*Transform) *Transform {
+	if t == nil {
+		return nil
+	}
+	if cpy, ok := copies[t]; ok {
+		return cpy
+	}
+	cpy := t.Copy()
+	copies[t] = cpy
+	if p := t.Parent(); p != nil {
+		cpy.SetParent(snapshotTransform(p.Transform(), copies))
+	}
+	return cpy
+}
+
+// snapshotObject returns a copy of the render inputs of the object o.
+//
+// The object's read lock must be held for this method to operate safely.
+func snapshotObject(o *Object, copies map[*Transform]*Transform) *Object {
+	cpy := o.Copy()
+
+	// Replace the copied transform with one whose parents are copied too.
+	cpy.Transform.Destroy()
+	cpy.Transform = snapshotTransform(o.Transform, copies)
+
+	// Samplers are small and often modified in place, so copy them.
+	for i, smp := range cpy.Samplers {
+		if smp != nil {
+			smpCpy := *smp
+			cpy.Samplers[i] = &smpCpy
+		}
+	}
+	return cpy
+}
+
+// SnapshotBuffer is a ring buffer of the most recent frame snapshots. Once the
+// buffer is full, capturing a new frame discards the oldest one.
+//
+// It is safe to use from multiple goroutines concurrently.
+type SnapshotBuffer struct {
+	access      sync.RWMutex
+	frames      []*FrameSnapshot
+	start, size int
+}
+
+// Capture captures a snapshot of the given camera and objects (in draw order)
+// at the given time, along with the arbitrary user data, into the buffer.
+//
+// Capture properly read-locks the camera and objects.
+func (b *SnapshotBuffer) Capture(t time.Duration, c *Camera, objects []*Object, user interface{}) {
+	copies := make(map[*Transform]*Transform)
+	f := &FrameSnapshot{
+		Time:    t,
+		Objects: make([]*Object, len(objects)),
+		User:    user,
+	}
+	if c != nil {
+		c.RLock()
+		f.Camera = &Camera{
+			Object:         snapshotObject(c.Object, copies),
+			Projection:     c.Projection,
+			PrevProjection: c.PrevProjection,
+			View:           c.View,
+		}
+		c.RUnlock()
+	}
+	for i, o := range objects {
+		o.RLock()
+		f.Objects[i] = snapshotObject(o, copies)
+		o.RUnlock()
+	}
+
+	b.access.Lock()
+	if len(b.frames) > 0 {
+		end := (b.start + b.size) % len(b.frames)
+		b.frames[end] = f
+		if b.size < len(b.frames) {
+			b.size++
+		} else {
+			b.start = (b.start + 1) % len(b.frames)
+		}
+	}
+	b.access.Unlock()
+}
+
+// Len returns the number of frames currently stored in the buffer.
+func (b *SnapshotBuffer) Len() int {
+	b.access.RLock()
+	n := b.size
+	b.access.RUnlock()
+	return n
+}
+
+// At returns the i'th frame stored in the buffer, where zero is the oldest
+// frame and b.Len()-1 is the most recent one. A panic will occur if i is out
+// of range.
+func (b *SnapshotBuffer) At(i int) *FrameSnapshot {
+	b.access.RLock()
+	defer b.access.RUnlock()
+	if i < 0 || i >= b.size {
+		panic("At(): index out of range")
+	}
+	return b.frames[(b.start+i)%len(b.frames)]
+}
+
+// Find returns the most recent frame captured at or before time t, or nil if
+// there is no such frame in the buffer.
+func (b *SnapshotBuffer) Find(t time.Duration) *FrameSnapshot {
+	b.access.RLock()
+	defer b.access.RUnlock()
+	for i := b.size - 1; i >= 0; i-- {
+		f := b.frames[(b.start+i)%len(b.frames)]
+		if f.Time <= t {
+			return f
+		}
+	}
+	return nil
+}
+
+// Reset removes all frames from the buffer.
+func (b *SnapshotBuffer) Reset() {
+	b.access.Lock()
+	for i := range b.frames {
+		b.frames[i] = nil
+	}
+	b.start = 0
+	b.size = 0
+	b.access.Unlock()
+}
+
+// NewSnapshotBuffer returns a new snapshot buffer which stores at most the
+// given number of frames (e.g. five seconds at 60 FPS would be 300 frames).
+func NewSnapshotBuffer(frames int) *SnapshotBuffer {
+	return &SnapshotBuffer{
+		frames: make([]*FrameSnapshot, frames),
+	}
+}
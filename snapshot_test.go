@@ -0,0 +1,109 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestSnapshotBuffer(t *testing.T) {
+	parent := NewTransform()
+	o := NewObject()
+	o.Transform.SetParent(parent)
+
+	b := NewSnapshotBuffer(3)
+	for i := 0; i < 5; i++ {
+		parent.SetPos(lmath.Vec3{X: float64(i)})
+		b.Capture(time.Duration(i)*time.Second, nil, []*Object{o}, i)
+	}
+	if b.Len() != 3 {
+		t.Fatal("expected 3 frames, got", b.Len())
+	}
+	if b.At(0).User != 2 || b.At(2).User != 4 {
+		t.Fatal("wrong frames kept", b.At(0).User, b.At(2).User)
+	}
+
+	// Later changes to the parent must not affect the snapshot.
+	parent.SetPos(lmath.Vec3{X: 100})
+	f := b.Find(3500 * time.Millisecond)
+	if f == nil || f.User != 3 {
+		t.Fatal("Find returned wrong frame", f)
+	}
+	got := f.Objects[0].Transform.ConvertPos(lmath.Vec3Zero, LocalToWorld)
+	if !got.Equals(lmath.Vec3{X: 3}) {
+		t.Fatal("snapshot transform changed, got", got)
+	}
+	if b.Find(time.Second) != nil {
+		t.Fatal("expected no frame before the oldest one")
+	}
+}
+
+func TestSnapshotObject(t *testing.T) {
+	o := NewObject()
+	o.OcclusionTest = true
+	o.Category = "props"
+	o.Fade = 0.5
+	o.UVTransform = AtlasFrame(5, 4, 2)
+	o.Tint = Color{1, 0.5, 0.25, 1}
+	smp := DefaultSampler
+	o.Samplers = []*Sampler{nil, &smp}
+	cond := NewObject()
+	o.Condition = cond
+	o.UpdateMotion()
+	parent := NewTransform()
+	o.Transform.SetParent(parent)
+
+	b := NewSnapshotBuffer(1)
+	b.Capture(0, nil, []*Object{o}, nil)
+	got := b.At(0).Objects[0]
+	if c := parent.Children(); len(c) != 1 || c[0] != o.Transform {
+		t.Fatal("snapshot transform registered with the live parent, got", c)
+	}
+	if got.Transform.Parent().Transform() == parent {
+		t.Fatal("parent transform not copied")
+	}
+	if !got.OcclusionTest || got.Category != "props" {
+		t.Fatal("occlusion test or category not captured", got.OcclusionTest, got.Category)
+	}
+	if got.Fade != 0.5 {
+		t.Fatal("fade not captured, got", got.Fade)
+	}
+	if got.UVTransform != AtlasFrame(5, 4, 2) {
+		t.Fatal("UV transform not captured, got", got.UVTransform)
+	}
+	if got.Tint != (Color{1, 0.5, 0.25, 1}) {
+		t.Fatal("tint not captured, got", got.Tint)
+	}
+	if len(got.Samplers) != 2 || got.Samplers[0] != nil || got.Samplers[1] == &smp || *got.Samplers[1] != smp {
+		t.Fatal("samplers not captured, got", got.Samplers)
+	}
+	if got.Condition != cond {
+		t.Fatal("condition not captured, got", got.Condition)
+	}
+	if got.PrevModel == nil || got.PrevModel == o.PrevModel || *got.PrevModel != *o.PrevModel {
+		t.Fatal("previous world matrix not captured, got", got.PrevModel)
+	}
+}
+
+func TestSnapshotCamera(t *testing.T) {
+	cam := NewCamera()
+	cam.SetPersp(image.Rect(0, 0, 100, 100), 75, 0.1, 100)
+	cam.UpdateMotion()
+	cam.SetOrtho(image.Rect(0, 0, 100, 100), 0.1, 100)
+
+	b := NewSnapshotBuffer(1)
+	b.Capture(0, cam, nil, nil)
+	got := b.At(0).Camera
+	if got.Projection != cam.Projection || got.PrevProjection != cam.PrevProjection {
+		t.Fatal("projections not captured")
+	}
+	if got.PrevModel == nil || *got.PrevModel != *cam.PrevModel {
+		t.Fatal("previous world matrix not captured, got", got.PrevModel)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// SoakConfig represents the configuration of a renderer soak test, see the
+// Soak function.
+type SoakConfig struct {
+	// The total duration of the soak test.
+	Duration time.Duration
+
+	// The number of goroutines that concurrently create and destroy
+	// resources.
+	Goroutines int
+
+	// The maximum amount of time to wait for any single load operation to
+	// complete before reporting it as an error.
+	Timeout time.Duration
+
+	// The seed of the random number generator that chooses resource sizes,
+	// such that a failing run can be reproduced.
+	Seed int64
+}
+
+// DefaultSoakConfig is the default soak test configuration.
+var DefaultSoakConfig = SoakConfig{
+	Duration:   time.Minute,
+	Goroutines: 4,
+	Timeout:    5 * time.Second,
+	Seed:       1,
+}
+
+// SoakResult represents the results of a soak test.
+type SoakResult struct {
+	// The number of meshes, textures, shaders, and render-to-texture canvases
+	// that were created, loaded, and destroyed.
+	Meshes, Textures, Shaders, Canvases int
+
+	// The errors that occurred (e.g. load operations that timed out, shaders
+	// that failed to compile, or panics).
+	Errors []error
+}
+
+var soakShaderVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+uniform mat4 MVP;
+
+void main()
+{
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+var soakShaderFrag = []byte(`
+#version 120
+
+void main()
+{
+	gl_FragColor = vec4(1.0);
+}
+`)
+
+type soakWorker struct {
+	r      Renderer
+	cfg    SoakConfig
+	rand   *rand.Rand
+	result SoakResult
+}
+
+func (w *soakWorker) errorf(format string, args ...interface{}) {
+	w.result.Errors = append(w.result.Errors, fmt.Errorf(format, args...))
+}
+
+func (w *soakWorker) mesh() {
+	m := NewMesh()
+	n := 3 * (1 + w.rand.Intn(1000))
+	for i := 0; i < n; i++ {
+		m.Vertices = append(m.Vertices, Vec3{w.rand.Float32(), w.rand.Float32(), w.rand.Float32()})
+	}
+	done := make(chan *Mesh, 1)
+	w.r.LoadMesh(m, done)
+	select {
+	case <-done:
+		w.result.Meshes++
+	case <-time.After(w.cfg.Timeout):
+		w.errorf("soak: timed out loading mesh with %d vertices", n)
+	}
+	m.Lock()
+	m.Destroy()
+	m.Unlock()
+}
+
+func (w *soakWorker) texture() {
+	t := NewTexture()
+	size := 1 << uint(w.rand.Intn(10))
+	t.Source = image.NewRGBA(image.Rect(0, 0, size, size))
+	t.Bounds = t.Source.Bounds()
+	done := make(chan *Texture, 1)
+	w.r.LoadTexture(t, done)
+	select {
+	case <-done:
+		w.result.Textures++
+	case <-time.After(w.cfg.Timeout):
+		w.errorf("soak: timed out loading %dx%d texture", size, size)
+	}
+	t.Lock()
+	t.Destroy()
+	t.Unlock()
+}
+
+func (w *soakWorker) shader() {
+	s := NewShader("soak")
+	s.GLSLVert = append(s.GLSLVert, soakShaderVert...)
+	s.GLSLFrag = append(s.GLSLFrag, soakShaderFrag...)
+	done := make(chan *Shader, 1)
+	w.r.LoadShader(s, done)
+	select {
+	case <-done:
+		w.result.Shaders++
+		s.RLock()
+		if len(s.Error) > 0 {
+			w.errorf("soak: shader failed to compile: %s", s.Error)
+		}
+		s.RUnlock()
+	case <-time.After(w.cfg.Timeout):
+		w.errorf("soak: timed out loading shader")
+	}
+	s.Lock()
+	s.Destroy()
+	s.Unlock()
+}
+
+func (w *soakWorker) canvas() {
+	size := 1 << uint(1+w.rand.Intn(9))
+	cfg := w.r.GPUInfo().RTTFormats.ChooseConfig(Precision{
+		RedBits: 8, GreenBits: 8, BlueBits: 8, AlphaBits: 8,
+		DepthBits: 24,
+	}, false)
+	cfg.Bounds = image.Rect(0, 0, size, size)
+	cfg.Color = NewTexture()
+	if !cfg.Valid() {
+		return
+	}
+	c := w.r.RenderToTexture(cfg)
+	if c == nil {
+		// Render-to-texture is not supported.
+		return
+	}
+	c.Clear(image.Rectangle{}, Color{R: 1, A: 1})
+	c.Render()
+	w.result.Canvases++
+	cfg.Color.Lock()
+	cfg.Color.Destroy()
+	cfg.Color.Unlock()
+}
+
+func (w *soakWorker) run(deadline time.Time) {
+	defer func() {
+		if v := recover(); v != nil {
+			w.errorf("soak: panic: %v", v)
+		}
+	}()
+	ops := []func(){w.mesh, w.texture, w.shader, w.canvas}
+	for time.Now().Before(deadline) {
+		ops[w.rand.Intn(len(ops))]()
+	}
+}
+
+// Soak runs a soak (stress) test against the given renderer: for the
+// configured duration, multiple goroutines rapidly create, load, and destroy
+// meshes, textures, shaders, and render-to-texture canvases, in order to
+// catch resource leaks, races, and driver instability.
+//
+// Soak blocks until the test completes, so it must be called from a goroutine
+// other than the one running the renderer's main loop (which must continue
+// rendering frames for load operations to complete).
+func Soak(r Renderer, cfg SoakConfig) SoakResult {
+	deadline := time.Now().Add(cfg.Duration)
+	workers := make([]*soakWorker, cfg.Goroutines)
+	var wg sync.WaitGroup
+	for i := range workers {
+		workers[i] = &soakWorker{
+			r:    r,
+			cfg:  cfg,
+			rand: rand.New(rand.NewSource(cfg.Seed + int64(i))),
+		}
+		wg.Add(1)
+		go func(w *soakWorker) {
+			w.run(deadline)
+			wg.Done()
+		}(workers[i])
+	}
+	wg.Wait()
+
+	var result SoakResult
+	for _, w := range workers {
+		result.Meshes += w.result.Meshes
+		result.Textures += w.result.Textures
+		result.Shaders += w.result.Shaders
+		result.Canvases += w.result.Canvases
+		result.Errors = append(result.Errors, w.result.Errors...)
+	}
+	return result
+}
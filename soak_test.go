@@ -0,0 +1,22 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSoakNil(t *testing.T) {
+	cfg := DefaultSoakConfig
+	cfg.Duration = 50 * time.Millisecond
+	result := Soak(Nil(), cfg)
+	for _, err := range result.Errors {
+		t.Error(err)
+	}
+	if result.Meshes == 0 || result.Textures == 0 || result.Shaders == 0 {
+		t.Fatalf("expected resources to be created, got %+v", result)
+	}
+}
@@ -4,6 +4,8 @@
 
 package gfx
 
+import "image"
+
 // State represents a generic set of graphics state properties to be used when
 // rendering a graphics object. Changes to such properties across multiple draw
 // calls (called 'graphics state changes' or 'render state changes') have a
@@ -29,6 +31,21 @@ type State struct {
 	// buffer or not when rendering this object.
 	WriteRed, WriteGreen, WriteBlue, WriteAlpha bool
 
+	// Whether or not each render target uses it's own blend state and color
+	// write mask (see the Targets field). If false, the Blend and Write*
+	// fields apply to all render targets.
+	//
+	// If the graphics hardware does not support independent blending (see
+	// GPUInfo.IndependentBlend) then the state of the first target is used
+	// for all of them.
+	IndependentBlend bool
+
+	// The blend state and color write mask of each render target, used only
+	// when IndependentBlend is true. Targets[0] corresponds to the Color
+	// texture of a render-to-texture canvas and Targets[N] to it's
+	// ExtraColor[N-1] texture.
+	Targets [MaxTargets]TargetState
+
 	// Whether or not dithering should be used when rendering the object.
 	Dithering bool
 
@@ -40,17 +57,78 @@ type State struct {
 	// in the depth buffer.
 	DepthCmp Cmp
 
+	// A constant bias applied to the depth of each rasterized pixel, in
+	// multiples of the smallest resolvable depth buffer difference. Together
+	// with SlopeScaledDepthBias it can be used to avoid z-fighting when e.g.
+	// rendering decals or filling shadow maps. Positive values push pixels
+	// away from the viewer.
+	DepthBias float32
+
+	// A bias applied to the depth of each rasterized pixel that is scaled by
+	// the maximum depth slope of the polygon being rendered (i.e. polygons
+	// viewed at glancing angles are biased more). See also DepthBias.
+	SlopeScaledDepthBias float32
+
+	// The depth range, i.e. the window-space depth values that the near and
+	// far clipping planes are mapped to, respectively. Both must be in the
+	// range of 0.0 to 1.0. By default the full range is used (0.0 and 1.0).
+	//
+	// This can be used e.g. to render a first-person weapon view-model into a
+	// small slice of the depth range (like 0.0 to 0.1) such that it never
+	// intersects the rest of the scene.
+	DepthNear, DepthFar float64
+
 	// Whether or not stencil testing should be enabled when rendering the
 	// object.
 	StencilTest bool
 
+	// The scissor rectangle, in canvas coordinates, which restricts
+	// rasterization of the object to pixels inside of it (e.g. for clipping
+	// user interface elements). It is intersected with the rectangle given to
+	// Canvas.Draw.
+	//
+	// If the rectangle is empty then scissor testing is disabled.
+	Scissor image.Rectangle
+
 	// Whether or not (and how) face culling should occur when rendering
 	// the object.
 	// Must be one of: BackFaceCulling, FrontFaceCulling, NoFaceCulling
 	FaceCulling FaceCullMode
 
+	// The number of user-defined clip distances that are enabled when
+	// rendering the object, i.e. clip distances zero through ClipDistances-1
+	// are enabled. The shader program must write each enabled clip distance
+	// (e.g. gl_ClipDistance[N] in GLSL), pixels whose interpolated distance is
+	// negative are clipped away.
+	//
+	// This is useful e.g. for clipping geometry below the water plane when
+	// rendering planar reflections, or to the plane of a portal. It may not
+	// exceed GPUInfo.MaxClipDistances.
+	ClipDistances uint8
+
+	// How polygons should be rasterized when rendering the object, useful
+	// e.g. for toggling a debug wireframe view.
+	// Must be one of: PolygonFill, PolygonLine, PolygonPoint
+	PolygonMode PolygonMode
+
 	// The stencil state for front and back facing pixels, respectively.
 	StencilFront, StencilBack StencilState
+
+	// The width in pixels of rasterized lines (i.e. when rendering meshes
+	// whose primitive is Lines, LineStrip, or LineLoop).
+	//
+	// Widths other than 1.0 may not be supported by the graphics hardware, in
+	// which case they are clamped to the nearest supported width.
+	LineWidth float32
+
+	// The size in pixels of rasterized points (i.e. when rendering meshes
+	// whose primitive is Points). Ignored if ProgramPointSize is true.
+	PointSize float32
+
+	// Whether or not the size of rasterized points is controlled by the
+	// shader program (i.e. by writing to gl_PointSize in GLSL) instead of the
+	// PointSize field. This is useful for shader-controlled point sprites.
+	ProgramPointSize bool
 }
 
 // Compare compares this state against the other one using DefaultState as a
@@ -72,6 +150,9 @@ func (s State) Compare(other State) bool {
 	if s.StencilTest != other.StencilTest {
 		return s.StencilTest == DefaultState.StencilTest
 	}
+	if s.Scissor != other.Scissor {
+		return s.Scissor == DefaultState.Scissor
+	}
 	if s.StencilFront != other.StencilFront {
 		return s.StencilFront.Compare(other.StencilFront)
 	}
@@ -84,9 +165,37 @@ func (s State) Compare(other State) bool {
 	if s.DepthCmp != other.DepthCmp {
 		return s.DepthCmp == DefaultState.DepthCmp
 	}
+	if s.DepthNear != other.DepthNear {
+		return s.DepthNear == DefaultState.DepthNear
+	}
+	if s.DepthFar != other.DepthFar {
+		return s.DepthFar == DefaultState.DepthFar
+	}
+	if s.DepthBias != other.DepthBias {
+		return s.DepthBias == DefaultState.DepthBias
+	}
+	if s.SlopeScaledDepthBias != other.SlopeScaledDepthBias {
+		return s.SlopeScaledDepthBias == DefaultState.SlopeScaledDepthBias
+	}
 	if s.FaceCulling != other.FaceCulling {
 		return s.FaceCulling == DefaultState.FaceCulling
 	}
+	if s.ClipDistances != other.ClipDistances {
+		return s.ClipDistances == DefaultState.ClipDistances
+	}
+	if s.PolygonMode != other.PolygonMode {
+		return s.PolygonMode == DefaultState.PolygonMode
+	}
+	if s.IndependentBlend != other.IndependentBlend {
+		return s.IndependentBlend == DefaultState.IndependentBlend
+	}
+	if s.Targets != other.Targets {
+		for i, t := range s.Targets {
+			if t != other.Targets[i] {
+				return t.Compare(other.Targets[i])
+			}
+		}
+	}
 	if s.WriteRed != other.WriteRed {
 		return s.WriteRed == DefaultState.WriteRed
 	}
@@ -102,6 +211,15 @@ func (s State) Compare(other State) bool {
 	if s.Dithering != other.Dithering {
 		return s.Dithering == DefaultState.Dithering
 	}
+	if s.LineWidth != other.LineWidth {
+		return s.LineWidth == DefaultState.LineWidth
+	}
+	if s.PointSize != other.PointSize {
+		return s.PointSize == DefaultState.PointSize
+	}
+	if s.ProgramPointSize != other.ProgramPointSize {
+		return s.ProgramPointSize == DefaultState.ProgramPointSize
+	}
 	return true
 }
 
@@ -117,8 +235,69 @@ var DefaultState = State{
 	DepthTest:    true,
 	DepthWrite:   true,
 	DepthCmp:     Less,
+	DepthNear:    0,
+	DepthFar:     1,
 	StencilTest:  false,
 	FaceCulling:  BackFaceCulling,
+	PolygonMode:  PolygonFill,
 	StencilFront: DefaultStencilState,
 	StencilBack:  DefaultStencilState,
+	LineWidth:    1,
+	PointSize:    1,
+	Targets: [MaxTargets]TargetState{
+		DefaultTargetState, DefaultTargetState,
+		DefaultTargetState, DefaultTargetState,
+		DefaultTargetState, DefaultTargetState,
+		DefaultTargetState, DefaultTargetState,
+	},
+}
+
+// MaxTargets is the maximum number of render targets whose state may be
+// specified independently (see State.IndependentBlend).
+const MaxTargets = 8
+
+// TargetState represents the blend state and color write mask of a single
+// render target, when independent blending is in use.
+type TargetState struct {
+	// Blend represents how blending occurs for this render target (see
+	// State.Blend).
+	Blend BlendState
+
+	// Whether or not red/green/blue/alpha should be written to this render
+	// target.
+	WriteRed, WriteGreen, WriteBlue, WriteAlpha bool
+}
+
+// Compare compares this state against the other one using DefaultTargetState
+// as a reference when inequality occurs and returns whether or not this state
+// should sort before the other one for purposes of state sorting.
+func (t TargetState) Compare(other TargetState) bool {
+	if t == other {
+		return true
+	}
+	if t.Blend != other.Blend {
+		return t.Blend.Compare(other.Blend)
+	}
+	if t.WriteRed != other.WriteRed {
+		return t.WriteRed == DefaultTargetState.WriteRed
+	}
+	if t.WriteGreen != other.WriteGreen {
+		return t.WriteGreen == DefaultTargetState.WriteGreen
+	}
+	if t.WriteBlue != other.WriteBlue {
+		return t.WriteBlue == DefaultTargetState.WriteBlue
+	}
+	if t.WriteAlpha != other.WriteAlpha {
+		return t.WriteAlpha == DefaultTargetState.WriteAlpha
+	}
+	return true
+}
+
+// The default render target state.
+var DefaultTargetState = TargetState{
+	Blend:      DefaultBlendState,
+	WriteRed:   true,
+	WriteGreen: true,
+	WriteBlue:  true,
+	WriteAlpha: true,
 }
@@ -0,0 +1,153 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// cornerAngle returns the angle, in radians, of the triangle corner at p
+// between the edges towards a and b.
+func cornerAngle(p, a, b lmath.Vec3) float64 {
+	ea, ok1 := a.Sub(p).Normalized()
+	eb, ok2 := b.Sub(p).Normalized()
+	if !ok1 || !ok2 {
+		return 0
+	}
+	return math.Acos(math.Max(-1, math.Min(1, ea.Dot(eb))))
+}
+
+// perpendicular returns an arbitrary unit vector perpendicular to n.
+func perpendicular(n lmath.Vec3) lmath.Vec3 {
+	axis := lmath.Vec3{1, 0, 0}
+	if math.Abs(n.X) > 0.9 {
+		axis = lmath.Vec3{0, 1, 0}
+	}
+	p, _ := n.Cross(axis).Normalized()
+	return p
+}
+
+// GenerateTangents generates per-vertex tangents for normal mapping from the
+// vertex positions, normals, and first texture coordinate set of this mesh,
+// and stores them as the []gfx.Vec4 custom attribute named "Tangent" (see the
+// Attribs field), accessed in GLSL using:
+//  attribute vec4 Tangent;
+//
+// The XYZ components are the unit tangent (the direction of increasing U),
+// orthogonal to the vertex normal, and the W component is the handedness
+// (either +1 or -1) of the tangent space such that the bitangent is:
+//  vec3 bitangent = Tangent.w * cross(Normal, Tangent.xyz);
+//
+// The tangents follow the conventions of MikkTSpace (the de facto standard
+// used by most baking tools): triangle tangents are weighted by the angle of
+// each corner, orthogonalized against the per-vertex normal, and vertices of
+// an indexed mesh which are shared by triangles of differing handedness (e.g.
+// at a mirrored UV seam) are duplicated, in which case the indices are updated
+// and all per-vertex data slices are marked as changed.
+//
+// The normals are taken from the []gfx.Vec3 custom attribute named "Normal"
+// (see GenerateNormals), if the mesh has no such attribute then normals are
+// first generated using:
+//  m.GenerateNormals(180)
+//
+// Only meshes whose primitive is Triangles and which have a texture coordinate
+// set are supported, any other mesh is left unchanged.
+//
+// The mesh's write lock must be held for this method to operate safely.
+func (m *Mesh) GenerateTangents() {
+	if m.Primitive != Triangles {
+		return
+	}
+	corners := m.triangleCorners()
+	if len(corners) == 0 || len(m.TexCoords) == 0 || len(m.TexCoords[0].Slice) != len(m.Vertices) {
+		return
+	}
+	if n, ok := m.Attribs["Normal"].Data.([]Vec3); !ok || len(n) != len(m.Vertices) {
+		m.GenerateNormals(180)
+		corners = m.triangleCorners()
+	}
+
+	// Accumulate the angle-weighted tangent of each triangle into each of
+	// it's vertices, separately for each handedness.
+	type tangentSum struct {
+		t    lmath.Vec3
+		used bool
+	}
+	sums := make([][2]tangentSum, len(m.Vertices))
+	sides := make([]int, len(corners)/3)
+	for f := range sides {
+		var p [3]lmath.Vec3
+		var uv [3]TexCoord
+		for k := range p {
+			vi := corners[3*f+k]
+			p[k] = m.Vertices[vi].Vec3()
+			uv[k] = m.TexCoords[0].Slice[vi]
+		}
+		e1, e2 := p[1].Sub(p[0]), p[2].Sub(p[0])
+		du1, dv1 := float64(uv[1].U-uv[0].U), float64(uv[1].V-uv[0].V)
+		du2, dv2 := float64(uv[2].U-uv[0].U), float64(uv[2].V-uv[0].V)
+		det := du1*dv2 - du2*dv1
+		if det == 0 {
+			// Degenerate texture coordinates, the triangle does not
+			// contribute a tangent.
+			continue
+		}
+		t := e1.MulScalar(dv2).Sub(e2.MulScalar(dv1)).DivScalar(det)
+		b := e2.MulScalar(du1).Sub(e1.MulScalar(du2)).DivScalar(det)
+		t, ok := t.Normalized()
+		if !ok {
+			continue
+		}
+		if e1.Cross(e2).Cross(t).Dot(b) < 0 {
+			sides[f] = 1
+		}
+		for k := range p {
+			w := cornerAngle(p[k], p[(k+1)%3], p[(k+2)%3])
+			s := &sums[corners[3*f+k]][sides[f]]
+			s.t = s.t.Add(t.MulScalar(w))
+			s.used = true
+		}
+	}
+
+	// Split vertices which are shared by triangles of differing handedness,
+	// moving the left-handed sum to the new vertex.
+	splits := make(map[uint32]uint32)
+	for c, vi := range corners {
+		if sides[c/3] != 1 || !sums[vi][0].used || len(m.Indices) == 0 {
+			continue
+		}
+		dup, ok := splits[vi]
+		if !ok {
+			dup = m.duplicateVertex(vi)
+			sums = append(sums, [2]tangentSum{1: sums[vi][1]})
+			splits[vi] = dup
+		}
+		m.Indices[c] = dup
+		m.IndicesChanged = true
+	}
+	for vi := range splits {
+		sums[vi][1] = tangentSum{}
+	}
+
+	normals := m.Attribs["Normal"].Data.([]Vec3)
+	tangents := make([]Vec4, len(m.Vertices))
+	for vi := range tangents {
+		n, _ := normals[vi].Vec3().Normalized()
+		sum, w := sums[vi][0], 1.0
+		if !sum.used && sums[vi][1].used {
+			sum, w = sums[vi][1], -1.0
+		}
+
+		// Gram-Schmidt orthogonalize against the normal.
+		t, ok := sum.t.Sub(n.MulScalar(n.Dot(sum.t))).Normalized()
+		if !ok {
+			t = perpendicular(n)
+		}
+		tangents[vi] = Vec4{float32(t.X), float32(t.Y), float32(t.Z), float32(w)}
+	}
+	m.Attribs["Tangent"] = VertexAttrib{Data: tangents, Changed: true}
+}
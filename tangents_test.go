@@ -0,0 +1,77 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+// mirroredQuads returns an indexed mesh of two quads in the XZ plane facing -Y
+// which share an edge, with their U texture coordinates mirrored across it.
+func mirroredQuads() *Mesh {
+	m := NewMesh()
+	m.Vertices = []Vec3{
+		{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {0, 0, 1}, // Right quad.
+		{-1, 0, 0}, {-1, 0, 1}, // Left quad.
+	}
+	m.TexCoords = []TexCoordSet{{Slice: []TexCoord{
+		{0, 0}, {1, 0}, {1, 1}, {0, 1},
+		{1, 0}, {1, 1},
+	}}}
+	m.Indices = []uint32{
+		0, 1, 2, 0, 2, 3,
+		4, 0, 3, 4, 3, 5,
+	}
+	return m
+}
+
+func TestGenerateTangents(t *testing.T) {
+	m := mirroredQuads()
+	m.GenerateTangents()
+
+	// The two shared vertices must have been split.
+	if len(m.Vertices) != 8 {
+		t.Fatalf("got %d vertices, want 8", len(m.Vertices))
+	}
+	normals := m.Attribs["Normal"].Data.([]Vec3)
+	tangents := m.Attribs["Tangent"].Data.([]Vec4)
+	if len(normals) != 8 || len(tangents) != 8 {
+		t.Fatalf("got %d normals %d tangents, want 8", len(normals), len(tangents))
+	}
+	for c, vi := range m.Indices {
+		want := Vec4{1, 0, 0, 1}
+		if c >= 6 {
+			want = Vec4{-1, 0, 0, -1}
+		}
+		got := tangents[vi]
+		if !vec3Near(Vec3{got.X, got.Y, got.Z}, Vec3{want.X, want.Y, want.Z}) || got.W != want.W {
+			t.Fatalf("corner %d tangent %v, want %v", c, got, want)
+		}
+		if !vec3Near(normals[vi], Vec3{0, -1, 0}) {
+			t.Fatalf("corner %d normal %v, want -Y", c, normals[vi])
+		}
+	}
+}
+
+func TestGenerateTangentsNoTexCoords(t *testing.T) {
+	m := foldMesh()
+	m.GenerateTangents()
+	if _, ok := m.Attribs["Tangent"]; ok {
+		t.Fatal("tangents generated without texture coordinates")
+	}
+}
+
+func TestGenerateTangentsNotTriangles(t *testing.T) {
+	m := mirroredQuads()
+	m.Primitive = TriangleStrip
+	m.GenerateTangents()
+	if _, ok := m.Attribs["Tangent"]; ok {
+		t.Fatal("tangents generated for a triangle strip")
+	}
+	if _, ok := m.Attribs["Normal"]; ok {
+		t.Fatal("normals generated for a triangle strip")
+	}
+	if len(m.Vertices) != 6 {
+		t.Fatalf("got %d vertices, want the mesh unchanged", len(m.Vertices))
+	}
+}
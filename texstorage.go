@@ -0,0 +1,224 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"errors"
+	"fmt"
+	"image"
+)
+
+// TexConversion describes how texture data is converted from the requested
+// texture format into the format that the graphics hardware stores, see
+// TexStorage.
+type TexConversion uint8
+
+// String returns a string representation of this texture conversion.
+// e.g. ExpandAlpha -> "ExpandAlpha"
+func (c TexConversion) String() string {
+	switch c {
+	case NoConversion:
+		return "NoConversion"
+	case ExpandAlpha:
+		return "ExpandAlpha"
+	case Transcode:
+		return "Transcode"
+	case Uncompressed:
+		return "Uncompressed"
+	case WidenFloat:
+		return "WidenFloat"
+	case NarrowFloat:
+		return "NarrowFloat"
+	case ClampFloat:
+		return "ClampFloat"
+	case Unsupported:
+		return "Unsupported"
+	}
+	return fmt.Sprintf("TexConversion(%d)", c)
+}
+
+const (
+	// NoConversion means the requested format is stored as-is.
+	NoConversion TexConversion = iota
+
+	// ExpandAlpha means the format (e.g. RGB) is stored with an additional
+	// alpha channel (e.g. RGBA) which is always one.
+	ExpandAlpha
+
+	// Transcode means the data is compressed into a different compressed
+	// format of similar quality (e.g. ETC2 instead of DXT1).
+	Transcode
+
+	// Uncompressed means the data of a compressed format is stored
+	// uncompressed (i.e. as RGB or RGBA).
+	Uncompressed
+
+	// WidenFloat means the data of a floating-point format is stored in a
+	// more precise floating-point format (e.g. RGBA16F as RGBA32F).
+	WidenFloat
+
+	// NarrowFloat means the data of a floating-point format is stored in a
+	// less precise floating-point format (e.g. RGBA32F as RGBA16F).
+	NarrowFloat
+
+	// ClampFloat means the data of a floating-point format is stored in an
+	// 8-bit format, clamping values to the range of 0.0 to 1.0.
+	ClampFloat
+
+	// Unsupported means the data cannot be stored at all, i.e. it is a
+	// *CompressedImage whose format is not supported by the graphics
+	// hardware.
+	Unsupported
+)
+
+// TexStorage describes how a texture of a requested format is actually
+// stored by the graphics hardware, see GPUInfo.NegotiateTexFormat.
+type TexStorage struct {
+	// The requested texture format (i.e. Texture.Format) and the format that
+	// is stored (i.e. NativeTexture.ChosenFormat).
+	Requested, Stored TexFormat
+
+	// The conversion from the requested format to the stored one.
+	Conversion TexConversion
+
+	// The swizzle which renderers apply (in addition to Texture.Swizzle) when
+	// sampling the stored format, such that it samples identically to the
+	// requested one. For example when RGB is stored as RGBA the alpha channel
+	// is swizzled to one.
+	Swizzle TexSwizzle
+}
+
+// texFallbacks lists the formats, in order of preference, that each format is
+// stored as if it is not supported. RGBA is the last resort of every format
+// and is always supported.
+var texFallbacks = map[TexFormat][]TexFormat{
+	RGB:        {RGBA},
+	DXT1:       {ETC2, ASTC4x4, RGB, RGBA},
+	DXT1RGBA:   {ETC2RGBA, DXT5, ASTC4x4, RGBA},
+	DXT3:       {DXT5, ETC2RGBA, ASTC4x4, RGBA},
+	DXT5:       {DXT3, ETC2RGBA, ASTC4x4, RGBA},
+	ETC2:       {DXT1, ASTC4x4, RGB, RGBA},
+	ETC2RGBA:   {DXT5, ASTC4x4, DXT3, RGBA},
+	ASTC4x4:    {ETC2RGBA, DXT5, RGBA},
+	ASTC8x8:    {ASTC4x4, ETC2RGBA, DXT5, RGBA},
+	RGBA16F:    {RGBA32F, RGBA},
+	RGBA32F:    {RGBA16F, RGBA},
+	R11G11B10F: {RGBA16F, RGBA32F, RGB, RGBA},
+}
+
+// hasAlpha tells if the texture format has an alpha channel.
+func (t TexFormat) hasAlpha() bool {
+	switch t {
+	case RGB, DXT1, ETC2, R11G11B10F:
+		return false
+	}
+	return true
+}
+
+// supports tells if the texture format is in the list of supported ones. RGBA
+// is always supported.
+func (g GPUInfo) supports(f TexFormat) bool {
+	if f == RGBA {
+		return true
+	}
+	for _, s := range g.TexFormats {
+		if s == f {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateTexFormat reports how a texture of the given format is stored by
+// the graphics hardware, given it's supported formats (see TexFormats). If
+// the format is not supported, the most similar supported format is chosen
+// consistently across renderers, for example:
+//  RGB        -> RGBA (ExpandAlpha)
+//  DXT1       -> ETC2 (Transcode), or RGB (Uncompressed)
+//  RGBA16F    -> RGBA32F (WidenFloat), or RGBA (ClampFloat)
+//
+// The compressed parameter tells whether the texture's source is a
+// *CompressedImage, which cannot be transcoded or decompressed: if it's
+// format is not supported the conversion is Unsupported.
+//
+// The ZeroTexFormat is stored as RGBA.
+func (g GPUInfo) NegotiateTexFormat(f TexFormat, compressed bool) TexStorage {
+	s := TexStorage{Requested: f, Stored: f}
+	if f == ZeroTexFormat {
+		s.Stored = RGBA
+		return s
+	}
+	if g.supports(f) {
+		return s
+	}
+	if compressed {
+		s.Conversion = Unsupported
+		return s
+	}
+	for _, fb := range texFallbacks[f] {
+		if !g.supports(fb) {
+			continue
+		}
+		s.Stored = fb
+		switch {
+		case f.Compressed() && fb.Compressed():
+			s.Conversion = Transcode
+		case f.Compressed():
+			s.Conversion = Uncompressed
+		case f.Float() && !fb.Float():
+			s.Conversion = ClampFloat
+		case f.Float() && texelBits(fb) < texelBits(f) && fb.hasAlpha() == f.hasAlpha():
+			s.Conversion = NarrowFloat
+		case f.Float():
+			s.Conversion = WidenFloat
+		default:
+			s.Conversion = ExpandAlpha
+		}
+		if !f.hasAlpha() && fb.hasAlpha() {
+			s.Swizzle[3] = SwizzleOne
+		}
+		return s
+	}
+	panic("NegotiateTexFormat(): invalid format")
+}
+
+// ErrUnsupportedFormat is returned by TexStorage.Convert when a compressed
+// image's format is not supported by the graphics hardware.
+var ErrUnsupportedFormat = errors.New("texture format is not supported")
+
+// Convert converts the source image of a texture into the image data that
+// renderers upload for the stored format, such that the texture renders
+// identically regardless of the renderer's supported formats:
+//
+// Compressed images (i.e. *CompressedImage) are returned as-is, unless their
+// format is not supported in which case ErrUnsupportedFormat is returned.
+//
+// Other images are converted into premultiplied 8-bit RGBA form (see ToRGBA),
+// and if the requested format has no alpha channel (e.g. RGB or DXT1) then
+// the alpha of every pixel is set to one, as it is when the hardware stores
+// the format without an alpha channel. The renderer then compresses the image
+// if the stored format is a compressed one.
+func (s TexStorage) Convert(img image.Image) (image.Image, error) {
+	if c, ok := img.(*CompressedImage); ok {
+		if s.Conversion != NoConversion || c.Format != s.Stored {
+			return nil, ErrUnsupportedFormat
+		}
+		return c, nil
+	}
+	rgba := ToRGBA(img)
+	if s.Requested.hasAlpha() {
+		return rgba, nil
+	}
+	if rgba == img {
+		// Don't modify the caller's image.
+		cpy := *rgba
+		cpy.Pix = append([]uint8(nil), rgba.Pix...)
+		rgba = &cpy
+	}
+	for i := 3; i < len(rgba.Pix); i += 4 {
+		rgba.Pix[i] = 0xFF
+	}
+	return rgba, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"testing"
+)
+
+func TestNegotiateTexFormat(t *testing.T) {
+	mobile := GPUInfo{TexFormats: []TexFormat{RGBA, ETC2, ETC2RGBA, RGBA16F}}
+	tests := []struct {
+		f          TexFormat
+		compressed bool
+		want       TexStorage
+	}{
+		{RGBA, false, TexStorage{RGBA, RGBA, NoConversion, TexSwizzle{}}},
+		{ZeroTexFormat, false, TexStorage{ZeroTexFormat, RGBA, NoConversion, TexSwizzle{}}},
+		{RGB, false, TexStorage{RGB, RGBA, ExpandAlpha, TexSwizzle{3: SwizzleOne}}},
+		{DXT1, false, TexStorage{DXT1, ETC2, Transcode, TexSwizzle{}}},
+		{DXT5, false, TexStorage{DXT5, ETC2RGBA, Transcode, TexSwizzle{}}},
+		{DXT5, true, TexStorage{DXT5, DXT5, Unsupported, TexSwizzle{}}},
+		{ASTC4x4, false, TexStorage{ASTC4x4, ETC2RGBA, Transcode, TexSwizzle{}}},
+		{RGBA32F, false, TexStorage{RGBA32F, RGBA16F, NarrowFloat, TexSwizzle{}}},
+		{R11G11B10F, false, TexStorage{R11G11B10F, RGBA16F, WidenFloat, TexSwizzle{3: SwizzleOne}}},
+		{ETC2, true, TexStorage{ETC2, ETC2, NoConversion, TexSwizzle{}}},
+	}
+	for _, tst := range tests {
+		if got := mobile.NegotiateTexFormat(tst.f, tst.compressed); got != tst.want {
+			t.Errorf("%v (compressed=%v): got %+v, want %+v", tst.f, tst.compressed, got, tst.want)
+		}
+	}
+
+	var basic GPUInfo
+	if got := basic.NegotiateTexFormat(DXT1, false); got.Stored != RGBA || got.Conversion != Uncompressed || got.Swizzle[3] != SwizzleOne {
+		t.Errorf("DXT1 on basic hardware: got %+v", got)
+	}
+	if got := basic.NegotiateTexFormat(RGBA16F, false); got.Stored != RGBA || got.Conversion != ClampFloat {
+		t.Errorf("RGBA16F on basic hardware: got %+v", got)
+	}
+}
+
+func TestTexStorageConvert(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Pix = []uint8{10, 20, 30, 40, 50, 60, 70, 80}
+
+	s := GPUInfo{}.NegotiateTexFormat(RGB, false)
+	img, err := s.Convert(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := img.(*image.RGBA).Pix
+	want := []uint8{10, 20, 30, 255, 50, 60, 70, 255}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+	if src.Pix[3] != 40 {
+		t.Fatal("Convert modified the source image")
+	}
+
+	c := &CompressedImage{Format: DXT1, Rect: image.Rect(0, 0, 4, 4), Data: make([]byte, 8)}
+	s = GPUInfo{}.NegotiateTexFormat(DXT1, true)
+	if _, err := s.Convert(c); err != ErrUnsupportedFormat {
+		t.Fatalf("got error %v, want ErrUnsupportedFormat", err)
+	}
+}
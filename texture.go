@@ -7,6 +7,7 @@ package gfx
 import (
 	"fmt"
 	"image"
+	"image/color"
 	"sync"
 )
 
@@ -31,6 +32,20 @@ func (t TexFormat) String() string {
 		return "DXT3"
 	case DXT5:
 		return "DXT5"
+	case ETC2:
+		return "ETC2"
+	case ETC2RGBA:
+		return "ETC2RGBA"
+	case ASTC4x4:
+		return "ASTC4x4"
+	case ASTC8x8:
+		return "ASTC8x8"
+	case RGBA16F:
+		return "RGBA16F"
+	case RGBA32F:
+		return "RGBA32F"
+	case R11G11B10F:
+		return "R11G11B10F"
 	}
 	return fmt.Sprintf("TexFormat(%d)", t)
 }
@@ -42,13 +57,20 @@ func (t TexFormat) String() string {
 // A panic will occur if the format is not one of the predefined ones in this
 // package.
 //
-// ZeroTexFormat, DXT1, DXT3, and DXT5 formats will return only zero.
+// ZeroTexFormat and compressed formats (see the Compressed method) will return
+// only zero.
 func (t TexFormat) Bits() (r, g, b, a uint8) {
 	switch t {
 	case RGB:
 		return 8, 8, 8, 0
 	case RGBA:
 		return 8, 8, 8, 8
+	case RGBA16F:
+		return 16, 16, 16, 16
+	case RGBA32F:
+		return 32, 32, 32, 32
+	case R11G11B10F:
+		return 11, 11, 10, 0
 
 	case ZeroTexFormat:
 		return 0, 0, 0, 0
@@ -60,10 +82,43 @@ func (t TexFormat) Bits() (r, g, b, a uint8) {
 		return 0, 0, 0, 0
 	case DXT5:
 		return 0, 0, 0, 0
+	case ETC2, ETC2RGBA, ASTC4x4, ASTC8x8:
+		return 0, 0, 0, 0
 	}
 	panic("invalid format")
 }
 
+// Float tells if this is a floating-point texture format (e.g. RGBA16F),
+// whose components are not limited to the range of 0.0 to 1.0.
+func (t TexFormat) Float() bool {
+	return t == RGBA16F || t == RGBA32F || t == R11G11B10F
+}
+
+// Compressed tells if this is a block-compressed texture format (e.g. DXT1,
+// ETC2, or ASTC4x4).
+func (t TexFormat) Compressed() bool {
+	_, _, size := t.BlockSize()
+	return size > 0
+}
+
+// BlockSize returns the width and height in pixels of each block of this
+// compressed texture format, and the size of each block in bytes. For
+// example:
+//  w, h, size := DXT1.BlockSize()
+//  w == 4 && h == 4 && size == 8
+// If the format is not compressed then zero is returned for all values.
+func (t TexFormat) BlockSize() (w, h, size int) {
+	switch t {
+	case DXT1, DXT1RGBA, ETC2:
+		return 4, 4, 8
+	case DXT3, DXT5, ETC2RGBA, ASTC4x4:
+		return 4, 4, 16
+	case ASTC8x8:
+		return 8, 8, 16
+	}
+	return 0, 0, 0
+}
+
 const (
 	// Zero-value texture format. Used to represent nil/none/zero.
 	ZeroTexFormat TexFormat = iota
@@ -96,6 +151,230 @@ const (
 	// chunk in a similar manner to DXT1's color storage. It provides the same
 	// 4:1 compression ratio as DXT3.
 	DXT5
+
+	// ETC2 is the ETC2 RGB texture compression format (i.e. fully opaque),
+	// which is mandatory in OpenGL ES 3.0 and thus widely supported on mobile
+	// hardware. Each 4x4 block of pixels takes up 64-bits of data.
+	ETC2
+
+	// ETC2RGBA is the ETC2 texture compression format with an EAC-compressed
+	// alpha channel. Each 4x4 block of pixels takes up 128-bits of data.
+	ETC2RGBA
+
+	// ASTC4x4 is the ASTC (LDR) texture compression format using 4x4 pixel
+	// blocks, each of which takes up 128-bits of data (i.e. eight bits per
+	// pixel).
+	ASTC4x4
+
+	// ASTC8x8 is the ASTC (LDR) texture compression format using 8x8 pixel
+	// blocks, each of which takes up 128-bits of data (i.e. two bits per
+	// pixel).
+	ASTC8x8
+
+	// RGBA16F is a 64-bit RGBA format with a 16-bit (half-precision)
+	// floating-point value per component, suitable for high dynamic range
+	// (HDR) rendering.
+	RGBA16F
+
+	// RGBA32F is a 128-bit RGBA format with a 32-bit floating-point value per
+	// component, suitable for storing arbitrary data (e.g. positions) in
+	// textures.
+	RGBA32F
+
+	// R11G11B10F is a packed 32-bit RGB format with unsigned floating-point
+	// values (11 bits for red and green, 10 bits for blue) and no alpha
+	// component. It is a compact alternative to RGBA16F for HDR color
+	// buffers.
+	R11G11B10F
+)
+
+// CompressedImage is an image whose pixel data is pre-compressed in a block
+// compressed texture format (e.g. as loaded from a DDS or KTX file). When it
+// is the source image of a texture, it's blocks are uploaded to the graphics
+// hardware directly (without decompression or re-compression), and the
+// texture's format must be equal to the image's format.
+//
+// The image does not support decompression: it's At method always returns a
+// transparent color.
+type CompressedImage struct {
+	// The compressed texture format of the blocks.
+	Format TexFormat
+
+	// The bounds of the image, in pixels.
+	Rect image.Rectangle
+
+	// The compressed blocks of the image, in row-major order starting at the
+	// top-left block.
+	Data []byte
+}
+
+// ColorModel implements the image.Image interface.
+func (c *CompressedImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds implements the image.Image interface.
+func (c *CompressedImage) Bounds() image.Rectangle {
+	return c.Rect
+}
+
+// At implements the image.Image interface. Since compressed images cannot be
+// decompressed, it always returns a transparent color.
+func (c *CompressedImage) At(x, y int) color.Color {
+	return color.RGBA{}
+}
+
+// Valid tells if the image's format is a compressed one and the length of
+// it's data matches the number of blocks required for it's bounds.
+func (c *CompressedImage) Valid() bool {
+	bw, bh, size := c.Format.BlockSize()
+	if size == 0 {
+		return false
+	}
+	w := (c.Rect.Dx() + bw - 1) / bw
+	h := (c.Rect.Dy() + bh - 1) / bh
+	return len(c.Data) == w*h*size
+}
+
+// SwizzleSource specifies the source of a single channel of a texture's
+// color when it is sampled, see TexSwizzle.
+type SwizzleSource uint8
+
+// String returns a string representation of this swizzle source.
+// e.g. SwizzleR -> "SwizzleR"
+func (s SwizzleSource) String() string {
+	switch s {
+	case SwizzleKeep:
+		return "SwizzleKeep"
+	case SwizzleR:
+		return "SwizzleR"
+	case SwizzleG:
+		return "SwizzleG"
+	case SwizzleB:
+		return "SwizzleB"
+	case SwizzleA:
+		return "SwizzleA"
+	case SwizzleZero:
+		return "SwizzleZero"
+	case SwizzleOne:
+		return "SwizzleOne"
+	}
+	return fmt.Sprintf("SwizzleSource(%d)", s)
+}
+
+const (
+	// The channel keeps it's own value (i.e. no swizzling).
+	SwizzleKeep SwizzleSource = iota
+
+	// The channel takes the value of the red, green, blue, or alpha channel.
+	SwizzleR
+	SwizzleG
+	SwizzleB
+	SwizzleA
+
+	// The channel takes the constant value of zero or one.
+	SwizzleZero
+	SwizzleOne
+)
+
+// TexSwizzle specifies the source of the red, green, blue, and alpha channels
+// (in that order) of a texture's color when it is sampled. The zero value
+// leaves every channel unchanged.
+type TexSwizzle [4]SwizzleSource
+
+// AlphaSwizzle is the swizzle which samples a single-channel texture (e.g. a
+// grayscale font or mask image) as white, with the red channel as it's alpha.
+var AlphaSwizzle = TexSwizzle{SwizzleOne, SwizzleOne, SwizzleOne, SwizzleR}
+
+// Apply returns the color c with the swizzle applied to it.
+func (s TexSwizzle) Apply(c Color) Color {
+	in := [4]float32{c.R, c.G, c.B, c.A}
+	var out [4]float32
+	for i, src := range s {
+		switch src {
+		case SwizzleKeep:
+			out[i] = in[i]
+		case SwizzleR, SwizzleG, SwizzleB, SwizzleA:
+			out[i] = in[src-SwizzleR]
+		case SwizzleOne:
+			out[i] = 1
+		}
+	}
+	return Color{out[0], out[1], out[2], out[3]}
+}
+
+// TexType specifies the type (i.e. dimensionality) of a texture.
+type TexType uint8
+
+// String returns a string name for this texture type. For example:
+//  TextureArray -> "TextureArray"
+func (t TexType) String() string {
+	switch t {
+	case Texture2D:
+		return "Texture2D"
+	case TextureArray:
+		return "TextureArray"
+	case Texture3D:
+		return "Texture3D"
+	case CubeMap:
+		return "CubeMap"
+	case BufferTexture:
+		return "BufferTexture"
+	}
+	return fmt.Sprintf("TexType(%d)", t)
+}
+
+const (
+	// Texture2D is a standard two-dimensional texture whose image data is
+	// the texture's Source image. It is accessed in GLSL using a sampler2D.
+	Texture2D TexType = iota
+
+	// TextureArray is an array of two-dimensional textures (layers) of equal
+	// size and format, whose image data is the texture's Layers slice. It is
+	// accessed in GLSL using a sampler2DArray, where the third texture
+	// coordinate selects the layer. Filtering never occurs between layers, so
+	// unlike texture atlases there is no bleeding between neighbouring
+	// images.
+	TextureArray
+
+	// Texture3D is a three-dimensional (volume) texture, whose depth slices
+	// are the texture's Layers slice (from front to back). It is accessed in
+	// GLSL using a sampler3D. Unlike with TextureArray, linear filtering also
+	// occurs between slices (i.e. trilinear filtering), making it suitable for
+	// volumetric fog, color-grading lookup tables, and noise volumes.
+	Texture3D
+
+	// CubeMap is a cube map texture, whose six square faces are the texture's
+	// Layers slice (in the order given by the CubePositiveX, etc, constants).
+	// It is accessed in GLSL using a samplerCube with a direction vector,
+	// which makes it suitable for skyboxes, environment mapping, and
+	// point-light shadows.
+	//
+	// If the graphics hardware supports it (see GPUInfo.SeamlessCubeMap) then
+	// filtering occurs across the edges of faces, avoiding visible seams.
+	CubeMap
+
+	// BufferTexture is a large one-dimensional buffer of texels, whose data
+	// is the texture's Buffer slice (four floats per texel). It is accessed
+	// in GLSL 1.40 or later using a samplerBuffer and texelFetch, without any
+	// filtering or wrapping, which makes it suitable for feeding data that
+	// exceeds the uniform limits (e.g. skinning matrices or per-instance
+	// data) to shaders.
+	//
+	// If the graphics hardware does not support buffer textures (see
+	// GPUInfo.MaxBufferTextureSize) then the texture cannot be loaded.
+	BufferTexture
+)
+
+// The indices of the faces of a CubeMap texture, e.g. in it's Layers slice or
+// in RTTConfig.Layer.
+const (
+	CubePositiveX = iota
+	CubeNegativeX
+	CubePositiveY
+	CubeNegativeY
+	CubePositiveZ
+	CubeNegativeZ
 )
 
 // Downloadable represents a image that can be downloaded from the graphics
@@ -105,6 +384,13 @@ type Downloadable interface {
 	// downloadable image from the graphics hardware into system memory and
 	// send it to the complete channel when done.
 	//
+	// If the graphics hardware supports asynchronous pixel transfers (see
+	// GPUInfo.AsyncTransfer) then the download does not stall rendering: the
+	// pixels are copied into a pixel buffer once prior operations complete,
+	// and sent over the channel once the copy has finished (typically one or
+	// more frames later). As such the channel should be received from
+	// without blocking the goroutine that renders frames.
+	//
 	// If downloading this texture is impossible (i.e. hardware does not
 	// support this) then nil will be sent over the channel and all future
 	// attempts to download this texture will fail as well.
@@ -162,29 +448,203 @@ type Texture struct {
 	// to texture, unless downloaded).
 	Source image.Image
 
+	// The type of the texture.
+	Type TexType
+
+	// The source images of each layer of a TextureArray texture (or each
+	// depth slice of a Texture3D texture, or each face of a CubeMap texture),
+	// each of which must have bounds equal in size to the texture's Bounds.
+	//
+	// Once the texture is loaded, layers may be updated individually by
+	// loading the texture again with only the changed layers non-nil: layers
+	// which are nil are left unchanged, and the number of layers may not
+	// change.
+	Layers []image.Image
+
+	// The data of a BufferTexture texture, four floats (i.e. one RGBA32F
+	// texel) per texel, see the PackMat4s function.
+	Buffer []float32
+
 	// The texture format to use for storing this texture on the GPU, which may
 	// result in lossy conversions (e.g. RGB would lose the alpha channel, etc).
 	//
-	// If the format is not supported then the renderer may use an image format
-	// that is similar and is supported (and the format chosen by the renderer
-	// can be determined via NativeTexture's ChosenFormat method).
+	// If the format is not supported then the renderer uses an image format
+	// that is similar and is supported, as chosen by
+	// GPUInfo.NegotiateTexFormat (and the format chosen by the renderer can be
+	// determined via NativeTexture's ChosenFormat method).
+	//
+	// If the source image is a *CompressedImage then the format must be equal
+	// to it's format, and the format must be supported by the graphics
+	// hardware (see GPUInfo.TexFormats).
 	Format TexFormat
 
+	// Whether or not the color data of this texture is sRGB-encoded (as is
+	// the case for most images, e.g. photographs and hand-painted textures).
+	// If true then the graphics hardware converts the color data into linear
+	// space when the texture is sampled, such that lighting calculations may
+	// be done in linear space. The alpha component is never converted.
+	//
+	// Textures which hold non-color data (e.g. normal maps) should not set
+	// this to true. If the graphics hardware does not support sRGB textures
+	// (see GPUInfo.SRGB) then this field is ignored.
+	SRGB bool
+
+	// The swizzle applied to the texture's color when it is sampled, e.g.
+	// AlphaSwizzle. If the graphics hardware does not support swizzling (see
+	// GPUInfo.TexSwizzle) then the renderer applies it to the image data when
+	// the texture is loaded instead.
+	Swizzle TexSwizzle
+
 	// The U and V wrap modes of this texture.
 	WrapU, WrapV TexWrap
 
+	// The W (depth) wrap mode of this texture, used only by Texture3D
+	// textures.
+	WrapW TexWrap
+
 	// The color of the border when a wrap mode is set to BorderColor.
 	BorderColor Color
 
 	// The texture filtering used for minification and magnification of the
 	// texture.
 	MinFilter, MagFilter TexFilter
+
+	// The degree of anisotropic filtering, which improves the sharpness of
+	// textures viewed at glancing angles (e.g. the ground) at the cost of
+	// performance. Typical values are 2, 4, 8, and 16. Values of one or less
+	// disable anisotropic filtering.
+	//
+	// The value is clamped to the maximum supported by the graphics hardware
+	// (see GPUInfo.MaxAnisotropy), and only has effect if the MinFilter is a
+	// mipmapped one.
+	Anisotropy float64
+
+	// The explicitly provided mipmap levels of the texture, starting at level
+	// one (level zero being the Source image), e.g. pre-filtered environment
+	// maps or mipmaps generated offline with a high-quality filter. Each level
+	// must be half the size of the previous one (rounded down, to a minimum
+	// of one pixel, see MipLevels) and the chain must be complete.
+	//
+	// If nil and the MinFilter is a mipmapped one then the mipmaps are instead
+	// generated automatically by the graphics hardware when the texture is
+	// loaded.
+	Mipmaps []image.Image
+
+	// The minimum and maximum level-of-detail (i.e. mipmap levels, which may
+	// be fractional) that sampling is clamped to. For example a MaxLOD of
+	// 2 would prevent the two lowest-resolution mipmaps from being sampled.
+	//
+	// The default values are -1000 and 1000, which do not clamp at all.
+	MinLOD, MaxLOD float64
+
+	// The bias added to the level-of-detail that is computed by the graphics
+	// hardware when sampling, e.g. a negative bias makes a mipmapped texture
+	// appear sharper (at the risk of aliasing) and a positive one makes it
+	// appear blurrier. This is typically used to tune the sharpness of UI
+	// textures.
+	LODBias float64
+
+	// Whether or not sampling this texture performs a depth comparison. This
+	// only applies to depth textures (i.e. the Depth texture of a
+	// render-to-texture canvas, see RTTConfig), which are then accessed in
+	// GLSL using a sampler2DShadow: sampling returns the result of comparing
+	// the reference value (the third texture coordinate) against the stored
+	// depth using DepthCmp, as 1.0 if the comparison passes or 0.0 otherwise.
+	//
+	// If the MagFilter is Linear then the results of the comparisons of
+	// neighbouring texels are filtered (i.e. percentage-closer filtering),
+	// which is the basis of smooth shadow mapping.
+	DepthCompare bool
+
+	// The comparison operator used when DepthCompare is true, typically
+	// LessOrEqual.
+	DepthCmp Cmp
+
+	// The pending partial updates of the texture's image data, in the order
+	// that they were made (see the UpdateRect method). The renderer applies
+	// them the next time the texture is loaded and then sets this slice to
+	// nil.
+	Updates []TexUpdate
+}
+
+// TexUpdate represents a partial update of a texture's image data, see the
+// Texture.UpdateRect method.
+type TexUpdate struct {
+	// The mipmap level to update, zero being the base level.
+	Level int
+
+	// The rectangle of the level to update.
+	Rect image.Rectangle
+
+	// The new pixels of the rectangle, in 8-bit RGBA form, row-major order
+	// starting at the top-left of the rectangle.
+	Pix []uint8
+}
+
+// UpdateRect queues a partial update of the given rectangle of the given
+// mipmap level of the texture's image data, where pix holds the new pixels of
+// the rectangle in 8-bit RGBA form (four bytes per pixel) in row-major order
+// starting at the top-left of the rectangle.
+//
+// The rectangle must lie within the bounds of the mipmap level, which are the
+// texture's bounds (t.Bounds, or the bounds of t.Source if t.Bounds is empty)
+// with their size halved for each level (down to one pixel). A panic occurs if
+// it does not, or if the length of pix does not match the size of the
+// rectangle.
+//
+// Updates are applied by the renderer the next time the texture is loaded
+// (see Renderer.LoadTexture), in-place on the GPU without reallocating the
+// texture's storage. This makes them suitable for dynamic atlases which are
+// updated frequently (e.g. glyph caches, minimaps, or video frames).
+//
+// The pix slice is not copied, it must not be modified until the update is
+// applied.
+//
+// The texture's write lock must be held for this method to operate safely.
+func (t *Texture) UpdateRect(level int, r image.Rectangle, pix []uint8) {
+	if !r.In(t.levelBounds(level)) {
+		panic("UpdateRect(): rectangle out of mipmap level bounds")
+	}
+	if len(pix) != 4*r.Dx()*r.Dy() {
+		panic("UpdateRect(): len(pix) does not match rectangle size")
+	}
+	t.Updates = append(t.Updates, TexUpdate{
+		Level: level,
+		Rect:  r,
+		Pix:   pix,
+	})
+}
+
+// levelBounds returns the bounds of the given mipmap level of the texture, see
+// UpdateRect. The bounds are empty if the level is invalid.
+func (t *Texture) levelBounds(level int) image.Rectangle {
+	b := t.Bounds
+	if b.Empty() && t.Source != nil {
+		b = t.Source.Bounds()
+	}
+	if level < 0 || b.Empty() {
+		return image.Rectangle{}
+	}
+	size := b.Size()
+	for i := 0; i < level; i++ {
+		if size.X == 1 && size.Y == 1 {
+			return image.Rectangle{}
+		}
+		if size.X > 1 {
+			size.X /= 2
+		}
+		if size.Y > 1 {
+			size.Y /= 2
+		}
+	}
+	return image.Rectangle{Min: b.Min, Max: b.Min.Add(size)}
 }
 
 // Copy returns a new copy of this Texture. Explicitly not copied over is the
-// native texture, the OnLoad slice, the Loaded status, and the source image
-// (because the image type is not strictly known). Because the texture's source
-// image is not copied over, you may want to copy it directly over yourself.
+// native texture, the OnLoad slice, the Loaded status, the pending updates,
+// the buffer data, and the source, layer, and mipmap images (because the image
+// type is not strictly known). Because the texture's source images are not copied over,
+// you may want to copy them directly over yourself.
 //
 // The texture's read lock must be held for this method to operate safely.
 func (t *Texture) Copy() *Texture {
@@ -195,22 +655,49 @@ func (t *Texture) Copy() *Texture {
 		t.KeepDataOnLoad,
 		t.Bounds,
 		nil, // Source image -- not copied.
+		t.Type,
+		nil, // Layer images -- not copied.
+		nil, // Buffer data -- not copied.
 		t.Format,
+		t.SRGB,
+		t.Swizzle,
 		t.WrapU,
 		t.WrapV,
+		t.WrapW,
 		t.BorderColor,
 		t.MinFilter,
 		t.MagFilter,
+		t.Anisotropy,
+		nil, // Mipmap images -- not copied.
+		t.MinLOD,
+		t.MaxLOD,
+		t.LODBias,
+		t.DepthCompare,
+		t.DepthCmp,
+		nil, // Updates -- not copied.
 	}
 }
 
-// ClearData sets the data source image, t.Source, of this texture to nil if
-// t.KeepDataOnLoad is set to false.
+// ClearData sets the data source image, t.Source, of this texture (and each
+// of it's layer images, t.Layers, it's mipmap images, t.Mipmaps, and it's
+// buffer data, t.Buffer) to nil if t.KeepDataOnLoad is set to false.
+//
+// Explicitly provided mipmap levels are cleared along with the source image,
+// as they are uploaded together: t.Mipmaps is set to nil. If only the source
+// image is provided again before the texture is loaded again, the mipmaps are
+// generated automatically instead. Textures which are reloaded (e.g. after
+// the graphics context is lost) and whose mipmaps cannot be provided again
+// should set t.KeepDataOnLoad.
 //
 // The texture's write lock must be held for this method to operate safely.
 func (t *Texture) ClearData() {
 	if !t.KeepDataOnLoad {
 		t.Source = nil
+		for i := range t.Layers {
+			t.Layers[i] = nil
+		}
+		t.Mipmaps = nil
+		t.Buffer = nil
 	}
 }
 
@@ -223,12 +710,82 @@ func (t *Texture) Reset() {
 	t.KeepDataOnLoad = false
 	t.Bounds = image.Rectangle{}
 	t.Source = nil
+	t.Type = Texture2D
+	t.Layers = nil
+	t.Buffer = nil
 	t.Format = RGBA
+	t.SRGB = false
+	t.Swizzle = TexSwizzle{}
 	t.WrapU = 0
 	t.WrapV = 0
+	t.WrapW = 0
 	t.BorderColor = Color{}
 	t.MinFilter = 0
 	t.MagFilter = 0
+	t.Anisotropy = 0
+	t.Mipmaps = nil
+	t.MinLOD = -1000
+	t.MaxLOD = 1000
+	t.LODBias = 0
+	t.DepthCompare = false
+	t.DepthCmp = Always
+	t.Updates = nil
+}
+
+// PackMat4s appends the given matrices to dst, as the data of a
+// BufferTexture texture, and returns the extended slice. Each matrix occupies
+// four texels, one per row, such that a shader can fetch matrix i using:
+//  mat4(texelFetch(Buffer, 4*i), texelFetch(Buffer, 4*i+1),
+//       texelFetch(Buffer, 4*i+2), texelFetch(Buffer, 4*i+3))
+func PackMat4s(dst []float32, ms []Mat4) []float32 {
+	for _, m := range ms {
+		for _, row := range m {
+			dst = append(dst, row[:]...)
+		}
+	}
+	return dst
+}
+
+// MipLevels returns the number of levels in a complete mipmap chain for a
+// texture of the given size, including the base level. The size of level i is
+// max(1, size.X>>i) by max(1, size.Y>>i).
+func MipLevels(size image.Point) int {
+	n := 1
+	for size.X > 1 || size.Y > 1 {
+		size.X /= 2
+		size.Y /= 2
+		n++
+	}
+	return n
+}
+
+// MipmapsValid tells if the explicitly provided mipmap levels of this texture
+// (see the Mipmaps field) form a complete chain for the texture's Bounds (or
+// it's source image's bounds, if Bounds is empty). It returns true if no
+// mipmap levels are provided.
+//
+// The texture's read lock must be held for this method to operate safely.
+func (t *Texture) MipmapsValid() bool {
+	if t.Mipmaps == nil {
+		return true
+	}
+	size := t.levelBounds(0).Size()
+	if len(t.Mipmaps) != MipLevels(size)-1 {
+		return false
+	}
+	for i, m := range t.Mipmaps {
+		want := image.Pt(size.X>>uint(i+1), size.Y>>uint(i+1))
+		if want.X < 1 {
+			want.X = 1
+		}
+		if want.Y < 1 {
+			want.Y = 1
+		}
+		if m == nil || m.Bounds().Size() != want {
+			return false
+		}
+	}
+	return true
 }
 
 // Destroy destroys this texture for use by other callees to NewTexture. You
@@ -248,6 +805,8 @@ var texturePool = sync.Pool{
 	New: func() interface{} {
 		return &Texture{
 			Format: RGBA,
+			MinLOD: -1000,
+			MaxLOD: 1000,
 		}
 	},
 }
@@ -0,0 +1,166 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"testing"
+)
+
+func TestMipLevels(t *testing.T) {
+	tests := []struct {
+		size image.Point
+		want int
+	}{
+		{image.Pt(1, 1), 1},
+		{image.Pt(2, 2), 2},
+		{image.Pt(256, 256), 9},
+		{image.Pt(256, 16), 9},
+		{image.Pt(5, 3), 3},
+	}
+	for _, tst := range tests {
+		if got := MipLevels(tst.size); got != tst.want {
+			t.Errorf("MipLevels(%v) = %d, want %d", tst.size, got, tst.want)
+		}
+	}
+}
+
+func TestMipmapsValid(t *testing.T) {
+	tex := NewTexture()
+	tex.Bounds = image.Rect(0, 0, 8, 2)
+	if !tex.MipmapsValid() {
+		t.Fatal("nil mipmaps should be valid")
+	}
+	tex.Mipmaps = []image.Image{
+		image.NewRGBA(image.Rect(0, 0, 4, 1)),
+		image.NewRGBA(image.Rect(0, 0, 2, 1)),
+		image.NewRGBA(image.Rect(0, 0, 1, 1)),
+	}
+	if !tex.MipmapsValid() {
+		t.Fatal("complete chain should be valid")
+	}
+	tex.Mipmaps = tex.Mipmaps[:2]
+	if tex.MipmapsValid() {
+		t.Fatal("incomplete chain should be invalid")
+	}
+
+	// Without Bounds, the chain is checked against the source image.
+	tex.Bounds = image.Rectangle{}
+	tex.Source = image.NewRGBA(image.Rect(0, 0, 4, 4))
+	tex.Mipmaps = []image.Image{
+		image.NewRGBA(image.Rect(0, 0, 2, 2)),
+		image.NewRGBA(image.Rect(0, 0, 1, 1)),
+	}
+	if !tex.MipmapsValid() {
+		t.Fatal("complete chain for the source image should be valid")
+	}
+}
+
+func TestTextureClearDataMipmaps(t *testing.T) {
+	newTex := func() *Texture {
+		tex := NewTexture()
+		tex.Bounds = image.Rect(0, 0, 2, 2)
+		tex.Source = image.NewRGBA(tex.Bounds)
+		tex.Mipmaps = []image.Image{image.NewRGBA(image.Rect(0, 0, 1, 1))}
+		return tex
+	}
+
+	// Explicit mipmaps are cleared with the source image, so providing only
+	// the source image again leaves them to be generated automatically.
+	tex := newTex()
+	tex.ClearData()
+	if tex.Source != nil || tex.Mipmaps != nil {
+		t.Fatal("ClearData did not clear the source and mipmap images")
+	}
+	tex.Source = image.NewRGBA(tex.Bounds)
+	if !tex.MipmapsValid() {
+		t.Fatal("mipmaps invalid after ClearData")
+	}
+
+	// They are kept along with the source image with KeepDataOnLoad.
+	tex = newTex()
+	tex.KeepDataOnLoad = true
+	tex.ClearData()
+	if tex.Source == nil || tex.Mipmaps[0] == nil || !tex.MipmapsValid() {
+		t.Fatal("ClearData cleared the mipmaps with KeepDataOnLoad set")
+	}
+}
+
+func TestSwizzleApply(t *testing.T) {
+	c := Color{0.1, 0.2, 0.3, 0.4}
+	if got := (TexSwizzle{}).Apply(c); got != c {
+		t.Errorf("zero swizzle: got %v, want %v", got, c)
+	}
+	want := Color{1, 1, 1, 0.1}
+	if got := AlphaSwizzle.Apply(c); got != want {
+		t.Errorf("AlphaSwizzle: got %v, want %v", got, want)
+	}
+	want = Color{0.4, 0.3, 0, 0.1}
+	if got := (TexSwizzle{SwizzleA, SwizzleB, SwizzleZero, SwizzleR}).Apply(c); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestTextureUpdateRect(t *testing.T) {
+	tex := NewTexture()
+	tex.Bounds = image.Rect(0, 0, 64, 64)
+	r := image.Rect(8, 8, 12, 10)
+	pix := make([]uint8, 4*4*2)
+	tex.UpdateRect(0, r, pix)
+	if len(tex.Updates) != 1 || tex.Updates[0].Rect != r || tex.Updates[0].Level != 0 {
+		t.Fatalf("got updates %+v", tex.Updates)
+	}
+
+	panics := func(level int, r image.Rectangle, pix []uint8) (p bool) {
+		defer func() {
+			p = recover() != nil
+		}()
+		tex.UpdateRect(level, r, pix)
+		return
+	}
+	if !panics(1, r, pix[:4]) {
+		t.Fatal("expected panic for mismatched pixel length")
+	}
+
+	// Level 4 of a 64x64 texture is 4x4 pixels.
+	if panics(4, image.Rect(0, 0, 4, 4), make([]uint8, 4*4*4)) {
+		t.Fatal("unexpected panic for rectangle within level bounds")
+	}
+	for _, c := range []struct {
+		level int
+		r     image.Rectangle
+	}{
+		{0, image.Rect(60, 60, 65, 64)},
+		{0, image.Rect(-1, 0, 1, 1)},
+		{4, image.Rect(2, 2, 6, 4)},
+		{7, image.Rect(0, 0, 1, 1)},
+		{-1, image.Rect(0, 0, 1, 1)},
+	} {
+		if !panics(c.level, c.r, make([]uint8, 4*c.r.Dx()*c.r.Dy())) {
+			t.Errorf("level %d rectangle %v: expected panic for rectangle out of bounds", c.level, c.r)
+		}
+	}
+	if len(tex.Updates) != 2 {
+		t.Fatalf("got %d updates, want 2", len(tex.Updates))
+	}
+}
+
+func TestPackMat4s(t *testing.T) {
+	var m Mat4
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = float32(i*4 + j)
+		}
+	}
+	buf := PackMat4s(nil, []Mat4{m, m})
+	if len(buf) != 32 {
+		t.Fatalf("got %d floats, want 32", len(buf))
+	}
+	for i, f := range buf {
+		if f != float32(i%16) {
+			t.Fatalf("buf[%d] = %v, want %v", i, f, i%16)
+		}
+	}
+}
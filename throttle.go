@@ -0,0 +1,154 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Visibility describes whether the window being rendered to is focused,
+// unfocused, or occluded (e.g. minimized or entirely covered by another
+// window).
+type Visibility uint8
+
+// String returns a string representation of this visibility.
+// e.g. Unfocused -> "Unfocused"
+func (v Visibility) String() string {
+	switch v {
+	case Focused:
+		return "Focused"
+	case Unfocused:
+		return "Unfocused"
+	case Occluded:
+		return "Occluded"
+	}
+	return fmt.Sprintf("Visibility(%d)", v)
+}
+
+const (
+	// Focused is the visibility of a window that has input focus.
+	Focused Visibility = iota
+
+	// Unfocused is the visibility of a window that is visible but does not
+	// have input focus (e.g. a tool running in the background).
+	Unfocused
+
+	// Occluded is the visibility of a window that cannot be seen at all (e.g.
+	// it is minimized or entirely covered by another window).
+	Occluded
+)
+
+// ThrottlePolicy describes how rendering is throttled while the window is not
+// focused, in order to save power.
+type ThrottlePolicy struct {
+	// The maximum frame rate while the window is unfocused or occluded,
+	// respectively. Zero means the frame rate is not reduced (i.e. only the
+	// governor's cap applies).
+	UnfocusedFrameRate, OccludedFrameRate float64
+
+	// Whether or not expensive passes (see Throttle.SkipExpensive) should be
+	// skipped while the window is unfocused. Expensive passes are always
+	// skipped while the window is occluded.
+	SkipPassesUnfocused bool
+}
+
+// DefaultThrottlePolicy is the default throttle policy, it renders at 10 FPS
+// while unfocused, and at 1 FPS while occluded, skipping expensive passes in
+// both cases.
+var DefaultThrottlePolicy = ThrottlePolicy{
+	UnfocusedFrameRate:  10,
+	OccludedFrameRate:   1,
+	SkipPassesUnfocused: true,
+}
+
+// Throttle reduces the frame rate of a renderer, according to a policy, while
+// the window is unfocused or occluded. Applications report changes to the
+// visibility of their window using the SetVisibility method, and consult the
+// SkipExpensive method each frame to decide whether or not to perform
+// expensive rendering passes (e.g. shadows, reflections, post-processing).
+//
+// It is safe to use a throttle from multiple goroutines concurrently.
+type Throttle struct {
+	access   sync.Mutex
+	r        Renderer
+	governor Governor
+	policy   ThrottlePolicy
+	vis      Visibility
+}
+
+// NewThrottle returns a new throttle for the given renderer, using the given
+// governor while the window is focused and the given policy while it is not.
+// The governor is applied to the renderer immediately (see ApplyGovernor).
+func NewThrottle(r Renderer, g Governor, p ThrottlePolicy) *Throttle {
+	t := &Throttle{
+		r:        r,
+		governor: g,
+		policy:   p,
+	}
+	t.SetVisibility(Focused)
+	return t
+}
+
+// SetPolicy sets the policy of the throttle and reapplies it for the current
+// visibility, returning the applied settings as SetVisibility does.
+func (t *Throttle) SetPolicy(p ThrottlePolicy) GovernorSettings {
+	t.access.Lock()
+	t.policy = p
+	vis := t.vis
+	t.access.Unlock()
+	return t.SetVisibility(vis)
+}
+
+// SetVisibility sets the visibility of the window and applies the resulting
+// frame rate cap to the clock of the renderer. While the window is not
+// focused the cap is the lower of the governor's cap and the policy's frame
+// rate, and WaitEvents is set in the returned settings.
+//
+// The returned settings should be applied to the window (see ApplyGovernor).
+func (t *Throttle) SetVisibility(v Visibility) GovernorSettings {
+	t.access.Lock()
+	defer t.access.Unlock()
+	t.vis = v
+
+	s := t.governor.Settings(t.r.SwapChain().RefreshRate)
+	var rate float64
+	switch v {
+	case Unfocused:
+		rate = t.policy.UnfocusedFrameRate
+	case Occluded:
+		rate = t.policy.OccludedFrameRate
+	}
+	if v != Focused {
+		if rate > 0 && (s.MaxFrameRate == 0 || rate < s.MaxFrameRate) {
+			s.MaxFrameRate = rate
+		}
+		s.WaitEvents = true
+	}
+	t.r.Clock().SetMaxFrameRate(s.MaxFrameRate)
+	return s
+}
+
+// Visibility returns the visibility last set using SetVisibility.
+func (t *Throttle) Visibility() Visibility {
+	t.access.Lock()
+	defer t.access.Unlock()
+	return t.vis
+}
+
+// SkipExpensive reports whether or not expensive rendering passes should be
+// skipped this frame, i.e. whether the window is occluded, or unfocused and
+// the policy's SkipPassesUnfocused is set.
+func (t *Throttle) SkipExpensive() bool {
+	t.access.Lock()
+	defer t.access.Unlock()
+	switch t.vis {
+	case Occluded:
+		return true
+	case Unfocused:
+		return t.policy.SkipPassesUnfocused
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestThrottle(t *testing.T) {
+	r := Nil()
+	th := NewThrottle(r, MatchRefresh, DefaultThrottlePolicy)
+	if max := r.Clock().MaxFrameRate(); max != 0 {
+		t.Errorf("focused max frame rate = %v, want 0", max)
+	}
+	if th.SkipExpensive() {
+		t.Error("SkipExpensive() = true while focused")
+	}
+
+	tests := []struct {
+		vis  Visibility
+		max  float64
+		skip bool
+	}{
+		{Unfocused, 10, true},
+		{Occluded, 1, true},
+		{Focused, 0, false},
+	}
+	for _, tst := range tests {
+		s := th.SetVisibility(tst.vis)
+		if s.MaxFrameRate != tst.max || r.Clock().MaxFrameRate() != tst.max {
+			t.Errorf("%v: max frame rate = %v (clock %v), want %v", tst.vis, s.MaxFrameRate, r.Clock().MaxFrameRate(), tst.max)
+		}
+		if s.WaitEvents != (tst.vis != Focused) {
+			t.Errorf("%v: WaitEvents = %v", tst.vis, s.WaitEvents)
+		}
+		if got := th.SkipExpensive(); got != tst.skip {
+			t.Errorf("%v: SkipExpensive() = %v, want %v", tst.vis, got, tst.skip)
+		}
+	}
+
+	// A lower governor cap is kept while unfocused.
+	th = NewThrottle(r, BatterySaver, ThrottlePolicy{UnfocusedFrameRate: 45})
+	if s := th.SetVisibility(Unfocused); s.MaxFrameRate != 30 {
+		t.Errorf("unfocused battery saver max frame rate = %v, want 30", s.MaxFrameRate)
+	}
+	if th.SkipExpensive() {
+		t.Error("SkipExpensive() = true while unfocused without SkipPassesUnfocused")
+	}
+	th.SetPolicy(DefaultThrottlePolicy)
+	if max := r.Clock().MaxFrameRate(); max != 10 {
+		t.Errorf("max frame rate after SetPolicy = %v, want 10", max)
+	}
+}
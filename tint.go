@@ -0,0 +1,40 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+// TintGLSL is a GLSL 1.20 snippet implementing the standard tinting
+// convention (see ApplyTint), for inclusion in fragment shaders after the
+// #version directive. It declares the Tint uniform, which renderers supply in
+// linear space (see Object.Tint), and the tint function which applies it and
+// the interpolated (sRGB-encoded) vertex color to a linear texture color:
+//  gl_FragColor = tint(texture2D(Texture0, tc0), vColor);
+var TintGLSL = []byte(`
+uniform vec4 Tint;
+
+vec3 tintLinear(vec3 c)
+{
+	vec3 lo = c / 12.92;
+	vec3 hi = pow((c + 0.055) / 1.055, vec3(2.4));
+	return mix(lo, hi, step(vec3(0.04045), c));
+}
+
+vec4 tint(vec4 texel, vec4 vertexColor)
+{
+	return texel * vec4(tintLinear(vertexColor.rgb), vertexColor.a) * Tint;
+}
+`)
+
+// ApplyTint applies the standard tinting convention to the given texture
+// color (in linear space), vertex color, and per-instance tint (both
+// sRGB-encoded). The vertex color and tint are converted into linear space and
+// multiplied with the texture color, component-wise:
+//  texel * vertexColor.Linear() * tint.Linear()
+//
+// The result is in linear space. All shaders which support tinting should
+// follow this convention (e.g. by using TintGLSL), such that vertex colors and
+// tints behave identically everywhere.
+func ApplyTint(texel, vertexColor, tint Color) Color {
+	return texel.Mul(vertexColor.Linear()).Mul(tint.Linear())
+}
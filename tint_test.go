@@ -0,0 +1,37 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestColorSRGBRoundTrip(t *testing.T) {
+	for i := 0; i <= 255; i++ {
+		v := float32(i) / 255
+		c := Color{v, v, v, v}
+		got := c.Linear().SRGB()
+		if math.Abs(float64(got.R-v)) > 1e-5 || got.A != v {
+			t.Fatalf("%v: round trip gave %v", c, got)
+		}
+	}
+	if l := (Color{0.5, 0.5, 0.5, 0.5}).Linear(); math.Abs(float64(l.R)-0.214) > 1e-3 || l.A != 0.5 {
+		t.Fatalf("Linear of sRGB 0.5 = %v", l)
+	}
+}
+
+func TestApplyTint(t *testing.T) {
+	white := Color{1, 1, 1, 1}
+	texel := Color{0.2, 0.4, 0.6, 0.8}
+	if got := ApplyTint(texel, white, white); got != texel {
+		t.Errorf("white tint: got %v, want %v", got, texel)
+	}
+	got := ApplyTint(texel, white, Color{1, 0, 1, 0.5})
+	want := Color{0.2, 0, 0.6, 0.4}
+	if got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
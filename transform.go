@@ -6,6 +6,7 @@ package gfx
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"azul3d.org/lmath.v1"
 )
@@ -61,12 +62,30 @@ const (
 // any value in any transform's local space can be converted to world space and
 // back) converting between world and local/parent space can be extremely
 // useful for e.g. relative movement/rotation to another object's transform.
+//
+// The world space matrices of a transform are computed lazily (i.e. only once
+// they are requested) and cached. When a transform changes it marks each of
+// it's descendants dirty, such that their cached matrices are only rebuilt
+// once they are requested again: moving a tank updates it's turret without
+// any manual matrix multiplication, and transforms which are never requested
+// cost nothing.
 type Transform struct {
 	access sync.RWMutex
 
 	// The parent transform, or nil if there is none.
-	parent     Transformable
-	lastParent *lmath.Mat4
+	parent Transformable
+
+	// The transform of the parent that this transform is registered as a
+	// child of (see the children field), or nil if it is not registered.
+	parentT *Transform
+
+	// The child transforms of this transform, which are marked dirty when it
+	// changes.
+	children []*Transform
+
+	// Set to one (atomically) when an ancestor of this transform has changed
+	// and the world space matrices must be rebuilt.
+	dirty int32
 
 	// A pointer to the built (i.e. cached) transformation matrix or nil if a
 	// rebuild is required.
@@ -125,59 +144,129 @@ fail:
 	return false
 }
 
-// build builds and stores the transformation matrix from the components of
-// this transform.
+// build builds and stores the transformation matrices of this transform, if
+// they are not cached. The write lock must be held.
 func (t *Transform) build() {
-	var parent *Transform
-	if t.parent != nil {
-		parent = t.parent.Transform()
-	}
+	t.register()
+	parent := t.parentT
 
-	nilParents := t.lastParent == nil && parent == nil
-	nonNilParents := t.lastParent != nil && parent != nil
-	if t.built != nil && (nilParents || (nonNilParents && t.lastParent.Equals(parent.Mat4()))) {
-		// No update is required.
-		return
-	}
-	if parent != nil {
-		parentMat := parent.Mat4()
-		t.lastParent = &parentMat
+	// Clear the dirty flag before querying the parent, such that changes to
+	// the parent made after the query mark this transform dirty again.
+	if atomic.SwapInt32(&t.dirty, 0) != 0 {
+		t.localToWorld = nil
 	}
 
-	// Apply rotation
-	var hpr lmath.Vec3
-	if t.quat != nil {
-		// Use quaternion rotation.
-		hpr = (*t.quat).Hpr(lmath.CoordSysZUpRight)
-	} else {
-		// Use euler rotation.
-		hpr = t.rot.XyzToHpr().Radians()
-	}
+	if t.built == nil {
+		// Apply rotation
+		var hpr lmath.Vec3
+		if t.quat != nil {
+			// Use quaternion rotation.
+			hpr = (*t.quat).Hpr(lmath.CoordSysZUpRight)
+		} else {
+			// Use euler rotation.
+			hpr = t.rot.XyzToHpr().Radians()
+		}
 
-	// Compose upper 3x3 matrics using scale, shear, and HPR components.
-	scaleShearHpr := lmath.Mat3Compose(t.scale, t.shear, hpr, lmath.CoordSysZUpRight)
+		// Compose upper 3x3 matrics using scale, shear, and HPR components.
+		scaleShearHpr := lmath.Mat3Compose(t.scale, t.shear, hpr, lmath.CoordSysZUpRight)
 
-	// Build this space's transformation matrix.
-	built := lmath.Mat4Identity.SetUpperMat3(scaleShearHpr)
-	built = built.SetTranslation(t.pos)
-	t.built = &built
+		// Build this space's transformation matrix.
+		built := lmath.Mat4Identity.SetUpperMat3(scaleShearHpr)
+		built = built.SetTranslation(t.pos)
+		t.built = &built
+		t.localToWorld = nil
+	}
+	if t.localToWorld != nil {
+		// No update is required.
+		return
+	}
 
 	// Build the local-to-world transformation matrix.
-	ltw := built
+	ltw := *t.built
 	if parent != nil {
 		ltw = ltw.Mul(parent.Convert(LocalToWorld))
 	}
 	t.localToWorld = &ltw
 
 	// Build the world-to-local transformation matrix.
-	wtl, _ := built.Inverse()
+	wtl, _ := t.built.Inverse()
 	if parent != nil {
-		parentToWorld := parent.Convert(WorldToLocal)
-		wtl = wtl.Mul(parentToWorld)
+		worldToParent := parent.Convert(WorldToLocal)
+		wtl = worldToParent.Mul(wtl)
 	}
 	t.worldToLocal = &wtl
 }
 
+// register registers this transform as a child of the transform of it's
+// parent, if it is not already (e.g. because the parent object was reset and
+// has a different transform now). The write lock must be held.
+func (t *Transform) register() {
+	var parent *Transform
+	if t.parent != nil {
+		parent = t.parent.Transform()
+	}
+	if parent == t.parentT {
+		return
+	}
+	if t.parentT != nil {
+		t.parentT.removeChild(t)
+	}
+	if parent != nil {
+		parent.addChild(t)
+	}
+	t.parentT = parent
+	t.localToWorld = nil
+}
+
+// addChild registers c as a child of this transform.
+func (t *Transform) addChild(c *Transform) {
+	t.access.Lock()
+	t.children = append(t.children, c)
+	t.access.Unlock()
+}
+
+// removeChild unregisters c as a child of this transform.
+func (t *Transform) removeChild(c *Transform) {
+	t.access.Lock()
+	for i, child := range t.children {
+		if child == c {
+			last := len(t.children) - 1
+			t.children[i] = t.children[last]
+			t.children[last] = nil
+			t.children = t.children[:last]
+			break
+		}
+	}
+	t.access.Unlock()
+}
+
+// invalidate marks each descendant of this transform dirty. The lock must not
+// be held, as children lock their parents while building.
+func (t *Transform) invalidate() {
+	t.access.RLock()
+	if len(t.children) == 0 {
+		t.access.RUnlock()
+		return
+	}
+	children := make([]*Transform, len(t.children))
+	copy(children, t.children)
+	t.access.RUnlock()
+	for _, c := range children {
+		atomic.StoreInt32(&c.dirty, 1)
+		c.invalidate()
+	}
+}
+
+// Children returns the transforms whose parent is this transform (i.e. which
+// are registered to be marked dirty when this transform changes).
+func (t *Transform) Children() []*Transform {
+	t.access.RLock()
+	children := make([]*Transform, len(t.children))
+	copy(children, t.children)
+	t.access.RUnlock()
+	return children
+}
+
 // Implements Transformable interface by simply returning t.
 func (t *Transform) Transform() *Transform {
 	return t
@@ -205,13 +294,23 @@ func (t *Transform) LocalMat4() lmath.Mat4 {
 //
 // e.g. setting the parent of a camera's transform to the player's transform
 // makes it such that the camera follows the player.
+//
+// A transform is registered as a child of it's parent (see Children) until
+// it's parent is set to nil or it is destroyed, transforms which are no longer
+// used should do either such that their parent does not retain them (objects
+// do so for their transform when they are reset or destroyed).
 func (t *Transform) SetParent(p Transformable) {
 	t.access.Lock()
-	if t.parent != p {
-		t.built = nil
+	changed := t.parent != p
+	if changed {
+		t.localToWorld = nil
 		t.parent = p
+		t.register()
 	}
 	t.access.Unlock()
+	if changed {
+		t.invalidate()
+	}
 }
 
 // Parent returns the parent of this transform, as previously set.
@@ -228,11 +327,15 @@ func (t *Transform) Parent() Transformable {
 // whether quaternion or euler rotation will be used by this transform.
 func (t *Transform) SetQuat(q lmath.Quat) {
 	t.access.Lock()
-	if (*t.quat) != q {
+	changed := t.quat == nil || *t.quat != q
+	if changed {
 		t.built = nil
 		t.quat = &q
 	}
 	t.access.Unlock()
+	if changed {
+		t.invalidate()
+	}
 }
 
 // Quat returns the quaternion rotation of this transform. If this transform is
@@ -274,12 +377,16 @@ func (t *Transform) IsQuat() bool {
 // whether quaternion or euler rotation will be used by this transform.
 func (t *Transform) SetRot(r lmath.Vec3) {
 	t.access.Lock()
-	if t.rot != r {
+	changed := t.quat != nil || t.rot != r
+	if changed {
 		t.built = nil
 		t.quat = nil
 		t.rot = r
 	}
 	t.access.Unlock()
+	if changed {
+		t.invalidate()
+	}
 }
 
 // Rot returns the euler rotation of this transform. If this transform is
@@ -304,11 +411,15 @@ func (t *Transform) Rot() lmath.Vec3 {
 // SetPos sets the local position of this transform.
 func (t *Transform) SetPos(p lmath.Vec3) {
 	t.access.Lock()
-	if t.pos != p {
+	changed := t.pos != p
+	if changed {
 		t.built = nil
 		t.pos = p
 	}
 	t.access.Unlock()
+	if changed {
+		t.invalidate()
+	}
 }
 
 // Pos returns the local position of this transform.
@@ -325,11 +436,15 @@ func (t *Transform) Pos() lmath.Vec3 {
 // on the local Z axis at all).
 func (t *Transform) SetScale(s lmath.Vec3) {
 	t.access.Lock()
-	if t.scale != s {
+	changed := t.scale != s
+	if changed {
 		t.built = nil
 		t.scale = s
 	}
 	t.access.Unlock()
+	if changed {
+		t.invalidate()
+	}
 }
 
 // Scale returns the local scacle of this transform.
@@ -343,11 +458,15 @@ func (t *Transform) Scale() lmath.Vec3 {
 // SetShear sets the local shear of this transform.
 func (t *Transform) SetShear(s lmath.Vec3) {
 	t.access.Lock()
-	if t.shear != s {
+	changed := t.shear != s
+	if changed {
 		t.built = nil
 		t.shear = s
 	}
 	t.access.Unlock()
+	if changed {
+		t.invalidate()
+	}
 }
 
 // Shear returns the local shear of this transform.
@@ -358,10 +477,19 @@ func (t *Transform) Shear() lmath.Vec3 {
 	return s
 }
 
-// Reset sets all of the values of this transform to the default ones.
+// Reset sets all of the values of this transform to the default ones. It is
+// unregistered from it's parent, and it's children are unregistered from it
+// (they still inherit from it, and register again once they are built).
 func (t *Transform) Reset() {
 	t.access.Lock()
+	if t.parentT != nil {
+		t.parentT.removeChild(t)
+	}
 	t.parent = nil
+	t.parentT = nil
+	children := t.children
+	t.children = nil
+	atomic.StoreInt32(&t.dirty, 0)
 	t.built = nil
 	t.localToWorld = nil
 	t.worldToLocal = nil
@@ -371,10 +499,21 @@ func (t *Transform) Reset() {
 	t.scale = lmath.Vec3One
 	t.shear = lmath.Vec3Zero
 	t.access.Unlock()
+
+	for _, c := range children {
+		c.access.Lock()
+		if c.parentT == t {
+			c.parentT = nil
+			c.localToWorld = nil
+		}
+		c.access.Unlock()
+		c.invalidate()
+	}
 }
 
 // Copy returns a new transform with all of it's values set equal to t (i.e. a
-// copy of this transform).
+// copy of this transform). Like SetParent, the copy is registered as a child
+// of it's parent such that it follows changes to the parent.
 func (t *Transform) Copy() *Transform {
 	t.access.RLock()
 	cpy := &Transform{
@@ -401,6 +540,10 @@ func (t *Transform) Copy() *Transform {
 		cpy.quat = &quatCpy
 	}
 	t.access.RUnlock()
+
+	cpy.access.Lock()
+	cpy.register()
+	cpy.access.Unlock()
 	return cpy
 }
 
@@ -439,7 +582,7 @@ func (t *Transform) Convert(c CoordConv) lmath.Mat4 {
 		wtl := *t.worldToLocal
 		local := *t.built
 		t.access.Unlock()
-		return local.Mul(wtl)
+		return wtl.Mul(local)
 	}
 	panic("Convert(): invalid conversion")
 }
@@ -122,3 +122,136 @@ func TestTransformRotToWorld(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestTransformDirtyPropagation(t *testing.T) {
+	tank := NewTransform()
+	turret := NewTransform()
+	turret.SetPos(lmath.Vec3{0, 0, 2})
+	turret.SetParent(tank)
+	barrel := NewTransform()
+	barrel.SetPos(lmath.Vec3{0, 3, 0})
+	barrel.SetParent(turret)
+
+	want := lmath.Vec3{0, 3, 2}
+	if got := barrel.Convert(LocalToWorld).Translation(); !got.Equals(want) {
+		t.Log("got (world)", got)
+		t.Log("want (world)", want)
+		t.Fail()
+	}
+	if c := tank.Children(); len(c) != 1 || c[0] != turret {
+		t.Log("tank children", c)
+		t.Fail()
+	}
+
+	// Moving the tank must move the barrel, although only the tank changed.
+	tank.SetPos(lmath.Vec3{10, 0, 0})
+	want = lmath.Vec3{10, 3, 2}
+	if got := barrel.Convert(LocalToWorld).Translation(); !got.Equals(want) {
+		t.Log("after move got (world)", got)
+		t.Log("after move want (world)", want)
+		t.Fail()
+	}
+	want = lmath.Vec3{-10, -3, -2}
+	if got := barrel.Convert(WorldToLocal).Translation(); !got.Equals(want) {
+		t.Log("after move got (local)", got)
+		t.Log("after move want (local)", want)
+		t.Fail()
+	}
+
+	// Detaching the turret must unregister it and move the barrel back.
+	turret.SetParent(nil)
+	if c := tank.Children(); len(c) != 0 {
+		t.Log("tank children after detach", c)
+		t.Fail()
+	}
+	want = lmath.Vec3{0, 3, 2}
+	if got := barrel.Convert(LocalToWorld).Translation(); !got.Equals(want) {
+		t.Log("after detach got (world)", got)
+		t.Log("after detach want (world)", want)
+		t.Fail()
+	}
+}
+
+func TestTransformChildren(t *testing.T) {
+	parent := NewTransform()
+	child := NewTransform()
+
+	// Children are registered as soon as their parent is set, before their
+	// world space matrices are requested.
+	child.SetParent(parent)
+	if c := parent.Children(); len(c) != 1 || c[0] != child {
+		t.Fatal("child not registered, got", c)
+	}
+
+	// Resetting or destroying an object must leave it's transform, which may
+	// be shared with other objects, attached to it's parent.
+	shared := NewTransform()
+	shared.SetParent(parent)
+	a, b := NewObject(), NewObject()
+	a.Transform, b.Transform = shared, shared
+	a.Reset()
+	b.Destroy()
+	if shared.Parent() != parent {
+		t.Fatal("shared transform detached, got parent", shared.Parent())
+	}
+	if c := parent.Children(); len(c) != 2 {
+		t.Fatal("shared transform unregistered, got", c)
+	}
+}
+
+func TestTransformCopyParent(t *testing.T) {
+	parent := NewTransform()
+	child := NewTransform()
+	child.SetPos(lmath.Vec3{0, 1, 0})
+	child.SetParent(parent)
+	child.Convert(LocalToWorld)
+
+	// The copy must be registered with the parent, such that it follows the
+	// parent as the original does.
+	cpy := child.Copy()
+	if c := parent.Children(); len(c) != 2 {
+		t.Fatal("copy not registered, got", c)
+	}
+	parent.SetPos(lmath.Vec3{5, 0, 0})
+	want := lmath.Vec3{5, 1, 0}
+	if got := cpy.Convert(LocalToWorld).Translation(); !got.Equals(want) {
+		t.Log("got (world)", got)
+		t.Log("want (world)", want)
+		t.Fail()
+	}
+}
+
+func TestTransformWorldToLocalRotated(t *testing.T) {
+	// A rotated and scaled parent exposes the order in which the inverses of
+	// the parent and local matrices are multiplied, which translation-only
+	// hierarchies do not.
+	a := NewTransform()
+	a.SetPos(lmath.Vec3{5, 0, 0})
+	a.SetRot(lmath.Vec3{0, 0, 90})
+	a.SetScale(lmath.Vec3{2, 2, 2})
+
+	b := NewTransform()
+	b.SetPos(lmath.Vec3{0, 3, 1})
+	b.SetRot(lmath.Vec3{30, 0, 0})
+	b.SetParent(a)
+
+	local := lmath.Vec3{1, 2, 3}
+	world := b.ConvertPos(local, LocalToWorld)
+	if got := b.ConvertPos(world, WorldToLocal); !got.Equals(local) {
+		t.Log("world-to-local got", got)
+		t.Log("world-to-local want", local)
+		t.Fail()
+	}
+
+	want := a.ConvertPos(world, WorldToLocal)
+	if got := b.ConvertPos(world, WorldToParent); !got.Equals(want) {
+		t.Log("world-to-parent got", got)
+		t.Log("world-to-parent want", want)
+		t.Fail()
+	}
+	if got := b.ConvertPos(want, ParentToWorld); !got.Equals(world) {
+		t.Log("parent-to-world got", got)
+		t.Log("parent-to-world want", world)
+		t.Fail()
+	}
+}
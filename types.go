@@ -74,3 +74,21 @@ func (v Vec4) Vec4() lmath.Vec4 {
 func ConvertVec4(v lmath.Vec4) Vec4 {
 	return Vec4{X: float32(v.X), Y: float32(v.Y), Z: float32(v.Z), W: float32(v.W)}
 }
+
+// IVec2 represents a 32-bit signed integer two-component vector, which is
+// supplied to shaders as an ivec2 (see Shader.Inputs).
+type IVec2 struct {
+	X, Y int32
+}
+
+// IVec3 represents a 32-bit signed integer three-component vector, which is
+// supplied to shaders as an ivec3 (see Shader.Inputs).
+type IVec3 struct {
+	X, Y, Z int32
+}
+
+// IVec4 represents a 32-bit signed integer four-component vector, which is
+// supplied to shaders as an ivec4 (see Shader.Inputs).
+type IVec4 struct {
+	X, Y, Z, W int32
+}
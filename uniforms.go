@@ -0,0 +1,165 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"reflect"
+
+	"azul3d.org/lmath.v1"
+)
+
+// inputSliceTypes lists the slice types supported as shader inputs.
+var inputSliceTypes = map[reflect.Type]bool{
+	reflect.TypeOf([]float32(nil)): true,
+	reflect.TypeOf([]Vec3(nil)):    true,
+	reflect.TypeOf([]Vec4(nil)):    true,
+	reflect.TypeOf([]Mat4(nil)):    true,
+	reflect.TypeOf([]int32(nil)):   true,
+	reflect.TypeOf([]IVec2(nil)):   true,
+	reflect.TypeOf([]IVec3(nil)):   true,
+	reflect.TypeOf([]IVec4(nil)):   true,
+}
+
+// inputValue converts the value into a shader input of one of the supported
+// non-slice types (see Shader.Inputs), if possible.
+func inputValue(v reflect.Value) (interface{}, bool) {
+	switch t := v.Interface().(type) {
+	case bool, float32, int32, Vec3, Vec4, Mat4, IVec2, IVec3, IVec4:
+		return t, true
+	case lmath.Vec3:
+		return ConvertVec3(t), true
+	case lmath.Vec4:
+		return ConvertVec4(t), true
+	case lmath.Mat4:
+		return ConvertMat4(t), true
+	case Color:
+		return Vec4{t.R, t.G, t.B, t.A}, true
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return float32(v.Float()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int32(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int32(v.Uint()), true
+	}
+	return nil, false
+}
+
+// structInputs stores the shader inputs of the value, named name, into the
+// inputs map.
+func structInputs(inputs map[string]interface{}, name string, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if in, ok := inputValue(v); ok {
+		inputs[name] = in
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				// Unexported field.
+				continue
+			}
+			fieldName := f.Name
+			if tag := f.Tag.Get("glsl"); tag == "-" {
+				continue
+			} else if tag != "" {
+				fieldName = tag
+			}
+			if err := structInputs(inputs, name+"."+fieldName, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if inputSliceTypes[v.Type()] {
+			inputs[name] = v.Interface()
+			return nil
+		}
+		if v.Len() == 0 {
+			return nil
+		}
+
+		// Convert each element of the slice, they must all convert to the
+		// same type as the first one.
+		first, ok := inputValue(v.Index(0))
+		if !ok {
+			// Not a basic type, e.g. an array of structs.
+			for i := 0; i < v.Len(); i++ {
+				if err := structInputs(inputs, fmt.Sprintf("%s[%d]", name, i), v.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		s := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(first)), v.Len(), v.Len())
+		if !inputSliceTypes[s.Type()] {
+			return fmt.Errorf("%s: unsupported array of %T", name, first)
+		}
+		for i := 0; i < v.Len(); i++ {
+			in, ok := inputValue(v.Index(i))
+			if !ok || reflect.TypeOf(in) != reflect.TypeOf(first) {
+				return fmt.Errorf("%s[%d]: mixed array element types", name, i)
+			}
+			s.Index(i).Set(reflect.ValueOf(in))
+		}
+		inputs[name] = s.Interface()
+		return nil
+	}
+	return fmt.Errorf("%s: unsupported type %v", name, v.Type())
+}
+
+// SetStruct sets the shader inputs (see the Inputs field) of the GLSL struct
+// uniform with the given name from the Go value v, which is typically a
+// struct. Each exported field of the struct is stored as the input named
+// after the uniform and the field, for example:
+//  type Light struct {
+//      Color    gfx.Color
+//      Position lmath.Vec3
+//      Range    float64
+//      Shadows  bool `glsl:"CastShadows"`
+//  }
+//  s.SetStruct("Lights", []Light{a, b})
+// Stores the inputs "Lights[0].Color", "Lights[0].Position", etc, matching
+// the GLSL declaration:
+//  struct Light {
+//      vec4 Color;
+//      vec3 Position;
+//      float Range;
+//      bool CastShadows;
+//  };
+//  uniform Light Lights[2];
+//
+// The GLSL name of a field may be given by it's glsl tag, a tag of "-" skips
+// the field. Structs may be nested, and arrays or slices of structs are
+// indexed as shown above. Values are converted into the supported input types
+// as follows:
+//  float64, lmath.Vec3, lmath.Vec4, lmath.Mat4 -> float32, Vec3, Vec4, Mat4
+//  gfx.Color                                    -> Vec4
+//  signed and unsigned integers                 -> int32
+//  arrays and slices of the above               -> the slice types
+// Nil pointers are skipped.
+//
+// If a value cannot be converted an error is returned, and the inputs of the
+// fields preceding it are kept.
+//
+// The shader's write lock must be held for this method to operate safely.
+func (s *Shader) SetStruct(name string, v interface{}) error {
+	return structInputs(s.Inputs, name, reflect.ValueOf(v))
+}
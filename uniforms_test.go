@@ -0,0 +1,71 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"reflect"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+type testAttenuation struct {
+	Constant, Linear float64
+}
+
+type testLight struct {
+	Color    Color
+	Position lmath.Vec3
+	Shadows  bool `glsl:"CastShadows"`
+	Atten    *testAttenuation
+	Cascades []float64
+	Tiles    IVec2
+	Skip     string `glsl:"-"`
+	internal int
+}
+
+func TestShaderSetStruct(t *testing.T) {
+	s := NewShader("test")
+	lights := []testLight{
+		{
+			Color:    Color{1, 0.5, 0, 1},
+			Position: lmath.Vec3{1, 2, 3},
+			Shadows:  true,
+			Atten:    &testAttenuation{1, 0.5},
+			Cascades: []float64{10, 50},
+			Tiles:    IVec2{4, 4},
+		},
+		{Color: Color{0, 0, 1, 1}},
+	}
+	if err := s.SetStruct("Lights", lights); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"Lights[0].Color":          Vec4{1, 0.5, 0, 1},
+		"Lights[0].Position":       Vec3{1, 2, 3},
+		"Lights[0].CastShadows":    true,
+		"Lights[0].Atten.Constant": float32(1),
+		"Lights[0].Atten.Linear":   float32(0.5),
+		"Lights[0].Cascades":       []float32{10, 50},
+		"Lights[0].Tiles":          IVec2{4, 4},
+		"Lights[1].Color":          Vec4{0, 0, 1, 1},
+		"Lights[1].Position":       Vec3{},
+		"Lights[1].CastShadows":    false,
+		"Lights[1].Tiles":          IVec2{},
+	}
+	if !reflect.DeepEqual(s.Inputs, want) {
+		t.Fatalf("got inputs:\n%v\nwant:\n%v", s.Inputs, want)
+	}
+
+	if err := s.SetStruct("Count", 3); err != nil || s.Inputs["Count"] != int32(3) {
+		t.Errorf("Count = %v, %v", s.Inputs["Count"], err)
+	}
+	if err := s.SetStruct("Bad", struct{ C chan int }{}); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+	if err := s.SetStruct("Flags", []bool{true}); err == nil {
+		t.Error("expected error for unsupported array type")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+
+	"azul3d.org/lmath.v1"
+)
+
+// UVTransform represents a transformation of texture coordinates, which is
+// used to scroll, tile, rotate, or select a frame of a texture atlas without
+// regenerating the texture coordinates of a mesh on the CPU.
+//
+// Texture coordinates are first scaled, then rotated, and then offset.
+type UVTransform struct {
+	// The offset added to the texture coordinates, e.g. incrementing it each
+	// frame scrolls the texture.
+	Offset TexCoord
+
+	// The scale of the texture coordinates, e.g. {4, 4} tiles a repeating
+	// texture four times in each direction.
+	Scale TexCoord
+
+	// The counter-clockwise rotation of the texture coordinates about the
+	// origin, in degrees.
+	Rotation float64
+}
+
+// IdentityUV is the identity UV transformation, which leaves texture
+// coordinates unchanged.
+var IdentityUV = UVTransform{
+	Scale: TexCoord{1, 1},
+}
+
+// AtlasFrame returns the UV transformation that selects a single frame of a
+// texture atlas made up of a grid of equally sized frames with the given
+// number of columns and rows. Frames are numbered in row-major order,
+// starting with the frame at the texture coordinate origin.
+func AtlasFrame(frame, cols, rows int) UVTransform {
+	col, row := frame%cols, frame/cols
+	return UVTransform{
+		Offset: TexCoord{float32(col) / float32(cols), float32(row) / float32(rows)},
+		Scale:  TexCoord{1 / float32(cols), 1 / float32(rows)},
+	}
+}
+
+// Mat4 returns the matrix form of this transformation. It transforms texture
+// coordinates as a vector with zero Z and one W components, e.g. in GLSL:
+//  tc0 = (UVTransform * vec4(TexCoord0, 0.0, 1.0)).xy;
+func (t UVTransform) Mat4() lmath.Mat4 {
+	s, c := math.Sincos(lmath.Radians(t.Rotation))
+	su, sv := float64(t.Scale.U), float64(t.Scale.V)
+	return lmath.Mat4{
+		{su * c, su * s, 0, 0},
+		{-sv * s, sv * c, 0, 0},
+		{0, 0, 1, 0},
+		{float64(t.Offset.U), float64(t.Offset.V), 0, 1},
+	}
+}
+
+// Apply returns the texture coordinate tc transformed by this transformation.
+func (t UVTransform) Apply(tc TexCoord) TexCoord {
+	s, c := math.Sincos(lmath.Radians(t.Rotation))
+	u := float64(tc.U * t.Scale.U)
+	v := float64(tc.V * t.Scale.V)
+	return TexCoord{
+		U: float32(u*c-v*s) + t.Offset.U,
+		V: float32(u*s+v*c) + t.Offset.V,
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+
+	"azul3d.org/lmath.v1"
+)
+
+func TestUVTransformMat4(t *testing.T) {
+	transforms := []UVTransform{
+		IdentityUV,
+		{Offset: TexCoord{0.25, 0.5}, Scale: TexCoord{2, 3}},
+		{Offset: TexCoord{-1, 1}, Scale: TexCoord{0.5, 1}, Rotation: 30},
+		AtlasFrame(5, 4, 2),
+	}
+	tc := TexCoord{0.3, 0.7}
+	for _, tr := range transforms {
+		want := tr.Apply(tc)
+		m := tr.Mat4()
+		got := lmath.Vec3{float64(tc.U), float64(tc.V), 0}.TransformMat4(m)
+		if math.Abs(got.X-float64(want.U)) > 1e-6 || math.Abs(got.Y-float64(want.V)) > 1e-6 {
+			t.Errorf("%+v: Mat4 gives (%v, %v), Apply gives %v", tr, got.X, got.Y, want)
+		}
+	}
+}
+
+func TestAtlasFrame(t *testing.T) {
+	tr := AtlasFrame(5, 4, 2)
+	min, max := tr.Apply(TexCoord{0, 0}), tr.Apply(TexCoord{1, 1})
+	if min != (TexCoord{0.25, 0.5}) || max != (TexCoord{0.5, 1}) {
+		t.Fatalf("frame 5 of 4x2 spans %v to %v", min, max)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ShaderFeatures is a bitmask of the features of a shader variant, see
+// ShaderVariants.
+type ShaderFeatures uint32
+
+// ShaderFeature describes a single feature of a shader variant, which
+// corresponds to a macro defined in it's GLSL sources (see Shader.Defines).
+type ShaderFeature struct {
+	// The name of the macro, e.g. "USE_FOG" or "NUM_LIGHTS".
+	Define string
+
+	// The number of bits of the feature in the bitmask. If zero the feature
+	// is a toggle occupying a single bit, and the macro is defined (without a
+	// value) only if the bit is set. Otherwise the feature is an unsigned
+	// integer (e.g. a number of lights) and the macro is always defined with
+	// it's value.
+	Bits uint
+}
+
+// ShaderVariants manages the permutations (variants) of a single shader
+// program, where each variant is the base shader compiled with a different
+// set of features (e.g. skinning on or off, fog on or off, or N lights)
+// defined as macros. Variants are created from the base shader as they are
+// first requested, and are cached such that each is only compiled once.
+//
+// It is safe to use shader variants from multiple goroutines concurrently.
+type ShaderVariants struct {
+	// Select, if non-nil, returns the features required to draw the given
+	// object (e.g. skinning if it's meshes have joint weights), see the Apply
+	// method. The object's write lock is held while it is called.
+	Select func(o *Object) ShaderFeatures
+
+	access   sync.Mutex
+	base     *Shader
+	features []ShaderFeature
+	shifts   []uint
+	variants map[ShaderFeatures]*Shader
+}
+
+// NewShaderVariants returns a new set of variants of the given base shader
+// with the given features, which are assigned bits of the feature bitmask in
+// the order given.
+//
+// The base shader is copied for each variant (see Shader.Copy), it should not
+// be modified afterwards. A panic occurs if the features require more than 32
+// bits.
+func NewShaderVariants(base *Shader, features ...ShaderFeature) *ShaderVariants {
+	v := &ShaderVariants{
+		base:     base,
+		features: features,
+		shifts:   make([]uint, len(features)),
+		variants: make(map[ShaderFeatures]*Shader),
+	}
+	var shift uint
+	for i, f := range features {
+		v.shifts[i] = shift
+		shift += f.bits()
+	}
+	if shift > 32 {
+		panic("NewShaderVariants(): features require more than 32 bits")
+	}
+	return v
+}
+
+func (f ShaderFeature) bits() uint {
+	if f.Bits == 0 {
+		return 1
+	}
+	return f.Bits
+}
+
+// Mask returns the bitmask of the named feature with the given value, which
+// is one or zero for toggles (any non-zero value is treated as one). Masks of
+// different features are combined using bitwise OR:
+//  mask := v.Mask("USE_FOG", 1) | v.Mask("NUM_LIGHTS", 4)
+//
+// A panic occurs if there is no such feature or if the value does not fit in
+// the feature's bits.
+func (v *ShaderVariants) Mask(define string, value int) ShaderFeatures {
+	for i, f := range v.features {
+		if f.Define != define {
+			continue
+		}
+		if f.Bits == 0 && value != 0 {
+			value = 1
+		}
+		if value < 0 || uint64(value) >= 1<<f.bits() {
+			panic(fmt.Sprintf("Mask(): value %d of feature %q out of range", value, define))
+		}
+		return ShaderFeatures(value) << v.shifts[i]
+	}
+	panic(fmt.Sprintf("Mask(): no such feature %q", define))
+}
+
+// defines returns the macros defined by the given features.
+func (v *ShaderVariants) defines(mask ShaderFeatures) map[string]string {
+	d := make(map[string]string, len(v.features))
+	for i, f := range v.features {
+		value := uint64(mask>>v.shifts[i]) & (1<<f.bits() - 1)
+		switch {
+		case f.Bits > 0:
+			d[f.Define] = strconv.FormatUint(value, 10)
+		case value != 0:
+			d[f.Define] = ""
+		}
+	}
+	return d
+}
+
+// Shader returns the variant of the shader with the given features, creating
+// it from the base shader if it does not exist yet. It's name is the name of
+// the base shader followed by the features in order, e.g.
+// "lit[USE_FOG,NUM_LIGHTS=4]".
+func (v *ShaderVariants) Shader(mask ShaderFeatures) *Shader {
+	v.access.Lock()
+	defer v.access.Unlock()
+	if s, ok := v.variants[mask]; ok {
+		return s
+	}
+
+	v.base.RLock()
+	s := v.base.Copy()
+	v.base.RUnlock()
+	defines := v.defines(mask)
+	var names []string
+	for _, f := range v.features {
+		value, ok := defines[f.Define]
+		if !ok {
+			continue
+		}
+		s.Defines[f.Define] = value
+		if value != "" {
+			names = append(names, f.Define+"="+value)
+		} else {
+			names = append(names, f.Define)
+		}
+	}
+	s.Name = fmt.Sprintf("%s[%s]", s.Name, strings.Join(names, ","))
+	v.variants[mask] = s
+	return s
+}
+
+// Apply assigns each object the variant of the shader with the features
+// chosen by the Select function (or no features if it is nil).
+//
+// This method properly write-locks each object.
+func (v *ShaderVariants) Apply(objects ...*Object) {
+	for _, o := range objects {
+		o.Lock()
+		var mask ShaderFeatures
+		if v.Select != nil {
+			mask = v.Select(o)
+		}
+		o.Shader = v.Shader(mask)
+		o.Unlock()
+	}
+}
+
+// Preload loads (i.e. compiles) the variants with the given features using
+// the renderer, such that they are ready before they are first drawn (e.g.
+// during a loading screen). It blocks until each variant is loaded.
+func (v *ShaderVariants) Preload(r Renderer, masks ...ShaderFeatures) {
+	done := make(chan *Shader, len(masks))
+	for _, mask := range masks {
+		r.LoadShader(v.Shader(mask), done)
+	}
+	for range masks {
+		<-done
+	}
+}
+
+// Len returns the number of variants that have been created.
+func (v *ShaderVariants) Len() int {
+	v.access.Lock()
+	defer v.access.Unlock()
+	return len(v.variants)
+}
+
+// Destroy destroys every variant of the shader (see Shader.Destroy), but not
+// the base shader. Variants are created again as they are requested.
+func (v *ShaderVariants) Destroy() {
+	v.access.Lock()
+	for mask, s := range v.variants {
+		s.Destroy()
+		delete(v.variants, mask)
+	}
+	v.access.Unlock()
+}
@@ -0,0 +1,69 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "testing"
+
+func TestShaderVariants(t *testing.T) {
+	base := NewShader("lit")
+	base.GLSLVert = []byte("#version 120\nvoid main() {}\n")
+	base.GLSLFrag = []byte("#version 120\nvoid main() {}\n")
+	v := NewShaderVariants(base,
+		ShaderFeature{Define: "SKINNING"},
+		ShaderFeature{Define: "USE_FOG"},
+		ShaderFeature{Define: "NUM_LIGHTS", Bits: 3},
+	)
+
+	mask := v.Mask("USE_FOG", 1) | v.Mask("NUM_LIGHTS", 4)
+	if mask != 2|4<<2 {
+		t.Fatalf("mask = %b", mask)
+	}
+	s := v.Shader(mask)
+	if s.Name != "lit[USE_FOG,NUM_LIGHTS=4]" {
+		t.Errorf("name = %q", s.Name)
+	}
+	if _, ok := s.Defines["SKINNING"]; ok || s.Defines["USE_FOG"] != "" || s.Defines["NUM_LIGHTS"] != "4" {
+		t.Errorf("defines = %v", s.Defines)
+	}
+	if len(base.Defines) != 0 {
+		t.Error("base shader was modified")
+	}
+	if v.Shader(mask) != s {
+		t.Error("variant was not cached")
+	}
+
+	skinned := NewObject()
+	skinned.Category = "skinned"
+	plain := NewObject()
+	v.Select = func(o *Object) ShaderFeatures {
+		if o.Category == "skinned" {
+			return v.Mask("SKINNING", 1)
+		}
+		return 0
+	}
+	v.Apply(skinned, plain)
+	if skinned.Shader.Name != "lit[SKINNING,NUM_LIGHTS=0]" || plain.Shader.Name != "lit[NUM_LIGHTS=0]" {
+		t.Errorf("got shaders %q and %q", skinned.Shader.Name, plain.Shader.Name)
+	}
+
+	v.Preload(Nil(), mask)
+	if !s.Loaded {
+		t.Error("Preload did not load the variant")
+	}
+	if v.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", v.Len())
+	}
+	v.Destroy()
+	if v.Len() != 0 {
+		t.Errorf("Len() after Destroy = %d, want 0", v.Len())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for out of range value")
+		}
+	}()
+	v.Mask("NUM_LIGHTS", 8)
+}
@@ -0,0 +1,187 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import "math"
+
+// Half represents a 16-bit (half-precision) IEEE 754 floating point number,
+// for storing vertex attributes (see VertexAttrib) at half the memory and
+// bandwidth of 32-bit floats.
+type Half uint16
+
+// NewHalf returns the half-precision floating point number closest to f,
+// rounding to nearest even. Values too large to be represented become
+// infinity, and values too small become zero.
+func NewHalf(f float32) Half {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int(b>>23) & 0xff
+	mant := b & 0x7fffff
+	if exp == 0xff {
+		// Infinity or NaN.
+		if mant != 0 {
+			return Half(sign | 0x7e00)
+		}
+		return Half(sign | 0x7c00)
+	}
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		// Overflow, becomes infinity.
+		return Half(sign | 0x7c00)
+	}
+	if e <= 0 {
+		// Denormal (or too small, becoming zero).
+		if e < -10 {
+			return Half(sign)
+		}
+		mant |= 0x800000
+		shift := uint(14 - e)
+		h := mant >> shift
+		rem, half := mant&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > half || (rem == half && h&1 != 0) {
+			h++
+		}
+		return Half(sign | uint16(h))
+	}
+	h := uint32(e)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || (rem == 0x1000 && h&1 != 0) {
+		// May carry into the exponent, which is still correct.
+		h++
+	}
+	return Half(sign | uint16(h))
+}
+
+// Float32 returns the 32-bit floating point representation of h.
+func (h Half) Float32() float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		f := float32(mant) / (1 << 24)
+		if sign != 0 {
+			return -f
+		}
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp-15+127)<<23 | mant<<13)
+}
+
+// Half2 and Half4 represent two and four component half-precision vectors,
+// e.g. for texture coordinates.
+type (
+	Half2 [2]Half
+	Half4 [4]Half
+)
+
+// UNorm8x4 represents a four component vector of unsigned normalized 8-bit
+// integers, where 0 maps to 0.0 and 255 maps to 1.0 in shaders, e.g. for
+// vertex colors.
+type UNorm8x4 [4]uint8
+
+// ConvertUNorm8x4 converts the 32-bit Vec4 (whose components are clamped to
+// the range of 0.0 to 1.0) to the closest unsigned normalized vector.
+func ConvertUNorm8x4(v Vec4) UNorm8x4 {
+	return UNorm8x4{unorm8(v.X), unorm8(v.Y), unorm8(v.Z), unorm8(v.W)}
+}
+
+// Vec4 converts this unsigned normalized vector to a 32-bit Vec4.
+func (u UNorm8x4) Vec4() Vec4 {
+	return Vec4{
+		float32(u[0]) / 255,
+		float32(u[1]) / 255,
+		float32(u[2]) / 255,
+		float32(u[3]) / 255,
+	}
+}
+
+func unorm8(f float32) uint8 {
+	return uint8(math.Floor(float64(clampf(f, 0, 1))*255 + 0.5))
+}
+
+// SNorm16x2 and SNorm16x4 represent two and four component vectors of signed
+// normalized 16-bit integers, where -32767 maps to -1.0 and 32767 maps to 1.0
+// in shaders, e.g. for texture coordinates or positions within a known
+// range.
+type (
+	SNorm16x2 [2]int16
+	SNorm16x4 [4]int16
+)
+
+// ConvertSNorm16x4 converts the 32-bit Vec4 (whose components are clamped to
+// the range of -1.0 to 1.0) to the closest signed normalized vector.
+func ConvertSNorm16x4(v Vec4) SNorm16x4 {
+	return SNorm16x4{snorm16(v.X), snorm16(v.Y), snorm16(v.Z), snorm16(v.W)}
+}
+
+// Vec4 converts this signed normalized vector to a 32-bit Vec4.
+func (s SNorm16x4) Vec4() Vec4 {
+	return Vec4{
+		unsnorm(int32(s[0]), 32767),
+		unsnorm(int32(s[1]), 32767),
+		unsnorm(int32(s[2]), 32767),
+		unsnorm(int32(s[3]), 32767),
+	}
+}
+
+func snorm16(f float32) int16 {
+	return int16(math.Floor(float64(clampf(f, -1, 1))*32767 + 0.5))
+}
+
+// unsnorm converts the signed normalized integer v, whose maximum is max, to
+// a float (clamping to -1.0, as the most negative integer has no positive
+// counterpart).
+func unsnorm(v, max int32) float32 {
+	return clampf(float32(v)/float32(max), -1, 1)
+}
+
+func clampf(f, min, max float32) float32 {
+	if f < min {
+		return min
+	}
+	if f > max {
+		return max
+	}
+	return f
+}
+
+// Int2101010 represents a four component vector of signed normalized
+// integers packed into 32 bits: 10 bits each for the X, Y, and Z components
+// (in the least significant bits, in that order) and 2 bits for the W
+// component. It is typically used to store normals and tangents at a
+// quarter of the memory of a Vec3.
+type Int2101010 uint32
+
+// ConvertInt2101010 converts the 32-bit Vec4 (whose components are clamped to
+// the range of -1.0 to 1.0) to the closest packed vector.
+func ConvertInt2101010(v Vec4) Int2101010 {
+	pack := func(f float32, max float64, bits uint) uint32 {
+		i := int32(math.Floor(float64(clampf(f, -1, 1))*max + 0.5))
+		return uint32(i) & (1<<bits - 1)
+	}
+	return Int2101010(pack(v.X, 511, 10) |
+		pack(v.Y, 511, 10)<<10 |
+		pack(v.Z, 511, 10)<<20 |
+		pack(v.W, 1, 2)<<30)
+}
+
+// Vec4 converts this packed vector to a 32-bit Vec4.
+func (p Int2101010) Vec4() Vec4 {
+	unpack := func(shift, bits uint, max int32) float32 {
+		// Shift the field into the most significant bits and back, sign
+		// extending it.
+		v := int32(uint32(p)<<(32-shift-bits)) >> (32 - bits)
+		return unsnorm(v, max)
+	}
+	return Vec4{
+		unpack(0, 10, 511),
+		unpack(10, 10, 511),
+		unpack(20, 10, 511),
+		unpack(30, 2, 1),
+	}
+}
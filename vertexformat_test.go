@@ -0,0 +1,81 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHalf(t *testing.T) {
+	tests := []struct {
+		f     float32
+		h     Half
+		exact bool
+	}{
+		{0, 0x0000, true},
+		{1, 0x3c00, true},
+		{-2, 0xc000, true},
+		{0.5, 0x3800, true},
+		{65504, 0x7bff, true},
+		{float32(math.Inf(-1)), 0xfc00, true},
+		{5.960464477539063e-08, 0x0001, true},
+		{1e6, 0x7c00, false},
+		{1e-10, 0x0000, false},
+	}
+	for _, tst := range tests {
+		if got := NewHalf(tst.f); got != tst.h {
+			t.Errorf("NewHalf(%v) = %#04x, want %#04x", tst.f, got, tst.h)
+		}
+		if got := tst.h.Float32(); tst.exact && got != tst.f {
+			t.Errorf("Half(%#04x).Float32() = %v, want %v", tst.h, got, tst.f)
+		}
+	}
+	if f := NewHalf(float32(math.NaN())).Float32(); f == f {
+		t.Errorf("NaN round trip gave %v", f)
+	}
+
+	// Every finite half must round trip exactly.
+	for h := Half(0); h < 0x7c00; h++ {
+		if got := NewHalf(h.Float32()); got != h {
+			t.Fatalf("round trip of %#04x gave %#04x", h, got)
+		}
+	}
+}
+
+func TestNormalizedFormats(t *testing.T) {
+	v := Vec4{0.5, -0.25, 1, -1}
+	near := func(a, b Vec4, eps float32) bool {
+		d := [4]float32{a.X - b.X, a.Y - b.Y, a.Z - b.Z, a.W - b.W}
+		for _, c := range d {
+			if c < -eps || c > eps {
+				return false
+			}
+		}
+		return true
+	}
+	if got := ConvertSNorm16x4(v).Vec4(); !near(got, v, 1.0/32767) {
+		t.Errorf("SNorm16x4 round trip gave %v, want %v", got, v)
+	}
+	if got := ConvertInt2101010(v).Vec4(); !near(got, v, 1.0/511) {
+		t.Errorf("Int2101010 round trip gave %v, want %v", got, v)
+	}
+	u := Vec4{0, 0.5, 1, 2}
+	want := Vec4{0, 0.5, 1, 1}
+	if got := ConvertUNorm8x4(u).Vec4(); !near(got, want, 1.0/255) {
+		t.Errorf("UNorm8x4 round trip gave %v, want %v", got, want)
+	}
+}
+
+func TestVertexAttribCopy(t *testing.T) {
+	a := VertexAttrib{Data: [][]Vec3{{{1, 2, 3}}}}
+	if c, ok := a.Copy().Data.([][]Vec3); !ok || c[0][0] != (Vec3{1, 2, 3}) {
+		t.Fatalf("got copy %v", a.Copy().Data)
+	}
+	b := VertexAttrib{Data: []Int2101010{1, 2}}
+	if c, ok := b.Copy().Data.([]Int2101010); !ok || len(c) != 2 {
+		t.Fatalf("got copy %v", b.Copy().Data)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"fmt"
+	"image"
+)
+
+// YUVFormat represents a planar YUV (Y'CbCr) pixel format, as produced by
+// video decoders and webcams.
+type YUVFormat uint8
+
+// String returns a string representation of this YUVFormat.
+// e.g. NV12 -> "NV12"
+func (f YUVFormat) String() string {
+	switch f {
+	case I420:
+		return "I420"
+	case NV12:
+		return "NV12"
+	}
+	return fmt.Sprintf("YUVFormat(%d)", f)
+}
+
+const (
+	// I420 stores the Y, Cb, and Cr components in three separate planes.
+	// This is the layout of an *image.YCbCr (for any subsample ratio).
+	I420 YUVFormat = iota
+
+	// NV12 stores the Y component in one plane, and the Cb and Cr components
+	// interleaved in a second plane at half the horizontal and vertical
+	// resolution.
+	NV12
+)
+
+// YUVFrame represents a single YUV frame (e.g. of a video) whose planes are
+// stored in separate textures, such that the conversion to RGB may be done on
+// the GPU (see the Shader method) instead of on the CPU.
+type YUVFrame struct {
+	// The format of the frame.
+	Format YUVFormat
+
+	// The bounds of the frame (i.e. of the Y plane).
+	Bounds image.Rectangle
+
+	// The textures for each plane of the frame, in the order Y, Cb, Cr for
+	// I420 frames, or Y, CbCr for NV12 frames. They are meant to be assigned
+	// to an object's Textures slice directly.
+	Planes []*Texture
+}
+
+// planeTexture returns a new texture whose source is the given plane of
+// single-byte samples (which is not copied).
+//
+// This package defines no single-channel texture format, so planes use RGB:
+// the narrowest uncompressed format, which every renderer stores (possibly
+// as RGBA, see NegotiateTexFormat). Renderers convert the *image.Gray source
+// by replicating each sample into the red, green, and blue channels, and the
+// shaders only read the red one. This costs three (or four) times the memory
+// of a single-channel texture.
+func planeTexture(pix []uint8, stride, w, h int, filter TexFilter) *Texture {
+	t := NewTexture()
+	t.Source = &image.Gray{
+		Pix:    pix,
+		Stride: stride,
+		Rect:   image.Rect(0, 0, w, h),
+	}
+	t.Bounds = t.Source.Bounds()
+	t.Format = RGB
+	t.MinFilter = filter
+	t.MagFilter = filter
+	t.WrapU = Clamp
+	t.WrapV = Clamp
+	return t
+}
+
+// NewYUVFrame returns a new I420 frame whose planes are those of the given
+// image. The pixel data of the image is not copied, so the image must not be
+// modified until the textures are loaded.
+func NewYUVFrame(img *image.YCbCr) *YUVFrame {
+	r := img.Rect
+	w, h := r.Dx(), r.Dy()
+
+	// Determine the size of the chroma planes.
+	var cw, ch int
+	switch img.SubsampleRatio {
+	case image.YCbCrSubsampleRatio422:
+		cw, ch = (r.Max.X+1)/2-r.Min.X/2, h
+	case image.YCbCrSubsampleRatio420:
+		cw, ch = (r.Max.X+1)/2-r.Min.X/2, (r.Max.Y+1)/2-r.Min.Y/2
+	case image.YCbCrSubsampleRatio440:
+		cw, ch = w, (r.Max.Y+1)/2-r.Min.Y/2
+	case image.YCbCrSubsampleRatio411:
+		cw, ch = (r.Max.X+3)/4-r.Min.X/4, h
+	case image.YCbCrSubsampleRatio410:
+		cw, ch = (r.Max.X+3)/4-r.Min.X/4, (r.Max.Y+1)/2-r.Min.Y/2
+	default:
+		cw, ch = w, h
+	}
+
+	yi := img.YOffset(r.Min.X, r.Min.Y)
+	ci := img.COffset(r.Min.X, r.Min.Y)
+	return &YUVFrame{
+		Format: I420,
+		Bounds: image.Rect(0, 0, w, h),
+		Planes: []*Texture{
+			planeTexture(img.Y[yi:], img.YStride, w, h, Linear),
+			planeTexture(img.Cb[ci:], img.CStride, cw, ch, Linear),
+			planeTexture(img.Cr[ci:], img.CStride, cw, ch, Linear),
+		},
+	}
+}
+
+// NewNV12Frame returns a new NV12 frame of the given size whose planes are
+// the given Y plane (w*h bytes) and interleaved CbCr plane (w*h/2 bytes),
+// as produced by most hardware video decoders. The pixel data is not copied,
+// so it must not be modified until the textures are loaded.
+func NewNV12Frame(y, uv []uint8, w, h int) *YUVFrame {
+	cw, ch := (w+1)/2, (h+1)/2
+	return &YUVFrame{
+		Format: NV12,
+		Bounds: image.Rect(0, 0, w, h),
+		Planes: []*Texture{
+			planeTexture(y, w, w, h, Linear),
+
+			// The CbCr plane is treated as a single-channel texture of twice
+			// the width, which the shader samples at exact texel centers.
+			planeTexture(uv, 2*cw, 2*cw, ch, Nearest),
+		},
+	}
+}
+
+// Shader returns a new shader that converts the frame's planes (bound as
+// Texture0, Texture1, and so on) into RGB using the ITU-R BT.601 (limited
+// range) conversion, which is what most video and webcams use.
+func (f *YUVFrame) Shader() *Shader {
+	s := NewShader("YUV-" + f.Format.String())
+	s.GLSLVert = append(s.GLSLVert, yuvVert...)
+	switch f.Format {
+	case NV12:
+		s.GLSLFrag = append(s.GLSLFrag, yuvFragNV12...)
+		s.Inputs["ChromaWidth"] = float32((f.Bounds.Dx() + 1) / 2)
+	default:
+		s.GLSLFrag = append(s.GLSLFrag, yuvFragI420...)
+	}
+	return s
+}
+
+var yuvVert = []byte(`
+#version 120
+
+attribute vec3 Vertex;
+attribute vec2 TexCoord0;
+
+uniform mat4 MVP;
+
+varying vec2 tc0;
+
+void main()
+{
+	tc0 = TexCoord0;
+	gl_Position = MVP * vec4(Vertex, 1.0);
+}
+`)
+
+var yuvFragI420 = []byte(`
+#version 120
+
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+uniform sampler2D Texture1;
+uniform sampler2D Texture2;
+
+vec3 yuvToRGB(float y, float u, float v)
+{
+	y = 1.164 * (y - 0.0625);
+	u = u - 0.5;
+	v = v - 0.5;
+	return vec3(
+		y + 1.596 * v,
+		y - 0.391 * u - 0.813 * v,
+		y + 2.018 * u
+	);
+}
+
+void main()
+{
+	float y = texture2D(Texture0, tc0).r;
+	float u = texture2D(Texture1, tc0).r;
+	float v = texture2D(Texture2, tc0).r;
+	gl_FragColor = vec4(clamp(yuvToRGB(y, u, v), 0.0, 1.0), 1.0);
+}
+`)
+
+var yuvFragNV12 = []byte(`
+#version 120
+
+varying vec2 tc0;
+
+uniform sampler2D Texture0;
+uniform sampler2D Texture1;
+uniform float ChromaWidth;
+
+vec3 yuvToRGB(float y, float u, float v)
+{
+	y = 1.164 * (y - 0.0625);
+	u = u - 0.5;
+	v = v - 0.5;
+	return vec3(
+		y + 1.596 * v,
+		y - 0.391 * u - 0.813 * v,
+		y + 2.018 * u
+	);
+}
+
+void main()
+{
+	float texel = 1.0 / (2.0 * ChromaWidth);
+	float x = (floor(tc0.x * ChromaWidth) * 2.0 + 0.5) * texel;
+
+	float y = texture2D(Texture0, tc0).r;
+	float u = texture2D(Texture1, vec2(x, tc0.y)).r;
+	float v = texture2D(Texture1, vec2(x + texel, tc0.y)).r;
+	gl_FragColor = vec4(clamp(yuvToRGB(y, u, v), 0.0, 1.0), 1.0);
+}
+`)
@@ -0,0 +1,117 @@
+// Copyright 2014 The Azul3D Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gfx
+
+import (
+	"image"
+	"testing"
+)
+
+func TestYUVFramePlaneSizes(t *testing.T) {
+	tests := []struct {
+		ratio  image.YCbCrSubsampleRatio
+		r      image.Rectangle
+		cw, ch int
+	}{
+		{image.YCbCrSubsampleRatio444, image.Rect(0, 0, 5, 3), 5, 3},
+		{image.YCbCrSubsampleRatio422, image.Rect(0, 0, 5, 3), 3, 3},
+		{image.YCbCrSubsampleRatio420, image.Rect(0, 0, 5, 3), 3, 2},
+		{image.YCbCrSubsampleRatio440, image.Rect(0, 0, 5, 3), 5, 2},
+		{image.YCbCrSubsampleRatio411, image.Rect(0, 0, 5, 3), 2, 3},
+		{image.YCbCrSubsampleRatio410, image.Rect(0, 0, 5, 3), 2, 2},
+
+		// Odd origins, as of sub-images, cover an extra chroma sample.
+		{image.YCbCrSubsampleRatio420, image.Rect(1, 1, 5, 5), 3, 3},
+		{image.YCbCrSubsampleRatio411, image.Rect(3, 0, 7, 1), 2, 1},
+	}
+	for _, tst := range tests {
+		img := image.NewYCbCr(image.Rect(0, 0, 8, 8), tst.ratio).SubImage(tst.r).(*image.YCbCr)
+		f := NewYUVFrame(img)
+		w, h := tst.r.Dx(), tst.r.Dy()
+		if f.Format != I420 || f.Bounds != image.Rect(0, 0, w, h) || len(f.Planes) != 3 {
+			t.Errorf("%v %v: got format %v bounds %v and %d planes", tst.ratio, tst.r, f.Format, f.Bounds, len(f.Planes))
+			continue
+		}
+		want := []image.Rectangle{
+			image.Rect(0, 0, w, h),
+			image.Rect(0, 0, tst.cw, tst.ch),
+			image.Rect(0, 0, tst.cw, tst.ch),
+		}
+		for i, p := range f.Planes {
+			if p.Bounds != want[i] || p.Source.Bounds() != want[i] {
+				t.Errorf("%v %v: plane %d has bounds %v, want %v", tst.ratio, tst.r, i, p.Bounds, want[i])
+			}
+		}
+	}
+}
+
+func TestYUVFrameStride(t *testing.T) {
+	// A sub-image's planes are views into the larger image's planes, whose
+	// strides exceed the width of the sub-image.
+	full := image.NewYCbCr(image.Rect(0, 0, 8, 8), image.YCbCrSubsampleRatio420)
+	for i := range full.Y {
+		full.Y[i] = uint8(i)
+	}
+	for i := range full.Cb {
+		full.Cb[i] = uint8(100 + i)
+		full.Cr[i] = uint8(200 + i)
+	}
+	img := full.SubImage(image.Rect(3, 1, 8, 6)).(*image.YCbCr)
+	f := NewYUVFrame(img)
+
+	sample := func(p *Texture, x, y int) uint8 {
+		return p.Source.(*image.Gray).GrayAt(x, y).Y
+	}
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 5; x++ {
+			if got, want := sample(f.Planes[0], x, y), full.Y[full.YOffset(3+x, 1+y)]; got != want {
+				t.Fatalf("Y(%d, %d) = %d, want %d", x, y, got, want)
+			}
+		}
+	}
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			ci := full.COffset(3+2*x, 1+2*y)
+			if got, want := sample(f.Planes[1], x, y), full.Cb[ci]; got != want {
+				t.Fatalf("Cb(%d, %d) = %d, want %d", x, y, got, want)
+			}
+			if got, want := sample(f.Planes[2], x, y), full.Cr[ci]; got != want {
+				t.Fatalf("Cr(%d, %d) = %d, want %d", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestNV12Frame(t *testing.T) {
+	// An odd-sized frame rounds the chroma plane up.
+	const w, h = 5, 3
+	y := make([]uint8, w*h)
+	uv := make([]uint8, 2*3*2)
+	for i := range uv {
+		uv[i] = uint8(i)
+	}
+	f := NewNV12Frame(y, uv, w, h)
+	if f.Format != NV12 || f.Bounds != image.Rect(0, 0, w, h) || len(f.Planes) != 2 {
+		t.Fatalf("got format %v bounds %v and %d planes", f.Format, f.Bounds, len(f.Planes))
+	}
+	if b := f.Planes[0].Bounds; b != image.Rect(0, 0, w, h) {
+		t.Errorf("Y plane has bounds %v", b)
+	}
+	if b := f.Planes[1].Bounds; b != image.Rect(0, 0, 6, 2) {
+		t.Errorf("CbCr plane has bounds %v, want 6x2", b)
+	}
+
+	// Cb and Cr samples are interleaved along each row of the CbCr plane.
+	cbcr := f.Planes[1].Source.(*image.Gray)
+	if cb, cr := cbcr.GrayAt(2, 1).Y, cbcr.GrayAt(3, 1).Y; cb != 8 || cr != 9 {
+		t.Errorf("got Cb %d and Cr %d of the second chroma sample of row 1, want 8 and 9", cb, cr)
+	}
+	if f.Planes[1].MinFilter != Nearest {
+		t.Errorf("CbCr plane uses %v filtering, want Nearest", f.Planes[1].MinFilter)
+	}
+	if cw := f.Shader().Inputs["ChromaWidth"]; cw != float32(3) {
+		t.Errorf("got ChromaWidth %v, want 3", cw)
+	}
+}